@@ -0,0 +1,26 @@
+package activity
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cschleiden/go-workflows/internal/activity"
+)
+
+// ErrResultPending can be returned by an activity to signal that it will be completed asynchronously, using
+// the token returned by TaskToken and Client.CompleteActivity.
+var ErrResultPending = activity.ErrResultPending
+
+// TaskToken returns a token for completing the current activity execution asynchronously. Once a token has
+// been requested, the activity task is not retried when the worker executing it fails, only its
+// ScheduleToCloseTimeout applies.
+//
+// Local activities and activities executed by the workflow tester cannot be completed asynchronously.
+func TaskToken(ctx context.Context) (string, error) {
+	as := activity.GetActivityState(ctx)
+	if as.TaskToken == nil {
+		return "", errors.New("activity cannot be completed asynchronously")
+	}
+
+	return as.TaskToken()
+}
@@ -0,0 +1,55 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cschleiden/go-workflows/internal/activity"
+)
+
+// ErrHeartbeatTimeout is matched by the error an activity fails with if it does not record a heartbeat
+// within the HeartbeatTimeout of its ActivityOptions.
+var ErrHeartbeatTimeout = activity.ErrHeartbeatTimeout
+
+// RecordHeartbeat records a heartbeat for the current activity execution. If the activity was scheduled
+// with a HeartbeatTimeout, it has to record heartbeats at least that often, otherwise it is failed and
+// retried according to its RetryOptions.
+//
+// details can be used to record progress, pass nil to only record liveness. Details are persisted in the
+// backend. If the activity fails or its worker is lost, the details of the last heartbeat are available to
+// the next attempt via LastHeartbeatDetails.
+func RecordHeartbeat(ctx context.Context, details any) error {
+	as := activity.GetActivityState(ctx)
+
+	if details == nil {
+		return as.RecordHeartbeat(nil)
+	}
+
+	p, err := as.Converter.To(details)
+	if err != nil {
+		return fmt.Errorf("converting heartbeat details: %w", err)
+	}
+
+	if err := as.RecordHeartbeat(p); err != nil {
+		return fmt.Errorf("recording heartbeat: %w", err)
+	}
+
+	return nil
+}
+
+// LastHeartbeatDetails returns the details of the last heartbeat recorded by a previous attempt of this
+// activity. If no heartbeat with details was recorded, the zero value is returned.
+func LastHeartbeatDetails[T any](ctx context.Context) (T, error) {
+	as := activity.GetActivityState(ctx)
+
+	var t T
+	if as.LastHeartbeatDetails == nil {
+		return t, nil
+	}
+
+	if err := as.Converter.From(as.LastHeartbeatDetails, &t); err != nil {
+		return t, fmt.Errorf("converting heartbeat details: %w", err)
+	}
+
+	return t, nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/cschleiden/go-workflows/backend/history"
 	"github.com/cschleiden/go-workflows/backend/metrics"
+	"github.com/cschleiden/go-workflows/backend/payload"
 	"github.com/cschleiden/go-workflows/core"
 	"github.com/cschleiden/go-workflows/workflow"
 	"go.opentelemetry.io/otel/trace"
@@ -15,6 +16,9 @@ import (
 var ErrInstanceNotFound = errors.New("workflow instance not found")
 var ErrInstanceAlreadyExists = errors.New("workflow instance already exists")
 var ErrInstanceNotFinished = errors.New("workflow instance is not finished")
+var ErrInstanceNotActive = errors.New("workflow instance is not active")
+var ErrInstanceTerminated = errors.New("workflow instance terminated")
+var ErrTaskNotFound = errors.New("task not found")
 
 type ErrNotSupported struct {
 	Message string
@@ -34,15 +38,29 @@ type Backend interface {
 	// CancelWorkflowInstance cancels a running workflow instance
 	CancelWorkflowInstance(ctx context.Context, instance *workflow.Instance, cancelEvent *history.Event) error
 
+	// TerminateWorkflowInstance terminates a running workflow instance without executing any more workflow code.
+	//
+	// The given event is added to the instance's history, the instance is marked as finished, and any pending
+	// activities and timers are abandoned. If the instance is a sub-workflow, the parent is notified that the
+	// sub-workflow failed with ErrInstanceTerminated. If the instance is not active, ErrInstanceNotActive is returned.
+	TerminateWorkflowInstance(ctx context.Context, instance *workflow.Instance, terminateEvent *history.Event) error
+
 	// RemoveWorkflowInstance removes a workflow instance
 	RemoveWorkflowInstance(ctx context.Context, instance *workflow.Instance) error
 
 	// RemoveWorkflowInstances removes multiple workflow instances
 	RemoveWorkflowInstances(ctx context.Context, options ...RemovalOption) error
 
+	// GetLatestWorkflowInstance returns the most recently created execution of the workflow instance with the
+	// given id, regardless of its state. If no execution exists, ErrInstanceNotFound is returned.
+	GetLatestWorkflowInstance(ctx context.Context, instanceID string) (*workflow.Instance, error)
+
 	// GetWorkflowInstanceState returns the state of the given workflow instance
 	GetWorkflowInstanceState(ctx context.Context, instance *workflow.Instance) (core.WorkflowInstanceState, error)
 
+	// ListWorkflowInstances returns the workflow instances matching the given query, newest first.
+	ListWorkflowInstances(ctx context.Context, query *WorkflowInstanceQuery) (*WorkflowInstanceQueryResult, error)
+
 	// GetWorkflowInstanceHistory returns the workflow history for the given instance. When lastSequenceID
 	// is given, only events after that event are returned. Otherwise the full history is returned.
 	GetWorkflowInstanceHistory(ctx context.Context, instance *workflow.Instance, lastSequenceID *int64) ([]*history.Event, error)
@@ -52,6 +70,11 @@ type Backend interface {
 	// If the given instance does not exist, it will return an error
 	SignalWorkflow(ctx context.Context, instanceID string, event *history.Event) error
 
+	// SignalWithStartWorkflowInstance atomically signals the active execution of the given workflow instance or,
+	// if there is none, creates the instance using the given started event. A newly created instance receives
+	// the signal as the first event after the started event. Returns the instance that received the signal.
+	SignalWithStartWorkflowInstance(ctx context.Context, instance *workflow.Instance, startedEvent, signalEvent *history.Event) (*workflow.Instance, error)
+
 	// PrepareWorkflowQueues prepares workflow queues for later consumption using this backend instane
 	PrepareWorkflowQueues(ctx context.Context, queues []workflow.Queue) error
 
@@ -79,7 +102,18 @@ type Backend interface {
 	// ExtendActivityTask extends the lock of an activity task
 	ExtendActivityTask(ctx context.Context, task *ActivityTask) error
 
-	// CompleteActivityTask completes an activity task retrieved using GetActivityTask
+	// SetActivityTaskPending marks an activity task as pending completion from outside of the worker. Pending
+	// activity tasks are not redelivered when their lock expires. Marking a task that has already been
+	// completed is not an error.
+	SetActivityTaskPending(ctx context.Context, task *ActivityTask) error
+
+	// RecordActivityHeartbeat persists the details of the latest heartbeat of an activity task. They are returned
+	// with the task if it is redelivered and discarded when the task is completed. Returns ErrTaskNotFound if the
+	// task has been completed or is not owned by this worker anymore.
+	RecordActivityHeartbeat(ctx context.Context, task *ActivityTask, details payload.Payload) error
+
+	// CompleteActivityTask completes an activity task retrieved using GetActivityTask. Pending activity tasks
+	// can be completed by any backend instance.
 	CompleteActivityTask(ctx context.Context, task *ActivityTask, result *history.Event) error
 
 	// GetStats returns stats about the backend
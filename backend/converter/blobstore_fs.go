@@ -0,0 +1,94 @@
+package converter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+type filesystemBlobStore struct {
+	dir string
+}
+
+var _ BlobStore = (*filesystemBlobStore)(nil)
+
+// NewFilesystemBlobStore returns a blob store that keeps every blob in a file in the given directory. The
+// directory is created if it does not exist.
+func NewFilesystemBlobStore(dir string) (BlobStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating blob directory: %w", err)
+	}
+
+	return &filesystemBlobStore{dir: dir}, nil
+}
+
+func (s *filesystemBlobStore) Put(ctx context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that readers never see partial blobs
+	f, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return nil
+}
+
+func (s *filesystemBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %v", ErrBlobNotFound, key)
+	}
+
+	return data, err
+}
+
+func (s *filesystemBlobStore) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		path, err := s.path(key)
+		if err != nil {
+			return err
+		}
+
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *filesystemBlobStore) path(key string) (string, error) {
+	if key == "" || key == "." || key == ".." || filepath.Base(key) != key || key[0] == '.' {
+		return "", fmt.Errorf("invalid blob key: %q", key)
+	}
+
+	return filepath.Join(s.dir, key), nil
+}
@@ -0,0 +1,78 @@
+package converter
+
+import (
+	"fmt"
+
+	"github.com/cschleiden/go-workflows/backend/payload"
+)
+
+// Codec transforms payloads after they have been produced by a converter, for example to compress or encrypt
+// them. Codecs should pass through payloads they did not encode, so that they can be added to existing
+// deployments.
+type Codec interface {
+	// Encode encodes the given payload
+	Encode(p payload.Payload) (payload.Payload, error)
+
+	// Decode decodes the given payload
+	Decode(p payload.Payload) (payload.Payload, error)
+}
+
+type codecConverter struct {
+	converter Converter
+	codecs    []Codec
+}
+
+var _ Converter = (*codecConverter)(nil)
+var _ Codec = (*codecConverter)(nil)
+
+// NewCodecConverter returns a converter that applies the given codecs, in order, to the payloads produced by
+// the given converter. Payloads are decoded in reverse order. To compress and encrypt payloads, pass the
+// compression codec first.
+//
+// The returned converter also implements Codec, which is used to encode the errors recorded in the history.
+func NewCodecConverter(c Converter, codecs ...Codec) Converter {
+	return &codecConverter{
+		converter: c,
+		codecs:    codecs,
+	}
+}
+
+func (cc *codecConverter) To(v interface{}) (payload.Payload, error) {
+	p, err := cc.converter.To(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return cc.Encode(p)
+}
+
+func (cc *codecConverter) From(data payload.Payload, v interface{}) error {
+	p, err := cc.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	return cc.converter.From(p, v)
+}
+
+func (cc *codecConverter) Encode(p payload.Payload) (payload.Payload, error) {
+	for _, codec := range cc.codecs {
+		var err error
+		if p, err = codec.Encode(p); err != nil {
+			return nil, fmt.Errorf("encoding payload: %w", err)
+		}
+	}
+
+	return p, nil
+}
+
+func (cc *codecConverter) Decode(p payload.Payload) (payload.Payload, error) {
+	for i := len(cc.codecs) - 1; i >= 0; i-- {
+		var err error
+		if p, err = cc.codecs[i].Decode(p); err != nil {
+			return nil, fmt.Errorf("decoding payload: %w", err)
+		}
+	}
+
+	return p, nil
+}
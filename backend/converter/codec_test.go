@@ -0,0 +1,117 @@
+package converter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodecs(t *testing.T) {
+	gzipCodec, err := NewGzipCodec(gzip.BestSpeed)
+	require.NoError(t, err)
+
+	zstdCodec, err := NewZstdCodec()
+	require.NoError(t, err)
+
+	aesCodec, err := NewAESGCMCodec("key-1", map[string][]byte{"key-1": bytes.Repeat([]byte{1}, 16)})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		codec Codec
+	}{
+		{"gzip", gzipCodec},
+		{"zstd", zstdCodec},
+		{"aes-gcm", aesCodec},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plain := payload.Payload(`{"email":"jane@example.com"}`)
+
+			encoded, err := tt.codec.Encode(plain)
+			require.NoError(t, err)
+			require.NotEqual(t, plain, encoded)
+
+			decoded, err := tt.codec.Decode(encoded)
+			require.NoError(t, err)
+			require.Equal(t, plain, decoded)
+
+			// Payloads that have not been encoded are passed through
+			decoded, err = tt.codec.Decode(plain)
+			require.NoError(t, err)
+			require.Equal(t, plain, decoded)
+		})
+	}
+}
+
+func TestCodecConverter(t *testing.T) {
+	zstdCodec, err := NewZstdCodec()
+	require.NoError(t, err)
+
+	aesCodec, err := NewAESGCMCodec("key-1", map[string][]byte{"key-1": bytes.Repeat([]byte{1}, 32)})
+	require.NoError(t, err)
+
+	c := NewCodecConverter(DefaultConverter, zstdCodec, aesCodec)
+
+	p, err := c.To(map[string]string{"email": "jane@example.com"})
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(p, aesGCMMagic))
+	require.NotContains(t, string(p), "jane@example.com")
+
+	var v map[string]string
+	require.NoError(t, c.From(p, &v))
+	require.Equal(t, map[string]string{"email": "jane@example.com"}, v)
+
+	// Payloads recorded before the codecs were added can still be read
+	var s string
+	require.NoError(t, c.From(payload.Payload(`"plain"`), &s))
+	require.Equal(t, "plain", s)
+}
+
+func TestAESGCMCodec_KeyRotation(t *testing.T) {
+	key1 := bytes.Repeat([]byte{1}, 32)
+	key2 := bytes.Repeat([]byte{2}, 32)
+
+	old, err := NewAESGCMCodec("key-1", map[string][]byte{"key-1": key1})
+	require.NoError(t, err)
+
+	encoded, err := old.Encode(payload.Payload(`"secret"`))
+	require.NoError(t, err)
+
+	rotated, err := NewAESGCMCodec("key-2", map[string][]byte{"key-1": key1, "key-2": key2})
+	require.NoError(t, err)
+
+	decoded, err := rotated.Decode(encoded)
+	require.NoError(t, err)
+	require.Equal(t, payload.Payload(`"secret"`), decoded)
+
+	withoutOldKey, err := NewAESGCMCodec("key-2", map[string][]byte{"key-2": key2})
+	require.NoError(t, err)
+
+	_, err = withoutOldKey.Decode(encoded)
+	require.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestAESGCMCodec_Tampered(t *testing.T) {
+	codec, err := NewAESGCMCodec("key-1", map[string][]byte{"key-1": bytes.Repeat([]byte{1}, 32)})
+	require.NoError(t, err)
+
+	encoded, err := codec.Encode(payload.Payload(`"secret"`))
+	require.NoError(t, err)
+
+	encoded[len(encoded)-1] ^= 0xff
+
+	_, err = codec.Decode(encoded)
+	require.Error(t, err)
+}
+
+func TestNewAESGCMCodec_InvalidKeys(t *testing.T) {
+	_, err := NewAESGCMCodec("key-1", map[string][]byte{"key-2": bytes.Repeat([]byte{1}, 32)})
+	require.ErrorIs(t, err, ErrUnknownKey)
+
+	_, err = NewAESGCMCodec("key-1", map[string][]byte{"key-1": []byte("short")})
+	require.Error(t, err)
+}
@@ -0,0 +1,99 @@
+package converter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+type gzipCodec struct {
+	level int
+}
+
+// NewGzipCodec returns a codec compressing payloads with gzip using the given compression level, see the
+// constants in compress/gzip. Payloads that are not gzip compressed are passed through when decoding.
+func NewGzipCodec(level int) (Codec, error) {
+	// Validate the level upfront
+	if _, err := gzip.NewWriterLevel(io.Discard, level); err != nil {
+		return nil, err
+	}
+
+	return &gzipCodec{level: level}, nil
+}
+
+func (c *gzipCodec) Encode(p payload.Payload) (payload.Payload, error) {
+	var buf bytes.Buffer
+
+	w, err := gzip.NewWriterLevel(&buf, c.level)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(p); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (c *gzipCodec) Decode(p payload.Payload) (payload.Payload, error) {
+	if !bytes.HasPrefix(p, gzipMagic) {
+		return p, nil
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(p))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+type zstdCodec struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+// NewZstdCodec returns a codec compressing payloads with zstd. Payloads that are not zstd compressed are passed
+// through when decoding.
+func NewZstdCodec() (Codec, error) {
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zstdCodec{
+		encoder: encoder,
+		decoder: decoder,
+	}, nil
+}
+
+func (c *zstdCodec) Encode(p payload.Payload) (payload.Payload, error) {
+	return c.encoder.EncodeAll(p, nil), nil
+}
+
+func (c *zstdCodec) Decode(p payload.Payload) (payload.Payload, error) {
+	if !bytes.HasPrefix(p, zstdMagic) {
+		return p, nil
+	}
+
+	return c.decoder.DecodeAll(p, nil)
+}
@@ -1,6 +1,8 @@
 package converter
 
 import (
+	"context"
+
 	"github.com/cschleiden/go-workflows/backend/payload"
 )
 
@@ -13,3 +15,27 @@ type Converter interface {
 }
 
 var DefaultConverter Converter = &jsonConverter{}
+
+// ContextConverter is implemented by converters that perform I/O during conversion, e.g., to load offloaded
+// payloads.
+type ContextConverter interface {
+	// WithContext returns a copy of the converter that uses the given context for I/O
+	WithContext(ctx context.Context) Converter
+}
+
+// WithContext returns a converter that uses the given context for I/O if c implements ContextConverter,
+// otherwise c.
+func WithContext(ctx context.Context, c Converter) Converter {
+	if cc, ok := c.(ContextConverter); ok {
+		return cc.WithContext(ctx)
+	}
+
+	return c
+}
+
+// magicPrefix returns the prefix identifying payloads produced by the non-JSON converters and codecs in this
+// package. Encoded JSON never starts with a zero byte, so prefixed payloads can be told apart from JSON payloads,
+// for example ones recorded before a converter or codec was configured.
+func magicPrefix(id string) []byte {
+	return append([]byte{0x00}, id...)
+}
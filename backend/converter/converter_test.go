@@ -0,0 +1,158 @@
+package converter
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type testStruct struct {
+	Name  string    `json:"name"`
+	Count int64     `json:"count"`
+	At    time.Time `json:"at"`
+	Tags  []string  `json:"tags,omitempty"`
+}
+
+func TestProtoConverter(t *testing.T) {
+	c := NewProtoConverter()
+
+	ts := timestamppb.New(time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC))
+
+	p, err := c.To(ts)
+	require.NoError(t, err)
+
+	// Pointer to message
+	var m *timestamppb.Timestamp
+	require.NoError(t, c.From(p, &m))
+	require.True(t, proto.Equal(ts, m))
+
+	// Message
+	m = &timestamppb.Timestamp{}
+	require.NoError(t, c.From(p, m))
+	require.True(t, proto.Equal(ts, m))
+
+	// Interface, resolved with the global registry
+	var v interface{}
+	require.NoError(t, c.From(p, &v))
+	require.True(t, proto.Equal(ts, v.(proto.Message)))
+
+	var pm proto.Message
+	require.NoError(t, c.From(p, &pm))
+	require.True(t, proto.Equal(ts, pm))
+
+	// Different message type
+	var w *wrapperspb.Int64Value
+	require.ErrorContains(t, c.From(p, &w), "cannot convert to google.protobuf.Int64Value")
+}
+
+func TestProtoConverter_Int64Precision(t *testing.T) {
+	c := NewProtoConverter()
+
+	p, err := c.To(wrapperspb.Int64(math.MaxInt64))
+	require.NoError(t, err)
+
+	var v *wrapperspb.Int64Value
+	require.NoError(t, c.From(p, &v))
+	require.Equal(t, int64(math.MaxInt64), v.GetValue())
+}
+
+func TestProtoConverter_Fallback(t *testing.T) {
+	c := NewProtoConverter()
+
+	in := testStruct{Name: "test", Count: 42, At: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	p, err := c.To(in)
+	require.NoError(t, err)
+	require.Equal(t, payload.Payload(`{"name":"test","count":42,"at":"2024-01-02T03:04:05Z"}`), p)
+
+	var out testStruct
+	require.NoError(t, c.From(p, &out))
+	require.Equal(t, in, out)
+
+	// Nil messages are converted with the fallback converter
+	p, err = c.To((*structpb.Struct)(nil))
+	require.NoError(t, err)
+	require.Equal(t, payload.Payload("null"), p)
+
+	var s *structpb.Struct
+	require.NoError(t, c.From(p, &s))
+	require.Nil(t, s)
+}
+
+func TestMessagePackConverter(t *testing.T) {
+	c := NewMessagePackConverter()
+
+	in := testStruct{Name: "test", Count: math.MaxInt64, At: time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC), Tags: []string{"a", "b"}}
+	p, err := c.To(in)
+	require.NoError(t, err)
+
+	var out testStruct
+	require.NoError(t, c.From(p, &out))
+	require.Equal(t, in.Name, out.Name)
+	require.Equal(t, in.Count, out.Count)
+	require.True(t, in.At.Equal(out.At))
+	require.Equal(t, in.Tags, out.Tags)
+
+	// Struct fields are named after their json tags
+	var m map[string]interface{}
+	require.NoError(t, c.From(p, &m))
+	require.Equal(t, "test", m["name"])
+
+	// Payloads recorded with the default converter
+	var s string
+	require.NoError(t, c.From(payload.Payload(`"json"`), &s))
+	require.Equal(t, "json", s)
+}
+
+func BenchmarkConverters(b *testing.B) {
+	v := testStruct{
+		Name:  "benchmark",
+		Count: 1234567890,
+		At:    time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		Tags:  strings.Split(strings.Repeat("tag,", 20), ","),
+	}
+
+	m, err := structpb.NewStruct(map[string]interface{}{
+		"name":  v.Name,
+		"count": v.Count,
+		"tags":  []interface{}{"a", "b", "c"},
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	benchmarks := []struct {
+		name string
+		c    Converter
+		v    interface{}
+		new  func() interface{}
+	}{
+		{"json", DefaultConverter, v, func() interface{} { return &testStruct{} }},
+		{"msgpack", NewMessagePackConverter(), v, func() interface{} { return &testStruct{} }},
+		{"json/proto-message", DefaultConverter, m, func() interface{} { return &structpb.Struct{} }},
+		{"proto", NewProtoConverter(), m, func() interface{} { return &structpb.Struct{} }},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				p, err := bm.c.To(bm.v)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				if err := bm.c.From(p, bm.new()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package converter
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/cschleiden/go-workflows/backend/payload"
+)
+
+// ErrUnknownKey is returned when a payload was encrypted with a key that is not known to the codec.
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// aesGCMMagic prefixes payloads encrypted by the AES-GCM codec
+var aesGCMMagic = magicPrefix("gcm")
+
+type aesGCMCodec struct {
+	keyID string
+	aeads map[string]cipher.AEAD
+}
+
+// NewAESGCMCodec returns a codec encrypting payloads with AES-GCM using the key with the given ID. Keys have to
+// be 16, 24, or 32 bytes long to select AES-128, AES-192, or AES-256.
+//
+// Encrypted payloads carry the ID of the key they were encrypted with. To rotate keys, add a new key and pass
+// its ID, and keep the previous keys as long as there are payloads encrypted with them. Payloads that are not
+// encrypted are passed through when decoding.
+func NewAESGCMCodec(keyID string, keys map[string][]byte) (Codec, error) {
+	if _, ok := keys[keyID]; !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownKey, keyID)
+	}
+
+	aeads := make(map[string]cipher.AEAD, len(keys))
+	for id, key := range keys {
+		if len(id) > 255 {
+			return nil, fmt.Errorf("key id must not be longer than 255 bytes: %v", id)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("creating cipher for key %v: %w", id, err)
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("creating cipher for key %v: %w", id, err)
+		}
+
+		aeads[id] = aead
+	}
+
+	return &aesGCMCodec{
+		keyID: keyID,
+		aeads: aeads,
+	}, nil
+}
+
+// Encode encrypts the payload. The encrypted payload is laid out as
+//
+//	magic | len(key id) | key id | nonce | ciphertext
+//
+// with the key id also authenticated as additional data.
+func (c *aesGCMCodec) Encode(p payload.Payload) (payload.Payload, error) {
+	aead := c.aeads[c.keyID]
+
+	header := make([]byte, 0, len(aesGCMMagic)+1+len(c.keyID))
+	header = append(header, aesGCMMagic...)
+	header = append(header, byte(len(c.keyID)))
+	header = append(header, c.keyID...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+
+	out := make([]byte, 0, len(header)+len(nonce)+len(p)+aead.Overhead())
+	out = append(out, header...)
+	out = append(out, nonce...)
+
+	return aead.Seal(out, nonce, p, []byte(c.keyID)), nil
+}
+
+func (c *aesGCMCodec) Decode(p payload.Payload) (payload.Payload, error) {
+	if !bytes.HasPrefix(p, aesGCMMagic) {
+		return p, nil
+	}
+
+	data := p[len(aesGCMMagic):]
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return nil, errors.New("invalid encrypted payload")
+	}
+
+	keyID := string(data[1 : 1+data[0]])
+	data = data[1+len(keyID):]
+
+	aead, ok := c.aeads[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownKey, keyID)
+	}
+
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("invalid encrypted payload")
+	}
+
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("decrypting payload: %w", err)
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,51 @@
+package converter
+
+import (
+	"bytes"
+
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// msgpackMagic prefixes MessagePack payloads
+var msgpackMagic = magicPrefix("msg")
+
+type msgpackConverter struct {
+	fallback Converter
+}
+
+var _ Converter = (*msgpackConverter)(nil)
+
+// NewMessagePackConverter returns a converter that uses MessagePack. Struct fields are named after their json
+// tags, so types used with the default converter can be used as is. Payloads recorded with the default
+// converter can still be converted back.
+func NewMessagePackConverter() Converter {
+	return &msgpackConverter{
+		fallback: DefaultConverter,
+	}
+}
+
+func (mc *msgpackConverter) To(v interface{}) (payload.Payload, error) {
+	buf := bytes.NewBuffer(append([]byte{}, msgpackMagic...))
+
+	enc := msgpack.NewEncoder(buf)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (mc *msgpackConverter) From(data payload.Payload, vptr interface{}) error {
+	if !bytes.HasPrefix(data, msgpackMagic) {
+		return mc.fallback.From(data, vptr)
+	}
+
+	dec := msgpack.NewDecoder(bytes.NewReader(data[len(msgpackMagic):]))
+	dec.SetCustomStructTag("json")
+
+	return dec.Decode(vptr)
+}
@@ -0,0 +1,194 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/google/uuid"
+)
+
+// ErrBlobNotFound is returned by blob stores when there is no blob for a key.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore stores large payloads outside of the backend.
+type BlobStore interface {
+	// Put stores the given data under the given key
+	Put(ctx context.Context, key string, data []byte) error
+
+	// Get returns the data stored under the given key, or ErrBlobNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Delete removes the blobs with the given keys. Keys without a blob are ignored.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// PayloadStore is implemented by converters that store payloads outside of the history of workflow instances.
+// Backends use it to release stored payloads when workflow instances are removed or expire, and the payloads
+// recorded for other workflow instances, e.g., the inputs of sub-workflows, are copied so that every workflow
+// instance owns the payloads in its history.
+type PayloadStore interface {
+	// IsStored returns true if the given payload is a reference to a payload stored outside of the history
+	IsStored(p payload.Payload) bool
+
+	// CopyPayload returns a copy of the given payload that references its own copy of the stored payload
+	CopyPayload(ctx context.Context, p payload.Payload) (payload.Payload, error)
+
+	// ReleasePayloads removes the stored payloads referenced by the given payloads
+	ReleasePayloads(ctx context.Context, payloads []payload.Payload) error
+}
+
+// blobRefMagic prefixes references to offloaded payloads, followed by the blob key
+var blobRefMagic = magicPrefix("blb")
+
+type offloadingConverter struct {
+	converter Converter
+	store     BlobStore
+	threshold int
+
+	// inline is set for converters that keep all payloads in place, see WithoutOffloading
+	inline bool
+
+	// ctx is used to access the blob store when converting payloads
+	ctx context.Context
+}
+
+var _ Converter = (*offloadingConverter)(nil)
+var _ PayloadStore = (*offloadingConverter)(nil)
+var _ ContextConverter = (*offloadingConverter)(nil)
+
+// WithoutOffloading returns a converter that converts values like the given converter, but does not write any
+// payloads to a blob store. Payloads that are not recorded in the history, for example the arguments of queries or
+// the inputs of commands recreated while replaying the history of a workflow instance, are converted with it, so
+// that no blobs are left behind for them. Offloaded payloads are still loaded when they are converted back.
+func WithoutOffloading(c Converter) Converter {
+	switch oc := c.(type) {
+	case *offloadingConverter:
+		return oc.inlined()
+	case *offloadingCodecConverter:
+		return &offloadingCodecConverter{oc.offloadingConverter.inlined(), oc.Codec}
+	}
+
+	return c
+}
+
+// NewOffloadingConverter returns a converter that writes payloads produced by the given converter that are
+// larger than threshold bytes to the given blob store, and records only a small reference in the history.
+// Offloaded payloads are loaded when they are converted back.
+//
+// To offload compressed or encrypted payloads, pass a converter created with NewCodecConverter.
+func NewOffloadingConverter(c Converter, store BlobStore, threshold int) Converter {
+	oc := &offloadingConverter{
+		converter: c,
+		store:     store,
+		threshold: threshold,
+		ctx:       context.Background(),
+	}
+
+	// Keep encoding errors with the codecs of the given converter
+	if codec, ok := c.(Codec); ok {
+		return &offloadingCodecConverter{oc, codec}
+	}
+
+	return oc
+}
+
+func (oc *offloadingConverter) To(v interface{}) (payload.Payload, error) {
+	p, err := oc.converter.To(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if oc.inline || len(p) <= oc.threshold {
+		return p, nil
+	}
+
+	return oc.put(oc.ctx, p)
+}
+
+func (oc *offloadingConverter) From(data payload.Payload, v interface{}) error {
+	if oc.IsStored(data) {
+		var err error
+		if data, err = oc.store.Get(oc.ctx, blobKey(data)); err != nil {
+			return fmt.Errorf("loading offloaded payload: %w", err)
+		}
+	}
+
+	return oc.converter.From(data, v)
+}
+
+func (oc *offloadingConverter) WithContext(ctx context.Context) Converter {
+	return oc.withContext(ctx)
+}
+
+func (oc *offloadingConverter) withContext(ctx context.Context) *offloadingConverter {
+	c := *oc
+	c.ctx = ctx
+	return &c
+}
+
+func (oc *offloadingConverter) inlined() *offloadingConverter {
+	c := *oc
+	c.inline = true
+	return &c
+}
+
+func (oc *offloadingConverter) IsStored(p payload.Payload) bool {
+	return bytes.HasPrefix(p, blobRefMagic)
+}
+
+func (oc *offloadingConverter) CopyPayload(ctx context.Context, p payload.Payload) (payload.Payload, error) {
+	if !oc.IsStored(p) {
+		return p, nil
+	}
+
+	data, err := oc.store.Get(ctx, blobKey(p))
+	if err != nil {
+		return nil, fmt.Errorf("loading offloaded payload: %w", err)
+	}
+
+	return oc.put(ctx, data)
+}
+
+func (oc *offloadingConverter) ReleasePayloads(ctx context.Context, payloads []payload.Payload) error {
+	keys := make([]string, 0, len(payloads))
+	for _, p := range payloads {
+		if oc.IsStored(p) {
+			keys = append(keys, blobKey(p))
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if err := oc.store.Delete(ctx, keys...); err != nil {
+		return fmt.Errorf("deleting offloaded payloads: %w", err)
+	}
+
+	return nil
+}
+
+func (oc *offloadingConverter) put(ctx context.Context, data []byte) (payload.Payload, error) {
+	key := uuid.NewString()
+	if err := oc.store.Put(ctx, key, data); err != nil {
+		return nil, fmt.Errorf("offloading payload: %w", err)
+	}
+
+	return append(append(payload.Payload{}, blobRefMagic...), key...), nil
+}
+
+func blobKey(p payload.Payload) string {
+	return string(p[len(blobRefMagic):])
+}
+
+type offloadingCodecConverter struct {
+	*offloadingConverter
+	Codec
+}
+
+func (occ *offloadingCodecConverter) WithContext(ctx context.Context) Converter {
+	return &offloadingCodecConverter{occ.offloadingConverter.withContext(ctx), occ.Codec}
+}
@@ -0,0 +1,180 @@
+package converter
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffloadingConverter(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFilesystemBlobStore(dir)
+	require.NoError(t, err)
+
+	c := NewOffloadingConverter(DefaultConverter, store, 16)
+	ps := c.(PayloadStore)
+
+	// Small payloads are kept in the history
+	small, err := c.To("small")
+	require.NoError(t, err)
+	require.Equal(t, payload.Payload(`"small"`), small)
+	require.False(t, ps.IsStored(small))
+
+	large := strings.Repeat("x", 100)
+	p, err := c.To(large)
+	require.NoError(t, err)
+	require.True(t, ps.IsStored(p))
+	require.Less(t, len(p), len(large))
+
+	var v string
+	require.NoError(t, c.From(p, &v))
+	require.Equal(t, large, v)
+
+	copied, err := ps.CopyPayload(context.Background(), p)
+	require.NoError(t, err)
+	require.NotEqual(t, p, copied)
+
+	require.NoError(t, ps.ReleasePayloads(context.Background(), []payload.Payload{p, small}))
+	require.ErrorIs(t, c.From(p, &v), ErrBlobNotFound)
+
+	// The copy is not affected by releasing the original
+	require.NoError(t, c.From(copied, &v))
+	require.Equal(t, large, v)
+
+	require.NoError(t, ps.ReleasePayloads(context.Background(), []payload.Payload{copied}))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestOffloadingConverter_Codecs(t *testing.T) {
+	store, err := NewFilesystemBlobStore(t.TempDir())
+	require.NoError(t, err)
+
+	zstdCodec, err := NewZstdCodec()
+	require.NoError(t, err)
+
+	c := NewOffloadingConverter(NewCodecConverter(DefaultConverter, zstdCodec), store, 16)
+
+	// Errors are still encoded with the codecs of the wrapped converter
+	_, ok := c.(Codec)
+	require.True(t, ok)
+
+	p, err := c.To(strings.Repeat("x", 1000))
+	require.NoError(t, err)
+	require.True(t, c.(PayloadStore).IsStored(p))
+
+	var v string
+	require.NoError(t, c.From(p, &v))
+	require.Equal(t, strings.Repeat("x", 1000), v)
+}
+
+func TestOffloadingConverter_WithoutOffloading(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFilesystemBlobStore(dir)
+	require.NoError(t, err)
+
+	zstdCodec, err := NewZstdCodec()
+	require.NoError(t, err)
+
+	c := NewOffloadingConverter(NewCodecConverter(DefaultConverter, zstdCodec), store, 16)
+	stored, err := c.To(strings.Repeat("x", 1000))
+	require.NoError(t, err)
+
+	ic := WithoutOffloading(c)
+	_, ok := ic.(Codec)
+	require.True(t, ok)
+
+	// Large payloads are kept in place
+	p, err := ic.To(strings.Repeat("y", 1000))
+	require.NoError(t, err)
+	require.False(t, c.(PayloadStore).IsStored(p))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// Payloads stored before can still be loaded
+	var v string
+	require.NoError(t, ic.From(stored, &v))
+	require.Equal(t, strings.Repeat("x", 1000), v)
+
+	require.NoError(t, ic.From(p, &v))
+	require.Equal(t, strings.Repeat("y", 1000), v)
+
+	// Other converters are returned as is
+	require.Equal(t, DefaultConverter, WithoutOffloading(DefaultConverter))
+}
+
+type ctxKey struct{}
+
+type contextRecordingBlobStore struct {
+	BlobStore
+
+	values []any
+}
+
+func (s *contextRecordingBlobStore) Put(ctx context.Context, key string, data []byte) error {
+	s.values = append(s.values, ctx.Value(ctxKey{}))
+	return s.BlobStore.Put(ctx, key, data)
+}
+
+func (s *contextRecordingBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.values = append(s.values, ctx.Value(ctxKey{}))
+	return s.BlobStore.Get(ctx, key)
+}
+
+func TestOffloadingConverter_WithContext(t *testing.T) {
+	fs, err := NewFilesystemBlobStore(t.TempDir())
+	require.NoError(t, err)
+
+	store := &contextRecordingBlobStore{BlobStore: fs}
+
+	zstdCodec, err := NewZstdCodec()
+	require.NoError(t, err)
+
+	c := NewOffloadingConverter(NewCodecConverter(DefaultConverter, zstdCodec), store, 16)
+	cc := WithContext(context.WithValue(context.Background(), ctxKey{}, "caller"), c)
+
+	// The bound converter keeps the codecs and the payload store of the original converter
+	_, ok := cc.(Codec)
+	require.True(t, ok)
+	_, ok = cc.(PayloadStore)
+	require.True(t, ok)
+
+	p, err := cc.To(strings.Repeat("x", 1000))
+	require.NoError(t, err)
+
+	var v string
+	require.NoError(t, cc.From(p, &v))
+	require.Equal(t, []any{"caller", "caller"}, store.values)
+
+	// Converters that don't perform I/O are returned as is
+	require.Equal(t, DefaultConverter, WithContext(context.Background(), DefaultConverter))
+}
+
+func TestFilesystemBlobStore(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := NewFilesystemBlobStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(ctx, "key", []byte("data")))
+
+	data, err := store.Get(ctx, "key")
+	require.NoError(t, err)
+	require.Equal(t, []byte("data"), data)
+
+	require.NoError(t, store.Delete(ctx, "key", "missing"))
+
+	_, err = store.Get(ctx, "key")
+	require.ErrorIs(t, err, ErrBlobNotFound)
+
+	for _, key := range []string{"", "..", "../key", "a/b", ".tmp-1"} {
+		require.Error(t, store.Put(ctx, key, []byte("data")), key)
+	}
+}
@@ -0,0 +1,125 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// protoMagic prefixes payloads of protobuf messages, followed by the length-prefixed full name of the message
+// type and the binary encoding of the message
+var protoMagic = magicPrefix("pb")
+
+var protoMessageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+
+type protoConverter struct {
+	fallback Converter
+}
+
+var _ Converter = (*protoConverter)(nil)
+
+// NewProtoConverter returns a converter that uses the binary protobuf encoding for values implementing
+// proto.Message, and JSON for all other values.
+//
+// Messages can be converted back to values of their own type, or to interface{} values if their type is
+// registered in the global protobuf registry.
+func NewProtoConverter() Converter {
+	return &protoConverter{
+		fallback: DefaultConverter,
+	}
+}
+
+func (pc *protoConverter) To(v interface{}) (payload.Payload, error) {
+	m, ok := v.(proto.Message)
+	if !ok || reflect.ValueOf(v).IsNil() {
+		return pc.fallback.To(v)
+	}
+
+	name := m.ProtoReflect().Descriptor().FullName()
+
+	p := append([]byte{}, protoMagic...)
+	p = binary.AppendUvarint(p, uint64(len(name)))
+	p = append(p, name...)
+
+	return proto.MarshalOptions{Deterministic: true}.MarshalAppend(p, m)
+}
+
+func (pc *protoConverter) From(data payload.Payload, vptr interface{}) error {
+	if !bytes.HasPrefix(data, protoMagic) {
+		return pc.fallback.From(data, vptr)
+	}
+
+	name, data, err := parseProtoPayload(data)
+	if err != nil {
+		return err
+	}
+
+	// Target is a message, e.g., when the caller passes a *pb.Message
+	if m, ok := vptr.(proto.Message); ok {
+		return unmarshalProto(name, data, m)
+	}
+
+	rv := reflect.ValueOf(vptr)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("converting protobuf message %v: target must be a non-nil pointer", name)
+	}
+
+	target := rv.Elem()
+	switch {
+	case target.Type().Implements(protoMessageType) && target.Kind() == reflect.Pointer:
+		// Target is a pointer to a message pointer, e.g., when converting arguments of type *pb.Message
+		m := reflect.New(target.Type().Elem())
+		if err := unmarshalProto(name, data, m.Interface().(proto.Message)); err != nil {
+			return err
+		}
+
+		target.Set(m)
+		return nil
+
+	case target.Kind() == reflect.Interface:
+		mt, err := protoregistry.GlobalTypes.FindMessageByName(name)
+		if err != nil {
+			return fmt.Errorf("converting protobuf message %v: %w", name, err)
+		}
+
+		m := mt.New().Interface()
+		if err := unmarshalProto(name, data, m); err != nil {
+			return err
+		}
+
+		if !reflect.TypeOf(m).AssignableTo(target.Type()) {
+			return fmt.Errorf("converting protobuf message %v: cannot assign to %v", name, target.Type())
+		}
+
+		target.Set(reflect.ValueOf(m))
+		return nil
+	}
+
+	return fmt.Errorf("converting protobuf message %v: cannot convert to %v", name, target.Type())
+}
+
+func parseProtoPayload(data payload.Payload) (protoreflect.FullName, []byte, error) {
+	data = data[len(protoMagic):]
+
+	l, n := binary.Uvarint(data)
+	if n <= 0 || uint64(len(data)-n) < l {
+		return "", nil, errors.New("invalid protobuf payload")
+	}
+
+	return protoreflect.FullName(data[n : n+int(l)]), data[n+int(l):], nil
+}
+
+func unmarshalProto(name protoreflect.FullName, data []byte, m proto.Message) error {
+	if actual := m.ProtoReflect().Descriptor().FullName(); actual != name {
+		return fmt.Errorf("converting protobuf message %v: cannot convert to %v", name, actual)
+	}
+
+	return proto.Unmarshal(data, m)
+}
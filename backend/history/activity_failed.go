@@ -1,7 +1,13 @@
 package history
 
-import "github.com/cschleiden/go-workflows/internal/workflowerrors"
+import (
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
+)
 
 type ActivityFailedAttributes struct {
 	Error *workflowerrors.Error `json:"error,omitempty"`
+
+	// LastHeartbeatDetails are the details of the last heartbeat recorded by the activity
+	LastHeartbeatDetails payload.Payload `json:"last_heartbeat_details,omitempty"`
 }
@@ -1,6 +1,8 @@
 package history
 
 import (
+	"time"
+
 	"github.com/cschleiden/go-workflows/backend/metadata"
 	"github.com/cschleiden/go-workflows/backend/payload"
 	"github.com/cschleiden/go-workflows/core"
@@ -16,4 +18,16 @@ type ActivityScheduledAttributes struct {
 	Metadata *metadata.WorkflowMetadata `json:"metadata,omitempty"`
 
 	Queue core.Queue `json:"queue,omitempty"`
+
+	HeartbeatTimeout time.Duration `json:"heartbeat_timeout,omitempty"`
+
+	ScheduleToStartTimeout time.Duration `json:"schedule_to_start_timeout,omitempty"`
+
+	StartToCloseTimeout time.Duration `json:"start_to_close_timeout,omitempty"`
+
+	// ScheduleToCloseDeadline is the time by which the activity, including all retries, has to be completed
+	ScheduleToCloseDeadline *time.Time `json:"schedule_to_close_deadline,omitempty"`
+
+	// LastHeartbeatDetails are the details of the last heartbeat recorded by a previous attempt
+	LastHeartbeatDetails payload.Payload `json:"last_heartbeat_details,omitempty"`
 }
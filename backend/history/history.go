@@ -18,7 +18,7 @@ const (
 	EventType_WorkflowExecutionFinished
 	// Workflow has continued as new
 	EventType_WorkflowExecutionContinuedAsNew
-	// Workflow has been terminated (not yet used)
+	// Workflow has been terminated
 	EventType_WorkflowExecutionTerminated
 	// Workflow has been canceled
 	EventType_WorkflowExecutionCanceled
@@ -58,6 +58,27 @@ const (
 
 	// Distributed tracing span has been started
 	EventType_TraceStarted
+
+	// Workflow code version for a change has been recorded
+	EventType_VersionMarker
+
+	// Workflow has exceeded its execution or run timeout
+	EventType_WorkflowExecutionTimedOut
+
+	// Workflow has upserted search attributes
+	EventType_SearchAttributesUpserted
+
+	// Update has been requested for the workflow instance
+	EventType_UpdateRequested
+
+	// Update handler has completed
+	EventType_UpdateCompleted
+
+	// Recorded result of a local activity
+	EventType_LocalActivityResult
+
+	// Detached sub-workflow instance has been started
+	EventType_SubWorkflowStarted
 )
 
 func (et EventType) String() string {
@@ -84,6 +105,8 @@ func (et EventType) String() string {
 		return "SubWorkflowCompleted"
 	case EventType_SubWorkflowFailed:
 		return "SubWorkflowFailed"
+	case EventType_SubWorkflowStarted:
+		return "SubWorkflowStarted"
 
 	case EventType_ActivityScheduled:
 		return "ActivityScheduled"
@@ -108,6 +131,23 @@ func (et EventType) String() string {
 	case EventType_TraceStarted:
 		return "TraceStarted"
 
+	case EventType_VersionMarker:
+		return "VersionMarker"
+
+	case EventType_WorkflowExecutionTimedOut:
+		return "WorkflowExecutionTimedOut"
+
+	case EventType_SearchAttributesUpserted:
+		return "SearchAttributesUpserted"
+
+	case EventType_UpdateRequested:
+		return "UpdateRequested"
+	case EventType_UpdateCompleted:
+		return "UpdateCompleted"
+
+	case EventType_LocalActivityResult:
+		return "LocalActivityResult"
+
 	default:
 		return "Unknown"
 	}
@@ -177,3 +217,9 @@ func NewPendingEvent(timestamp time.Time, eventType EventType, attributes interf
 func NewWorkflowCancellationEvent(timestamp time.Time) *Event {
 	return NewPendingEvent(timestamp, EventType_WorkflowExecutionCanceled, &ExecutionCanceledAttributes{})
 }
+
+func NewWorkflowTerminationEvent(timestamp time.Time, reason string) *Event {
+	return NewPendingEvent(timestamp, EventType_WorkflowExecutionTerminated, &ExecutionTerminatedAttributes{
+		Reason: reason,
+	})
+}
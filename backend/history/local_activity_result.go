@@ -0,0 +1,23 @@
+package history
+
+import (
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
+)
+
+type LocalActivityResultAttributes struct {
+	Name string `json:"name,omitempty"`
+
+	// Attempts is the number of attempts it took to execute the local activity
+	Attempts int `json:"attempts,omitempty"`
+
+	Result payload.Payload `json:"result,omitempty"`
+
+	Error *workflowerrors.Error `json:"error,omitempty"`
+
+	// RetryAfter is set if the local activity failed and can be retried, but not within the workflow task. It's
+	// retried after a timer with this delay.
+	RetryAfter time.Duration `json:"retry_after,omitempty"`
+}
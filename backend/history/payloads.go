@@ -0,0 +1,75 @@
+package history
+
+import (
+	"reflect"
+
+	"github.com/cschleiden/go-workflows/backend/payload"
+)
+
+var (
+	payloadType  = reflect.TypeOf(payload.Payload(nil))
+	payloadsType = reflect.TypeOf([]payload.Payload(nil))
+)
+
+// Payloads returns all payloads of the given event attributes
+func Payloads(attributes interface{}) []payload.Payload {
+	var payloads []payload.Payload
+
+	_, _ = MapPayloads(attributes, func(p payload.Payload) (payload.Payload, error) {
+		if p != nil {
+			payloads = append(payloads, p)
+		}
+
+		return p, nil
+	})
+
+	return payloads
+}
+
+// MapPayloads returns a copy of the given event attributes with every payload replaced by the result of fn.
+// The given attributes are not modified.
+func MapPayloads(attributes interface{}, fn func(p payload.Payload) (payload.Payload, error)) (interface{}, error) {
+	v := reflect.ValueOf(attributes)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return attributes, nil
+	}
+
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+
+	s := c.Elem()
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+		if !f.CanSet() {
+			continue
+		}
+
+		switch f.Type() {
+		case payloadType:
+			p, err := fn(f.Interface().(payload.Payload))
+			if err != nil {
+				return nil, err
+			}
+
+			f.Set(reflect.ValueOf(p))
+
+		case payloadsType:
+			ps := f.Interface().([]payload.Payload)
+			if ps == nil {
+				continue
+			}
+
+			mapped := make([]payload.Payload, len(ps))
+			for j, p := range ps {
+				var err error
+				if mapped[j], err = fn(p); err != nil {
+					return nil, err
+				}
+			}
+
+			f.Set(reflect.ValueOf(mapped))
+		}
+	}
+
+	return c.Interface(), nil
+}
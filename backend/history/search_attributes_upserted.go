@@ -0,0 +1,6 @@
+package history
+
+type SearchAttributesUpsertedAttributes struct {
+	// SearchAttributes are the encoded search attributes set by the workflow
+	SearchAttributes map[string]string `json:"search_attributes,omitempty"`
+}
@@ -40,8 +40,12 @@ func DeserializeAttributes(eventType EventType, attributes []byte) (attr interfa
 		attr = &ExecutionContinuedAsNewAttributes{}
 	case EventType_WorkflowExecutionFinished:
 		attr = &ExecutionCompletedAttributes{}
+	case EventType_WorkflowExecutionTerminated:
+		attr = &ExecutionTerminatedAttributes{}
 	case EventType_WorkflowExecutionCanceled:
 		attr = &ExecutionCanceledAttributes{}
+	case EventType_WorkflowExecutionTimedOut:
+		attr = &ExecutionTimedOutAttributes{}
 
 	case EventType_WorkflowTaskStarted:
 		attr = &WorkflowTaskStartedAttributes{}
@@ -62,6 +66,20 @@ func DeserializeAttributes(eventType EventType, attributes []byte) (attr interfa
 	case EventType_TraceStarted:
 		attr = &TraceStartedAttributes{}
 
+	case EventType_VersionMarker:
+		attr = &VersionMarkerAttributes{}
+
+	case EventType_SearchAttributesUpserted:
+		attr = &SearchAttributesUpsertedAttributes{}
+
+	case EventType_UpdateRequested:
+		attr = &UpdateRequestedAttributes{}
+	case EventType_UpdateCompleted:
+		attr = &UpdateCompletedAttributes{}
+
+	case EventType_LocalActivityResult:
+		attr = &LocalActivityResultAttributes{}
+
 	case EventType_TimerScheduled:
 		attr = &TimerScheduledAttributes{}
 	case EventType_TimerFired:
@@ -77,6 +95,8 @@ func DeserializeAttributes(eventType EventType, attributes []byte) (attr interfa
 		attr = &SubWorkflowCompletedAttributes{}
 	case EventType_SubWorkflowFailed:
 		attr = &SubWorkflowFailedAttributes{}
+	case EventType_SubWorkflowStarted:
+		attr = &SubWorkflowStartedAttributes{}
 
 	default:
 		return nil, errors.New("unknown event type when deserializing attributes")
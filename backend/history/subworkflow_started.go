@@ -0,0 +1,24 @@
+package history
+
+import (
+	"time"
+
+	"github.com/cschleiden/go-workflows/core"
+)
+
+type SubWorkflowStartedAttributes struct {
+	SubWorkflowInstance *core.WorkflowInstance `json:"sub_workflow_instance,omitempty"`
+}
+
+// NewSubWorkflowStartedEvent returns the event notifying the parent of the given detached sub-workflow instance
+// that it has been started. Backends send it to the parent after creating the sub-workflow instance.
+func NewSubWorkflowStartedEvent(timestamp time.Time, subWorkflowInstance *core.WorkflowInstance) *Event {
+	return NewPendingEvent(
+		timestamp,
+		EventType_SubWorkflowStarted,
+		&SubWorkflowStartedAttributes{
+			SubWorkflowInstance: subWorkflowInstance,
+		},
+		ScheduleEventID(subWorkflowInstance.ParentEventID),
+	)
+}
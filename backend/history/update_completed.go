@@ -0,0 +1,12 @@
+package history
+
+import (
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
+)
+
+type UpdateCompletedAttributes struct {
+	UpdateID string                `json:"update_id,omitempty"`
+	Result   payload.Payload       `json:"result,omitempty"`
+	Error    *workflowerrors.Error `json:"error,omitempty"`
+}
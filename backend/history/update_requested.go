@@ -0,0 +1,10 @@
+package history
+
+import "github.com/cschleiden/go-workflows/backend/payload"
+
+type UpdateRequestedAttributes struct {
+	// UpdateID uniquely identifies the update, the matching UpdateCompleted event carries the same ID
+	UpdateID string          `json:"update_id,omitempty"`
+	Name     string          `json:"name,omitempty"`
+	Arg      payload.Payload `json:"arg,omitempty"`
+}
@@ -1,9 +1,15 @@
 package history
 
-import "github.com/cschleiden/go-workflows/backend/payload"
+import (
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
+)
 
 type ExecutionContinuedAsNewAttributes struct {
 	Result payload.Payload `json:"result,omitempty"`
 
 	ContinuedExecutionID string `json:"continued_execution_id,omitempty"`
+
+	// Error is the error the execution failed with, if it was retried according to its RetryPolicy
+	Error *workflowerrors.Error `json:"error,omitempty"`
 }
@@ -1,6 +1,8 @@
 package history
 
 import (
+	"time"
+
 	"github.com/cschleiden/go-workflows/backend/metadata"
 	"github.com/cschleiden/go-workflows/backend/payload"
 	"github.com/cschleiden/go-workflows/core"
@@ -16,4 +18,43 @@ type ExecutionStartedAttributes struct {
 	Inputs []payload.Payload `json:"inputs,omitempty"`
 
 	WorkflowSpanID [8]byte `json:"workflowSpanID,omitempty"`
+
+	// RunTimeout is the maximum duration of this workflow execution
+	RunTimeout time.Duration `json:"run_timeout,omitempty"`
+
+	// ExecutionDeadline is the time by which the workflow instance has to be finished, including
+	// all continued executions
+	ExecutionDeadline *time.Time `json:"execution_deadline,omitempty"`
+
+	// SearchAttributes are the encoded search attributes the workflow instance was created with
+	SearchAttributes map[string]string `json:"search_attributes,omitempty"`
+
+	// RetryPolicy defines whether a new execution is started if this execution fails
+	RetryPolicy *RetryPolicy `json:"retry_policy,omitempty"`
+
+	// Attempt is the number of failed executions this execution retries
+	Attempt int `json:"attempt,omitempty"`
+
+	// ParentClosePolicy determines what happens to this sub-workflow execution if its parent finishes first
+	ParentClosePolicy core.ParentClosePolicy `json:"parent_close_policy,omitempty"`
+
+	// Detached sub-workflow executions don't report their result back to the parent
+	Detached bool `json:"detached,omitempty"`
+
+	// IDReusePolicy determines whether this execution can be created if other executions of the instance exist
+	IDReusePolicy core.IDReusePolicy `json:"id_reuse_policy,omitempty"`
+}
+
+// RetryPolicy is the recorded form of the retry options of a workflow instance
+type RetryPolicy struct {
+	MaxAttempts            int           `json:"max_attempts,omitempty"`
+	FirstRetryInterval     time.Duration `json:"first_retry_interval,omitempty"`
+	MaxRetryInterval       time.Duration `json:"max_retry_interval,omitempty"`
+	BackoffCoefficient     float64       `json:"backoff_coefficient,omitempty"`
+	NonRetryableErrorTypes []string      `json:"non_retryable_error_types,omitempty"`
+	Jitter                 float64       `json:"jitter,omitempty"`
+
+	// Deadline is the time after which no more executions are started, including all executions continued
+	// via ContinueAsNew
+	Deadline *time.Time `json:"deadline,omitempty"`
 }
@@ -0,0 +1,5 @@
+package history
+
+type ExecutionTerminatedAttributes struct {
+	Reason string `json:"reason,omitempty"`
+}
@@ -0,0 +1,50 @@
+package history
+
+import (
+	"time"
+
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
+)
+
+type ExecutionTimedOutAttributes struct {
+	TimeoutType workflowerrors.TimeoutType `json:"timeout_type,omitempty"`
+}
+
+// NewWorkflowTimeoutEvent returns the future event enforcing the earliest of the run and execution timeouts of the
+// execution started by the given event. Backends schedule it when creating the execution. Returns nil if the
+// execution does not have any timeouts.
+func NewWorkflowTimeoutEvent(startedEvent *Event) *Event {
+	a := startedEvent.Attributes.(*ExecutionStartedAttributes)
+
+	// Executions with a delayed start only start running once the started event becomes visible
+	startedAt := startedEvent.Timestamp
+	if startedEvent.VisibleAt != nil {
+		startedAt = *startedEvent.VisibleAt
+	}
+
+	var deadline time.Time
+	var timeoutType workflowerrors.TimeoutType
+
+	if a.RunTimeout > 0 {
+		deadline = startedAt.Add(a.RunTimeout)
+		timeoutType = workflowerrors.TimeoutTypeRun
+	}
+
+	if a.ExecutionDeadline != nil && (deadline.IsZero() || a.ExecutionDeadline.Before(deadline)) {
+		deadline = *a.ExecutionDeadline
+		timeoutType = workflowerrors.TimeoutTypeExecution
+	}
+
+	if deadline.IsZero() {
+		return nil
+	}
+
+	return NewPendingEvent(
+		startedEvent.Timestamp,
+		EventType_WorkflowExecutionTimedOut,
+		&ExecutionTimedOutAttributes{
+			TimeoutType: timeoutType,
+		},
+		VisibleAt(deadline),
+	)
+}
@@ -10,6 +10,8 @@ import (
 
 	metrics "github.com/cschleiden/go-workflows/backend/metrics"
 
+	payload "github.com/cschleiden/go-workflows/backend/payload"
+
 	mock "github.com/stretchr/testify/mock"
 
 	trace "go.opentelemetry.io/otel/trace"
@@ -158,6 +160,32 @@ func (_m *MockBackend) GetActivityTask(ctx context.Context, queues []core.Queue)
 	return r0, r1
 }
 
+// GetLatestWorkflowInstance provides a mock function with given fields: ctx, instanceID
+func (_m *MockBackend) GetLatestWorkflowInstance(ctx context.Context, instanceID string) (*core.WorkflowInstance, error) {
+	ret := _m.Called(ctx, instanceID)
+
+	var r0 *core.WorkflowInstance
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.WorkflowInstance, error)); ok {
+		return rf(ctx, instanceID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.WorkflowInstance); ok {
+		r0 = rf(ctx, instanceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.WorkflowInstance)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, instanceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetStats provides a mock function with given fields: ctx
 func (_m *MockBackend) GetStats(ctx context.Context) (*Stats, error) {
 	ret := _m.Called(ctx)
@@ -260,6 +288,32 @@ func (_m *MockBackend) GetWorkflowTask(ctx context.Context, queues []core.Queue)
 	return r0, r1
 }
 
+// ListWorkflowInstances provides a mock function with given fields: ctx, query
+func (_m *MockBackend) ListWorkflowInstances(ctx context.Context, query *WorkflowInstanceQuery) (*WorkflowInstanceQueryResult, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 *WorkflowInstanceQueryResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *WorkflowInstanceQuery) (*WorkflowInstanceQueryResult, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *WorkflowInstanceQuery) *WorkflowInstanceQueryResult); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*WorkflowInstanceQueryResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *WorkflowInstanceQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Metrics provides a mock function with given fields:
 func (_m *MockBackend) Metrics() metrics.Client {
 	ret := _m.Called()
@@ -355,6 +409,60 @@ func (_m *MockBackend) RemoveWorkflowInstances(ctx context.Context, options ...R
 	return r0
 }
 
+// RecordActivityHeartbeat provides a mock function with given fields: ctx, task, details
+func (_m *MockBackend) RecordActivityHeartbeat(ctx context.Context, task *ActivityTask, details payload.Payload) error {
+	ret := _m.Called(ctx, task, details)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *ActivityTask, payload.Payload) error); ok {
+		r0 = rf(ctx, task, details)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetActivityTaskPending provides a mock function with given fields: ctx, task
+func (_m *MockBackend) SetActivityTaskPending(ctx context.Context, task *ActivityTask) error {
+	ret := _m.Called(ctx, task)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *ActivityTask) error); ok {
+		r0 = rf(ctx, task)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SignalWithStartWorkflowInstance provides a mock function with given fields: ctx, instance, startedEvent, signalEvent
+func (_m *MockBackend) SignalWithStartWorkflowInstance(ctx context.Context, instance *core.WorkflowInstance, startedEvent *history.Event, signalEvent *history.Event) (*core.WorkflowInstance, error) {
+	ret := _m.Called(ctx, instance, startedEvent, signalEvent)
+
+	var r0 *core.WorkflowInstance
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.WorkflowInstance, *history.Event, *history.Event) (*core.WorkflowInstance, error)); ok {
+		return rf(ctx, instance, startedEvent, signalEvent)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.WorkflowInstance, *history.Event, *history.Event) *core.WorkflowInstance); ok {
+		r0 = rf(ctx, instance, startedEvent, signalEvent)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.WorkflowInstance)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.WorkflowInstance, *history.Event, *history.Event) error); ok {
+		r1 = rf(ctx, instance, startedEvent, signalEvent)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SignalWorkflow provides a mock function with given fields: ctx, instanceID, event
 func (_m *MockBackend) SignalWorkflow(ctx context.Context, instanceID string, event *history.Event) error {
 	ret := _m.Called(ctx, instanceID, event)
@@ -369,6 +477,20 @@ func (_m *MockBackend) SignalWorkflow(ctx context.Context, instanceID string, ev
 	return r0
 }
 
+// TerminateWorkflowInstance provides a mock function with given fields: ctx, instance, terminateEvent
+func (_m *MockBackend) TerminateWorkflowInstance(ctx context.Context, instance *core.WorkflowInstance, terminateEvent *history.Event) error {
+	ret := _m.Called(ctx, instance, terminateEvent)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.WorkflowInstance, *history.Event) error); ok {
+		r0 = rf(ctx, instance, terminateEvent)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Tracer provides a mock function with given fields:
 func (_m *MockBackend) Tracer() trace.Tracer {
 	ret := _m.Called()
@@ -79,6 +79,16 @@ func (b *monoprocessBackend) CreateWorkflowInstance(ctx context.Context, instanc
 	if err := b.Backend.CreateWorkflowInstance(ctx, instance, event); err != nil {
 		return err
 	}
+
+	if event.VisibleAt != nil {
+		// Delayed start, notify the worker once the workflow instance can be started
+		time.AfterFunc(time.Until(*event.VisibleAt), func() {
+			b.notifyWorkflowWorker(ctx)
+		})
+
+		return nil
+	}
+
 	b.notifyWorkflowWorker(ctx)
 	return nil
 }
@@ -133,6 +143,15 @@ func (b *monoprocessBackend) CancelWorkflowInstance(ctx context.Context, instanc
 	return nil
 }
 
+func (b *monoprocessBackend) TerminateWorkflowInstance(ctx context.Context, instance *workflow.Instance, terminateEvent *history.Event) error {
+	if err := b.Backend.TerminateWorkflowInstance(ctx, instance, terminateEvent); err != nil {
+		return err
+	}
+	// Parent instance might have been notified about the terminated sub-workflow
+	b.notifyWorkflowWorker(ctx)
+	return nil
+}
+
 func (b *monoprocessBackend) SignalWorkflow(ctx context.Context, instanceID string, event *history.Event) error {
 	if err := b.Backend.SignalWorkflow(ctx, instanceID, event); err != nil {
 		return err
@@ -141,6 +160,23 @@ func (b *monoprocessBackend) SignalWorkflow(ctx context.Context, instanceID stri
 	return nil
 }
 
+func (b *monoprocessBackend) SignalWithStartWorkflowInstance(ctx context.Context, instance *workflow.Instance, startedEvent, signalEvent *history.Event) (*workflow.Instance, error) {
+	instance, err := b.Backend.SignalWithStartWorkflowInstance(ctx, instance, startedEvent, signalEvent)
+	if err != nil {
+		return nil, err
+	}
+
+	// A new instance with a delayed start is picked up once the start is due
+	if startedEvent.VisibleAt != nil {
+		time.AfterFunc(time.Until(*startedEvent.VisibleAt), func() {
+			b.notifyWorkflowWorker(ctx)
+		})
+	}
+
+	b.notifyWorkflowWorker(ctx)
+	return instance, nil
+}
+
 func (b *monoprocessBackend) notifyActivityWorker(ctx context.Context) {
 	select {
 	case b.activitySignal <- struct{}{}:
@@ -0,0 +1,8 @@
+DROP INDEX `idx_instances_created_at` ON `instances`;
+DROP INDEX `idx_instances_workflow_name_created_at` ON `instances`;
+DROP INDEX `idx_instances_state_created_at` ON `instances`;
+DROP INDEX `idx_instances_queue_created_at` ON `instances`;
+DROP INDEX `idx_instances_completed_at_failed` ON `instances`;
+
+ALTER TABLE `instances` DROP COLUMN `workflow_name`;
+ALTER TABLE `instances` DROP COLUMN `failed`;
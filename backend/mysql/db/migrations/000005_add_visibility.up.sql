@@ -0,0 +1,9 @@
+-- Add columns for listing and filtering workflow instances
+ALTER TABLE `instances` ADD COLUMN `workflow_name` NVARCHAR(256) NOT NULL DEFAULT '';
+ALTER TABLE `instances` ADD COLUMN `failed` BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX `idx_instances_created_at` ON `instances` (`created_at`);
+CREATE INDEX `idx_instances_workflow_name_created_at` ON `instances` (`workflow_name`, `created_at`);
+CREATE INDEX `idx_instances_state_created_at` ON `instances` (`state`, `created_at`);
+CREATE INDEX `idx_instances_queue_created_at` ON `instances` (`queue`, `created_at`);
+CREATE INDEX `idx_instances_completed_at_failed` ON `instances` (`completed_at`, `failed`);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS `search_attributes` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  `instance_id` NVARCHAR(128) NOT NULL,
+  `execution_id` NVARCHAR(128) NOT NULL,
+  `name` NVARCHAR(255) NOT NULL,
+  `value` NVARCHAR(255) NOT NULL,
+
+  UNIQUE INDEX `idx_search_attributes_instance_id_execution_id_name` (`instance_id`, `execution_id`, `name`),
+  INDEX `idx_search_attributes_name_value` (`name`, `value`)
+);
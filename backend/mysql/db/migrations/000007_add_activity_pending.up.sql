@@ -0,0 +1 @@
+ALTER TABLE `activities` ADD COLUMN `async_pending` BOOLEAN NOT NULL DEFAULT FALSE;
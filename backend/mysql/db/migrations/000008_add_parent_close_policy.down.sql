@@ -0,0 +1 @@
+ALTER TABLE `instances` DROP COLUMN `parent_close_policy`;
@@ -0,0 +1 @@
+ALTER TABLE `instances` ADD COLUMN `parent_close_policy` INT NOT NULL DEFAULT 0;
@@ -131,10 +131,17 @@ CREATE TABLE `instances` (
   `sticky_until` datetime DEFAULT NULL,
   `worker` varchar(64) CHARACTER SET utf8mb3 COLLATE utf8mb3_general_ci DEFAULT NULL,
   `queue` varchar(128) CHARACTER SET utf8mb3 COLLATE utf8mb3_general_ci DEFAULT '',
+  `workflow_name` varchar(256) CHARACTER SET utf8mb3 COLLATE utf8mb3_general_ci NOT NULL DEFAULT '',
+  `failed` tinyint(1) NOT NULL DEFAULT '0',
   PRIMARY KEY (`id`),
   UNIQUE KEY `idx_instances_instance_id_execution_id` (`instance_id`,`execution_id`),
   KEY `idx_instances_parent_instance_id_parent_execution_id` (`parent_instance_id`,`parent_execution_id`),
-  KEY `idx_instances_locked_until_completed_at_queue` (`completed_at`,`locked_until`,`sticky_until`,`worker`,`queue`)
+  KEY `idx_instances_locked_until_completed_at_queue` (`completed_at`,`locked_until`,`sticky_until`,`worker`,`queue`),
+  KEY `idx_instances_created_at` (`created_at`),
+  KEY `idx_instances_workflow_name_created_at` (`workflow_name`,`created_at`),
+  KEY `idx_instances_state_created_at` (`state`,`created_at`),
+  KEY `idx_instances_queue_created_at` (`queue`,`created_at`),
+  KEY `idx_instances_completed_at_failed` (`completed_at`,`failed`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
 
 
@@ -177,6 +184,34 @@ CREATE TABLE `pending_events` (
 LOCK TABLES `pending_events` WRITE;
 
 
+UNLOCK TABLES;
+
+--
+-- Table structure for table `search_attributes`
+--
+
+DROP TABLE IF EXISTS `search_attributes`;
+
+
+CREATE TABLE `search_attributes` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `instance_id` varchar(128) CHARACTER SET utf8mb3 COLLATE utf8mb3_general_ci NOT NULL,
+  `execution_id` varchar(128) CHARACTER SET utf8mb3 COLLATE utf8mb3_general_ci NOT NULL,
+  `name` varchar(255) CHARACTER SET utf8mb3 COLLATE utf8mb3_general_ci NOT NULL,
+  `value` varchar(255) CHARACTER SET utf8mb3 COLLATE utf8mb3_general_ci NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_search_attributes_instance_id_execution_id_name` (`instance_id`,`execution_id`,`name`),
+  KEY `idx_search_attributes_name_value` (`name`,`value`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+
+--
+-- Dumping data for table `search_attributes`
+--
+
+LOCK TABLES `search_attributes` WRITE;
+
+
 UNLOCK TABLES;
 
 --
@@ -199,7 +234,7 @@ CREATE TABLE `schema_migrations` (
 
 LOCK TABLES `schema_migrations` WRITE;
 
-INSERT INTO `schema_migrations` VALUES (4,0);
+INSERT INTO `schema_migrations` VALUES (6,0);
 
 UNLOCK TABLES;
 
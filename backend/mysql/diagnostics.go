@@ -84,6 +84,16 @@ func (mb *mysqlBackend) GetWorkflowInstances(ctx context.Context, afterInstanceI
 		})
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows.Close()
+
+	if err := setSearchAttributes(ctx, tx, instances); err != nil {
+		return nil, err
+	}
+
 	return instances, nil
 }
 
@@ -127,13 +137,37 @@ func (mb *mysqlBackend) GetWorkflowInstance(ctx context.Context, instance *core.
 		instance = core.NewWorkflowInstance(id, executionID)
 	}
 
-	return &diag.WorkflowInstanceRef{
+	ref := &diag.WorkflowInstanceRef{
 		Instance:    core.NewWorkflowInstance(id, executionID),
 		CreatedAt:   createdAt,
 		CompletedAt: completedAt,
 		State:       state,
 		Queue:       queue,
-	}, nil
+	}
+
+	if err := setSearchAttributes(ctx, tx, []*diag.WorkflowInstanceRef{ref}); err != nil {
+		return nil, err
+	}
+
+	return ref, nil
+}
+
+func setSearchAttributes(ctx context.Context, tx *sql.Tx, refs []*diag.WorkflowInstanceRef) error {
+	instances := make([]*core.WorkflowInstance, 0, len(refs))
+	for _, ref := range refs {
+		instances = append(instances, ref.Instance)
+	}
+
+	searchAttributes, err := getSearchAttributes(ctx, tx, instances)
+	if err != nil {
+		return err
+	}
+
+	for i, ref := range refs {
+		ref.SearchAttributes = searchAttributes[i]
+	}
+
+	return nil
 }
 
 func (mb *mysqlBackend) GetWorkflowTree(ctx context.Context, instance *core.WorkflowInstance) (*diag.WorkflowInstanceTree, error) {
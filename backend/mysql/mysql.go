@@ -15,6 +15,7 @@ import (
 	"github.com/cschleiden/go-workflows/backend/history"
 	"github.com/cschleiden/go-workflows/backend/metadata"
 	"github.com/cschleiden/go-workflows/backend/metrics"
+	"github.com/cschleiden/go-workflows/backend/payload"
 	"github.com/cschleiden/go-workflows/core"
 	"github.com/cschleiden/go-workflows/internal/metrickeys"
 	"github.com/cschleiden/go-workflows/internal/workflowerrors"
@@ -143,12 +144,12 @@ func (b *mysqlBackend) CreateWorkflowInstance(ctx context.Context, instance *wor
 	a := event.Attributes.(*history.ExecutionStartedAttributes)
 
 	// Create workflow instance
-	if err := createInstance(ctx, tx, a.Queue, instance, a.Metadata); err != nil {
+	if err := createInstance(ctx, tx, a.Queue, instance, a); err != nil {
 		return err
 	}
 
 	// Initial history is empty, store only new events
-	if err := insertPendingEvents(ctx, tx, instance, []*history.Event{event}); err != nil {
+	if err := insertPendingEvents(ctx, tx, instance, withWorkflowTimeoutEvent(event)); err != nil {
 		return fmt.Errorf("inserting new event: %w", err)
 	}
 
@@ -159,6 +160,53 @@ func (b *mysqlBackend) CreateWorkflowInstance(ctx context.Context, instance *wor
 	return nil
 }
 
+func (b *mysqlBackend) SignalWithStartWorkflowInstance(ctx context.Context, instance *workflow.Instance, startedEvent, signalEvent *history.Event) (*workflow.Instance, error) {
+	// Use the default repeatable read isolation, so that locking the active execution below also prevents
+	// concurrent creation of a new execution for the same instance id.
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Signal the active execution, if there is one
+	var executionID string
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT execution_id FROM `instances` WHERE instance_id = ? AND state = ? LIMIT 1 FOR UPDATE",
+		instance.InstanceID,
+		core.WorkflowInstanceStateActive,
+	).Scan(&executionID)
+	switch {
+	case err == nil:
+		instance = core.NewWorkflowInstance(instance.InstanceID, executionID)
+
+		if err := insertPendingEvents(ctx, tx, instance, []*history.Event{signalEvent}); err != nil {
+			return nil, fmt.Errorf("inserting signal event: %w", err)
+		}
+
+	case err == sql.ErrNoRows:
+		a := startedEvent.Attributes.(*history.ExecutionStartedAttributes)
+
+		if err := createInstance(ctx, tx, a.Queue, instance, a); err != nil {
+			return nil, err
+		}
+
+		if err := insertPendingEvents(ctx, tx, instance, append(withWorkflowTimeoutEvent(startedEvent), signalEvent)); err != nil {
+			return nil, fmt.Errorf("inserting new events: %w", err)
+		}
+
+	default:
+		return nil, fmt.Errorf("getting active execution: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("signaling workflow instance: %w", err)
+	}
+
+	return instance, nil
+}
+
 func (b *mysqlBackend) RemoveWorkflowInstance(ctx context.Context, instance *core.WorkflowInstance) error {
 	tx, err := b.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -166,40 +214,71 @@ func (b *mysqlBackend) RemoveWorkflowInstance(ctx context.Context, instance *cor
 	}
 	defer tx.Rollback()
 
-	if err := b.removeWorkflowInstance(ctx, instance, tx); err != nil {
+	stored, err := b.removeWorkflowInstance(ctx, instance, tx)
+	if err != nil {
 		return err
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return backend.ReleasePayloads(ctx, b.options.Converter, stored)
 }
 
-func (b *mysqlBackend) removeWorkflowInstance(ctx context.Context, instance *core.WorkflowInstance, tx *sql.Tx) error {
+// removeWorkflowInstance removes the given instance and returns the payloads stored outside of its history,
+// which have to be released once the transaction has been committed.
+func (b *mysqlBackend) removeWorkflowInstance(ctx context.Context, instance *core.WorkflowInstance, tx *sql.Tx) ([]payload.Payload, error) {
 	row := tx.QueryRowContext(ctx, "SELECT state FROM `instances` WHERE instance_id = ? AND execution_id = ? LIMIT 1", instance.InstanceID, instance.ExecutionID)
 	var state core.WorkflowInstanceState
 	if err := row.Scan(&state); err != nil {
 		if err == sql.ErrNoRows {
-			return backend.ErrInstanceNotFound
+			return nil, backend.ErrInstanceNotFound
 		}
 	}
 
 	if state == core.WorkflowInstanceStateActive {
-		return backend.ErrInstanceNotFinished
+		return nil, backend.ErrInstanceNotFinished
+	}
+
+	stored, err := b.storedPayloads(ctx, tx, instance)
+	if err != nil {
+		return nil, err
 	}
 
 	// Delete from instances and history tables
 	if _, err := tx.ExecContext(ctx, "DELETE FROM `instances` WHERE instance_id = ? AND execution_id = ?", instance.InstanceID, instance.ExecutionID); err != nil {
-		return err
+		return nil, err
 	}
 
 	if _, err := tx.ExecContext(ctx, "DELETE FROM `history` WHERE instance_id = ? AND execution_id = ?", instance.InstanceID, instance.ExecutionID); err != nil {
-		return err
+		return nil, err
 	}
 
 	if _, err := tx.ExecContext(ctx, "DELETE FROM `attributes` WHERE instance_id = ? AND execution_id = ?", instance.InstanceID, instance.ExecutionID); err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	if _, err := tx.ExecContext(ctx, "DELETE FROM `search_attributes` WHERE instance_id = ? AND execution_id = ?", instance.InstanceID, instance.ExecutionID); err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+// storedPayloads returns the payloads stored outside of the history of the given instance, if the converter
+// stores payloads
+func (b *mysqlBackend) storedPayloads(ctx context.Context, tx *sql.Tx, instance *core.WorkflowInstance) ([]payload.Payload, error) {
+	if !backend.StoresPayloads(b.options.Converter) {
+		return nil, nil
+	}
+
+	h, err := getHistory(ctx, tx, instance, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting workflow history: %w", err)
+	}
+
+	return backend.StoredPayloads(b.options.Converter, h), nil
 }
 
 func (b *mysqlBackend) RemoveWorkflowInstances(ctx context.Context, options ...backend.RemovalOption) error {
@@ -237,6 +316,16 @@ func (b *mysqlBackend) RemoveWorkflowInstances(ctx context.Context, options ...b
 
 		defer tx.Rollback()
 
+		var stored []payload.Payload
+		for i := range instanceIDs {
+			ps, err := b.storedPayloads(ctx, tx, core.NewWorkflowInstance(instanceIDs[i], executionIDs[i]))
+			if err != nil {
+				return err
+			}
+
+			stored = append(stored, ps...)
+		}
+
 		placeholders := strings.Repeat(",?", len(instanceIDs)-1)
 		whereCondition := fmt.Sprintf("instance_id IN (?%v) AND execution_id IN (?%v)", placeholders, placeholders)
 		args := make([]interface{}, 0, len(instanceIDs)*2)
@@ -247,7 +336,7 @@ func (b *mysqlBackend) RemoveWorkflowInstances(ctx context.Context, options ...b
 			args = append(args, executionIDs[i])
 		}
 
-		// Delete from instances, history and attributes tables
+		// Delete from instances, history, attributes, and search attributes tables
 		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM `instances` WHERE %v", whereCondition), args...); err != nil {
 			return err
 		}
@@ -260,9 +349,17 @@ func (b *mysqlBackend) RemoveWorkflowInstances(ctx context.Context, options ...b
 			return err
 		}
 
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM `search_attributes` WHERE %v", whereCondition), args...); err != nil {
+			return err
+		}
+
 		if err := tx.Commit(); err != nil {
 			return err
 		}
+
+		if err := backend.ReleasePayloads(ctx, b.options.Converter, stored); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -295,6 +392,191 @@ func (b *mysqlBackend) CancelWorkflowInstance(ctx context.Context, instance *wor
 	return tx.Commit()
 }
 
+func (b *mysqlBackend) TerminateWorkflowInstance(ctx context.Context, instance *workflow.Instance, event *history.Event) error {
+	tx, err := b.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelReadCommitted,
+	})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := terminateInstance(ctx, tx, instance, event); err != nil {
+		return err
+	}
+
+	if err := applyParentClosePolicy(ctx, tx, instance); err != nil {
+		return fmt.Errorf("applying parent close policy: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// terminateInstance finishes the given active instance with the termination event, and notifies its parent
+func terminateInstance(ctx context.Context, tx *sql.Tx, instance *workflow.Instance, event *history.Event) error {
+	row := tx.QueryRowContext(
+		ctx,
+		"SELECT state, parent_instance_id, parent_execution_id, parent_schedule_event_id FROM `instances` WHERE instance_id = ? AND execution_id = ? LIMIT 1 FOR UPDATE",
+		instance.InstanceID,
+		instance.ExecutionID,
+	)
+
+	var state core.WorkflowInstanceState
+	var parentInstanceID, parentExecutionID *string
+	var parentEventID *int64
+	if err := row.Scan(&state, &parentInstanceID, &parentExecutionID, &parentEventID); err != nil {
+		if err == sql.ErrNoRows {
+			return backend.ErrInstanceNotFound
+		}
+
+		return err
+	}
+
+	if state != core.WorkflowInstanceStateActive {
+		return backend.ErrInstanceNotActive
+	}
+
+	// Append termination event to the history
+	var lastSequenceID sql.NullInt64
+	if err := tx.QueryRowContext(
+		ctx, "SELECT MAX(sequence_id) FROM `history` WHERE instance_id = ? AND execution_id = ?", instance.InstanceID, instance.ExecutionID,
+	).Scan(&lastSequenceID); err != nil {
+		return fmt.Errorf("getting most recent sequence id: %w", err)
+	}
+
+	event.SequenceID = lastSequenceID.Int64 + 1
+
+	if err := insertHistoryEvents(ctx, tx, instance, []*history.Event{event}); err != nil {
+		return fmt.Errorf("inserting termination event: %w", err)
+	}
+
+	// Finish the instance. Clearing the worker ensures that a workflow task that is currently being
+	// processed for this instance cannot be completed anymore.
+	if _, err := tx.ExecContext(
+		ctx,
+		"UPDATE `instances` SET state = ?, completed_at = ?, failed = 1, locked_until = NULL, sticky_until = NULL, worker = NULL WHERE instance_id = ? AND execution_id = ?",
+		core.WorkflowInstanceStateFinished,
+		time.Now(),
+		instance.InstanceID,
+		instance.ExecutionID,
+	); err != nil {
+		return fmt.Errorf("finishing workflow instance: %w", err)
+	}
+
+	// Abandon pending events including future timer events, and any activities
+	if _, err := tx.ExecContext(
+		ctx,
+		"DELETE `pending_events`, `attributes` FROM `pending_events` INNER JOIN `attributes` ON `pending_events`.event_id = `attributes`.event_id AND `pending_events`.instance_id = `attributes`.instance_id AND `pending_events`.execution_id = `attributes`.execution_id WHERE `pending_events`.instance_id = ? AND `pending_events`.execution_id = ?",
+		instance.InstanceID,
+		instance.ExecutionID,
+	); err != nil {
+		return fmt.Errorf("removing pending events: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM `activities` WHERE instance_id = ? AND execution_id = ?", instance.InstanceID, instance.ExecutionID); err != nil {
+		return fmt.Errorf("removing activities: %w", err)
+	}
+
+	// Notify parent instance, if it's still active
+	if parentInstanceID != nil && parentExecutionID != nil && parentEventID != nil {
+		var parentState core.WorkflowInstanceState
+		if err := tx.QueryRowContext(
+			ctx, "SELECT state FROM `instances` WHERE instance_id = ? AND execution_id = ? LIMIT 1", *parentInstanceID, *parentExecutionID,
+		).Scan(&parentState); err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("reading parent instance: %w", err)
+		} else if err == nil && parentState == core.WorkflowInstanceStateActive {
+			if err := insertPendingEvents(ctx, tx, core.NewWorkflowInstance(*parentInstanceID, *parentExecutionID), []*history.Event{
+				history.NewPendingEvent(time.Now(), history.EventType_SubWorkflowFailed, &history.SubWorkflowFailedAttributes{
+					Error: workflowerrors.NewPermanentError(backend.ErrInstanceTerminated),
+				}, history.ScheduleEventID(*parentEventID)),
+			}); err != nil {
+				return fmt.Errorf("inserting sub-workflow failed event: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// moveSignals moves pending signal events from one execution of a workflow instance to another
+func moveSignals(ctx context.Context, tx *sql.Tx, from, to *workflow.Instance) error {
+	if _, err := tx.ExecContext(
+		ctx,
+		"UPDATE `attributes` INNER JOIN `pending_events` ON `pending_events`.event_id = `attributes`.event_id AND `pending_events`.instance_id = `attributes`.instance_id AND `pending_events`.execution_id = `attributes`.execution_id SET `attributes`.execution_id = ?, `pending_events`.execution_id = ? WHERE `pending_events`.instance_id = ? AND `pending_events`.execution_id = ? AND `pending_events`.event_type = ?",
+		to.ExecutionID, to.ExecutionID, from.InstanceID, from.ExecutionID, history.EventType_SignalReceived,
+	); err != nil {
+		return fmt.Errorf("moving signals: %w", err)
+	}
+
+	return nil
+}
+
+// applyParentClosePolicy applies the parent close policy of the running sub-workflow instances of the given
+// instance, which has just finished or was terminated
+func applyParentClosePolicy(ctx context.Context, tx *sql.Tx, instance *workflow.Instance) error {
+	rows, err := tx.QueryContext(
+		ctx,
+		"SELECT instance_id, execution_id, parent_schedule_event_id, parent_close_policy FROM `instances` WHERE parent_instance_id = ? AND parent_execution_id = ? AND state = ?",
+		instance.InstanceID,
+		instance.ExecutionID,
+		core.WorkflowInstanceStateActive,
+	)
+	if err != nil {
+		return fmt.Errorf("getting sub-workflow instances: %w", err)
+	}
+
+	type subWorkflow struct {
+		instance *workflow.Instance
+		policy   core.ParentClosePolicy
+	}
+
+	var subWorkflows []subWorkflow
+	for rows.Next() {
+		var instanceID, executionID string
+		var parentEventID int64
+		var policy core.ParentClosePolicy
+		if err := rows.Scan(&instanceID, &executionID, &parentEventID, &policy); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning sub-workflow instance: %w", err)
+		}
+
+		subWorkflows = append(subWorkflows, subWorkflow{
+			instance: core.NewSubWorkflowInstance(instanceID, executionID, instance, parentEventID),
+			policy:   policy,
+		})
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("getting sub-workflow instances: %w", err)
+	}
+
+	for _, swf := range subWorkflows {
+		switch swf.policy {
+		case core.ParentClosePolicyTerminate:
+			if err := terminateInstance(
+				ctx, tx, swf.instance, history.NewWorkflowTerminationEvent(time.Now(), "parent workflow instance finished"),
+			); err != nil {
+				return fmt.Errorf("terminating sub-workflow instance: %w", err)
+			}
+
+			// The sub-workflow instance is finished as well
+			if err := applyParentClosePolicy(ctx, tx, swf.instance); err != nil {
+				return err
+			}
+
+		case core.ParentClosePolicyRequestCancel:
+			if err := insertPendingEvents(ctx, tx, swf.instance, []*history.Event{
+				history.NewWorkflowCancellationEvent(time.Now()),
+			}); err != nil {
+				return fmt.Errorf("requesting sub-workflow cancellation: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
 func (b *mysqlBackend) GetWorkflowInstanceHistory(ctx context.Context, instance *workflow.Instance, lastSequenceID *int64) ([]*history.Event, error) {
 	tx, err := b.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -302,7 +584,17 @@ func (b *mysqlBackend) GetWorkflowInstanceHistory(ctx context.Context, instance
 	}
 	defer tx.Rollback()
 
+	h, err := getHistory(ctx, tx, instance, lastSequenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+func getHistory(ctx context.Context, tx *sql.Tx, instance *core.WorkflowInstance, lastSequenceID *int64) ([]*history.Event, error) {
 	var historyEvents *sql.Rows
+	var err error
 	if lastSequenceID != nil {
 		historyEvents, err = tx.QueryContext(
 			ctx,
@@ -375,14 +667,45 @@ func (b *mysqlBackend) GetWorkflowInstanceState(ctx context.Context, instance *w
 	return state, nil
 }
 
-func createInstance(ctx context.Context, tx *sql.Tx, queue workflow.Queue, wfi *workflow.Instance, metadata *workflow.Metadata) error {
-	// Check for existing instance
+func (b *mysqlBackend) GetLatestWorkflowInstance(ctx context.Context, instanceID string) (*workflow.Instance, error) {
+	row := b.db.QueryRowContext(
+		ctx,
+		"SELECT execution_id, parent_instance_id, parent_execution_id, parent_schedule_event_id FROM `instances` WHERE instance_id = ? ORDER BY id DESC LIMIT 1",
+		instanceID,
+	)
+
+	var executionID string
+	var parentInstanceID, parentExecutionID *string
+	var parentEventID *int64
+	if err := row.Scan(&executionID, &parentInstanceID, &parentExecutionID, &parentEventID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, backend.ErrInstanceNotFound
+		}
+
+		return nil, err
+	}
+
+	if parentInstanceID != nil {
+		return core.NewSubWorkflowInstance(
+			instanceID, executionID, core.NewWorkflowInstance(*parentInstanceID, *parentExecutionID), *parentEventID), nil
+	}
+
+	return core.NewWorkflowInstance(instanceID, executionID), nil
+}
+
+func createInstance(ctx context.Context, tx *sql.Tx, queue workflow.Queue, wfi *workflow.Instance, a *history.ExecutionStartedAttributes) error {
+	// Check the latest execution of the instance against the id reuse policy
+	var state core.WorkflowInstanceState
+	var failed bool
 	if err := tx.QueryRowContext(
 		ctx,
-		"SELECT 1 FROM `instances` WHERE instance_id = ? AND state = ? LIMIT 1",
-		wfi.InstanceID,
-		core.WorkflowInstanceStateActive).
-		Scan(new(int)); err != sql.ErrNoRows {
+		"SELECT state, failed FROM `instances` WHERE instance_id = ? ORDER BY id DESC LIMIT 1",
+		wfi.InstanceID).
+		Scan(&state, &failed); err != nil {
+		if err != sql.ErrNoRows {
+			return fmt.Errorf("reading latest execution: %w", err)
+		}
+	} else if !a.IDReusePolicy.Allows(state, failed) {
 		return backend.ErrInstanceAlreadyExists
 	}
 
@@ -394,20 +717,22 @@ func createInstance(ctx context.Context, tx *sql.Tx, queue workflow.Queue, wfi *
 		parentEventID = &wfi.ParentEventID
 	}
 
-	metadataJson, err := json.Marshal(metadata)
+	metadataJson, err := json.Marshal(a.Metadata)
 	if err != nil {
 		return fmt.Errorf("marshaling metadata: %w", err)
 	}
 
 	_, err = tx.ExecContext(
 		ctx,
-		"INSERT INTO `instances` (queue, instance_id, execution_id, parent_instance_id, parent_execution_id, parent_schedule_event_id, metadata, state) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		"INSERT INTO `instances` (queue, instance_id, execution_id, workflow_name, parent_instance_id, parent_execution_id, parent_schedule_event_id, parent_close_policy, metadata, state) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
 		string(queue),
 		wfi.InstanceID,
 		wfi.ExecutionID,
+		a.Name,
 		parentInstanceID,
 		parentExecutionID,
 		parentEventID,
+		a.ParentClosePolicy,
 		string(metadataJson),
 		core.WorkflowInstanceStateActive,
 	)
@@ -415,6 +740,10 @@ func createInstance(ctx context.Context, tx *sql.Tx, queue workflow.Queue, wfi *
 		return fmt.Errorf("inserting workflow instance: %w", err)
 	}
 
+	if err := upsertSearchAttributes(ctx, tx, wfi, a.SearchAttributes); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -476,6 +805,11 @@ func (b *mysqlBackend) GetWorkflowTask(ctx context.Context, queues []workflow.Qu
 		args = append(args, string(q))
 	}
 
+	args = append(args,
+		history.EventType_WorkflowExecutionStarted, // delayed start event_type
+		now, // delayed start visible_at
+	)
+
 	// Lock next workflow task by finding an unlocked instance with new events to process.
 	row := tx.QueryRowContext(
 		ctx,
@@ -488,6 +822,11 @@ func (b *mysqlBackend) GetWorkflowTask(ctx context.Context, queues []workflow.Qu
 				AND (i.locked_until IS NULL OR i.locked_until < ?)
 				AND (i.sticky_until IS NULL OR i.sticky_until < ? OR i.worker = ?)
 				AND (i.queue in (?%s))
+				-- Instances with a delayed start are not executed before they are started, even if they received other events
+				AND NOT EXISTS (
+					SELECT 1 FROM pending_events dpe
+					WHERE dpe.instance_id = i.instance_id AND dpe.execution_id = i.execution_id AND dpe.event_type = ? AND dpe.visible_at > ?
+				)
 			LIMIT 1
 			FOR UPDATE OF i SKIP LOCKED`, queuePlaceholders),
 		args...,
@@ -649,10 +988,11 @@ func (b *mysqlBackend) CompleteWorkflowTask(
 
 	res, err := tx.ExecContext(
 		ctx,
-		`UPDATE instances SET locked_until = NULL, sticky_until = ?, completed_at = ?, state = ? WHERE instance_id = ? AND execution_id = ? AND worker = ?`,
+		`UPDATE instances SET locked_until = NULL, sticky_until = ?, completed_at = ?, state = ?, failed = ? WHERE instance_id = ? AND execution_id = ? AND worker = ?`,
 		time.Now().Add(b.options.StickyTimeout),
 		completedAt,
 		state,
+		backend.ExecutionFailed(executedEvents),
 		instance.InstanceID,
 		instance.ExecutionID,
 		b.workerName,
@@ -690,6 +1030,10 @@ func (b *mysqlBackend) CompleteWorkflowTask(
 		return fmt.Errorf("inserting new history events: %w", err)
 	}
 
+	if err := upsertSearchAttributes(ctx, tx, instance, backend.SearchAttributesFromEvents(executedEvents)); err != nil {
+		return err
+	}
+
 	// Schedule activities
 	for _, e := range activityEvents {
 		a := e.Attributes.(*history.ActivityScheduledAttributes)
@@ -718,6 +1062,16 @@ func (b *mysqlBackend) CompleteWorkflowTask(
 		}
 	}
 
+	// Remove the future event enforcing the workflow timeouts, if any, once the execution is done
+	if completedAt != nil {
+		if err := removeFutureEvent(ctx, tx, instance, 0); err != nil {
+			return fmt.Errorf("removing workflow timeout event: %w", err)
+		}
+	}
+
+	// Payloads stored outside of the history that are not referenced anymore once the transaction is committed
+	var released []payload.Payload
+
 	// Insert new workflow events
 	groupedEvents := history.EventsByWorkflowInstance(workflowEvents)
 
@@ -733,7 +1087,7 @@ func (b *mysqlBackend) CompleteWorkflowTask(
 			}
 
 			// Create new instance
-			if err := createInstance(ctx, tx, queue, m.WorkflowInstance, a.Metadata); err != nil {
+			if err := createInstance(ctx, tx, queue, m.WorkflowInstance, a); err != nil {
 				if err == backend.ErrInstanceAlreadyExists {
 					if err := insertPendingEvents(ctx, tx, instance, []*history.Event{
 						history.NewPendingEvent(time.Now(), history.EventType_SubWorkflowFailed, &history.SubWorkflowFailedAttributes{
@@ -743,16 +1097,32 @@ func (b *mysqlBackend) CompleteWorkflowTask(
 						return fmt.Errorf("inserting sub-workflow failed event: %w", err)
 					}
 
+					released = append(released, backend.StoredPayloads(b.options.Converter, []*history.Event{m.HistoryEvent})...)
+
 					continue
 				}
 
 				return fmt.Errorf("creating sub-workflow instance: %w", err)
 			}
+
+			// Notify the parent of a detached sub-workflow instance once it has been created
+			if a.Detached && state == core.WorkflowInstanceStateActive {
+				if err := insertPendingEvents(ctx, tx, instance, []*history.Event{
+					history.NewSubWorkflowStartedEvent(time.Now(), m.WorkflowInstance),
+				}); err != nil {
+					return fmt.Errorf("inserting sub-workflow started event: %w", err)
+				}
+			}
 		}
 
 		// Insert pending events for target instance
 		historyEvents := []*history.Event{}
 		for _, m := range events {
+			if m.HistoryEvent.Type == history.EventType_WorkflowExecutionStarted {
+				historyEvents = append(historyEvents, withWorkflowTimeoutEvent(m.HistoryEvent)...)
+				continue
+			}
+
 			historyEvents = append(historyEvents, m.HistoryEvent)
 		}
 		if err := insertPendingEvents(ctx, tx, &targetInstance, historyEvents); err != nil {
@@ -760,16 +1130,38 @@ func (b *mysqlBackend) CompleteWorkflowTask(
 		}
 	}
 
+	// Signals the current execution did not process are delivered to the new execution
+	if continuedInstance := backend.ContinuedExecution(instance, executedEvents); continuedInstance != nil {
+		if err := moveSignals(ctx, tx, instance, continuedInstance); err != nil {
+			return err
+		}
+	}
+
+	// Sub-workflow instances keep running when the instance continues as new
+	if state == core.WorkflowInstanceStateFinished {
+		if err := applyParentClosePolicy(ctx, tx, instance); err != nil {
+			return fmt.Errorf("applying parent close policy: %w", err)
+		}
+	}
+
 	if b.options.RemoveContinuedAsNewInstances && state == core.WorkflowInstanceStateContinuedAsNew {
-		if err := b.removeWorkflowInstance(ctx, instance, tx); err != nil {
+		stored, err := b.removeWorkflowInstance(ctx, instance, tx)
+		if err != nil {
 			return fmt.Errorf("removing old instance: %w", err)
 		}
+
+		released = append(released, stored...)
 	}
 
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("committing complete workflow transaction: %w", err)
 	}
 
+	// The task has been completed, only log failures to release payloads
+	if err := backend.ReleasePayloads(ctx, b.options.Converter, released); err != nil {
+		b.options.Logger.Error("releasing stored payloads", "error", err)
+	}
+
 	return nil
 }
 
@@ -826,10 +1218,10 @@ func (b *mysqlBackend) GetActivityTask(ctx context.Context, queues []workflow.Qu
 	res := tx.QueryRowContext(
 		ctx,
 		fmt.Sprintf(`SELECT a.id, a.activity_id, a.instance_id, a.execution_id, a.queue,
-			a.event_type, a.timestamp, a.schedule_event_id, at.data, a.visible_at
+			a.event_type, a.timestamp, a.schedule_event_id, at.data, a.visible_at, a.heartbeat_details
 			FROM activities a
 			JOIN attributes at ON at.event_id = a.activity_id AND at.instance_id = a.instance_id AND at.execution_id = a.execution_id
-			WHERE (a.locked_until IS NULL OR a.locked_until < ?) AND a.queue IN (?%s)
+			WHERE (a.locked_until IS NULL OR a.locked_until < ?) AND NOT a.async_pending AND a.queue IN (?%s)
 			LIMIT 1
 			FOR UPDATE SKIP LOCKED`, queuePlaceholders),
 		args...,
@@ -837,12 +1229,12 @@ func (b *mysqlBackend) GetActivityTask(ctx context.Context, queues []workflow.Qu
 
 	var id int64
 	var instanceID, executionID, queue string
-	var attributes []byte
+	var attributes, heartbeatDetails []byte
 	event := &history.Event{}
 
 	if err := res.Scan(
 		&id, &event.ID, &instanceID, &executionID, &queue, &event.Type,
-		&event.Timestamp, &event.ScheduleEventID, &attributes, &event.VisibleAt); err != nil {
+		&event.Timestamp, &event.ScheduleEventID, &attributes, &event.VisibleAt, &heartbeatDetails); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -857,6 +1249,13 @@ func (b *mysqlBackend) GetActivityTask(ctx context.Context, queues []workflow.Qu
 
 	event.Attributes = a
 
+	// The activity has been picked up, remove the future event enforcing its ScheduleToStart timeout
+	if a.(*history.ActivityScheduledAttributes).ScheduleToStartTimeout > 0 {
+		if err := removeFutureEvent(ctx, tx, core.NewWorkflowInstance(instanceID, executionID), event.ScheduleEventID); err != nil {
+			return nil, fmt.Errorf("removing schedule to start timeout event: %w", err)
+		}
+	}
+
 	if _, err := tx.ExecContext(
 		ctx,
 		`UPDATE activities SET locked_until = ?, worker = ? WHERE id = ?`,
@@ -868,11 +1267,12 @@ func (b *mysqlBackend) GetActivityTask(ctx context.Context, queues []workflow.Qu
 	}
 
 	t := &backend.ActivityTask{
-		ID:               event.ID,
-		ActivityID:       event.ID,
-		Queue:            workflow.Queue(queue),
-		WorkflowInstance: core.NewWorkflowInstance(instanceID, executionID),
-		Event:            event,
+		ID:                   event.ID,
+		ActivityID:           event.ID,
+		Queue:                workflow.Queue(queue),
+		WorkflowInstance:     core.NewWorkflowInstance(instanceID, executionID),
+		Event:                event,
+		LastHeartbeatDetails: heartbeatDetails,
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -895,7 +1295,7 @@ func (b *mysqlBackend) CompleteActivityTask(ctx context.Context, task *backend.A
 	// Remove activity
 	if res, err := tx.ExecContext(
 		ctx,
-		`DELETE FROM activities WHERE activity_id = ? AND instance_id = ? AND execution_id = ? AND worker = ? AND queue = ?`,
+		`DELETE FROM activities WHERE activity_id = ? AND instance_id = ? AND execution_id = ? AND (worker = ? OR async_pending) AND queue = ?`,
 		task.ActivityID,
 		task.WorkflowInstance.InstanceID,
 		task.WorkflowInstance.ExecutionID,
@@ -926,6 +1326,59 @@ func (b *mysqlBackend) CompleteActivityTask(ctx context.Context, task *backend.A
 	return nil
 }
 
+func (b *mysqlBackend) RecordActivityHeartbeat(ctx context.Context, task *backend.ActivityTask, details payload.Payload) error {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The task might have been completed or, after its lock expired, picked up by another worker. Check this
+	// separately, MySQL does not count rows as affected if the details did not change.
+	var id int64
+	if err := tx.QueryRowContext(
+		ctx,
+		`SELECT id FROM activities WHERE activity_id = ? AND instance_id = ? AND execution_id = ? AND worker = ? FOR UPDATE`,
+		task.ActivityID,
+		task.WorkflowInstance.InstanceID,
+		task.WorkflowInstance.ExecutionID,
+		b.workerName,
+	).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return backend.ErrTaskNotFound
+		}
+
+		return fmt.Errorf("finding activity task: %w", err)
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		`UPDATE activities SET heartbeat_details = ? WHERE id = ?`,
+		[]byte(details),
+		id,
+	); err != nil {
+		return fmt.Errorf("recording activity heartbeat: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (b *mysqlBackend) SetActivityTaskPending(ctx context.Context, task *backend.ActivityTask) error {
+	// The activity might have been completed already, nothing to do in that case
+	if _, err := b.db.ExecContext(
+		ctx,
+		`UPDATE activities SET async_pending = TRUE WHERE activity_id = ? AND instance_id = ? AND execution_id = ? AND worker = ?`,
+		task.ActivityID,
+		task.WorkflowInstance.InstanceID,
+		task.WorkflowInstance.ExecutionID,
+		b.workerName,
+	); err != nil {
+		return fmt.Errorf("setting activity pending: %w", err)
+	}
+
+	return nil
+}
+
 func (b *mysqlBackend) ExtendActivityTask(ctx context.Context, task *backend.ActivityTask) error {
 	tx, err := b.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -46,6 +46,14 @@ type Options struct {
 
 	// MaxHistorySize is the maximum size of a workflow history. If a workflow exceeds this size, it will be failed.
 	MaxHistorySize int64
+
+	// MaxHistorySizeBytes is the maximum approximate size of a workflow history in bytes, see
+	// workflow.HistorySizeBytes. If a workflow exceeds this size, it will be failed. Set to 0 for no limit.
+	MaxHistorySizeBytes int64
+
+	// ContinueAsNewSuggestedThreshold is the fraction of MaxHistorySize and MaxHistorySizeBytes at which
+	// workflow.ContinueAsNewSuggested starts returning true. Set to 0 to never suggest continuing as new.
+	ContinueAsNewSuggestedThreshold float64
 }
 
 var DefaultOptions Options = Options{
@@ -62,7 +70,8 @@ var DefaultOptions Options = Options{
 
 	RemoveContinuedAsNewInstances: false,
 
-	MaxHistorySize: 10_000,
+	MaxHistorySize:                  10_000,
+	ContinueAsNewSuggestedThreshold: 0.8,
 }
 
 type BackendOption func(*Options)
@@ -115,6 +124,18 @@ func WithMaxHistorySize(size int64) BackendOption {
 	}
 }
 
+func WithMaxHistorySizeBytes(size int64) BackendOption {
+	return func(o *Options) {
+		o.MaxHistorySizeBytes = size
+	}
+}
+
+func WithContinueAsNewSuggestedThreshold(threshold float64) BackendOption {
+	return func(o *Options) {
+		o.ContinueAsNewSuggestedThreshold = threshold
+	}
+}
+
 func ApplyOptions(opts ...BackendOption) *Options {
 	options := DefaultOptions
 
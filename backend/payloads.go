@@ -0,0 +1,47 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/cschleiden/go-workflows/backend/converter"
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/backend/payload"
+)
+
+// StoresPayloads returns true if the given converter stores payloads outside of the history, see
+// converter.PayloadStore.
+func StoresPayloads(c converter.Converter) bool {
+	_, ok := c.(converter.PayloadStore)
+	return ok
+}
+
+// StoredPayloads returns the payloads of the given events that reference payloads stored outside of the
+// history by the given converter.
+func StoredPayloads(c converter.Converter, events []*history.Event) []payload.Payload {
+	ps, ok := c.(converter.PayloadStore)
+	if !ok {
+		return nil
+	}
+
+	var stored []payload.Payload
+	for _, event := range events {
+		for _, p := range history.Payloads(event.Attributes) {
+			if ps.IsStored(p) {
+				stored = append(stored, p)
+			}
+		}
+	}
+
+	return stored
+}
+
+// ReleasePayloads releases the given payloads stored outside of the history by the given converter. Backends
+// call this after removing workflow instances.
+func ReleasePayloads(ctx context.Context, c converter.Converter, payloads []payload.Payload) error {
+	ps, ok := c.(converter.PayloadStore)
+	if !ok || len(payloads) == 0 {
+		return nil
+	}
+
+	return ps.ReleasePayloads(ctx, payloads)
+}
@@ -0,0 +1,204 @@
+package backend
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/workflow"
+)
+
+// DefaultQueryPageSize is the number of instances returned by ListWorkflowInstances if no page size is given.
+const DefaultQueryPageSize = 100
+
+// WorkflowInstanceQuery filters workflow instances returned by ListWorkflowInstances. All filters are optional and
+// combined with AND. Instances are returned newest first.
+//
+// Depending on the backend and the filters, the matching instances might not be counted, the TotalCount of the
+// result is TotalCountUnknown then.
+type WorkflowInstanceQuery struct {
+	// WorkflowName only returns instances of the workflow with the given name.
+	WorkflowName string
+
+	// State only returns instances in the given state.
+	State *core.WorkflowInstanceState
+
+	// Failed only returns finished instances that failed (true) or finished instances that did not fail (false).
+	// An instance failed if it finished with an error or was terminated.
+	Failed *bool
+
+	// Queue only returns instances on the given queue.
+	Queue workflow.Queue
+
+	// ParentInstanceID only returns sub-workflow instances started by the given parent instance.
+	ParentInstanceID string
+
+	// CreatedAfter and CreatedBefore only return instances created in the range [CreatedAfter, CreatedBefore).
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+
+	// CompletedAfter and CompletedBefore only return instances completed in the range
+	// [CompletedAfter, CompletedBefore). Setting either excludes instances that have not completed.
+	CompletedAfter  time.Time
+	CompletedBefore time.Time
+
+	// SearchAttributes only returns instances where each of the given search attributes has the given value.
+	SearchAttributes core.SearchAttributes
+
+	// Cursor continues a previous listing, pass the NextCursor of the previous result.
+	Cursor string
+
+	// PageSize is the maximum number of instances to return. Defaults to DefaultQueryPageSize.
+	PageSize int
+}
+
+// Matches returns true if the given instance matches all filters of the query. The cursor is ignored.
+func (q *WorkflowInstanceQuery) Matches(i *WorkflowInstanceInfo) bool {
+	if q.WorkflowName != "" && i.WorkflowName != q.WorkflowName {
+		return false
+	}
+
+	if q.State != nil && i.State != *q.State {
+		return false
+	}
+
+	if q.Failed != nil && (i.CompletedAt == nil || i.Failed != *q.Failed) {
+		return false
+	}
+
+	if q.Queue != "" && i.Queue != q.Queue {
+		return false
+	}
+
+	if q.ParentInstanceID != "" && (i.Instance.Parent == nil || i.Instance.Parent.InstanceID != q.ParentInstanceID) {
+		return false
+	}
+
+	if !inRange(&i.CreatedAt, q.CreatedAfter, q.CreatedBefore) {
+		return false
+	}
+
+	if (!q.CompletedAfter.IsZero() || !q.CompletedBefore.IsZero()) && !inRange(i.CompletedAt, q.CompletedAfter, q.CompletedBefore) {
+		return false
+	}
+
+	for name, value := range q.SearchAttributes {
+		v, ok := i.SearchAttributes[name]
+		if !ok {
+			return false
+		}
+
+		// Compare the encoded values, decoded attributes don't have the original types
+		ev, err1 := json.Marshal(value)
+		av, err2 := json.Marshal(v)
+		if err1 != nil || err2 != nil || string(ev) != string(av) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func inRange(t *time.Time, after, before time.Time) bool {
+	if t == nil {
+		return false
+	}
+
+	if !after.IsZero() && t.Before(after) {
+		return false
+	}
+
+	if !before.IsZero() && !t.Before(before) {
+		return false
+	}
+
+	return true
+}
+
+// WorkflowInstanceInfo describes a workflow instance returned by ListWorkflowInstances.
+type WorkflowInstanceInfo struct {
+	Instance     *workflow.Instance
+	WorkflowName string
+	Queue        workflow.Queue
+	State        core.WorkflowInstanceState
+
+	// Failed is true if the instance finished with an error or was terminated.
+	Failed bool
+
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+
+	// SearchAttributes are the current search attributes of the instance.
+	SearchAttributes core.SearchAttributes
+}
+
+// WorkflowInstanceQueryResult is a page of workflow instances matching a query.
+type WorkflowInstanceQueryResult struct {
+	Instances []*WorkflowInstanceInfo
+
+	// NextCursor continues the listing with the next page. Empty if there are no more instances.
+	NextCursor string
+
+	// TotalCount is the number of instances matching the query across all pages, or TotalCountUnknown if the
+	// backend can't determine it without scanning all instances.
+	TotalCount int64
+}
+
+// TotalCountUnknown is reported as the total count of a query result if the backend doesn't know it.
+const TotalCountUnknown = -1
+
+// ExecutionFailed returns true if the given events, executed in a workflow task, finish the execution with an error.
+func ExecutionFailed(events []*history.Event) bool {
+	for _, event := range events {
+		if event.Type == history.EventType_WorkflowExecutionFinished {
+			if a, ok := event.Attributes.(*history.ExecutionCompletedAttributes); ok && a.Error != nil {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ContinuedExecution returns the new execution of the given instance, if the given events, executed in a workflow
+// task, continue the instance as new. Otherwise it returns nil.
+func ContinuedExecution(instance *workflow.Instance, events []*history.Event) *workflow.Instance {
+	for _, event := range events {
+		if event.Type == history.EventType_WorkflowExecutionContinuedAsNew {
+			if a, ok := event.Attributes.(*history.ExecutionContinuedAsNewAttributes); ok {
+				continued := *instance
+				continued.ExecutionID = a.ContinuedExecutionID
+				return &continued
+			}
+		}
+	}
+
+	return nil
+}
+
+// SearchAttributesFromEvents returns the encoded search attributes set by the given events, either when starting
+// an execution or by upserting them. Later events overwrite values of earlier ones.
+func SearchAttributesFromEvents(events []*history.Event) map[string]string {
+	var r map[string]string
+
+	for _, event := range events {
+		var sa map[string]string
+		switch a := event.Attributes.(type) {
+		case *history.ExecutionStartedAttributes:
+			sa = a.SearchAttributes
+		case *history.SearchAttributesUpsertedAttributes:
+			sa = a.SearchAttributes
+		}
+
+		for name, value := range sa {
+			if r == nil {
+				r = map[string]string{}
+			}
+
+			r[name] = value
+		}
+	}
+
+	return r
+}
@@ -2,10 +2,15 @@ package redis
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/cschleiden/go-workflows/backend"
 	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/cschleiden/go-workflows/core"
 	"github.com/cschleiden/go-workflows/workflow"
+	redis "github.com/redis/go-redis/v9"
 )
 
 func (rb *redisBackend) PrepareActivityQueues(ctx context.Context, queues []workflow.Queue) error {
@@ -22,12 +27,51 @@ func (rb *redisBackend) GetActivityTask(ctx context.Context, queues []workflow.Q
 		return nil, nil
 	}
 
+	// Activities of instances which are not active anymore, e.g., because they were terminated, are abandoned
+	instanceState, err := readInstance(ctx, rb.rdb, rb.keys.instanceKey(activityTask.Data.Instance))
+	if err != nil && err != backend.ErrInstanceNotFound {
+		return nil, fmt.Errorf("reading workflow instance: %w", err)
+	}
+
+	if instanceState == nil || instanceState.State != core.WorkflowInstanceStateActive {
+		p := rb.rdb.TxPipeline()
+		if _, err := rb.activityQueue.Complete(ctx, p, workflow.Queue(activityTask.Data.Queue), activityTask.TaskID); err != nil {
+			return nil, err
+		}
+
+		if _, err := p.Exec(ctx); err != nil {
+			return nil, fmt.Errorf("abandoning activity task: %w", err)
+		}
+
+		return nil, nil
+	}
+
+	// The activity has been picked up, remove the future event enforcing its ScheduleToStart timeout
+	if activityTask.Data.Event.Attributes.(*history.ActivityScheduledAttributes).ScheduleToStartTimeout > 0 {
+		instance := activityTask.Data.Instance
+		if err := removeFutureEventCmd.Run(ctx, rb.rdb, []string{
+			rb.keys.futureEventsKey(),
+			rb.keys.instanceFutureEventsKey(instance),
+			rb.keys.payloadKey(instance),
+			rb.keys.futureEventKey(instance, activityTask.Data.Event.ScheduleEventID),
+		}).Err(); err != nil {
+			return nil, fmt.Errorf("removing schedule to start timeout event: %w", err)
+		}
+	}
+
+	// Pick up the progress recorded by a previous delivery of this task
+	heartbeatDetails, err := rb.rdb.HGet(ctx, rb.keys.activityHeartbeatsKey(activityTask.Data.Instance), activityTask.Data.ID).Bytes()
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("reading activity heartbeat details: %w", err)
+	}
+
 	return &backend.ActivityTask{
-		WorkflowInstance: activityTask.Data.Instance,
-		Queue:            workflow.Queue(activityTask.Data.Queue),
-		ID:               activityTask.TaskID, // Use the queue generated ID here
-		ActivityID:       activityTask.Data.ID,
-		Event:            activityTask.Data.Event,
+		WorkflowInstance:     activityTask.Data.Instance,
+		Queue:                workflow.Queue(activityTask.Data.Queue),
+		ID:                   activityTask.TaskID, // Use the queue generated ID here
+		ActivityID:           activityTask.Data.ID,
+		Event:                activityTask.Data.Event,
+		LastHeartbeatDetails: heartbeatDetails,
 	}, nil
 }
 
@@ -42,23 +86,81 @@ func (rb *redisBackend) ExtendActivityTask(ctx context.Context, task *backend.Ac
 	return err
 }
 
+// SetActivityTaskPending acknowledges the activity task without removing it from the queue. Acknowledged tasks
+// are not recovered when their lock expires, completing the task removes it.
+func (rb *redisBackend) SetActivityTaskPending(ctx context.Context, task *backend.ActivityTask) error {
+	if err := rb.activityQueue.Ack(ctx, rb.rdb, task.Queue, task.ID); err != nil {
+		return fmt.Errorf("setting activity pending: %w", err)
+	}
+
+	return nil
+}
+
+func (rb *redisBackend) RecordActivityHeartbeat(ctx context.Context, task *backend.ActivityTask, details payload.Payload) error {
+	// Only record the details if this worker still owns the task, otherwise they might overwrite the details of
+	// another attempt
+	err := recordActivityHeartbeatCmd.Run(ctx, rb.rdb, []string{
+		rb.activityQueue.Keys(task.Queue).StreamKey,
+		rb.keys.activityHeartbeatsKey(task.WorkflowInstance),
+	},
+		task.ID,
+		rb.activityQueue.groupName,
+		rb.activityQueue.workerName,
+		task.ActivityID,
+		[]byte(details),
+	).Err()
+	if err == redis.Nil {
+		return backend.ErrTaskNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("recording activity heartbeat: %w", err)
+	}
+
+	return nil
+}
+
 func (rb *redisBackend) CompleteActivityTask(ctx context.Context, task *backend.ActivityTask, result *history.Event) error {
 	instanceState, err := readInstance(ctx, rb.rdb, rb.keys.instanceKey(task.WorkflowInstance))
 	if err != nil {
 		return err
 	}
 
-	p := rb.rdb.TxPipeline()
-
-	if err := rb.addWorkflowInstanceEventP(ctx, p, workflow.Queue(instanceState.Queue), task.WorkflowInstance, result); err != nil {
+	eventData, payloadData, err := marshalEvent(result)
+	if err != nil {
 		return err
 	}
 
-	// Unlock activity
-	if _, err := rb.activityQueue.Complete(ctx, p, task.Queue, task.ID); err != nil {
-		return err
+	activityQueueKeys := rb.activityQueue.Keys(task.Queue)
+	workflowQueueKeys := rb.workflowQueue.Keys(workflow.Queue(instanceState.Queue))
+
+	// Completes the task and delivers the result only if the task still exists
+	err = completeActivityTaskCmd.Run(ctx, rb.rdb, []string{
+		activityQueueKeys.SetKey,
+		activityQueueKeys.StreamKey,
+		rb.keys.activityHeartbeatsKey(task.WorkflowInstance),
+		rb.keys.instanceKey(task.WorkflowInstance),
+		rb.keys.payloadKey(task.WorkflowInstance),
+		rb.keys.pendingEventsKey(task.WorkflowInstance),
+		rb.workflowQueue.queueSetKey,
+		workflowQueueKeys.SetKey,
+		workflowQueueKeys.StreamKey,
+	},
+		task.ID,
+		rb.activityQueue.groupName,
+		task.ActivityID,
+		instanceSegment(task.WorkflowInstance),
+		result.ID,
+		eventData,
+		payloadData,
+	).Err()
+	if err == redis.Nil {
+		return errors.New("could not find activity task to complete")
 	}
 
-	_, err = p.Exec(ctx)
-	return err
+	if err != nil {
+		return fmt.Errorf("completing activity task: %w", err)
+	}
+
+	return nil
 }
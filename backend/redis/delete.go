@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/backend/payload"
 	"github.com/cschleiden/go-workflows/core"
 	redis "github.com/redis/go-redis/v9"
 )
@@ -13,27 +15,86 @@ import (
 // KEYS[3] - history key
 // KEYS[4] - payload key
 // KEYS[5] - active-instance-execution key
-// KEYS[6] - instances-by-creation key
+// KEYS[6] - search attributes key
+// KEYS[7] - instance future events key
+// KEYS[8] - activity heartbeats key
+// KEYS[9] - latest-instance-execution key
+// KEYS[10] - stored payloads key
+// KEYS[11] - payloads-expiring key
+// KEYS[12...] - index keys, i.e., instances-by-creation, instances-by-workflow etc.
 // ARGV[1] - instance segment
+// ARGV[2] - execution id
+//
+// Returns the payloads stored outside of the history of the instance.
 var deleteCmd = redis.NewScript(
-	`redis.call("DEL", KEYS[1], KEYS[2], KEYS[3], KEYS[4], KEYS[5])
-	return redis.call("ZREM", KEYS[6], ARGV[1])`)
+	`redis.call("DEL", KEYS[1], KEYS[2], KEYS[3], KEYS[4], KEYS[6], KEYS[7], KEYS[8])
+
+	-- Other executions of the instance might have been created since, only remove references to this one
+	for _, key in ipairs({KEYS[5], KEYS[9]}) do
+		local execution = redis.call("GET", key)
+		if execution and cjson.decode(execution)["execution_id"] == ARGV[2] then
+			redis.call("DEL", key)
+		end
+	end
+
+	-- The payloads are released by the caller, they don't have to be released on expiration anymore
+	local storedPayloads = redis.call("SMEMBERS", KEYS[10])
+	for i = 1, #storedPayloads do
+		redis.call("ZREM", KEYS[11], storedPayloads[i])
+	end
+	redis.call("DEL", KEYS[10])
+
+	for i = 12, #KEYS do
+		redis.call("ZREM", KEYS[i], ARGV[1])
+	end
+	return storedPayloads`)
 
 // deleteInstance deletes an instance from Redis. It does not attempt to remove any future events or pending
 // workflow tasks. It's assumed that the instance is in the finished state.
 //
 // Note: might want to revisit this in the future if we want to support removing hung instances.
-func (rb *redisBackend) deleteInstance(ctx context.Context, instance *core.WorkflowInstance) error {
-	if err := deleteCmd.Run(ctx, rb.rdb, []string{
+func (rb *redisBackend) deleteInstance(ctx context.Context, state *instanceState) error {
+	instance := state.Instance
+
+	searchAttributes, err := rb.rdb.HGetAll(ctx, rb.keys.searchAttributesKey(instance)).Result()
+	if err != nil {
+		return fmt.Errorf("reading search attributes: %w", err)
+	}
+
+	keys := []string{
 		rb.keys.instanceKey(instance),
 		rb.keys.pendingEventsKey(instance),
 		rb.keys.historyKey(instance),
 		rb.keys.payloadKey(instance),
 		rb.keys.activeInstanceExecutionKey(instance.InstanceID),
+		rb.keys.searchAttributesKey(instance),
+		rb.keys.instanceFutureEventsKey(instance),
+		rb.keys.activityHeartbeatsKey(instance),
+		rb.keys.latestInstanceExecutionKey(instance.InstanceID),
+		rb.keys.storedPayloadsKey(instance),
+		rb.keys.payloadsExpiring(),
 		rb.keys.instancesByCreation(),
-	}, instanceSegment(instance)).Err(); err != nil {
+		rb.keys.instancesByWorkflowName(state.WorkflowName),
+		rb.keys.instancesByQueue(core.Queue(state.Queue)),
+	}
+
+	if instance.Parent != nil {
+		keys = append(keys, rb.keys.instancesByParent(instance.Parent.InstanceID))
+	}
+
+	for name, value := range searchAttributes {
+		keys = append(keys, rb.keys.instancesBySearchAttribute(name, value))
+	}
+
+	members, err := deleteCmd.Run(ctx, rb.rdb, keys, instanceSegment(instance), instance.ExecutionID).StringSlice()
+	if err != nil {
 		return fmt.Errorf("failed to delete instance: %w", err)
 	}
 
-	return nil
+	stored := make([]payload.Payload, 0, len(members))
+	for _, m := range members {
+		stored = append(stored, payload.Payload(m))
+	}
+
+	return backend.ReleasePayloads(ctx, rb.options.Converter, stored)
 }
@@ -82,6 +82,10 @@ func (rb *redisBackend) GetWorkflowInstances(ctx context.Context, afterInstanceI
 		})
 	}
 
+	if err := rb.setSearchAttributes(ctx, instanceRefs); err != nil {
+		return nil, err
+	}
+
 	return instanceRefs, nil
 }
 
@@ -91,7 +95,30 @@ func (rb *redisBackend) GetWorkflowInstance(ctx context.Context, instance *core.
 		return nil, err
 	}
 
-	return mapWorkflowInstance(instanceState), nil
+	ref := mapWorkflowInstance(instanceState)
+	if err := rb.setSearchAttributes(ctx, []*diag.WorkflowInstanceRef{ref}); err != nil {
+		return nil, err
+	}
+
+	return ref, nil
+}
+
+func (rb *redisBackend) setSearchAttributes(ctx context.Context, refs []*diag.WorkflowInstanceRef) error {
+	instances := make([]*core.WorkflowInstance, 0, len(refs))
+	for _, ref := range refs {
+		instances = append(instances, ref.Instance)
+	}
+
+	searchAttributes, err := rb.getSearchAttributes(ctx, instances)
+	if err != nil {
+		return err
+	}
+
+	for i, ref := range refs {
+		ref.SearchAttributes = searchAttributes[i]
+	}
+
+	return nil
 }
 
 func (rb *redisBackend) GetWorkflowTree(ctx context.Context, instance *core.WorkflowInstance) (*diag.WorkflowInstanceTree, error) {
@@ -2,10 +2,14 @@ package redis
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"time"
 
+	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/backend/payload"
 	"github.com/cschleiden/go-workflows/core"
+	"github.com/redis/go-redis/v9"
 )
 
 func (rb *redisBackend) setWorkflowInstanceExpiration(ctx context.Context, instance *core.WorkflowInstance, expiration time.Duration) error {
@@ -15,17 +19,67 @@ func (rb *redisBackend) setWorkflowInstanceExpiration(ctx context.Context, insta
 	exp := time.Now().Add(expiration).UnixMilli()
 	expStr := strconv.FormatInt(exp, 10)
 
-	return expireWorkflowInstanceCmd.Run(ctx, rb.rdb, []string{
+	if err := expireWorkflowInstanceCmd.Run(ctx, rb.rdb, []string{
 		rb.keys.instancesByCreation(),
 		rb.keys.instancesExpiring(),
+		rb.keys.payloadsExpiring(),
+		rb.keys.instanceIndexesExpiring(),
+		rb.keys.latestInstanceExecutionKey(instance.InstanceID),
+		rb.keys.storedPayloadsKey(instance),
 		rb.keys.instanceKey(instance),
 		rb.keys.pendingEventsKey(instance),
 		rb.keys.historyKey(instance),
 		rb.keys.payloadKey(instance),
+		rb.keys.searchAttributesKey(instance),
+		rb.keys.instanceFutureEventsKey(instance),
+		rb.keys.activityHeartbeatsKey(instance),
 	},
 		nowStr,
 		expiration.Seconds(),
 		expStr,
 		instanceSegment(instance),
-	).Err()
+		instance.ExecutionID,
+		rb.keys.prefix,
+	).Err(); err != nil {
+		return err
+	}
+
+	return rb.releaseExpiredPayloads(ctx, nowStr)
+}
+
+// releaseExpiredPayloads releases the stored payloads of instances that have expired. It's called regularly
+// when polling for workflow tasks.
+func (rb *redisBackend) releaseExpiredPayloads(ctx context.Context, nowStr string) error {
+	if !backend.StoresPayloads(rb.options.Converter) {
+		return nil
+	}
+
+	members, err := rb.rdb.ZRangeByScore(ctx, rb.keys.payloadsExpiring(), &redis.ZRangeBy{
+		Min: "-inf",
+		Max: nowStr,
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("reading expired payloads: %w", err)
+	}
+
+	if len(members) == 0 {
+		return nil
+	}
+
+	payloads := make([]payload.Payload, 0, len(members))
+	removed := make([]interface{}, 0, len(members))
+	for _, m := range members {
+		payloads = append(payloads, payload.Payload(m))
+		removed = append(removed, m)
+	}
+
+	if err := backend.ReleasePayloads(ctx, rb.options.Converter, payloads); err != nil {
+		return fmt.Errorf("releasing expired payloads: %w", err)
+	}
+
+	if err := rb.rdb.ZRem(ctx, rb.keys.payloadsExpiring(), removed...).Err(); err != nil {
+		return fmt.Errorf("removing expired payloads: %w", err)
+	}
+
+	return nil
 }
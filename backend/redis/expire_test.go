@@ -7,9 +7,11 @@ import (
 
 	"github.com/cschleiden/go-workflows/backend"
 	"github.com/cschleiden/go-workflows/client"
+	"github.com/cschleiden/go-workflows/internal/fn"
 	"github.com/cschleiden/go-workflows/worker"
 	"github.com/cschleiden/go-workflows/workflow"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -60,6 +62,59 @@ func Test_AutoExpiration(t *testing.T) {
 	require.NoError(t, w.WaitForCompletion())
 }
 
+func Test_AutoExpiration_RemovesExpiredInstancesFromIndexes(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	autoExpirationTime := time.Second * 2
+
+	redisClient := getClient()
+	setup := getCreateBackend(redisClient, WithAutoExpiration(autoExpirationTime))
+	b := setup()
+	rb := b.(*redisBackend)
+
+	c := client.New(b)
+	w := worker.New(b, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	require.NoError(t, w.Start(ctx))
+
+	wf := func(ctx workflow.Context) error {
+		return nil
+	}
+
+	w.RegisterWorkflow(wf)
+
+	run := func() *workflow.Instance {
+		wfi, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+			InstanceID: uuid.NewString(),
+		}, wf)
+		require.NoError(t, err)
+		require.NoError(t, c.WaitForWorkflowInstance(ctx, wfi, time.Second*10))
+
+		return wfi
+	}
+
+	expired := run()
+
+	// Wait for redis to expire the keys
+	time.Sleep(autoExpirationTime * 2)
+
+	// The index entries of the expired instance are removed when the next instance expires
+	run()
+
+	_, err := rb.rdb.ZScore(ctx, rb.keys.instancesByWorkflowName(fn.Name(wf)), instanceSegment(expired)).Result()
+	require.ErrorIs(t, err, redis.Nil)
+
+	_, err = rb.rdb.ZScore(ctx, rb.keys.instancesByQueue(workflow.QueueDefault), instanceSegment(expired)).Result()
+	require.ErrorIs(t, err, redis.Nil)
+
+	cancel()
+	require.NoError(t, w.WaitForCompletion())
+}
+
 func Test_AutoExpiration_SubWorkflow(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
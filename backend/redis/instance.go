@@ -4,77 +4,157 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/cschleiden/go-workflows/backend"
 	"github.com/cschleiden/go-workflows/backend/history"
 	"github.com/cschleiden/go-workflows/backend/metadata"
 	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
 	"github.com/cschleiden/go-workflows/workflow"
 	"github.com/redis/go-redis/v9"
 )
 
 func (rb *redisBackend) CreateWorkflowInstance(ctx context.Context, instance *workflow.Instance, event *history.Event) error {
+	_, err := rb.createWorkflowInstance(ctx, instance, event, nil)
+	return err
+}
+
+func (rb *redisBackend) SignalWithStartWorkflowInstance(ctx context.Context, instance *workflow.Instance, startedEvent, signalEvent *history.Event) (*workflow.Instance, error) {
+	return rb.createWorkflowInstance(ctx, instance, startedEvent, signalEvent)
+}
+
+// createWorkflowInstance creates the given workflow instance. If signalEvent is given, it's added after the started
+// event, or, if there is already an active execution, delivered to that execution instead. Returns the instance
+// the events were added to.
+func (rb *redisBackend) createWorkflowInstance(ctx context.Context, instance *workflow.Instance, event, signalEvent *history.Event) (*workflow.Instance, error) {
 	a := event.Attributes.(*history.ExecutionStartedAttributes)
 
+	// The creation time is also the score in the index sets
+	now := time.Now().UTC()
+
 	instanceState, err := json.Marshal(&instanceState{
-		Queue:     string(a.Queue),
-		Instance:  instance,
-		State:     core.WorkflowInstanceStateActive,
-		Metadata:  a.Metadata,
-		CreatedAt: time.Now(),
+		Queue:        string(a.Queue),
+		Instance:     instance,
+		WorkflowName: a.Name,
+		State:        core.WorkflowInstanceStateActive,
+		Metadata:     a.Metadata,
+		CreatedAt:    now,
+
+		ParentClosePolicy: a.ParentClosePolicy,
 	})
 	if err != nil {
-		return fmt.Errorf("marshaling instance state: %w", err)
+		return nil, fmt.Errorf("marshaling instance state: %w", err)
 	}
 
 	activeInstance, err := json.Marshal(instance)
 	if err != nil {
-		return fmt.Errorf("marshaling instance: %w", err)
+		return nil, fmt.Errorf("marshaling instance: %w", err)
 	}
 
 	eventData, err := marshalEventWithoutAttributes(event)
 	if err != nil {
-		return fmt.Errorf("marshaling event: %w", err)
+		return nil, fmt.Errorf("marshaling event: %w", err)
 	}
 
 	payloadData, err := json.Marshal(event.Attributes)
 	if err != nil {
-		return fmt.Errorf("marshaling event payload: %w", err)
+		return nil, fmt.Errorf("marshaling event payload: %w", err)
+	}
+
+	var signalEventID, signalEventData, signalPayloadData string
+	if signalEvent != nil {
+		signalEventID = signalEvent.ID
+
+		if signalEventData, err = marshalEventWithoutAttributes(signalEvent); err != nil {
+			return nil, fmt.Errorf("marshaling signal event: %w", err)
+		}
+
+		p, err := json.Marshal(signalEvent.Attributes)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling signal event payload: %w", err)
+		}
+		signalPayloadData = string(p)
+	}
+
+	var startAt int64
+	if event.VisibleAt != nil {
+		startAt = event.VisibleAt.UnixMilli()
+	}
+
+	var timeoutEventID, timeoutAt, timeoutEventData, timeoutPayloadData string
+	if timeoutEvent := history.NewWorkflowTimeoutEvent(event); timeoutEvent != nil {
+		timeoutEventID = timeoutEvent.ID
+		timeoutAt = strconv.FormatInt(timeoutEvent.VisibleAt.UnixMilli(), 10)
+
+		if timeoutEventData, timeoutPayloadData, err = marshalEvent(timeoutEvent); err != nil {
+			return nil, fmt.Errorf("marshaling timeout event: %w", err)
+		}
 	}
 
 	keyInfo := rb.workflowQueue.Keys(a.Queue)
-	_, err = createWorkflowInstanceCmd.Run(ctx, rb.rdb, []string{
+	res, err := createWorkflowInstanceCmd.Run(ctx, rb.rdb, []string{
 		rb.keys.instanceKey(instance),
 		rb.keys.activeInstanceExecutionKey(instance.InstanceID),
+		rb.keys.latestInstanceExecutionKey(instance.InstanceID),
 		rb.keys.pendingEventsKey(instance),
 		rb.keys.payloadKey(instance),
 		rb.keys.instancesActive(),
 		rb.keys.instancesByCreation(),
+		rb.keys.instancesByWorkflowName(a.Name),
+		rb.keys.instancesByQueue(a.Queue),
 		keyInfo.SetKey,
 		keyInfo.StreamKey,
 		rb.workflowQueue.queueSetKey,
+		rb.keys.futureEventsKey(),
+		rb.keys.instanceFutureEventsKey(instance),
+		rb.keys.startEventKey(instance),
+		rb.keys.futureEventKey(instance, 0),
 	},
 		instanceSegment(instance),
+		rb.keys.prefix,
+		signalEventID,
+		signalEventData,
+		signalPayloadData,
+		int(a.IDReusePolicy),
 		string(instanceState),
 		string(activeInstance),
 		event.ID,
 		eventData,
 		payloadData,
-		time.Now().UTC().UnixNano(),
-	).Result()
+		now.UnixNano(),
+		startAt,
+		string(a.Queue),
+		timeoutEventID,
+		timeoutAt,
+		timeoutEventData,
+		timeoutPayloadData,
+	).Text()
 
 	if err != nil {
 		if _, ok := err.(redis.Error); ok {
 			if err.Error() == "ERR InstanceAlreadyExists" {
-				return backend.ErrInstanceAlreadyExists
+				return nil, backend.ErrInstanceAlreadyExists
 			}
 		}
 
-		return fmt.Errorf("creating workflow instance: %w", err)
+		return nil, fmt.Errorf("creating workflow instance: %w", err)
 	}
 
-	return nil
+	var target *workflow.Instance
+	if err := json.Unmarshal([]byte(res), &target); err != nil {
+		return nil, fmt.Errorf("unmarshaling instance: %w", err)
+	}
+
+	// Only store search attributes if a new execution has been created
+	if target.ExecutionID == instance.ExecutionID {
+		if err := rb.upsertSearchAttributes(ctx, instance, now, a.SearchAttributes); err != nil {
+			return nil, err
+		}
+	}
+
+	return target, nil
 }
 
 func (rb *redisBackend) GetWorkflowInstanceHistory(ctx context.Context, instance *core.WorkflowInstance, lastSequenceID *int64) ([]*history.Event, error) {
@@ -125,6 +205,29 @@ func (rb *redisBackend) GetWorkflowInstanceState(ctx context.Context, instance *
 	return instanceState.State, nil
 }
 
+func (rb *redisBackend) GetLatestWorkflowInstance(ctx context.Context, instanceID string) (*core.WorkflowInstance, error) {
+	val, err := rb.rdb.Get(ctx, rb.keys.latestInstanceExecutionKey(instanceID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, backend.ErrInstanceNotFound
+		}
+
+		return nil, fmt.Errorf("reading latest instance execution: %w", err)
+	}
+
+	var instance *core.WorkflowInstance
+	if err := json.Unmarshal([]byte(val), &instance); err != nil {
+		return nil, fmt.Errorf("unmarshaling instance: %w", err)
+	}
+
+	// The execution might have been removed in the meantime
+	if _, err := readInstance(ctx, rb.rdb, rb.keys.instanceKey(instance)); err != nil {
+		return nil, err
+	}
+
+	return instance, nil
+}
+
 func (rb *redisBackend) CancelWorkflowInstance(ctx context.Context, instance *core.WorkflowInstance, event *history.Event) error {
 	// Read the instance to check if it exists
 	instanceState, err := readInstance(ctx, rb.rdb, rb.keys.instanceKey(instance))
@@ -143,6 +246,112 @@ func (rb *redisBackend) CancelWorkflowInstance(ctx context.Context, instance *co
 	return nil
 }
 
+func (rb *redisBackend) TerminateWorkflowInstance(ctx context.Context, instance *core.WorkflowInstance, event *history.Event) error {
+	instanceState, err := readInstance(ctx, rb.rdb, rb.keys.instanceKey(instance))
+	if err != nil {
+		return err
+	}
+
+	if instanceState.State != core.WorkflowInstanceStateActive {
+		return backend.ErrInstanceNotActive
+	}
+
+	event.SequenceID = instanceState.LastSequenceID + 1
+
+	eventData, payloadData, err := marshalEvent(event)
+	if err != nil {
+		return fmt.Errorf("marshaling event: %w", err)
+	}
+
+	keys := []string{
+		rb.keys.instanceKey(instance),
+		rb.keys.activeInstanceExecutionKey(instance.InstanceID),
+		rb.keys.historyKey(instance),
+		rb.keys.payloadKey(instance),
+		rb.keys.futureEventsKey(),
+		rb.keys.instanceFutureEventsKey(instance),
+		rb.keys.pendingEventsKey(instance),
+		rb.keys.instancesActive(),
+	}
+	args := []interface{}{
+		rb.keys.prefix,
+		instanceSegment(instance),
+		event.ID,
+		eventData,
+		payloadData,
+		event.SequenceID,
+		time.Now().UTC().Format(time.RFC3339),
+		int(core.WorkflowInstanceStateFinished),
+	}
+
+	// Apply the parent close policy to running sub-workflow instances
+	pcpArgs, err := parentClosePolicyArgs(time.Now())
+	if err != nil {
+		return err
+	}
+	args = append(args, pcpArgs...)
+
+	// Notify parent instance, if it's still active
+	notifyParent := false
+	if instanceState.Instance != nil && instanceState.Instance.SubWorkflow() {
+		parentInstance := instanceState.Instance.Parent
+
+		parentState, err := readInstance(ctx, rb.rdb, rb.keys.instanceKey(parentInstance))
+		if err != nil && err != backend.ErrInstanceNotFound {
+			return fmt.Errorf("reading parent instance: %w", err)
+		}
+
+		if parentState != nil && parentState.State == core.WorkflowInstanceStateActive {
+			pfe := history.NewPendingEvent(time.Now(), history.EventType_SubWorkflowFailed, &history.SubWorkflowFailedAttributes{
+				Error: workflowerrors.NewPermanentError(backend.ErrInstanceTerminated),
+			}, history.ScheduleEventID(instanceState.Instance.ParentEventID))
+			parentEventData, parentPayloadData, err := marshalEvent(pfe)
+			if err != nil {
+				return fmt.Errorf("marshaling event: %w", err)
+			}
+
+			queueKeys := rb.workflowQueue.Keys(workflow.Queue(parentState.Queue))
+			keys = append(keys,
+				rb.keys.instanceKey(parentInstance),
+				rb.keys.pendingEventsKey(parentInstance),
+				rb.keys.payloadKey(parentInstance),
+				queueKeys.SetKey,
+				queueKeys.StreamKey,
+				rb.workflowQueue.queueSetKey,
+			)
+			args = append(args, 1, instanceSegment(parentInstance), pfe.ID, parentEventData, parentPayloadData)
+			notifyParent = true
+		}
+	}
+
+	if !notifyParent {
+		args = append(args, 0)
+	}
+
+	terminated, err := terminateWorkflowInstanceCmd.Run(ctx, rb.rdb, keys, args...).Slice()
+	if err != nil {
+		if _, ok := err.(redis.Error); ok {
+			if err.Error() == "ERR InstanceNotActive" {
+				return backend.ErrInstanceNotActive
+			}
+		}
+
+		return fmt.Errorf("terminating workflow instance: %w", err)
+	}
+
+	if rb.options.AutoExpiration > 0 {
+		if err := rb.setWorkflowInstanceExpiration(ctx, instance, rb.options.AutoExpiration); err != nil {
+			return fmt.Errorf("setting workflow instance expiration: %w", err)
+		}
+	}
+
+	if err := rb.expireTerminatedSubWorkflows(ctx, terminated); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (rb *redisBackend) RemoveWorkflowInstance(ctx context.Context, instance *core.WorkflowInstance) error {
 	i, err := readInstance(ctx, rb.rdb, rb.keys.instanceKey(instance))
 	if err != nil {
@@ -153,7 +362,7 @@ func (rb *redisBackend) RemoveWorkflowInstance(ctx context.Context, instance *co
 		return backend.ErrInstanceNotFinished
 	}
 
-	return rb.deleteInstance(ctx, instance)
+	return rb.deleteInstance(ctx, i)
 }
 
 func (rb *redisBackend) RemoveWorkflowInstances(ctx context.Context, options ...backend.RemovalOption) error {
@@ -165,11 +374,18 @@ func (rb *redisBackend) RemoveWorkflowInstances(ctx context.Context, options ...
 type instanceState struct {
 	Queue string `json:"queue"`
 
-	Instance *core.WorkflowInstance     `json:"instance,omitempty"`
-	State    core.WorkflowInstanceState `json:"state,omitempty"`
+	Instance     *core.WorkflowInstance     `json:"instance,omitempty"`
+	WorkflowName string                     `json:"workflow_name,omitempty"`
+	State        core.WorkflowInstanceState `json:"state,omitempty"`
+
+	// Failed is set when the instance finished with an error or was terminated
+	Failed bool `json:"failed,omitempty"`
 
 	Metadata *metadata.WorkflowMetadata `json:"metadata,omitempty"`
 
+	// ParentClosePolicy is applied to sub-workflow instances when their parent finishes
+	ParentClosePolicy core.ParentClosePolicy `json:"parent_close_policy,omitempty"`
+
 	CreatedAt   time.Time  `json:"created_at,omitempty"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 
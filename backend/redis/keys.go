@@ -24,6 +24,12 @@ func (k *keys) activeInstanceExecutionKey(instanceID string) string {
 	return fmt.Sprintf("%sactive-instance-execution:%v", k.prefix, instanceID)
 }
 
+// latestInstanceExecutionKey returns the key for the most recently created execution of the given instance. Unlike
+// the active execution, it's kept after the execution has finished.
+func (k *keys) latestInstanceExecutionKey(instanceID string) string {
+	return fmt.Sprintf("%slatest-instance-execution:%v", k.prefix, instanceID)
+}
+
 func instanceSegment(instance *core.WorkflowInstance) string {
 	return fmt.Sprintf("%v:%v", instance.InstanceID, instance.ExecutionID)
 }
@@ -48,10 +54,56 @@ func (k *keys) instancesActive() string {
 	return fmt.Sprintf("%sinstances-active", k.prefix)
 }
 
+// instancesByWorkflowName returns the key for the ZSET that contains all instances of the given workflow sorted by
+// creation date. Used for listing workflow instances.
+func (k *keys) instancesByWorkflowName(workflowName string) string {
+	return fmt.Sprintf("%sinstances-by-workflow:%v", k.prefix, workflowName)
+}
+
+// instancesByQueue returns the key for the ZSET that contains all instances on the given queue sorted by creation date.
+func (k *keys) instancesByQueue(queue core.Queue) string {
+	return fmt.Sprintf("%sinstances-by-queue:%v", k.prefix, queue)
+}
+
+// instancesByParent returns the key for the ZSET that contains all sub-workflow instances started by the given parent
+// instance sorted by creation date.
+func (k *keys) instancesByParent(parentInstanceID string) string {
+	return fmt.Sprintf("%sinstances-by-parent:%v", k.prefix, parentInstanceID)
+}
+
+// instancesBySearchAttribute returns the key for the ZSET that contains all instances where the search attribute with
+// the given name has the given encoded value, sorted by creation date.
+func (k *keys) instancesBySearchAttribute(name, value string) string {
+	return fmt.Sprintf("%sinstances-by-search-attribute:%v:%v", k.prefix, name, value)
+}
+
+// searchAttributesKey returns the key for the HASH that contains the encoded search attributes of an instance.
+func (k *keys) searchAttributesKey(instance *core.WorkflowInstance) string {
+	return fmt.Sprintf("%ssearch-attributes:%v", k.prefix, instanceSegment(instance))
+}
+
 func (k *keys) instancesExpiring() string {
 	return fmt.Sprintf("%sinstances-expiring", k.prefix)
 }
 
+// instanceIndexesExpiring returns the key for the HASH with the index ZSETs each expiring instance is listed in,
+// keyed by instance segment. Expired instances are removed from them when the next instance expires.
+func (k *keys) instanceIndexesExpiring() string {
+	return fmt.Sprintf("%sinstance-indexes-expiring", k.prefix)
+}
+
+// payloadsExpiring returns the key for the ZSET of payloads stored outside of the history of expiring
+// instances, scored by the expiration time.
+func (k *keys) payloadsExpiring() string {
+	return fmt.Sprintf("%spayloads-expiring", k.prefix)
+}
+
+// storedPayloadsKey returns the key for the SET of payloads stored outside of the history that are referenced by
+// the history of the given instance.
+func (k *keys) storedPayloadsKey(instance *core.WorkflowInstance) string {
+	return fmt.Sprintf("%sstored-payloads:%v", k.prefix, instanceSegment(instance))
+}
+
 func (k *keys) pendingEventsKey(instance *core.WorkflowInstance) string {
 	return fmt.Sprintf("%spending-events:%v", k.prefix, instanceSegment(instance))
 }
@@ -68,6 +120,22 @@ func (k *keys) futureEventKey(instance *core.WorkflowInstance, scheduleEventID i
 	return fmt.Sprintf("%sfuture-event:%v:%v", k.prefix, instanceSegment(instance), scheduleEventID)
 }
 
+// instanceFutureEventsKey returns the key for the SET of the future event keys of the given instance
+func (k *keys) instanceFutureEventsKey(instance *core.WorkflowInstance) string {
+	return fmt.Sprintf("%sinstance-future-events:%v", k.prefix, instanceSegment(instance))
+}
+
+// activityHeartbeatsKey returns the key for the HASH that contains the details of the last heartbeat of the
+// instance's running activities, keyed by activity ID
+func (k *keys) activityHeartbeatsKey(instance *core.WorkflowInstance) string {
+	return fmt.Sprintf("%sactivity-heartbeats:%v", k.prefix, instanceSegment(instance))
+}
+
+// startEventKey is the future event holding the started event of an instance with a delayed start
+func (k *keys) startEventKey(instance *core.WorkflowInstance) string {
+	return fmt.Sprintf("%sfuture-event:%v:start", k.prefix, instanceSegment(instance))
+}
+
 func (k *keys) payloadKey(instance *core.WorkflowInstance) string {
 	return fmt.Sprintf("%spayload:%v", k.prefix, instanceSegment(instance))
 }
@@ -0,0 +1,45 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+// Scores in the instances-by-creation index that older versions stored in seconds for sub-workflow instances are
+// in this range, scores in nanoseconds are always larger.
+const (
+	legacyCreationScoreMin = "1000000000"
+	legacyCreationScoreMax = "(1000000000000"
+)
+
+// migrateCreationScores converts scores in the instances-by-creation index stored in seconds to nanoseconds, the
+// unit used for all other instances and indexes.
+func (rb *redisBackend) migrateCreationScores(ctx context.Context) error {
+	for {
+		zs, err := rb.rdb.ZRangeArgsWithScores(ctx, redis.ZRangeArgs{
+			Key:     rb.keys.instancesByCreation(),
+			Start:   legacyCreationScoreMin,
+			Stop:    legacyCreationScoreMax,
+			ByScore: true,
+			Count:   queryBatchSize,
+		}).Result()
+		if err != nil {
+			return fmt.Errorf("reading legacy creation scores: %w", err)
+		}
+
+		if len(zs) == 0 {
+			return nil
+		}
+
+		for i := range zs {
+			zs[i].Score *= 1e9
+		}
+
+		// Only update instances that haven't been removed in the meantime
+		if err := rb.rdb.ZAddXX(ctx, rb.keys.instancesByCreation(), zs...).Err(); err != nil {
+			return fmt.Errorf("migrating creation scores: %w", err)
+		}
+	}
+}
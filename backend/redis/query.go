@@ -0,0 +1,229 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/core"
+	"github.com/redis/go-redis/v9"
+)
+
+const queryBatchSize = 500
+
+// queryPosition is the position of an instance in one of the index ZSETs, which are sorted by creation time
+type queryPosition struct {
+	score   float64
+	segment string
+}
+
+// before returns true if p is listed before the given position, i.e., it's newer
+func (p *queryPosition) before(o *queryPosition) bool {
+	return p.score > o.score || (p.score == o.score && p.segment > o.segment)
+}
+
+func (p *queryPosition) String() string {
+	return strconv.FormatFloat(p.score, 'f', -1, 64) + "|" + p.segment
+}
+
+func parseQueryPosition(cursor string) (*queryPosition, error) {
+	score, segment, ok := strings.Cut(cursor, "|")
+	if !ok {
+		return nil, fmt.Errorf("invalid cursor: %v", cursor)
+	}
+
+	s, err := strconv.ParseFloat(score, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	return &queryPosition{score: s, segment: segment}, nil
+}
+
+// ListWorkflowInstances scans the most selective index ZSET for the given query, newest first, and applies the
+// remaining filters to the instances found. Scanning starts at the cursor and stops once the page is full. The total
+// count is only determined if the index fully answers the query, otherwise it's reported as unknown.
+func (rb *redisBackend) ListWorkflowInstances(ctx context.Context, query *backend.WorkflowInstanceQuery) (*backend.WorkflowInstanceQueryResult, error) {
+	pageSize := query.PageSize
+	if pageSize <= 0 {
+		pageSize = backend.DefaultQueryPageSize
+	}
+
+	encoded, err := query.SearchAttributes.Encode()
+	if err != nil {
+		return nil, fmt.Errorf("invalid search attributes: %w", err)
+	}
+
+	// Filters not covered by the chosen index are evaluated while scanning it
+	indexKey := rb.keys.instancesByCreation()
+	indexFilters := 0
+	switch {
+	case query.ParentInstanceID != "":
+		indexKey = rb.keys.instancesByParent(query.ParentInstanceID)
+		indexFilters = 1
+	case len(encoded) > 0:
+		name := query.SearchAttributes.Names()[0]
+		indexKey = rb.keys.instancesBySearchAttribute(name, encoded[name])
+		indexFilters = 1
+	case query.WorkflowName != "":
+		indexKey = rb.keys.instancesByWorkflowName(query.WorkflowName)
+		indexFilters = 1
+	case query.Queue != "":
+		indexKey = rb.keys.instancesByQueue(query.Queue)
+		indexFilters = 1
+	}
+
+	min, max := "-inf", "+inf"
+	if !query.CreatedAfter.IsZero() {
+		min = strconv.FormatInt(query.CreatedAfter.UnixNano(), 10)
+	}
+
+	if !query.CreatedBefore.IsZero() {
+		max = "(" + strconv.FormatInt(query.CreatedBefore.UnixNano(), 10)
+	}
+
+	result := &backend.WorkflowInstanceQueryResult{
+		Instances:  make([]*backend.WorkflowInstanceInfo, 0),
+		TotalCount: backend.TotalCountUnknown,
+	}
+
+	// Creation time ranges are covered by the index scores
+	if scanFilters(query) == indexFilters {
+		if result.TotalCount, err = rb.rdb.ZCount(ctx, indexKey, min, max).Result(); err != nil {
+			return nil, fmt.Errorf("counting workflow instances: %w", err)
+		}
+	}
+
+	// Continue from the cursor. Instances with the same score as the cursor are skipped below.
+	var scanned, last *queryPosition
+	if query.Cursor != "" {
+		if scanned, err = parseQueryPosition(query.Cursor); err != nil {
+			return nil, err
+		}
+
+		max = strconv.FormatFloat(scanned.score, 'f', -1, 64)
+	}
+
+	for {
+		zs, err := rb.rdb.ZRangeArgsWithScores(ctx, redis.ZRangeArgs{
+			Key:     indexKey,
+			Start:   min,
+			Stop:    max,
+			ByScore: true,
+			Rev:     true,
+			Count:   queryBatchSize,
+		}).Result()
+		if err != nil {
+			return nil, fmt.Errorf("reading workflow instance index: %w", err)
+		}
+
+		// Skip instances with the same score that have already been handled
+		positions := make([]*queryPosition, 0, len(zs))
+		for _, z := range zs {
+			p := &queryPosition{score: z.Score, segment: z.Member.(string)}
+			if scanned == nil || scanned.before(p) {
+				positions = append(positions, p)
+			}
+		}
+
+		if len(positions) == 0 {
+			break
+		}
+
+		instanceKeys := make([]string, 0, len(positions))
+		for _, p := range positions {
+			instanceKeys = append(instanceKeys, rb.keys.instanceKeyFromSegment(p.segment))
+		}
+
+		instances, err := rb.rdb.MGet(ctx, instanceKeys...).Result()
+		if err != nil {
+			return nil, fmt.Errorf("reading workflow instances: %w", err)
+		}
+
+		states := make([]*instanceState, len(instances))
+		existing := make([]*core.WorkflowInstance, 0, len(instances))
+		for i, instance := range instances {
+			instStr, ok := instance.(string)
+			if !ok {
+				continue
+			}
+
+			if err := json.Unmarshal([]byte(instStr), &states[i]); err != nil {
+				return nil, fmt.Errorf("unmarshaling instance state: %w", err)
+			}
+
+			existing = append(existing, states[i].Instance)
+		}
+
+		searchAttributes, err := rb.getSearchAttributes(ctx, existing)
+		if err != nil {
+			return nil, err
+		}
+
+		j := 0
+		for i, state := range states {
+			p := positions[i]
+
+			if state == nil {
+				// Instance has expired, it's removed from the indexes by the next expiration
+				continue
+			}
+
+			info := &backend.WorkflowInstanceInfo{
+				Instance:         state.Instance,
+				WorkflowName:     state.WorkflowName,
+				Queue:            core.Queue(state.Queue),
+				State:            state.State,
+				Failed:           state.Failed,
+				CreatedAt:        state.CreatedAt,
+				CompletedAt:      state.CompletedAt,
+				SearchAttributes: searchAttributes[j],
+			}
+			j++
+
+			if !query.Matches(info) {
+				continue
+			}
+
+			// There is at least one more matching instance after the page
+			if len(result.Instances) == pageSize {
+				result.NextCursor = last.String()
+				return result, nil
+			}
+
+			result.Instances = append(result.Instances, info)
+			last = p
+		}
+
+		scanned = positions[len(positions)-1]
+		max = strconv.FormatFloat(scanned.score, 'f', -1, 64)
+
+		if len(zs) < queryBatchSize {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// scanFilters returns the number of filters of the given query that can't be answered by the creation time index
+func scanFilters(query *backend.WorkflowInstanceQuery) int {
+	n := len(query.SearchAttributes)
+	for _, set := range []bool{
+		query.WorkflowName != "",
+		query.State != nil,
+		query.Failed != nil,
+		query.Queue != "",
+		query.ParentInstanceID != "",
+		!query.CompletedAfter.IsZero() || !query.CompletedBefore.IsZero(),
+	} {
+		if set {
+			n++
+		}
+	}
+
+	return n
+}
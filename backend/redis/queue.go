@@ -188,6 +188,15 @@ func (q *taskQueue[T]) Extend(ctx context.Context, p redis.Pipeliner, queue work
 	return nil
 }
 
+// Ack acknowledges the task, it is not recovered anymore but stays in the queue until it is completed
+func (q *taskQueue[T]) Ack(ctx context.Context, rdb redis.UniversalClient, queue workflow.Queue, taskID string) error {
+	if err := rdb.XAck(ctx, q.Keys(queue).StreamKey, q.groupName, taskID).Err(); err != nil {
+		return fmt.Errorf("acknowledging task: %w", err)
+	}
+
+	return nil
+}
+
 func (q *taskQueue[T]) Complete(ctx context.Context, p redis.Pipeliner, queue workflow.Queue, taskID string) (*redis.Cmd, error) {
 	cmd := completeCmd.Run(ctx, p, []string{
 		q.Keys(queue).SetKey,
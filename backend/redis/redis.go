@@ -5,6 +5,7 @@ import (
 	"embed"
 	"fmt"
 	"io/fs"
+	"strings"
 	"time"
 
 	"github.com/cschleiden/go-workflows/backend"
@@ -23,10 +24,14 @@ var _ backend.Backend = (*redisBackend)(nil)
 var luaScripts embed.FS
 
 var (
-	createWorkflowInstanceCmd *redis.Script
-	completeWorkflowTaskCmd   *redis.Script
-	futureEventsCmd           *redis.Script
-	expireWorkflowInstanceCmd *redis.Script
+	createWorkflowInstanceCmd    *redis.Script
+	completeWorkflowTaskCmd      *redis.Script
+	completeActivityTaskCmd      *redis.Script
+	futureEventsCmd              *redis.Script
+	expireWorkflowInstanceCmd    *redis.Script
+	terminateWorkflowInstanceCmd *redis.Script
+	removeFutureEventCmd         *redis.Script
+	recordActivityHeartbeatCmd   *redis.Script
 )
 
 func NewRedisBackend(client redis.UniversalClient, opts ...RedisBackendOption) (*redisBackend, error) {
@@ -77,27 +82,52 @@ func NewRedisBackend(client redis.UniversalClient, opts ...RedisBackendOption) (
 
 	// Load all Lua scripts
 	cmdMapping := map[string]**redis.Script{
-		"create_workflow_instance.lua": &createWorkflowInstanceCmd,
-		"complete_workflow_task.lua":   &completeWorkflowTaskCmd,
-		"schedule_future_events.lua":   &futureEventsCmd,
-		"expire_workflow_instance.lua": &expireWorkflowInstanceCmd,
+		"create_workflow_instance.lua":    &createWorkflowInstanceCmd,
+		"complete_workflow_task.lua":      &completeWorkflowTaskCmd,
+		"complete_activity_task.lua":      &completeActivityTaskCmd,
+		"schedule_future_events.lua":      &futureEventsCmd,
+		"expire_workflow_instance.lua":    &expireWorkflowInstanceCmd,
+		"terminate_workflow_instance.lua": &terminateWorkflowInstanceCmd,
+		"remove_future_event.lua":         &removeFutureEventCmd,
+		"record_activity_heartbeat.lua":   &recordActivityHeartbeatCmd,
 	}
 
 	if err := loadScripts(ctx, rb.rdb, cmdMapping); err != nil {
 		return nil, fmt.Errorf("loading Lua scripts: %w", err)
 	}
 
+	if err := rb.migrateCreationScores(ctx); err != nil {
+		return nil, err
+	}
+
 	return rb, nil
 }
 
 func loadScripts(ctx context.Context, rdb redis.UniversalClient, cmdMapping map[string]**redis.Script) error {
+	// Functions shared between scripts are prepended to every script
+	libFiles, err := fs.ReadDir(luaScripts, "scripts/lib")
+	if err != nil {
+		return fmt.Errorf("reading Lua libraries: %w", err)
+	}
+
+	var lib strings.Builder
+	for _, libFile := range libFiles {
+		libContent, err := fs.ReadFile(luaScripts, "scripts/lib/"+libFile.Name())
+		if err != nil {
+			return fmt.Errorf("reading Lua library %s: %w", libFile.Name(), err)
+		}
+
+		lib.Write(libContent)
+		lib.WriteString("\n")
+	}
+
 	for scriptFile, cmd := range cmdMapping {
 		scriptContent, err := fs.ReadFile(luaScripts, "scripts/"+scriptFile)
 		if err != nil {
 			return fmt.Errorf("reading Lua script %s: %w", scriptFile, err)
 		}
 
-		*cmd = redis.NewScript(string(scriptContent))
+		*cmd = redis.NewScript(lib.String() + string(scriptContent))
 
 		if c := (*cmd).Load(ctx, rdb); c.Err() != nil {
 			return fmt.Errorf("loading Lua script %s: %w", scriptFile, c.Err())
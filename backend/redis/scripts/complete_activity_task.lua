@@ -0,0 +1,65 @@
+local keyIdx = 1
+local argvIdx = 1
+
+local getKey = function()
+    local key = KEYS[keyIdx]
+    keyIdx = keyIdx + 1
+    return key
+end
+
+local getArgv = function()
+    local argv = ARGV[argvIdx]
+    argvIdx = argvIdx + 1
+    return argv
+end
+
+local activitySetKey = getKey()
+local activityStreamKey = getKey()
+local activityHeartbeatsKey = getKey()
+local instanceKey = getKey()
+local payloadHashKey = getKey()
+local pendingEventsKey = getKey()
+local workflowQueuesSetKey = getKey()
+local workflowSetKey = getKey()
+local workflowStreamKey = getKey()
+
+local taskId = getArgv()
+local groupName = getArgv()
+local activityId = getArgv()
+local instanceSegment = getArgv()
+local eventId = getArgv()
+local eventData = getArgv()
+local payloadData = getArgv()
+
+-- Complete the activity task. If it's gone, the activity has already been completed, e.g., by another worker or
+-- an asynchronous completion, and the result must not be delivered again.
+local task = redis.call("XRANGE", activityStreamKey, taskId, taskId)
+if #task == 0 then
+    return nil
+end
+
+local id = task[1][2][2]
+redis.call("SREM", activitySetKey, id)
+redis.call("XACK", activityStreamKey, groupName, taskId)
+redis.call("XDEL", activityStreamKey, taskId)
+
+redis.call("HDEL", activityHeartbeatsKey, activityId)
+
+-- Only deliver the result if the instance is still active, otherwise the activity has been abandoned. State is
+-- omitted when the instance is active.
+local instance = redis.call("GET", instanceKey)
+if not instance or cjson.decode(instance)["state"] ~= nil then
+    return true
+end
+
+redis.call("HSETNX", payloadHashKey, eventId, payloadData)
+redis.call("XADD", pendingEventsKey, "*", "event", eventData)
+
+-- Queue workflow task
+redis.call("SADD", workflowQueuesSetKey, workflowSetKey)
+local added = redis.call("SADD", workflowSetKey, instanceSegment)
+if added == 1 then
+    redis.call("XADD", workflowStreamKey, "*", "id", instanceSegment, "data", "")
+end
+
+return true
@@ -20,16 +20,23 @@ local historyStreamKey = getKey()
 local pendingEventsKey = getKey()
 local payloadHashKey = getKey()
 local futureEventZSetKey = getKey()
+local instanceFutureEventsKey = getKey()
 local activeInstancesKey = getKey()
 local instancesByCreation = getKey()
 
 local workflowSetKey = getKey()
 local workflowStreamKey = getKey()
 local workflowQueuesSetKey = getKey()
+local storedPayloadsKey = getKey()
 
 local prefix = getArgv()
 local instanceSegment = getArgv()
 
+local storedPayloads = {}
+for i = 1, tonumber(getArgv()) do
+    table.insert(storedPayloads, getArgv())
+end
+
 local storePayload = function(eventId, payload)
     redis.pcall("HSETNX", payloadHashKey, eventId, payload)
 end
@@ -37,6 +44,31 @@ end
 -- Read instance
 local instance = cjson.decode(redis.call("GET", instanceKey))
 
+-- Instance has been finished while this task was being processed, e.g., it was terminated. Discard
+-- the result and only complete the workflow task. State is omitted when the instance is active.
+if instance["state"] ~= nil then
+    local taskId = ARGV[#ARGV - 1]
+    local groupName = ARGV[#ARGV]
+
+    local task = redis.call("XRANGE", workflowStreamKey, taskId, taskId)
+    if #task ~= 0 then
+        local id = task[1][2][2]
+        redis.call("SREM", workflowSetKey, id)
+        redis.call("XACK", workflowStreamKey, groupName, taskId)
+        redis.call("XDEL", workflowStreamKey, taskId)
+    end
+
+    redis.call("DEL", pendingEventsKey)
+
+    -- No history references the payloads of the discarded events, return them to be released
+    return { storedPayloads, {} }
+end
+
+-- Track payloads stored outside of the history, to release them when the instance is removed or expires
+for i = 1, #storedPayloads do
+    redis.call("SADD", storedPayloadsKey, storedPayloads[i])
+end
+
 -- Add executed events to history
 local executedEvents = tonumber(getArgv())
 local lastSequenceId = 0
@@ -61,13 +93,16 @@ redis.call("XDEL", pendingEventsKey, lastPendingEventMessageId)
 
 -- Update instance state
 local now = getArgv()
-local nowUnix = tonumber(getArgv())
+local nowUnixNano = tonumber(getArgv())
 local state = tonumber(getArgv())
+local failed = tonumber(getArgv())
 
 -- State constants
 local ContinuedAsNew = tonumber(getArgv())
 local Finished = tonumber(getArgv())
 
+local parentClosePolicy = readParentClosePolicyArgs(getArgv, now, Finished)
+
 instance["state"] = state
 
 -- If workflow instance finished, remove active execution
@@ -78,6 +113,10 @@ if state == ContinuedAsNew or state == Finished then
 
     instance["completed_at"] = now
 
+    if failed == 1 then
+        instance["failed"] = true
+    end
+
     redis.call("SREM", activeInstancesKey, instanceSegment)
 end
 
@@ -87,12 +126,20 @@ end
 
 redis.call("SET", instanceKey, cjson.encode(instance))
 
+-- Apply the parent close policy, sub-workflow instances keep running when the instance continues as new
+local terminatedSubWorkflows = {}
+if state == Finished then
+    terminatedSubWorkflows = applyParentClosePolicy(
+        prefix, instance["instance"]["instance_id"], instance["instance"]["execution_id"], parentClosePolicy)
+end
+
 -- Remove canceled timers
 local timersToCancel = tonumber(getArgv())
 for i = 1, timersToCancel do
     local futureEventKey = getKey()
 
     local eventRemoved = redis.call("ZREM", futureEventZSetKey, futureEventKey)
+    redis.call("SREM", instanceFutureEventsKey, futureEventKey)
     -- Event might've become visible while this task was being processed, in that
     -- case it would be already removed from futureEventZSetKey
     if eventRemoved == 1 then
@@ -115,6 +162,7 @@ for i = 1, timersToSchedule do
     local futureEventKey = getKey()
 
     redis.call("ZADD", futureEventZSetKey, timestamp, futureEventKey)
+    redis.call("SADD", instanceFutureEventsKey, futureEventKey)
 	redis.call("HSET", futureEventKey, "instance", instanceSegment, "id", eventId, "event", eventData, "queue", instance["queue"])
 	storePayload(eventId, payloadData)
 end
@@ -142,15 +190,24 @@ local otherWorkflowInstances = tonumber(getArgv())
 for i = 1, otherWorkflowInstances do
     local targetInstanceKey = getKey()
     local targetActiveInstanceExecutionKey = getKey()
+    local targetLatestInstanceExecutionKey = getKey()
 
     local targetInstanceSegment = getArgv()
     local targetInstanceId = getArgv()
     local createNewInstance = tonumber(getArgv())
     local eventsToDeliver = tonumber(getArgv())
     local skipEvents = false
+    local timeoutEventId = ""
+    local timeoutPayload = ""
 
     -- Creating a new instance?
     if createNewInstance == 1 then
+        local targetInstancesByWorkflowNameKey = getKey()
+        local targetInstancesByQueueKey = getKey()
+        local targetInstancesByParentKey = getKey()
+        local targetInstanceFutureEventsKey = getKey()
+        local targetTimeoutEventKey = getKey()
+
         local targetInstanceState = getArgv()
         local targetActiveInstanceExecutionState = getArgv()
 
@@ -158,6 +215,18 @@ for i = 1, otherWorkflowInstances do
         local conflictEventData = getArgv()
         local conflictEventPayloadData = getArgv()
 
+        -- Event notifying the parent of a detached sub-workflow instance once it has been created, if any
+        local startedEventId = getArgv()
+        local startedEventData = getArgv()
+        local startedEventPayloadData = getArgv()
+
+        -- Future event enforcing the timeouts of the new instance, if any
+        timeoutEventId = getArgv()
+        local timeoutAt = getArgv()
+        local timeoutEventData = getArgv()
+        timeoutPayload = getArgv()
+        local timeoutQueue = getArgv()
+
         -- Does the instance exist already?
         local instanceExists = redis.call("EXISTS", targetActiveInstanceExecutionKey)
         if instanceExists == 1 then
@@ -174,10 +243,27 @@ for i = 1, otherWorkflowInstances do
 
             -- Set active execution
             redis.call("SET", targetActiveInstanceExecutionKey, targetActiveInstanceExecutionState)
+            redis.call("SET", targetLatestInstanceExecutionKey, targetActiveInstanceExecutionState)
 
             -- Track active instance
             redis.call("SADD", activeInstancesKey, targetInstanceSegment)
-            redis.call("ZADD", instancesByCreation, nowUnix, targetInstanceSegment)
+            redis.call("ZADD", instancesByCreation, nowUnixNano, targetInstanceSegment)
+            redis.call("ZADD", targetInstancesByWorkflowNameKey, nowUnixNano, targetInstanceSegment)
+            redis.call("ZADD", targetInstancesByQueueKey, nowUnixNano, targetInstanceSegment)
+            if targetInstancesByParentKey ~= "" then
+                redis.call("ZADD", targetInstancesByParentKey, nowUnixNano, targetInstanceSegment)
+            end
+
+            if timeoutEventId ~= "" then
+                redis.call("ZADD", futureEventZSetKey, timeoutAt, targetTimeoutEventKey)
+                redis.call("SADD", targetInstanceFutureEventsKey, targetTimeoutEventKey)
+                redis.call("HSET", targetTimeoutEventKey, "instance", targetInstanceSegment, "id", timeoutEventId, "event", timeoutEventData, "queue", timeoutQueue)
+            end
+
+            if startedEventId ~= "" then
+                redis.call("XADD", pendingEventsKey, "*", "event", startedEventData)
+                storePayload(startedEventId, startedEventPayloadData)
+            end
         end
     end
 
@@ -186,6 +272,10 @@ for i = 1, otherWorkflowInstances do
     local instancePendingEventsKey = getKey()
     local instancePayloadHashKey = getKey()
 
+    if timeoutEventId ~= "" and not skipEvents then
+        redis.pcall("HSETNX", instancePayloadHashKey, timeoutEventId, timeoutPayload)
+    end
+
     for j = 1, eventsToDeliver do
         local eventId = getArgv()
         local eventData = getArgv()
@@ -211,6 +301,33 @@ for i = 1, otherWorkflowInstances do
     end
 end
 
+-- Signals the current execution did not process are delivered to the new execution. A workflow task for the new
+-- execution has been queued when it was created above.
+local continuedInstanceSegment = getArgv()
+local signalReceived = tonumber(getArgv())
+if continuedInstanceSegment ~= "" then
+    local continuedPendingEventsKey = prefix .. "pending-events:" .. continuedInstanceSegment
+    local continuedPayloadHashKey = prefix .. "payload:" .. continuedInstanceSegment
+
+    local msgs = redis.call("XRANGE", pendingEventsKey, "-", "+")
+    for i = 1, #msgs do
+        local msgId = msgs[i][1]
+        local eventData = msgs[i][2][2]
+        local event = cjson.decode(eventData)
+
+        if event["t"] == signalReceived then
+            redis.call("XADD", continuedPendingEventsKey, "*", "event", eventData)
+
+            local payloadData = redis.call("HGET", payloadHashKey, event["id"])
+            if payloadData then
+                redis.pcall("HSETNX", continuedPayloadHashKey, event["id"], payloadData)
+            end
+
+            redis.call("XDEL", pendingEventsKey, msgId)
+        end
+    end
+end
+
 -- Complete workflow task and mark instance task as completed
 local taskId = getArgv()
 local groupName = getArgv()
@@ -231,4 +348,5 @@ if pending_events > 0 then
     end
 end
 
-return true
\ No newline at end of file
+-- Return the terminated sub-workflow instances, to set their expiration
+return { {}, terminatedSubWorkflows }
\ No newline at end of file
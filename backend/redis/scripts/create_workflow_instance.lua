@@ -15,22 +15,78 @@ end
 
 local instanceKey = getKey()
 local activeInstanceExecutionKey = getKey()
+local latestInstanceExecutionKey = getKey()
 local pendingEventsKey = getKey()
 local payloadHashKey = getKey()
 
 local instancesActiveKey = getKey()
 local instancesByCreation = getKey()
+local instancesByWorkflowName = getKey()
+local instancesByQueue = getKey()
 
 local workflowSetKey = getKey()
 local workflowStreamKey = getKey()
 local workflowQueuesSet = getKey()
 
+local futureEventZSetKey = getKey()
+local instanceFutureEventsKey = getKey()
+local startEventKey = getKey()
+local timeoutEventKey = getKey()
+
 local instanceSegment = getArgv()
+local prefix = getArgv()
+
+-- Optional signal event, delivered after the started event or to the already active execution
+local signalEventId = getArgv()
+local signalEventData = getArgv()
+local signalPayload = getArgv()
+
+-- Needs to be kept in sync with core.IDReusePolicy
+local idReusePolicy = tonumber(getArgv())
+local idReusePolicyRejectDuplicate = 1
+local idReusePolicyAllowDuplicateFailedOnly = 2
 
 -- Is there an existing instance with active execution?
 local instanceExists = redis.call("EXISTS", activeInstanceExecutionKey)
 if instanceExists == 1 then
-  return redis.error_reply("ERR InstanceAlreadyExists")
+  if signalEventId == "" then
+    return redis.error_reply("ERR InstanceAlreadyExists")
+  end
+
+  -- Deliver the signal to the active execution instead.
+  --
+  -- Note: this does not work with Redis Cluster since the keys of the active execution are not passed into the script.
+  local activeInstance = redis.call("GET", activeInstanceExecutionKey)
+  local active = cjson.decode(activeInstance)
+  local activeSegment = active["instance_id"] .. ":" .. active["execution_id"]
+  local activeState = cjson.decode(redis.call("GET", prefix .. "instance:" .. activeSegment))
+
+  redis.pcall("HSETNX", prefix .. "payload:" .. activeSegment, signalEventId, signalPayload)
+  redis.call("XADD", prefix .. "pending-events:" .. activeSegment, "*", "event", signalEventData)
+
+  local activeWorkflowSetKey = prefix .. "task-set:" .. activeState["queue"] .. ":workflows"
+  local added = redis.call("SADD", activeWorkflowSetKey, activeSegment)
+  if added == 1 then
+    redis.call("XADD", prefix .. "task-stream:" .. activeState["queue"] .. ":workflows", "*", "id", activeSegment, "data", "")
+  end
+
+  return activeInstance
+end
+
+-- Check the latest, finished execution against the id reuse policy
+local latestInstance = redis.call("GET", latestInstanceExecutionKey)
+if latestInstance then
+  local latest = cjson.decode(latestInstance)
+  local latestState = redis.call("GET", prefix .. "instance:" .. latest["instance_id"] .. ":" .. latest["execution_id"])
+  if latestState then
+    if idReusePolicy == idReusePolicyRejectDuplicate then
+      return redis.error_reply("ERR InstanceAlreadyExists")
+    end
+
+    if idReusePolicy == idReusePolicyAllowDuplicateFailedOnly and not cjson.decode(latestState)["failed"] then
+      return redis.error_reply("ERR InstanceAlreadyExists")
+    end
+  end
 end
 
 -- Create new instance
@@ -40,6 +96,7 @@ redis.call("SETNX", instanceKey, instanceState)
 -- Set active execution
 local activeInstanceExecutionState = getArgv()
 redis.call("SET", activeInstanceExecutionKey, activeInstanceExecutionState)
+redis.call("SET", latestInstanceExecutionKey, activeInstanceExecutionState)
 
 -- Track active instance
 redis.call("SADD", instancesActiveKey, instanceSegment)
@@ -47,19 +104,58 @@ redis.call("SADD", instancesActiveKey, instanceSegment)
 -- add initial event & payload
 local eventId = getArgv()
 local eventData = getArgv()
-redis.call("XADD", pendingEventsKey, "*", "event", eventData)
-
 local payload = getArgv()
 redis.pcall("HSETNX", payloadHashKey, eventId, payload)
 
+if signalEventId ~= "" then
+    redis.pcall("HSETNX", payloadHashKey, signalEventId, signalPayload)
+end
+
 local creationTimestamp = tonumber(getArgv())
 redis.call("ZADD", instancesByCreation, creationTimestamp, instanceSegment)
+redis.call("ZADD", instancesByWorkflowName, creationTimestamp, instanceSegment)
+redis.call("ZADD", instancesByQueue, creationTimestamp, instanceSegment)
 
--- queue workflow task
 redis.call("SADD", workflowQueuesSet, workflowSetKey) -- track queue
+
+local startAt = tonumber(getArgv())
+local queue = getArgv()
+
+-- Schedule the future event enforcing the workflow timeouts, if any
+local timeoutEventId = getArgv()
+local timeoutAt = getArgv()
+local timeoutEventData = getArgv()
+local timeoutPayload = getArgv()
+if timeoutEventId ~= "" then
+    redis.call("ZADD", futureEventZSetKey, timeoutAt, timeoutEventKey)
+    redis.call("SADD", instanceFutureEventsKey, timeoutEventKey)
+    redis.call("HSET", timeoutEventKey, "instance", instanceSegment, "id", timeoutEventId, "event", timeoutEventData, "queue", queue)
+    redis.pcall("HSETNX", payloadHashKey, timeoutEventId, timeoutPayload)
+end
+
+-- If the start is delayed, the initial event becomes visible like a timer
+if startAt > 0 then
+    redis.call("ZADD", futureEventZSetKey, startAt, startEventKey)
+    redis.call("SADD", instanceFutureEventsKey, startEventKey)
+    redis.call("HSET", startEventKey, "instance", instanceSegment, "id", eventId, "event", eventData, "queue", queue)
+
+    if signalEventId ~= "" then
+        redis.call("XADD", pendingEventsKey, "*", "event", signalEventData)
+    end
+
+    return activeInstanceExecutionState
+end
+
+redis.call("XADD", pendingEventsKey, "*", "event", eventData)
+
+if signalEventId ~= "" then
+    redis.call("XADD", pendingEventsKey, "*", "event", signalEventData)
+end
+
+-- queue workflow task
 local added = redis.call("SADD", workflowSetKey, instanceSegment)
 if added == 1 then
     redis.call("XADD", workflowStreamKey, "*", "id", instanceSegment, "data", "")
 end
 
-return true
\ No newline at end of file
+return activeInstanceExecutionState
@@ -1,28 +1,81 @@
 -- Set the given expiration time on all keys passed in
 -- KEYS[1] - instances-by-creation key
 -- KEYS[2] - instances-expiring key
--- KEYS[3] - instance key
--- KEYS[4] - pending events key
--- KEYS[5] - history key
--- KEYS[6] - payload key
+-- KEYS[3] - payloads-expiring key
+-- KEYS[4] - instance-indexes-expiring key
+-- KEYS[5] - latest-instance-execution key
+-- KEYS[6] - stored payloads key
+-- KEYS[7] - instance key
+-- KEYS[8] - pending events key
+-- KEYS[9] - history key
+-- KEYS[10] - payload key
+-- KEYS[11] - search attributes key
+-- KEYS[12] - instance future events key
+-- KEYS[13] - activity heartbeats key
 -- ARGV[1] - current timestamp
 -- ARGV[2] - expiration time in seconds
 -- ARGV[3] - expiration timestamp in unix milliseconds
 -- ARGV[4] - instance segment
+-- ARGV[5] - execution id
+-- ARGV[6] - key prefix
 
--- Find instances which have already expired and remove from the index set
+-- Find instances which have already expired and remove them from the index sets
 local expiredInstances = redis.call("ZRANGE", KEYS[2], "-inf", ARGV[1], "BYSCORE")
 for i = 1, #expiredInstances do
   local instanceSegment = expiredInstances[i]
   redis.call("ZREM", KEYS[1], instanceSegment) -- index set
+
+  local indexes = redis.call("HGET", KEYS[4], instanceSegment)
+  if indexes then
+    for _, indexKey in ipairs(cjson.decode(indexes)) do
+      redis.call("ZREM", indexKey, instanceSegment)
+    end
+    redis.call("HDEL", KEYS[4], instanceSegment)
+  end
+
   redis.call("ZREM", KEYS[2], instanceSegment) -- expiration set
 end
 
 -- Add expiration time for future cleanup
 redis.call("ZADD", KEYS[2], ARGV[3], ARGV[4])
 
--- Set expiration on all keys
-for i = 3, #KEYS do
+-- The instance is gone once it has expired, remember the other index sets it's listed in
+local instance = redis.call("GET", KEYS[7])
+if instance then
+  local state = cjson.decode(instance)
+  local indexes = { ARGV[6] .. "instances-by-queue:" .. state["queue"] }
+
+  if state["workflow_name"] then
+    table.insert(indexes, ARGV[6] .. "instances-by-workflow:" .. state["workflow_name"])
+  end
+
+  if type(state["instance"]) == "table" and type(state["instance"]["parent"]) == "table" then
+    table.insert(indexes, ARGV[6] .. "instances-by-parent:" .. state["instance"]["parent"]["instance_id"])
+  end
+
+  local searchAttributes = redis.call("HGETALL", KEYS[11])
+  for i = 1, #searchAttributes, 2 do
+    table.insert(indexes, ARGV[6] .. "instances-by-search-attribute:" .. searchAttributes[i] .. ":" .. searchAttributes[i + 1])
+  end
+
+  redis.call("HSET", KEYS[4], ARGV[4], cjson.encode(indexes))
+end
+
+-- Payloads stored outside of the history are not covered by the key expiration, release them once the instance
+-- has expired
+local storedPayloads = redis.call("SMEMBERS", KEYS[6])
+for i = 1, #storedPayloads do
+  redis.call("ZADD", KEYS[3], ARGV[3], storedPayloads[i])
+end
+
+-- Expire the reference to the latest execution only if it's this one, a new execution resets the expiration
+local latest = redis.call("GET", KEYS[5])
+if latest and cjson.decode(latest)["execution_id"] == ARGV[5] then
+  redis.call("EXPIRE", KEYS[5], ARGV[2])
+end
+
+-- Set expiration on all other keys
+for i = 6, #KEYS do
   redis.call("EXPIRE", KEYS[i], ARGV[2])
 end
 
@@ -0,0 +1,117 @@
+-- Shared by scripts finishing workflow instances. Prepended to every script when the scripts are loaded.
+
+-- Terminates the active instance with the given segment. Mirrors terminate_workflow_instance.lua, the parent
+-- of the instance is not notified since it has finished already.
+local terminateSubWorkflowInstance = function(prefix, segment, instance, pcp)
+    local historyStreamKey = prefix .. "history:" .. segment
+    local payloadHashKey = prefix .. "payload:" .. segment
+    local instanceFutureEventsKey = prefix .. "instance-future-events:" .. segment
+
+    -- Add termination event to history
+    local sequenceId = (instance["last_sequence_id"] or 0) + 1
+    local event = cjson.decode(pcp.terminationEventData)
+    event["sid"] = sequenceId
+
+    redis.call("XADD", historyStreamKey, sequenceId, "event", cjson.encode(event))
+    redis.pcall("HSETNX", payloadHashKey, pcp.terminationEventId, pcp.terminationPayloadData)
+
+    -- Finish instance
+    instance["state"] = pcp.finished
+    instance["completed_at"] = pcp.now
+    instance["failed"] = true
+    instance["last_sequence_id"] = sequenceId
+    redis.call("SET", prefix .. "instance:" .. segment, cjson.encode(instance))
+
+    redis.call("DEL", prefix .. "active-instance-execution:" .. instance["instance"]["instance_id"])
+    redis.call("SREM", prefix .. "instances-active", segment)
+
+    -- Remove future events, i.e., pending timers, for this instance
+    local futureEvents = redis.call("SMEMBERS", instanceFutureEventsKey)
+    for i = 1, #futureEvents do
+        local futureEventKey = futureEvents[i]
+        redis.call("ZREM", prefix .. "future-events", futureEventKey)
+
+        local futureEventId = redis.call("HGET", futureEventKey, "id")
+        if futureEventId then
+            redis.call("HDEL", payloadHashKey, futureEventId)
+        end
+
+        redis.call("DEL", futureEventKey)
+    end
+    redis.call("DEL", instanceFutureEventsKey)
+
+    -- Discard pending events
+    redis.call("DEL", prefix .. "pending-events:" .. segment)
+end
+
+-- Adds the cancellation event to the pending events of the instance with the given segment and queues a
+-- workflow task for it
+local requestSubWorkflowInstanceCancellation = function(prefix, segment, instance, pcp)
+    redis.call("XADD", prefix .. "pending-events:" .. segment, "*", "event", pcp.cancellationEventData)
+    redis.pcall("HSETNX", prefix .. "payload:" .. segment, pcp.cancellationEventId, pcp.cancellationPayloadData)
+
+    local queueSetKey = prefix .. "task-set:" .. instance["queue"] .. ":workflows"
+    local queueStreamKey = prefix .. "task-stream:" .. instance["queue"] .. ":workflows"
+    redis.call("SADD", prefix .. "workflows:queues", queueSetKey)
+    local added = redis.call("SADD", queueSetKey, segment)
+    if added == 1 then
+        redis.call("XADD", queueStreamKey, "*", "id", segment, "data", "")
+    end
+end
+
+-- Reads the arguments for applyParentClosePolicy
+local readParentClosePolicyArgs = function(getArgv, now, finished)
+    return {
+        now = now,
+        finished = finished,
+        terminate = tonumber(getArgv()),
+        requestCancel = tonumber(getArgv()),
+        terminationEventId = getArgv(),
+        terminationEventData = getArgv(),
+        terminationPayloadData = getArgv(),
+        cancellationEventId = getArgv(),
+        cancellationEventData = getArgv(),
+        cancellationPayloadData = getArgv(),
+    }
+end
+
+-- Applies the parent close policy of the running sub-workflow instances of the given instance, which has just
+-- finished or was terminated. Sub-workflow instances that are terminated have their policy applied in turn.
+-- Returns the segments of all terminated sub-workflow instances.
+local applyParentClosePolicy = function(prefix, instanceId, executionId, pcp)
+    local terminated = {}
+
+    local parents = { { instanceId, executionId } }
+    while #parents > 0 do
+        local parent = table.remove(parents)
+
+        local segments = redis.call("ZRANGE", prefix .. "instances-by-parent:" .. parent[1], 0, -1)
+        for i = 1, #segments do
+            local segment = segments[i]
+
+            -- Sub-workflow instance might have been removed already
+            local data = redis.call("GET", prefix .. "instance:" .. segment)
+            if data then
+                local subWorkflowInstance = cjson.decode(data)
+                local instance = subWorkflowInstance["instance"]
+
+                -- State is omitted when the instance is active
+                if subWorkflowInstance["state"] == nil and type(instance) == "table" and
+                    type(instance["parent"]) == "table" and instance["parent"]["execution_id"] == parent[2] then
+                    local policy = subWorkflowInstance["parent_close_policy"]
+                    if policy == pcp.terminate then
+                        terminateSubWorkflowInstance(prefix, segment, subWorkflowInstance, pcp)
+                        table.insert(terminated, segment)
+
+                        -- The sub-workflow instance is finished as well
+                        table.insert(parents, { instance["instance_id"], instance["execution_id"] })
+                    elseif policy == pcp.requestCancel then
+                        requestSubWorkflowInstanceCancellation(prefix, segment, subWorkflowInstance, pcp)
+                    end
+                end
+            end
+        end
+    end
+
+    return terminated
+end
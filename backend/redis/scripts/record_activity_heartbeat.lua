@@ -0,0 +1,27 @@
+-- Record the details of an activity heartbeat if the activity task is still owned by the worker
+-- KEYS[1] - activity stream key
+-- KEYS[2] - activity heartbeats key
+-- ARGV[1] - task id
+-- ARGV[2] - group name
+-- ARGV[3] - worker name
+-- ARGV[4] - activity id
+-- ARGV[5] - heartbeat details
+
+local activityStreamKey = KEYS[1]
+local activityHeartbeatsKey = KEYS[2]
+
+local taskId = ARGV[1]
+local groupName = ARGV[2]
+local workerName = ARGV[3]
+local activityId = ARGV[4]
+local details = ARGV[5]
+
+-- The task might have been completed or, after its lock expired, claimed by another worker
+local pending = redis.call("XPENDING", activityStreamKey, groupName, taskId, taskId, 1)
+if #pending == 0 or pending[1][2] ~= workerName then
+    return nil
+end
+
+redis.call("HSET", activityHeartbeatsKey, activityId, details)
+
+return true
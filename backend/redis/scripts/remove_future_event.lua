@@ -0,0 +1,21 @@
+-- Remove a future event before it becomes visible
+-- KEYS[1] - future events zset key
+-- KEYS[2] - instance future events key
+-- KEYS[3] - payload key
+-- KEYS[4] - future event key
+
+local futureEventZSetKey = KEYS[1]
+local instanceFutureEventsKey = KEYS[2]
+local payloadHashKey = KEYS[3]
+local futureEventKey = KEYS[4]
+
+local eventRemoved = redis.call("ZREM", futureEventZSetKey, futureEventKey)
+redis.call("SREM", instanceFutureEventsKey, futureEventKey)
+-- Event might've become visible already, in that case it has been moved to the pending events
+if eventRemoved == 1 then
+    local eventId = redis.call("HGET", futureEventKey, "id")
+    redis.call("HDEL", payloadHashKey, eventId)
+    redis.call("DEL", futureEventKey)
+end
+
+return eventRemoved
@@ -33,6 +33,7 @@ for i = 1, #events do
     -- Delete event hash data
     redis.call("DEL", events[i])
     redis.call("ZREM", KEYS[1], events[i])
+    redis.call("SREM", prefix .. "instance-future-events:" .. instanceSegment, events[i])
   end
 end
 
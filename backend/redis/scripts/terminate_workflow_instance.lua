@@ -0,0 +1,105 @@
+local keyIdx = 1
+local argvIdx = 1
+
+local getKey = function()
+    local key = KEYS[keyIdx]
+    keyIdx = keyIdx + 1
+    return key
+end
+
+local getArgv = function()
+    local argv = ARGV[argvIdx]
+    argvIdx = argvIdx + 1
+    return argv
+end
+
+local instanceKey = getKey()
+local activeInstanceExecutionKey = getKey()
+local historyStreamKey = getKey()
+local payloadHashKey = getKey()
+local futureEventZSetKey = getKey()
+local instanceFutureEventsKey = getKey()
+local pendingEventsKey = getKey()
+local instancesActiveKey = getKey()
+
+local prefix = getArgv()
+local instanceSegment = getArgv()
+
+-- Read instance, state is omitted when the instance is active
+local instance = cjson.decode(redis.call("GET", instanceKey))
+if instance["state"] ~= nil then
+    return redis.error_reply("ERR InstanceNotActive")
+end
+
+-- Add termination event to history
+local eventId = getArgv()
+local eventData = getArgv()
+local payloadData = getArgv()
+local sequenceId = getArgv()
+
+redis.call("XADD", historyStreamKey, sequenceId, "event", eventData)
+redis.pcall("HSETNX", payloadHashKey, eventId, payloadData)
+
+-- Finish instance
+local now = getArgv()
+local finished = tonumber(getArgv())
+local parentClosePolicy = readParentClosePolicyArgs(getArgv, now, finished)
+
+instance["state"] = finished
+instance["completed_at"] = now
+instance["failed"] = true
+instance["last_sequence_id"] = tonumber(sequenceId)
+redis.call("SET", instanceKey, cjson.encode(instance))
+
+redis.call("DEL", activeInstanceExecutionKey)
+redis.call("SREM", instancesActiveKey, instanceSegment)
+
+-- Remove future events, i.e., pending timers, for this instance
+local futureEvents = redis.call("SMEMBERS", instanceFutureEventsKey)
+for i = 1, #futureEvents do
+    local futureEventKey = futureEvents[i]
+    redis.call("ZREM", futureEventZSetKey, futureEventKey)
+
+    local futureEventId = redis.call("HGET", futureEventKey, "id")
+    if futureEventId then
+        redis.call("HDEL", payloadHashKey, futureEventId)
+    end
+
+    redis.call("DEL", futureEventKey)
+end
+redis.call("DEL", instanceFutureEventsKey)
+
+-- Discard pending events, a workflow task still queued for this instance is dropped when it's dequeued
+redis.call("DEL", pendingEventsKey)
+
+-- Notify parent instance, if it's still active
+local notifyParent = tonumber(getArgv())
+if notifyParent == 1 then
+    local parentInstanceKey = getKey()
+    local parentPendingEventsKey = getKey()
+    local parentPayloadHashKey = getKey()
+    local parentWorkflowSetKey = getKey()
+    local parentWorkflowStreamKey = getKey()
+    local workflowQueuesSetKey = getKey()
+
+    local parentInstanceSegment = getArgv()
+    local parentEventId = getArgv()
+    local parentEventData = getArgv()
+    local parentPayloadData = getArgv()
+
+    local parentInstance = redis.call("GET", parentInstanceKey)
+    if parentInstance and cjson.decode(parentInstance)["state"] == nil then
+        redis.call("XADD", parentPendingEventsKey, "*", "event", parentEventData)
+        redis.pcall("HSETNX", parentPayloadHashKey, parentEventId, parentPayloadData)
+
+        -- Enqueue workflow task for parent
+        redis.call("SADD", workflowQueuesSetKey, parentWorkflowSetKey)
+        local added = redis.call("SADD", parentWorkflowSetKey, parentInstanceSegment)
+        if added == 1 then
+            redis.call("XADD", parentWorkflowStreamKey, "*", "id", parentInstanceSegment, "data", "")
+        end
+    end
+end
+
+-- Apply the parent close policy and return the terminated sub-workflow instances, to set their expiration
+return applyParentClosePolicy(prefix, instance["instance"]["instance_id"], instance["instance"]["execution_id"], parentClosePolicy)
@@ -0,0 +1,92 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cschleiden/go-workflows/core"
+	"github.com/redis/go-redis/v9"
+)
+
+// upsertSearchAttributes stores the given encoded search attributes of an instance and maintains the index set for
+// each attribute value. Updates for a single instance are serialized by the workflow task, so reading the previous
+// values before updating is safe.
+func (rb *redisBackend) upsertSearchAttributes(ctx context.Context, instance *core.WorkflowInstance, createdAt time.Time, searchAttributes map[string]string) error {
+	if len(searchAttributes) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(searchAttributes))
+	for name := range searchAttributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	key := rb.keys.searchAttributesKey(instance)
+	previous, err := rb.rdb.HMGet(ctx, key, names...).Result()
+	if err != nil {
+		return fmt.Errorf("reading search attributes: %w", err)
+	}
+
+	segment := instanceSegment(instance)
+
+	if _, err := rb.rdb.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		values := make([]any, 0, len(names)*2)
+
+		for i, name := range names {
+			value := searchAttributes[name]
+			values = append(values, name, value)
+
+			if pv, ok := previous[i].(string); ok {
+				if pv == value {
+					continue
+				}
+
+				p.ZRem(ctx, rb.keys.instancesBySearchAttribute(name, pv), segment)
+			}
+
+			p.ZAdd(ctx, rb.keys.instancesBySearchAttribute(name, value), redis.Z{
+				Score:  float64(createdAt.UnixNano()),
+				Member: segment,
+			})
+		}
+
+		p.HSet(ctx, key, values...)
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("upserting search attributes: %w", err)
+	}
+
+	return nil
+}
+
+// getSearchAttributes returns the search attributes of the given instances, in the same order
+func (rb *redisBackend) getSearchAttributes(ctx context.Context, instances []*core.WorkflowInstance) ([]core.SearchAttributes, error) {
+	r := make([]core.SearchAttributes, len(instances))
+	if len(instances) == 0 {
+		return r, nil
+	}
+
+	cmds := make([]*redis.MapStringStringCmd, len(instances))
+	if _, err := rb.rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, instance := range instances {
+			cmds[i] = p.HGetAll(ctx, rb.keys.searchAttributesKey(instance))
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("reading search attributes: %w", err)
+	}
+
+	for i, cmd := range cmds {
+		var err error
+		if r[i], err = core.DecodeSearchAttributes(cmd.Val()); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
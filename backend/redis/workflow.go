@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/cschleiden/go-workflows/backend"
 	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/backend/payload"
 	"github.com/cschleiden/go-workflows/core"
 	"github.com/cschleiden/go-workflows/internal/log"
 	"github.com/cschleiden/go-workflows/internal/propagators"
@@ -26,6 +28,10 @@ func (rb *redisBackend) GetWorkflowTask(ctx context.Context, queues []workflow.Q
 		return nil, fmt.Errorf("scheduling future events: %w", err)
 	}
 
+	if err := rb.releaseExpiredPayloads(ctx, strconv.FormatInt(time.Now().UnixMilli(), 10)); err != nil {
+		return nil, err
+	}
+
 	// Try to get a workflow task, this locks the instance when it dequeues one
 	instanceTask, err := rb.workflowQueue.Dequeue(ctx, rb.rdb, queues, rb.options.WorkflowLockTimeout, rb.options.BlockTimeout)
 	if err != nil {
@@ -41,6 +47,11 @@ func (rb *redisBackend) GetWorkflowTask(ctx context.Context, queues []workflow.Q
 		return nil, fmt.Errorf("reading workflow instance: %w", err)
 	}
 
+	// A terminated instance might still have a workflow task queued, discard it
+	if instanceState.State == core.WorkflowInstanceStateFinished {
+		return nil, rb.abandonWorkflowTask(ctx, instanceState, instanceTask.TaskID)
+	}
+
 	// Read all pending events for this instance
 	msgs, err := rb.rdb.XRange(ctx, rb.keys.pendingEventsKey(instanceState.Instance), "-", "+").Result()
 	if err != nil {
@@ -75,6 +86,16 @@ func (rb *redisBackend) GetWorkflowTask(ctx context.Context, queues []workflow.Q
 		}
 	}
 
+	// An instance with a delayed start might receive events, e.g., signals, before it's started. The started
+	// event is added to the pending events once it becomes visible, until then abandon any workflow tasks.
+	if instanceState.LastSequenceID == 0 {
+		if !slices.ContainsFunc(newEvents, func(e *history.Event) bool {
+			return e.Type == history.EventType_WorkflowExecutionStarted
+		}) {
+			return nil, rb.abandonWorkflowTask(ctx, instanceState, instanceTask.TaskID)
+		}
+	}
+
 	return &backend.WorkflowTask{
 		ID:                    instanceTask.TaskID,
 		Queue:                 core.Queue(instanceState.Queue),
@@ -87,6 +108,20 @@ func (rb *redisBackend) GetWorkflowTask(ctx context.Context, queues []workflow.Q
 	}, nil
 }
 
+// abandonWorkflowTask completes the given workflow task without executing it
+func (rb *redisBackend) abandonWorkflowTask(ctx context.Context, instanceState *instanceState, taskID string) error {
+	p := rb.rdb.TxPipeline()
+	if _, err := rb.workflowQueue.Complete(ctx, p, core.Queue(instanceState.Queue), taskID); err != nil {
+		return err
+	}
+
+	if _, err := p.Exec(ctx); err != nil {
+		return fmt.Errorf("abandoning workflow task: %w", err)
+	}
+
+	return nil
+}
+
 func (rb *redisBackend) ExtendWorkflowTask(ctx context.Context, task *backend.WorkflowTask) error {
 	_, err := rb.rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
 		return rb.workflowQueue.Extend(ctx, p, task.Queue, task.ID)
@@ -114,14 +149,23 @@ func (rb *redisBackend) CompleteWorkflowTask(
 		rb.keys.pendingEventsKey(instance),
 		rb.keys.payloadKey(instance),
 		rb.keys.futureEventsKey(),
+		rb.keys.instanceFutureEventsKey(instance),
 		rb.keys.instancesActive(),
 		rb.keys.instancesByCreation(),
 		queueKeys.SetKey,
 		queueKeys.StreamKey,
 		rb.workflowQueue.queueSetKey,
+		rb.keys.storedPayloadsKey(instance),
 	)
 	args = append(args, rb.keys.prefix, instanceSegment(instance))
 
+	// Keep track of payloads stored outside of the history, to release them when the instance is removed
+	stored := backend.StoredPayloads(rb.options.Converter, executedEvents)
+	args = append(args, len(stored))
+	for _, p := range stored {
+		args = append(args, string(p))
+	}
+
 	// Add executed events to the history
 	args = append(args, len(executedEvents))
 
@@ -146,29 +190,41 @@ func (rb *redisBackend) CompleteWorkflowTask(
 	// Update instance state and update active execution
 	now := time.Now().UTC()
 	nowStr := now.Format(time.RFC3339)
-	nowUnix := now.Unix()
 	args = append(
 		args,
 		string(nowStr),
-		nowUnix,
+		now.UnixNano(),
 		int(state),
+		backend.ExecutionFailed(executedEvents),
 		int(core.WorkflowInstanceStateContinuedAsNew),
 		int(core.WorkflowInstanceStateFinished),
 	)
 	keys = append(keys, rb.keys.activeInstanceExecutionKey(instance.InstanceID))
 
+	// Apply the parent close policy to running sub-workflow instances, if the instance finished
+	pcpArgs, err := parentClosePolicyArgs(now)
+	if err != nil {
+		return err
+	}
+	args = append(args, pcpArgs...)
+
 	// Remove canceled timers
-	timersToCancel := make([]*history.Event, 0)
+	timersToCancel := make([]int64, 0)
 	for _, event := range executedEvents {
 		switch event.Type {
 		case history.EventType_TimerCanceled:
-			timersToCancel = append(timersToCancel, event)
+			timersToCancel = append(timersToCancel, event.ScheduleEventID)
 		}
 	}
 
+	// Remove the future event enforcing the workflow timeouts, if any, once the execution is done
+	if state == core.WorkflowInstanceStateFinished || state == core.WorkflowInstanceStateContinuedAsNew {
+		timersToCancel = append(timersToCancel, 0)
+	}
+
 	args = append(args, len(timersToCancel))
-	for _, event := range timersToCancel {
-		keys = append(keys, rb.keys.futureEventKey(instance, event.ScheduleEventID))
+	for _, scheduleEventID := range timersToCancel {
+		keys = append(keys, rb.keys.futureEventKey(instance, scheduleEventID))
 	}
 
 	// Schedule timers
@@ -214,9 +270,14 @@ func (rb *redisBackend) CompleteWorkflowTask(
 
 	// Send new workflow events to the respective streams
 	groupedEvents := history.EventsByWorkflowInstance(workflowEvents)
+	createdInstances := map[core.WorkflowInstance]*history.ExecutionStartedAttributes{}
 	args = append(args, len(groupedEvents))
 	for targetInstance, events := range groupedEvents {
-		keys = append(keys, rb.keys.instanceKey(&targetInstance), rb.keys.activeInstanceExecutionKey(targetInstance.InstanceID))
+		keys = append(keys,
+			rb.keys.instanceKey(&targetInstance),
+			rb.keys.activeInstanceExecutionKey(targetInstance.InstanceID),
+			rb.keys.latestInstanceExecutionKey(targetInstance.InstanceID),
+		)
 		args = append(args, instanceSegment(&targetInstance), targetInstance.InstanceID)
 
 		// Are we creating a new workflow instance?
@@ -234,16 +295,21 @@ func (rb *redisBackend) CompleteWorkflowTask(
 			}
 
 			isb, err := json.Marshal(&instanceState{
-				Queue:     string(queue),
-				Instance:  &targetInstance,
-				State:     core.WorkflowInstanceStateActive,
-				Metadata:  a.Metadata,
-				CreatedAt: time.Now(),
+				Queue:        string(queue),
+				Instance:     &targetInstance,
+				WorkflowName: a.Name,
+				State:        core.WorkflowInstanceStateActive,
+				Metadata:     a.Metadata,
+				CreatedAt:    now,
+
+				ParentClosePolicy: a.ParentClosePolicy,
 			})
 			if err != nil {
 				return fmt.Errorf("marshaling new instance state: %w", err)
 			}
 
+			createdInstances[targetInstance] = a
+
 			ib, err := json.Marshal(targetInstance)
 			if err != nil {
 				return fmt.Errorf("marshaling instance: %w", err)
@@ -251,6 +317,20 @@ func (rb *redisBackend) CompleteWorkflowTask(
 
 			args = append(args, isb, ib)
 
+			// Only sub-workflows are tracked by their parent
+			var instancesByParentKey string
+			if targetInstance.Parent != nil {
+				instancesByParentKey = rb.keys.instancesByParent(targetInstance.Parent.InstanceID)
+			}
+
+			keys = append(keys,
+				rb.keys.instancesByWorkflowName(a.Name),
+				rb.keys.instancesByQueue(queue),
+				instancesByParentKey,
+				rb.keys.instanceFutureEventsKey(&targetInstance),
+				rb.keys.futureEventKey(&targetInstance, 0),
+			)
+
 			// Create pending event for conflicts
 			pfe := history.NewPendingEvent(time.Now(), history.EventType_SubWorkflowFailed, &history.SubWorkflowFailedAttributes{
 				Error: workflowerrors.FromError(backend.ErrInstanceAlreadyExists),
@@ -262,6 +342,32 @@ func (rb *redisBackend) CompleteWorkflowTask(
 
 			args = append(args, pfe.ID, eventData, payloadEventData)
 
+			// Notify the parent of a detached sub-workflow instance once it has been created
+			var startedEventID, startedEventData, startedPayloadData string
+			if a.Detached && state == core.WorkflowInstanceStateActive {
+				startedEvent := history.NewSubWorkflowStartedEvent(time.Now(), m.WorkflowInstance)
+				startedEventID = startedEvent.ID
+
+				if startedEventData, startedPayloadData, err = marshalEvent(startedEvent); err != nil {
+					return fmt.Errorf("marshaling started event: %w", err)
+				}
+			}
+
+			args = append(args, startedEventID, startedEventData, startedPayloadData)
+
+			// Schedule the future event enforcing the timeouts of the new instance
+			var timeoutEventID, timeoutAt, timeoutEventData, timeoutPayloadData string
+			if timeoutEvent := history.NewWorkflowTimeoutEvent(m.HistoryEvent); timeoutEvent != nil {
+				timeoutEventID = timeoutEvent.ID
+				timeoutAt = strconv.FormatInt(timeoutEvent.VisibleAt.UnixMilli(), 10)
+
+				if timeoutEventData, timeoutPayloadData, err = marshalEvent(timeoutEvent); err != nil {
+					return fmt.Errorf("marshaling timeout event: %w", err)
+				}
+			}
+
+			args = append(args, timeoutEventID, timeoutAt, timeoutEventData, timeoutPayloadData, string(queue))
+
 			queueKeys := rb.workflowQueue.Keys(queue)
 			keys = append(keys, queueKeys.SetKey, queueKeys.StreamKey)
 		} else {
@@ -285,6 +391,13 @@ func (rb *redisBackend) CompleteWorkflowTask(
 		}
 	}
 
+	// Signals the current execution did not process are delivered to the new execution
+	var continuedInstanceSegment string
+	if continuedInstance := backend.ContinuedExecution(instance, executedEvents); continuedInstance != nil {
+		continuedInstanceSegment = instanceSegment(continuedInstance)
+	}
+	args = append(args, continuedInstanceSegment, int(history.EventType_SignalReceived))
+
 	// Complete workflow task and unlock instance.
 	args = append(args, task.ID, rb.workflowQueue.groupName)
 
@@ -292,11 +405,48 @@ func (rb *redisBackend) CompleteWorkflowTask(
 	// 	No args/keys needed
 
 	// Run script
-	_, err := completeWorkflowTaskCmd.Run(ctx, rb.rdb, keys, args...).Result()
+	res, err := completeWorkflowTaskCmd.Run(ctx, rb.rdb, keys, args...).Result()
 	if err != nil {
 		return fmt.Errorf("completing workflow task: %w", err)
 	}
 
+	result := res.([]interface{})
+
+	// The instance was finished while the task was executed, the stored payloads of the task are not referenced
+	if discarded := result[0].([]interface{}); len(discarded) > 0 {
+		payloads := make([]payload.Payload, 0, len(discarded))
+		for _, p := range discarded {
+			payloads = append(payloads, payload.Payload(p.(string)))
+		}
+
+		if err := backend.ReleasePayloads(ctx, rb.options.Converter, payloads); err != nil {
+			return fmt.Errorf("releasing discarded payloads: %w", err)
+		}
+	}
+
+	if err := rb.expireTerminatedSubWorkflows(ctx, result[1].([]interface{})); err != nil {
+		return err
+	}
+
+	if searchAttributes := backend.SearchAttributesFromEvents(executedEvents); len(searchAttributes) > 0 {
+		instanceState, err := readInstance(ctx, rb.rdb, rb.keys.instanceKey(instance))
+		if err != nil {
+			return fmt.Errorf("reading instance: %w", err)
+		}
+
+		if err := rb.upsertSearchAttributes(ctx, instance, instanceState.CreatedAt, searchAttributes); err != nil {
+			return err
+		}
+	}
+
+	for targetInstance, a := range createdInstances {
+		// If the instance already existed, the attributes are stored for an execution that does not exist and
+		// cleaned up when listing instances.
+		if err := rb.upsertSearchAttributes(ctx, &targetInstance, now, a.SearchAttributes); err != nil {
+			return err
+		}
+	}
+
 	if state == core.WorkflowInstanceStateFinished || state == core.WorkflowInstanceStateContinuedAsNew {
 		// Trace workflow completion
 		ctx, err = (&propagators.TracingContextPropagator{}).Extract(ctx, task.Metadata)
@@ -326,6 +476,49 @@ func (rb *redisBackend) CompleteWorkflowTask(
 	return nil
 }
 
+// parentClosePolicyArgs returns the arguments for applying the parent close policy in a Lua script
+func parentClosePolicyArgs(now time.Time) ([]interface{}, error) {
+	terminationEvent := history.NewWorkflowTerminationEvent(now, "parent workflow instance finished")
+	terminationEventData, terminationPayloadData, err := marshalEvent(terminationEvent)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling termination event: %w", err)
+	}
+
+	cancellationEvent := history.NewWorkflowCancellationEvent(now)
+	cancellationEventData, cancellationPayloadData, err := marshalEvent(cancellationEvent)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling cancellation event: %w", err)
+	}
+
+	return []interface{}{
+		int(core.ParentClosePolicyTerminate),
+		int(core.ParentClosePolicyRequestCancel),
+		terminationEvent.ID, terminationEventData, terminationPayloadData,
+		cancellationEvent.ID, cancellationEventData, cancellationPayloadData,
+	}, nil
+}
+
+// expireTerminatedSubWorkflows sets the expiration of the sub-workflow instances terminated by the parent close
+// policy, the same as for instances terminated directly
+func (rb *redisBackend) expireTerminatedSubWorkflows(ctx context.Context, segments []interface{}) error {
+	if rb.options.AutoExpiration <= 0 {
+		return nil
+	}
+
+	for _, segment := range segments {
+		subWorkflowState, err := readInstance(ctx, rb.rdb, rb.keys.instanceKeyFromSegment(segment.(string)))
+		if err != nil {
+			return fmt.Errorf("reading sub-workflow instance: %w", err)
+		}
+
+		if err := rb.setWorkflowInstanceExpiration(ctx, subWorkflowState.Instance, rb.options.AutoExpiration); err != nil {
+			return fmt.Errorf("setting sub-workflow instance expiration: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func marshalEvent(event *history.Event) (string, string, error) {
 	eventData, err := marshalEventWithoutAttributes(event)
 	if err != nil {
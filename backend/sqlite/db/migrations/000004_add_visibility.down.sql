@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS `idx_instances_created_at`;
+DROP INDEX IF EXISTS `idx_instances_workflow_name_created_at`;
+DROP INDEX IF EXISTS `idx_instances_state_created_at`;
+DROP INDEX IF EXISTS `idx_instances_queue_created_at`;
+DROP INDEX IF EXISTS `idx_instances_completed_at_failed`;
+
+ALTER TABLE `instances` DROP COLUMN `workflow_name`;
+ALTER TABLE `instances` DROP COLUMN `failed`;
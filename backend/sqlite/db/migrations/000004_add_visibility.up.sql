@@ -0,0 +1,9 @@
+-- Add columns for listing and filtering workflow instances
+ALTER TABLE `instances` ADD COLUMN `workflow_name` TEXT NOT NULL DEFAULT '';
+ALTER TABLE `instances` ADD COLUMN `failed` INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX `idx_instances_created_at` ON `instances` (`created_at`);
+CREATE INDEX `idx_instances_workflow_name_created_at` ON `instances` (`workflow_name`, `created_at`);
+CREATE INDEX `idx_instances_state_created_at` ON `instances` (`state`, `created_at`);
+CREATE INDEX `idx_instances_queue_created_at` ON `instances` (`queue`, `created_at`);
+CREATE INDEX `idx_instances_completed_at_failed` ON `instances` (`completed_at`, `failed`);
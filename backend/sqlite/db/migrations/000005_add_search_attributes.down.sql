@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS `idx_search_attributes_name_value`;
+DROP TABLE IF EXISTS `search_attributes`;
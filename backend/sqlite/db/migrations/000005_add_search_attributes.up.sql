@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS `search_attributes` (
+  `instance_id` TEXT NOT NULL,
+  `execution_id` TEXT NOT NULL,
+  `name` TEXT NOT NULL,
+  `value` TEXT NOT NULL,
+  PRIMARY KEY(`instance_id`, `execution_id`, `name`)
+);
+
+CREATE INDEX `idx_search_attributes_name_value` ON `search_attributes` (`name`, `value`);
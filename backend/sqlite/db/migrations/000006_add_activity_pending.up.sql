@@ -0,0 +1 @@
+ALTER TABLE `activities` ADD COLUMN `async_pending` INTEGER NOT NULL DEFAULT 0;
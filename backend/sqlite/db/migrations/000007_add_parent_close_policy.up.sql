@@ -0,0 +1 @@
+ALTER TABLE `instances` ADD COLUMN `parent_close_policy` INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE `activities` ADD COLUMN `heartbeat_details` BLOB NULL;
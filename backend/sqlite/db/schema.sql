@@ -12,7 +12,7 @@ CREATE TABLE `instances` (
   `completed_at` DATETIME NULL,
   `locked_until` DATETIME NULL,
   `sticky_until` DATETIME NULL,
-  `worker` TEXT NULL, `queue` NVARCHAR(128) DEFAULT '',
+  `worker` TEXT NULL, `queue` NVARCHAR(128) DEFAULT '', `workflow_name` TEXT NOT NULL DEFAULT '', `failed` INTEGER NOT NULL DEFAULT 0,
   PRIMARY KEY(`id`, `execution_id`)
 );
 CREATE INDEX `idx_instances_id_execution_id` ON `instances` (`id`, `execution_id`);
@@ -61,5 +61,18 @@ CREATE TABLE `attributes` (
   PRIMARY KEY(`id`, `instance_id`, `execution_id`)
 );
 CREATE INDEX `idx_instances_locked_until_completed_at_queue` ON `instances` (`completed_at`, `locked_until`, `sticky_until`, `worker`, `queue`);
+CREATE INDEX `idx_instances_created_at` ON `instances` (`created_at`);
+CREATE INDEX `idx_instances_workflow_name_created_at` ON `instances` (`workflow_name`, `created_at`);
+CREATE INDEX `idx_instances_state_created_at` ON `instances` (`state`, `created_at`);
+CREATE INDEX `idx_instances_queue_created_at` ON `instances` (`queue`, `created_at`);
+CREATE INDEX `idx_instances_completed_at_failed` ON `instances` (`completed_at`, `failed`);
 CREATE INDEX `idx_activities_instance_id_execution_id_worker_queue` ON `activities` (`instance_id`, `execution_id`, `worker`, `queue`);
 CREATE INDEX `idx_activities_locked_until_queue` ON `activities` (`locked_until`, `queue`);
+CREATE TABLE `search_attributes` (
+  `instance_id` TEXT NOT NULL,
+  `execution_id` TEXT NOT NULL,
+  `name` TEXT NOT NULL,
+  `value` TEXT NOT NULL,
+  PRIMARY KEY(`instance_id`, `execution_id`, `name`)
+);
+CREATE INDEX `idx_search_attributes_name_value` ON `search_attributes` (`name`, `value`);
@@ -86,6 +86,16 @@ func (sb *sqliteBackend) GetWorkflowInstances(ctx context.Context, afterInstance
 		})
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows.Close()
+
+	if err := setSearchAttributes(ctx, tx, instances); err != nil {
+		return nil, err
+	}
+
 	tx.Commit()
 
 	return instances, nil
@@ -133,13 +143,37 @@ func (sb *sqliteBackend) GetWorkflowInstance(ctx context.Context, instance *core
 		instance = core.NewWorkflowInstance(id, executionID)
 	}
 
-	return &diag.WorkflowInstanceRef{
+	ref := &diag.WorkflowInstanceRef{
 		Instance:    instance,
 		CreatedAt:   createdAt,
 		CompletedAt: completedAt,
 		State:       state,
 		Queue:       queue,
-	}, nil
+	}
+
+	if err := setSearchAttributes(ctx, tx, []*diag.WorkflowInstanceRef{ref}); err != nil {
+		return nil, err
+	}
+
+	return ref, nil
+}
+
+func setSearchAttributes(ctx context.Context, tx *sql.Tx, refs []*diag.WorkflowInstanceRef) error {
+	instances := make([]*core.WorkflowInstance, 0, len(refs))
+	for _, ref := range refs {
+		instances = append(instances, ref.Instance)
+	}
+
+	searchAttributes, err := getSearchAttributes(ctx, tx, instances)
+	if err != nil {
+		return err
+	}
+
+	for i, ref := range refs {
+		ref.SearchAttributes = searchAttributes[i]
+	}
+
+	return nil
 }
 
 func (sb *sqliteBackend) GetWorkflowTree(ctx context.Context, instance *core.WorkflowInstance) (*diag.WorkflowInstanceTree, error) {
@@ -255,3 +255,14 @@ func removeFutureEvent(ctx context.Context, tx *sql.Tx, instance *core.WorkflowI
 
 	return err
 }
+
+// withWorkflowTimeoutEvent returns the given started event followed by the future event enforcing the timeouts
+// of the new execution, if any
+func withWorkflowTimeoutEvent(startedEvent *history.Event) []*history.Event {
+	events := []*history.Event{startedEvent}
+	if timeoutEvent := history.NewWorkflowTimeoutEvent(startedEvent); timeoutEvent != nil {
+		events = append(events, timeoutEvent)
+	}
+
+	return events
+}
@@ -0,0 +1,180 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/core"
+)
+
+// sqliteTime formats the given time the way created_at and completed_at are stored. Timestamps are stored as
+// text in UTC, so that they can be compared independent of the local time zone.
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05.999999999")
+}
+
+func (sb *sqliteBackend) ListWorkflowInstances(ctx context.Context, query *backend.WorkflowInstanceQuery) (*backend.WorkflowInstanceQueryResult, error) {
+	pageSize := query.PageSize
+	if pageSize <= 0 {
+		pageSize = backend.DefaultQueryPageSize
+	}
+
+	conditions := []string{"1 = 1"}
+	args := []any{}
+
+	if query.WorkflowName != "" {
+		conditions = append(conditions, "workflow_name = ?")
+		args = append(args, query.WorkflowName)
+	}
+
+	if query.State != nil {
+		conditions = append(conditions, "state = ?")
+		args = append(args, *query.State)
+	}
+
+	if query.Failed != nil {
+		conditions = append(conditions, "completed_at IS NOT NULL AND failed = ?")
+		args = append(args, *query.Failed)
+	}
+
+	if query.Queue != "" {
+		conditions = append(conditions, "queue = ?")
+		args = append(args, string(query.Queue))
+	}
+
+	if query.ParentInstanceID != "" {
+		conditions = append(conditions, "parent_instance_id = ?")
+		args = append(args, query.ParentInstanceID)
+	}
+
+	if !query.CreatedAfter.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, sqliteTime(query.CreatedAfter))
+	}
+
+	if !query.CreatedBefore.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, sqliteTime(query.CreatedBefore))
+	}
+
+	if !query.CompletedAfter.IsZero() {
+		conditions = append(conditions, "completed_at >= ?")
+		args = append(args, sqliteTime(query.CompletedAfter))
+	}
+
+	if !query.CompletedBefore.IsZero() {
+		conditions = append(conditions, "completed_at < ?")
+		args = append(args, sqliteTime(query.CompletedBefore))
+	}
+
+	if len(query.SearchAttributes) > 0 {
+		encoded, err := query.SearchAttributes.Encode()
+		if err != nil {
+			return nil, fmt.Errorf("invalid search attributes: %w", err)
+		}
+
+		for _, name := range query.SearchAttributes.Names() {
+			conditions = append(conditions, "EXISTS (SELECT 1 FROM `search_attributes` sa WHERE sa.instance_id = instances.id AND sa.execution_id = instances.execution_id AND sa.name = ? AND sa.value = ?)")
+			args = append(args, name, encoded[name])
+		}
+	}
+
+	where := strings.Join(conditions, " AND ")
+
+	tx, err := sb.db.BeginTx(ctx, &sql.TxOptions{
+		ReadOnly: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result := &backend.WorkflowInstanceQueryResult{}
+
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM `instances` WHERE "+where, args...).Scan(&result.TotalCount); err != nil {
+		return nil, fmt.Errorf("counting workflow instances: %w", err)
+	}
+
+	// The cursor is the rowid of the last instance of the previous page
+	if query.Cursor != "" {
+		rowID, err := strconv.ParseInt(query.Cursor, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %w", err)
+		}
+
+		where += " AND (created_at, rowid) < (SELECT created_at, rowid FROM `instances` WHERE rowid = ?)"
+		args = append(args, rowID)
+	}
+
+	rows, err := tx.QueryContext(
+		ctx,
+		`SELECT rowid, id, execution_id, parent_instance_id, parent_execution_id, parent_schedule_event_id, workflow_name, queue, state, failed, created_at, completed_at
+			FROM instances
+			WHERE `+where+`
+			ORDER BY created_at DESC, rowid DESC
+			LIMIT ?`,
+		append(args, pageSize+1)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing workflow instances: %w", err)
+	}
+	defer rows.Close()
+
+	var lastRowID int64
+	for rows.Next() {
+		if len(result.Instances) == pageSize {
+			// There is at least one more instance
+			result.NextCursor = strconv.FormatInt(lastRowID, 10)
+			break
+		}
+
+		var id, executionID, workflowName, queue string
+		var parentID, parentExecutionID *string
+		var parentScheduleEventID *int64
+		var info backend.WorkflowInstanceInfo
+		if err := rows.Scan(
+			&lastRowID, &id, &executionID, &parentID, &parentExecutionID, &parentScheduleEventID,
+			&workflowName, &queue, &info.State, &info.Failed, &info.CreatedAt, &info.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if parentID != nil {
+			info.Instance = core.NewSubWorkflowInstance(id, executionID, core.NewWorkflowInstance(*parentID, *parentExecutionID), *parentScheduleEventID)
+		} else {
+			info.Instance = core.NewWorkflowInstance(id, executionID)
+		}
+
+		info.WorkflowName = workflowName
+		info.Queue = core.Queue(queue)
+
+		result.Instances = append(result.Instances, &info)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows.Close()
+
+	instances := make([]*core.WorkflowInstance, 0, len(result.Instances))
+	for _, info := range result.Instances {
+		instances = append(instances, info.Instance)
+	}
+
+	searchAttributes, err := getSearchAttributes(ctx, tx, instances)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, info := range result.Instances {
+		info.SearchAttributes = searchAttributes[i]
+	}
+
+	return result, nil
+}
@@ -0,0 +1,78 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/cschleiden/go-workflows/core"
+)
+
+func upsertSearchAttributes(ctx context.Context, tx *sql.Tx, instance *core.WorkflowInstance, searchAttributes map[string]string) error {
+	for name, value := range searchAttributes {
+		if _, err := tx.ExecContext(
+			ctx,
+			"INSERT INTO `search_attributes` (instance_id, execution_id, name, value) VALUES (?, ?, ?, ?) ON CONFLICT (instance_id, execution_id, name) DO UPDATE SET value = excluded.value",
+			instance.InstanceID,
+			instance.ExecutionID,
+			name,
+			value,
+		); err != nil {
+			return fmt.Errorf("upserting search attribute %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// getSearchAttributes returns the search attributes of the given instances, in the same order
+func getSearchAttributes(ctx context.Context, tx *sql.Tx, instances []*core.WorkflowInstance) ([]core.SearchAttributes, error) {
+	r := make([]core.SearchAttributes, len(instances))
+	if len(instances) == 0 {
+		return r, nil
+	}
+
+	args := make([]any, 0, len(instances))
+	for _, instance := range instances {
+		args = append(args, instance.InstanceID)
+	}
+
+	rows, err := tx.QueryContext(
+		ctx,
+		fmt.Sprintf("SELECT instance_id, execution_id, name, value FROM `search_attributes` WHERE instance_id IN (?%v)", strings.Repeat(",?", len(instances)-1)),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("getting search attributes: %w", err)
+	}
+	defer rows.Close()
+
+	type key struct{ instanceID, executionID string }
+	encoded := map[key]map[string]string{}
+	for rows.Next() {
+		var instanceID, executionID, name, value string
+		if err := rows.Scan(&instanceID, &executionID, &name, &value); err != nil {
+			return nil, err
+		}
+
+		k := key{instanceID, executionID}
+		if encoded[k] == nil {
+			encoded[k] = map[string]string{}
+		}
+
+		encoded[k][name] = value
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, instance := range instances {
+		if r[i], err = core.DecodeSearchAttributes(encoded[key{instance.InstanceID, instance.ExecutionID}]); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
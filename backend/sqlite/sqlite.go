@@ -15,6 +15,7 @@ import (
 	"github.com/cschleiden/go-workflows/backend/history"
 	"github.com/cschleiden/go-workflows/backend/metadata"
 	"github.com/cschleiden/go-workflows/backend/metrics"
+	"github.com/cschleiden/go-workflows/backend/payload"
 	"github.com/cschleiden/go-workflows/core"
 	"github.com/cschleiden/go-workflows/internal/metrickeys"
 	"github.com/cschleiden/go-workflows/internal/workflowerrors"
@@ -166,11 +167,11 @@ func (sb *sqliteBackend) CreateWorkflowInstance(ctx context.Context, instance *w
 	a := event.Attributes.(*history.ExecutionStartedAttributes)
 
 	// Create workflow instance
-	if err := createInstance(ctx, tx, a.Queue, instance, a.Metadata); err != nil {
+	if err := createInstance(ctx, tx, a.Queue, instance, a); err != nil {
 		return err
 	}
 
-	if err := insertPendingEvents(ctx, tx, instance, []*history.Event{event}); err != nil {
+	if err := insertPendingEvents(ctx, tx, instance, withWorkflowTimeoutEvent(event)); err != nil {
 		return fmt.Errorf("inserting new event: %w", err)
 	}
 
@@ -181,10 +182,57 @@ func (sb *sqliteBackend) CreateWorkflowInstance(ctx context.Context, instance *w
 	return nil
 }
 
-func createInstance(ctx context.Context, tx *sql.Tx, queue workflow.Queue, wfi *workflow.Instance, metadata *workflow.Metadata) error {
-	// Check for existing instance
-	if err := tx.QueryRowContext(ctx, "SELECT 1 FROM `instances` WHERE id = ? AND state = ? LIMIT 1", wfi.InstanceID, core.WorkflowInstanceStateActive).
-		Scan(new(int)); err != sql.ErrNoRows {
+func (sb *sqliteBackend) SignalWithStartWorkflowInstance(ctx context.Context, instance *workflow.Instance, startedEvent, signalEvent *history.Event) (*workflow.Instance, error) {
+	tx, err := sb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Signal the active execution, if there is one
+	var executionID string
+	err = tx.QueryRowContext(ctx, "SELECT execution_id FROM `instances` WHERE id = ? AND state = ? LIMIT 1", instance.InstanceID, core.WorkflowInstanceStateActive).
+		Scan(&executionID)
+	switch {
+	case err == nil:
+		instance = core.NewWorkflowInstance(instance.InstanceID, executionID)
+
+		if err := insertPendingEvents(ctx, tx, instance, []*history.Event{signalEvent}); err != nil {
+			return nil, fmt.Errorf("inserting signal event: %w", err)
+		}
+
+	case err == sql.ErrNoRows:
+		a := startedEvent.Attributes.(*history.ExecutionStartedAttributes)
+
+		if err := createInstance(ctx, tx, a.Queue, instance, a); err != nil {
+			return nil, err
+		}
+
+		if err := insertPendingEvents(ctx, tx, instance, append(withWorkflowTimeoutEvent(startedEvent), signalEvent)); err != nil {
+			return nil, fmt.Errorf("inserting new events: %w", err)
+		}
+
+	default:
+		return nil, fmt.Errorf("getting active execution: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("signaling workflow instance: %w", err)
+	}
+
+	return instance, nil
+}
+
+func createInstance(ctx context.Context, tx *sql.Tx, queue workflow.Queue, wfi *workflow.Instance, a *history.ExecutionStartedAttributes) error {
+	// Check the latest execution of the instance against the id reuse policy
+	var state core.WorkflowInstanceState
+	var failed bool
+	if err := tx.QueryRowContext(ctx, "SELECT state, failed FROM `instances` WHERE id = ? ORDER BY rowid DESC LIMIT 1", wfi.InstanceID).
+		Scan(&state, &failed); err != nil {
+		if err != sql.ErrNoRows {
+			return fmt.Errorf("reading latest execution: %w", err)
+		}
+	} else if !a.IDReusePolicy.Allows(state, failed) {
 		return backend.ErrInstanceAlreadyExists
 	}
 
@@ -196,27 +244,34 @@ func createInstance(ctx context.Context, tx *sql.Tx, queue workflow.Queue, wfi *
 		parentEventID = &wfi.ParentEventID
 	}
 
-	metadataJson, err := json.Marshal(metadata)
+	metadataJson, err := json.Marshal(a.Metadata)
 	if err != nil {
 		return fmt.Errorf("marshaling metadata: %w", err)
 	}
 
 	_, err = tx.ExecContext(
 		ctx,
-		"INSERT INTO `instances` (queue, id, execution_id, parent_instance_id, parent_execution_id, parent_schedule_event_id, metadata, state) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		"INSERT INTO `instances` (queue, id, execution_id, workflow_name, parent_instance_id, parent_execution_id, parent_schedule_event_id, parent_close_policy, metadata, state, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
 		string(queue),
 		wfi.InstanceID,
 		wfi.ExecutionID,
+		a.Name,
 		parentInstanceID,
 		parentExecutionID,
 		parentEventID,
+		a.ParentClosePolicy,
 		string(metadataJson),
 		core.WorkflowInstanceStateActive,
+		sqliteTime(time.Now()),
 	)
 	if err != nil {
 		return fmt.Errorf("inserting workflow instance: %w", err)
 	}
 
+	if err := upsertSearchAttributes(ctx, tx, wfi, a.SearchAttributes); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -227,14 +282,21 @@ func (sb *sqliteBackend) RemoveWorkflowInstance(ctx context.Context, instance *c
 	}
 	defer tx.Rollback()
 
-	if err := sb.removeWorkflowInstance(ctx, instance, tx); err != nil {
+	stored, err := sb.removeWorkflowInstance(ctx, instance, tx)
+	if err != nil {
 		return err
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return backend.ReleasePayloads(ctx, sb.options.Converter, stored)
 }
 
-func (sb *sqliteBackend) removeWorkflowInstance(ctx context.Context, instance *core.WorkflowInstance, tx *sql.Tx) error {
+// removeWorkflowInstance removes the given instance and returns the payloads stored outside of its history,
+// which have to be released once the transaction has been committed.
+func (sb *sqliteBackend) removeWorkflowInstance(ctx context.Context, instance *core.WorkflowInstance, tx *sql.Tx) ([]payload.Payload, error) {
 	instanceID := instance.InstanceID
 	executionID := instance.ExecutionID
 
@@ -242,27 +304,51 @@ func (sb *sqliteBackend) removeWorkflowInstance(ctx context.Context, instance *c
 	var state core.WorkflowInstanceState
 	if err := row.Scan(&state); err != nil {
 		if err == sql.ErrNoRows {
-			return backend.ErrInstanceNotFound
+			return nil, backend.ErrInstanceNotFound
 		}
 	}
 
 	if state == core.WorkflowInstanceStateActive {
-		return backend.ErrInstanceNotFinished
+		return nil, backend.ErrInstanceNotFinished
+	}
+
+	stored, err := sb.storedPayloads(ctx, tx, instance)
+	if err != nil {
+		return nil, err
 	}
 
 	if _, err := tx.ExecContext(ctx, "DELETE FROM `instances` WHERE id = ? AND execution_id = ?", instanceID, executionID); err != nil {
-		return err
+		return nil, err
 	}
 
 	if _, err := tx.ExecContext(ctx, "DELETE FROM `history` WHERE instance_id = ? AND execution_id = ?", instanceID, executionID); err != nil {
-		return err
+		return nil, err
 	}
 
 	if _, err := tx.ExecContext(ctx, "DELETE FROM `attributes` WHERE instance_id = ? AND execution_id = ?", instanceID, executionID); err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	if _, err := tx.ExecContext(ctx, "DELETE FROM `search_attributes` WHERE instance_id = ? AND execution_id = ?", instanceID, executionID); err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+// storedPayloads returns the payloads stored outside of the history of the given instance, if the converter
+// stores payloads
+func (sb *sqliteBackend) storedPayloads(ctx context.Context, tx *sql.Tx, instance *core.WorkflowInstance) ([]payload.Payload, error) {
+	if !backend.StoresPayloads(sb.options.Converter) {
+		return nil, nil
+	}
+
+	h, err := getHistory(ctx, tx, instance, nil)
+	if err != nil {
+		return nil, fmt.Errorf("getting workflow history: %w", err)
+	}
+
+	return backend.StoredPayloads(sb.options.Converter, h), nil
 }
 
 func (sb *sqliteBackend) RemoveWorkflowInstances(ctx context.Context, options ...backend.RemovalOption) error {
@@ -271,7 +357,7 @@ func (sb *sqliteBackend) RemoveWorkflowInstances(ctx context.Context, options ..
 		opt(&ro)
 	}
 
-	rows, err := sb.db.QueryContext(ctx, `SELECT id, execution_id FROM instances WHERE completed_at < ?`, ro.FinishedBefore)
+	rows, err := sb.db.QueryContext(ctx, `SELECT id, execution_id FROM instances WHERE completed_at < ?`, sqliteTime(ro.FinishedBefore))
 	if err != nil {
 		return err
 	}
@@ -300,6 +386,16 @@ func (sb *sqliteBackend) RemoveWorkflowInstances(ctx context.Context, options ..
 
 		defer tx.Rollback()
 
+		var stored []payload.Payload
+		for i := range instanceIDs {
+			ps, err := sb.storedPayloads(ctx, tx, core.NewWorkflowInstance(instanceIDs[i], executionIDs[i]))
+			if err != nil {
+				return err
+			}
+
+			stored = append(stored, ps...)
+		}
+
 		placeholders := strings.Repeat(",?", len(instanceIDs)-1)
 		whereCondition := fmt.Sprintf("id IN (?%v) AND execution_id IN (?%v)", placeholders, placeholders)
 		args := make([]interface{}, 0, len(instanceIDs)*2)
@@ -310,7 +406,7 @@ func (sb *sqliteBackend) RemoveWorkflowInstances(ctx context.Context, options ..
 			args = append(args, executionIDs[i])
 		}
 
-		// Delete from instances, history and attributes tables
+		// Delete from instances, history, attributes, and search attributes tables
 		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM `instances` WHERE %v", whereCondition), args...); err != nil {
 			return err
 		}
@@ -323,9 +419,17 @@ func (sb *sqliteBackend) RemoveWorkflowInstances(ctx context.Context, options ..
 			return err
 		}
 
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM `search_attributes` WHERE instance_id IN (?%v) AND execution_id IN (?%v)", placeholders, placeholders), args...); err != nil {
+			return err
+		}
+
 		if err := tx.Commit(); err != nil {
 			return err
 		}
+
+		if err := backend.ReleasePayloads(ctx, sb.options.Converter, stored); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -358,6 +462,203 @@ func (sb *sqliteBackend) CancelWorkflowInstance(ctx context.Context, instance *w
 	return tx.Commit()
 }
 
+func (sb *sqliteBackend) TerminateWorkflowInstance(ctx context.Context, instance *workflow.Instance, event *history.Event) error {
+	tx, err := sb.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := terminateInstance(ctx, tx, instance, event); err != nil {
+		return err
+	}
+
+	if err := applyParentClosePolicy(ctx, tx, instance); err != nil {
+		return fmt.Errorf("applying parent close policy: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// terminateInstance finishes the given active instance with the termination event, and notifies its parent
+func terminateInstance(ctx context.Context, tx *sql.Tx, instance *workflow.Instance, event *history.Event) error {
+	instanceID := instance.InstanceID
+	executionID := instance.ExecutionID
+
+	row := tx.QueryRowContext(
+		ctx,
+		"SELECT state, parent_instance_id, parent_execution_id, parent_schedule_event_id FROM `instances` WHERE id = ? AND execution_id = ? LIMIT 1",
+		instanceID,
+		executionID,
+	)
+
+	var state core.WorkflowInstanceState
+	var parentInstanceID, parentExecutionID *string
+	var parentEventID *int64
+	if err := row.Scan(&state, &parentInstanceID, &parentExecutionID, &parentEventID); err != nil {
+		if err == sql.ErrNoRows {
+			return backend.ErrInstanceNotFound
+		}
+
+		return err
+	}
+
+	if state != core.WorkflowInstanceStateActive {
+		return backend.ErrInstanceNotActive
+	}
+
+	// Append termination event to the history
+	var lastSequenceID sql.NullInt64
+	if err := tx.QueryRowContext(
+		ctx, "SELECT MAX(sequence_id) FROM `history` WHERE instance_id = ? AND execution_id = ?", instanceID, executionID,
+	).Scan(&lastSequenceID); err != nil {
+		return fmt.Errorf("getting most recent sequence id: %w", err)
+	}
+
+	event.SequenceID = lastSequenceID.Int64 + 1
+
+	if err := insertEvents(ctx, tx, "history", instance, []*history.Event{event}); err != nil {
+		return fmt.Errorf("inserting termination event: %w", err)
+	}
+
+	// Finish the instance. Clearing the worker ensures that a workflow task that is currently being
+	// processed for this instance cannot be completed anymore.
+	if _, err := tx.ExecContext(
+		ctx,
+		"UPDATE `instances` SET state = ?, completed_at = ?, failed = 1, locked_until = NULL, sticky_until = NULL, worker = NULL WHERE id = ? AND execution_id = ?",
+		core.WorkflowInstanceStateFinished,
+		sqliteTime(time.Now()),
+		instanceID,
+		executionID,
+	); err != nil {
+		return fmt.Errorf("finishing workflow instance: %w", err)
+	}
+
+	// Abandon pending events including future timer events, and any activities
+	if _, err := tx.ExecContext(
+		ctx,
+		"DELETE FROM `attributes` WHERE instance_id = ? AND execution_id = ? AND id IN (SELECT id FROM `pending_events` WHERE instance_id = ? AND execution_id = ?)",
+		instanceID, executionID, instanceID, executionID,
+	); err != nil {
+		return fmt.Errorf("removing pending event attributes: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM `pending_events` WHERE instance_id = ? AND execution_id = ?", instanceID, executionID); err != nil {
+		return fmt.Errorf("removing pending events: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM `activities` WHERE instance_id = ? AND execution_id = ?", instanceID, executionID); err != nil {
+		return fmt.Errorf("removing activities: %w", err)
+	}
+
+	// Notify parent instance, if it's still active
+	if parentInstanceID != nil && parentExecutionID != nil && parentEventID != nil {
+		var parentState core.WorkflowInstanceState
+		if err := tx.QueryRowContext(
+			ctx, "SELECT state FROM `instances` WHERE id = ? AND execution_id = ? LIMIT 1", *parentInstanceID, *parentExecutionID,
+		).Scan(&parentState); err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("reading parent instance: %w", err)
+		} else if err == nil && parentState == core.WorkflowInstanceStateActive {
+			if err := insertPendingEvents(ctx, tx, core.NewWorkflowInstance(*parentInstanceID, *parentExecutionID), []*history.Event{
+				history.NewPendingEvent(time.Now(), history.EventType_SubWorkflowFailed, &history.SubWorkflowFailedAttributes{
+					Error: workflowerrors.NewPermanentError(backend.ErrInstanceTerminated),
+				}, history.ScheduleEventID(*parentEventID)),
+			}); err != nil {
+				return fmt.Errorf("inserting sub-workflow failed event: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// moveSignals moves pending signal events from one execution of a workflow instance to another
+func moveSignals(ctx context.Context, tx *sql.Tx, from, to *workflow.Instance) error {
+	if _, err := tx.ExecContext(
+		ctx,
+		"UPDATE `attributes` SET execution_id = ? WHERE instance_id = ? AND execution_id = ? AND id IN (SELECT id FROM `pending_events` WHERE instance_id = ? AND execution_id = ? AND event_type = ?)",
+		to.ExecutionID, from.InstanceID, from.ExecutionID, from.InstanceID, from.ExecutionID, history.EventType_SignalReceived,
+	); err != nil {
+		return fmt.Errorf("moving signal attributes: %w", err)
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		"UPDATE `pending_events` SET execution_id = ? WHERE instance_id = ? AND execution_id = ? AND event_type = ?",
+		to.ExecutionID, from.InstanceID, from.ExecutionID, history.EventType_SignalReceived,
+	); err != nil {
+		return fmt.Errorf("moving signals: %w", err)
+	}
+
+	return nil
+}
+
+// applyParentClosePolicy applies the parent close policy of the running sub-workflow instances of the given
+// instance, which has just finished or was terminated
+func applyParentClosePolicy(ctx context.Context, tx *sql.Tx, instance *workflow.Instance) error {
+	rows, err := tx.QueryContext(
+		ctx,
+		"SELECT id, execution_id, parent_schedule_event_id, parent_close_policy FROM `instances` WHERE parent_instance_id = ? AND parent_execution_id = ? AND state = ?",
+		instance.InstanceID,
+		instance.ExecutionID,
+		core.WorkflowInstanceStateActive,
+	)
+	if err != nil {
+		return fmt.Errorf("getting sub-workflow instances: %w", err)
+	}
+
+	type subWorkflow struct {
+		instance *workflow.Instance
+		policy   core.ParentClosePolicy
+	}
+
+	var subWorkflows []subWorkflow
+	for rows.Next() {
+		var instanceID, executionID string
+		var parentEventID int64
+		var policy core.ParentClosePolicy
+		if err := rows.Scan(&instanceID, &executionID, &parentEventID, &policy); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning sub-workflow instance: %w", err)
+		}
+
+		subWorkflows = append(subWorkflows, subWorkflow{
+			instance: core.NewSubWorkflowInstance(instanceID, executionID, instance, parentEventID),
+			policy:   policy,
+		})
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("getting sub-workflow instances: %w", err)
+	}
+
+	for _, swf := range subWorkflows {
+		switch swf.policy {
+		case core.ParentClosePolicyTerminate:
+			if err := terminateInstance(
+				ctx, tx, swf.instance, history.NewWorkflowTerminationEvent(time.Now(), "parent workflow instance finished"),
+			); err != nil {
+				return fmt.Errorf("terminating sub-workflow instance: %w", err)
+			}
+
+			// The sub-workflow instance is finished as well
+			if err := applyParentClosePolicy(ctx, tx, swf.instance); err != nil {
+				return err
+			}
+
+		case core.ParentClosePolicyRequestCancel:
+			if err := insertPendingEvents(ctx, tx, swf.instance, []*history.Event{
+				history.NewWorkflowCancellationEvent(time.Now()),
+			}); err != nil {
+				return fmt.Errorf("requesting sub-workflow cancellation: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
 func (sb *sqliteBackend) GetWorkflowInstanceHistory(ctx context.Context, instance *workflow.Instance, lastSequenceID *int64) ([]*history.Event, error) {
 	tx, err := sb.db.BeginTx(ctx, &sql.TxOptions{
 		ReadOnly: true,
@@ -401,6 +702,32 @@ func (sb *sqliteBackend) GetWorkflowInstanceState(ctx context.Context, instance
 	return state, nil
 }
 
+func (sb *sqliteBackend) GetLatestWorkflowInstance(ctx context.Context, instanceID string) (*workflow.Instance, error) {
+	row := sb.db.QueryRowContext(
+		ctx,
+		"SELECT execution_id, parent_instance_id, parent_execution_id, parent_schedule_event_id FROM `instances` WHERE id = ? ORDER BY rowid DESC LIMIT 1",
+		instanceID,
+	)
+
+	var executionID string
+	var parentInstanceID, parentExecutionID *string
+	var parentEventID *int64
+	if err := row.Scan(&executionID, &parentInstanceID, &parentExecutionID, &parentEventID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, backend.ErrInstanceNotFound
+		}
+
+		return nil, err
+	}
+
+	if parentInstanceID != nil {
+		return core.NewSubWorkflowInstance(
+			instanceID, executionID, core.NewWorkflowInstance(*parentInstanceID, *parentExecutionID), *parentEventID), nil
+	}
+
+	return core.NewWorkflowInstance(instanceID, executionID), nil
+}
+
 func (sb *sqliteBackend) SignalWorkflow(ctx context.Context, instanceID string, event *history.Event) error {
 	tx, err := sb.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -456,6 +783,8 @@ func (sb *sqliteBackend) GetWorkflowTask(ctx context.Context, queues []workflow.
 
 	args = append(args,
 		now, // pending_event.visible_at
+		history.EventType_WorkflowExecutionStarted, // delayed start event_type
+		now, // delayed start visible_at
 	)
 
 	row := tx.QueryRowContext(
@@ -475,6 +804,12 @@ func (sb *sqliteBackend) GetWorkflowTask(ctx context.Context, queues []workflow.
 								FROM pending_events
 								WHERE instance_id = i.id AND execution_id = i.execution_id AND (visible_at IS NULL OR visible_at <= ?)
 						)
+						-- Instances with a delayed start are not executed before they are started, even if they received other events
+						AND NOT EXISTS (
+							SELECT 1
+								FROM pending_events
+								WHERE instance_id = i.id AND execution_id = i.execution_id AND event_type = ? AND visible_at > ?
+						)
 					LIMIT 1
 			) RETURNING queue, id, execution_id, parent_instance_id, parent_execution_id, parent_schedule_event_id, metadata, sticky_until`, strings.Repeat(",?", len(queues)-1)),
 		args...,
@@ -561,19 +896,20 @@ func (sb *sqliteBackend) CompleteWorkflowTask(
 
 	instance := task.WorkflowInstance
 
-	var completedAt *time.Time
+	var completedAt *string
 	if state == core.WorkflowInstanceStateContinuedAsNew || state == core.WorkflowInstanceStateFinished {
-		t := time.Now()
+		t := sqliteTime(time.Now())
 		completedAt = &t
 	}
 
 	// Unlock instance, but keep it sticky to the current worker
 	if res, err := tx.ExecContext(
 		ctx,
-		`UPDATE instances SET locked_until = NULL, sticky_until = ?, completed_at = ?, state = ? WHERE id = ? AND execution_id = ? AND worker = ?`,
+		`UPDATE instances SET locked_until = NULL, sticky_until = ?, completed_at = ?, state = ?, failed = ? WHERE id = ? AND execution_id = ? AND worker = ?`,
 		time.Now().Add(sb.options.StickyTimeout),
 		completedAt,
 		state,
+		backend.ExecutionFailed(executedEvents),
 		instance.InstanceID,
 		instance.ExecutionID,
 		sb.workerName,
@@ -607,6 +943,10 @@ func (sb *sqliteBackend) CompleteWorkflowTask(
 		return fmt.Errorf("inserting history events: %w", err)
 	}
 
+	if err := upsertSearchAttributes(ctx, tx, instance, backend.SearchAttributesFromEvents(executedEvents)); err != nil {
+		return err
+	}
+
 	// Schedule activities
 	for _, e := range activityEvents {
 		a := e.Attributes.(*history.ActivityScheduledAttributes)
@@ -635,6 +975,16 @@ func (sb *sqliteBackend) CompleteWorkflowTask(
 		}
 	}
 
+	// Remove the future event enforcing the workflow timeouts, if any, once the execution is done
+	if completedAt != nil {
+		if err := removeFutureEvent(ctx, tx, instance, 0); err != nil {
+			return fmt.Errorf("removing workflow timeout event: %w", err)
+		}
+	}
+
+	// Payloads stored outside of the history that are not referenced anymore once the transaction is committed
+	var released []payload.Payload
+
 	// Insert new workflow events
 	groupedEvents := history.EventsByWorkflowInstance(workflowEvents)
 
@@ -650,7 +1000,7 @@ func (sb *sqliteBackend) CompleteWorkflowTask(
 			}
 
 			// Create new instance
-			if err := createInstance(ctx, tx, queue, m.WorkflowInstance, a.Metadata); err != nil {
+			if err := createInstance(ctx, tx, queue, m.WorkflowInstance, a); err != nil {
 				if err == backend.ErrInstanceAlreadyExists {
 					if err := insertPendingEvents(ctx, tx, instance, []*history.Event{
 						history.NewPendingEvent(time.Now(), history.EventType_SubWorkflowFailed, &history.SubWorkflowFailedAttributes{
@@ -660,16 +1010,32 @@ func (sb *sqliteBackend) CompleteWorkflowTask(
 						return fmt.Errorf("inserting sub-workflow failed event: %w", err)
 					}
 
+					released = append(released, backend.StoredPayloads(sb.options.Converter, []*history.Event{m.HistoryEvent})...)
+
 					continue
 				}
 
 				return fmt.Errorf("creating sub-workflow instance: %w", err)
 			}
+
+			// Notify the parent of a detached sub-workflow instance once it has been created
+			if a.Detached && state == core.WorkflowInstanceStateActive {
+				if err := insertPendingEvents(ctx, tx, instance, []*history.Event{
+					history.NewSubWorkflowStartedEvent(time.Now(), m.WorkflowInstance),
+				}); err != nil {
+					return fmt.Errorf("inserting sub-workflow started event: %w", err)
+				}
+			}
 		}
 
 		// Insert pending events for target instance
 		historyEvents := []*history.Event{}
 		for _, m := range events {
+			if m.HistoryEvent.Type == history.EventType_WorkflowExecutionStarted {
+				historyEvents = append(historyEvents, withWorkflowTimeoutEvent(m.HistoryEvent)...)
+				continue
+			}
+
 			historyEvents = append(historyEvents, m.HistoryEvent)
 		}
 		if err := insertPendingEvents(ctx, tx, &targetInstance, historyEvents); err != nil {
@@ -677,13 +1043,39 @@ func (sb *sqliteBackend) CompleteWorkflowTask(
 		}
 	}
 
+	// Signals the current execution did not process are delivered to the new execution
+	if continuedInstance := backend.ContinuedExecution(instance, executedEvents); continuedInstance != nil {
+		if err := moveSignals(ctx, tx, instance, continuedInstance); err != nil {
+			return err
+		}
+	}
+
+	// Sub-workflow instances keep running when the instance continues as new
+	if state == core.WorkflowInstanceStateFinished {
+		if err := applyParentClosePolicy(ctx, tx, instance); err != nil {
+			return fmt.Errorf("applying parent close policy: %w", err)
+		}
+	}
+
 	if sb.options.RemoveContinuedAsNewInstances && state == core.WorkflowInstanceStateContinuedAsNew {
-		if err := sb.removeWorkflowInstance(ctx, instance, tx); err != nil {
+		stored, err := sb.removeWorkflowInstance(ctx, instance, tx)
+		if err != nil {
 			return fmt.Errorf("removing old instance: %w", err)
 		}
+
+		released = append(released, stored...)
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// The task has been completed, only log failures to release payloads
+	if err := backend.ReleasePayloads(ctx, sb.options.Converter, released); err != nil {
+		sb.options.Logger.Error("releasing stored payloads", "error", err)
+	}
+
+	return nil
 }
 
 func (sb *sqliteBackend) ExtendWorkflowTask(ctx context.Context, task *backend.WorkflowTask) error {
@@ -741,12 +1133,13 @@ func (sb *sqliteBackend) GetActivityTask(ctx context.Context, queues []workflow.
 		fmt.Sprintf(`UPDATE activities
 			SET locked_until = ?, worker = ?
 			WHERE rowid = (
-				SELECT rowid FROM activities WHERE (locked_until IS NULL OR locked_until < ?) AND queue IN (?%s) LIMIT 1
-			) RETURNING id, instance_id, execution_id, event_type, timestamp, schedule_event_id, visible_at`, strings.Repeat(",?", len(queues)-1)),
+				SELECT rowid FROM activities WHERE (locked_until IS NULL OR locked_until < ?) AND async_pending = 0 AND queue IN (?%s) LIMIT 1
+			) RETURNING id, instance_id, execution_id, event_type, timestamp, schedule_event_id, visible_at, heartbeat_details`, strings.Repeat(",?", len(queues)-1)),
 		args...,
 	)
 
 	var instanceID, executionID string
+	var heartbeatDetails []byte
 	event := &history.Event{}
 
 	if err := row.Scan(
@@ -757,6 +1150,7 @@ func (sb *sqliteBackend) GetActivityTask(ctx context.Context, queues []workflow.
 		&event.Timestamp,
 		&event.ScheduleEventID,
 		&event.VisibleAt,
+		&heartbeatDetails,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			// No rows locked, just return
@@ -780,6 +1174,13 @@ func (sb *sqliteBackend) GetActivityTask(ctx context.Context, queues []workflow.
 
 	event.Attributes = a
 
+	// The activity has been picked up, remove the future event enforcing its ScheduleToStart timeout
+	if a.(*history.ActivityScheduledAttributes).ScheduleToStartTimeout > 0 {
+		if err := removeFutureEvent(ctx, tx, core.NewWorkflowInstance(instanceID, executionID), event.ScheduleEventID); err != nil {
+			return nil, fmt.Errorf("removing schedule to start timeout event: %w", err)
+		}
+	}
+
 	var metadataJson sql.NullString
 	if err := tx.QueryRowContext(ctx, "SELECT metadata FROM instances WHERE id = ?", instanceID).Scan(&metadataJson); err != nil {
 		return nil, fmt.Errorf("scanning metadata: %w", err)
@@ -791,10 +1192,11 @@ func (sb *sqliteBackend) GetActivityTask(ctx context.Context, queues []workflow.
 	}
 
 	t := &backend.ActivityTask{
-		ID:               event.ID,
-		ActivityID:       event.ID,
-		WorkflowInstance: core.NewWorkflowInstance(instanceID, executionID),
-		Event:            event,
+		ID:                   event.ID,
+		ActivityID:           event.ID,
+		WorkflowInstance:     core.NewWorkflowInstance(instanceID, executionID),
+		Event:                event,
+		LastHeartbeatDetails: heartbeatDetails,
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -814,7 +1216,7 @@ func (sb *sqliteBackend) CompleteActivityTask(ctx context.Context, task *backend
 	// Remove activity but keep the attributes, they are still needed for the history
 	if res, err := tx.ExecContext(
 		ctx,
-		`DELETE FROM activities WHERE instance_id = ? AND execution_id = ? AND id = ? AND worker = ?`,
+		`DELETE FROM activities WHERE instance_id = ? AND execution_id = ? AND id = ? AND (worker = ? OR async_pending = 1)`,
 		task.WorkflowInstance.InstanceID,
 		task.WorkflowInstance.ExecutionID,
 		task.ActivityID,
@@ -835,6 +1237,46 @@ func (sb *sqliteBackend) CompleteActivityTask(ctx context.Context, task *backend
 	return tx.Commit()
 }
 
+func (sb *sqliteBackend) SetActivityTaskPending(ctx context.Context, task *backend.ActivityTask) error {
+	// The activity might have been completed already, nothing to do in that case
+	if _, err := sb.db.ExecContext(
+		ctx,
+		`UPDATE activities SET async_pending = 1 WHERE instance_id = ? AND execution_id = ? AND id = ? AND worker = ?`,
+		task.WorkflowInstance.InstanceID,
+		task.WorkflowInstance.ExecutionID,
+		task.ActivityID,
+		sb.workerName,
+	); err != nil {
+		return fmt.Errorf("setting activity pending: %w", err)
+	}
+
+	return nil
+}
+
+func (sb *sqliteBackend) RecordActivityHeartbeat(ctx context.Context, task *backend.ActivityTask, details payload.Payload) error {
+	res, err := sb.db.ExecContext(
+		ctx,
+		`UPDATE activities SET heartbeat_details = ? WHERE instance_id = ? AND execution_id = ? AND id = ? AND worker = ?`,
+		[]byte(details),
+		task.WorkflowInstance.InstanceID,
+		task.WorkflowInstance.ExecutionID,
+		task.ActivityID,
+		sb.workerName,
+	)
+	if err != nil {
+		return fmt.Errorf("recording activity heartbeat: %w", err)
+	}
+
+	// The task has been completed or, after its lock expired, picked up by another worker
+	if rowsAffected, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("determining if activity heartbeat was recorded: %w", err)
+	} else if rowsAffected == 0 {
+		return backend.ErrTaskNotFound
+	}
+
+	return nil
+}
+
 func (sb *sqliteBackend) ExtendActivityTask(ctx context.Context, task *backend.ActivityTask) error {
 	tx, err := sb.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -2,6 +2,8 @@ package sqlite
 
 import (
 	"testing"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/cschleiden/go-workflows/backend"
 	"github.com/cschleiden/go-workflows/backend/test"
@@ -28,6 +30,16 @@ func Test_EndToEndSqliteBackend(t *testing.T) {
 		t.Skip()
 	}
 
+	// Timestamps are stored as text, run in a time zone other than UTC to ensure they are compared correctly
+	la, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err)
+
+	local := time.Local
+	time.Local = la
+	t.Cleanup(func() {
+		time.Local = local
+	})
+
 	test.EndToEndBackendTest(t, func(options ...backend.BackendOption) test.TestBackend {
 		// Disable sticky workflow behavior for the test execution
 		return NewInMemoryBackend(WithBackendOptions(append(options, backend.WithStickyTimeout(0))...))
@@ -3,6 +3,7 @@ package backend
 import (
 	"github.com/cschleiden/go-workflows/backend/history"
 	"github.com/cschleiden/go-workflows/backend/metadata"
+	"github.com/cschleiden/go-workflows/backend/payload"
 	"github.com/cschleiden/go-workflows/core"
 	"github.com/cschleiden/go-workflows/workflow"
 )
@@ -46,4 +47,7 @@ type ActivityTask struct {
 	WorkflowInstance *core.WorkflowInstance
 
 	Event *history.Event
+
+	// LastHeartbeatDetails are the details of the last heartbeat persisted for this activity task, if any
+	LastHeartbeatDetails payload.Payload
 }
@@ -3,6 +3,7 @@ package test
 import (
 	"context"
 	"errors"
+	"slices"
 	"testing"
 	"time"
 
@@ -20,8 +21,9 @@ import (
 
 func BackendTest(t *testing.T, setup func(options ...backend.BackendOption) TestBackend, teardown func(b TestBackend)) {
 	tests := []struct {
-		name string
-		f    func(t *testing.T, ctx context.Context, b backend.Backend)
+		name    string
+		options []backend.BackendOption
+		f       func(t *testing.T, ctx context.Context, b backend.Backend)
 	}{
 		{
 			name: "CreateWorkflowInstance_DoesNotError",
@@ -406,6 +408,44 @@ func BackendTest(t *testing.T, setup func(options ...backend.BackendOption) Test
 				require.Equal(t, history.EventType_WorkflowExecutionCanceled, task.NewEvents[len(task.NewEvents)-1].Type)
 			},
 		},
+		{
+			name: "CreateWorkflowInstance_SchedulesTimeoutEvent",
+			f: func(t *testing.T, ctx context.Context, b backend.Backend) {
+				wfi := core.NewWorkflowInstance(uuid.NewString(), uuid.NewString())
+				err := b.CreateWorkflowInstance(
+					ctx, wfi, history.NewHistoryEvent(1, time.Now(), history.EventType_WorkflowExecutionStarted, &history.ExecutionStartedAttributes{
+						Queue:      workflow.QueueDefault,
+						RunTimeout: 100 * time.Millisecond,
+					}))
+				require.NoError(t, err)
+
+				queues := []workflow.Queue{workflow.QueueDefault, core.QueueSystem}
+				require.NoError(t, b.PrepareWorkflowQueues(ctx, queues))
+
+				// The timeout is enforced even if no workflow task has been executed yet
+				time.Sleep(200 * time.Millisecond)
+
+				var types []history.EventType
+				require.Eventually(t, func() bool {
+					task, err := b.GetWorkflowTask(ctx, queues)
+					require.NoError(t, err)
+					if task == nil {
+						return false
+					}
+
+					for _, e := range task.NewEvents {
+						types = append(types, e.Type)
+					}
+
+					require.NoError(t, b.CompleteWorkflowTask(
+						ctx, task, core.WorkflowInstanceStateActive, task.NewEvents, nil, nil, nil))
+
+					return slices.Contains(types, history.EventType_WorkflowExecutionTimedOut)
+				}, 5*time.Second, 50*time.Millisecond)
+
+				require.Equal(t, history.EventType_WorkflowExecutionStarted, types[0])
+			},
+		},
 		{
 			name: "GetActivityTask_ReturnsNilWhenTimeout",
 			f: func(t *testing.T, ctx context.Context, b backend.Backend) {
@@ -464,11 +504,62 @@ func BackendTest(t *testing.T, setup func(options ...backend.BackendOption) Test
 				require.Equal(t, history.EventType_ActivityCompleted, wfTask.NewEvents[len(wfTask.NewEvents)-1].Type)
 			},
 		},
+		{
+			name: "RecordActivityHeartbeat_DetailsReturnedOnRedelivery",
+			options: []backend.BackendOption{func(o *backend.Options) {
+				o.ActivityLockTimeout = 100 * time.Millisecond
+			}},
+			f: func(t *testing.T, ctx context.Context, b backend.Backend) {
+				wfi := runWorkflowWithActivity(t, ctx, b, workflow.QueueDefault, workflow.QueueDefault)
+
+				require.NoError(t, b.PrepareActivityQueues(ctx, []workflow.Queue{workflow.QueueDefault}))
+
+				task, err := b.GetActivityTask(ctx, []workflow.Queue{workflow.QueueDefault})
+				require.NoError(t, err)
+				require.NotNil(t, task)
+				require.Nil(t, task.LastHeartbeatDetails)
+
+				details := payload.Payload(`42`)
+				require.NoError(t, b.RecordActivityHeartbeat(ctx, task, details))
+
+				// Let the lock expire, the task is redelivered with the recorded details
+				time.Sleep(200 * time.Millisecond)
+
+				task, err = b.GetActivityTask(ctx, []workflow.Queue{workflow.QueueDefault})
+				require.NoError(t, err)
+				require.NotNil(t, task)
+				require.Equal(t, wfi.InstanceID, task.WorkflowInstance.InstanceID)
+				require.Equal(t, details, task.LastHeartbeatDetails)
+
+				require.NoError(t,
+					b.CompleteActivityTask(ctx, task, history.NewHistoryEvent(1, time.Now(), history.EventType_ActivityCompleted, &history.ActivityCompletedAttributes{})),
+				)
+			},
+		},
+		{
+			name: "RecordActivityHeartbeat_CompletedTask",
+			f: func(t *testing.T, ctx context.Context, b backend.Backend) {
+				runWorkflowWithActivity(t, ctx, b, workflow.QueueDefault, workflow.QueueDefault)
+
+				require.NoError(t, b.PrepareActivityQueues(ctx, []workflow.Queue{workflow.QueueDefault}))
+
+				task, err := b.GetActivityTask(ctx, []workflow.Queue{workflow.QueueDefault})
+				require.NoError(t, err)
+				require.NotNil(t, task)
+
+				require.NoError(t,
+					b.CompleteActivityTask(ctx, task, history.NewHistoryEvent(1, time.Now(), history.EventType_ActivityCompleted, &history.ActivityCompletedAttributes{})),
+				)
+
+				err = b.RecordActivityHeartbeat(ctx, task, payload.Payload(`42`))
+				require.ErrorIs(t, err, backend.ErrTaskNotFound)
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			b := setup()
+			b := setup(tt.options...)
 			ctx := context.Background()
 
 			t.Cleanup(func() {
@@ -205,6 +205,31 @@ func EndToEndBackendTest(t *testing.T, setup func(options ...backend.BackendOpti
 				require.Equal(t, 7, r)
 			},
 		},
+		{
+			name: "GetVersion_Simple",
+			f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+				wf := func(ctx workflow.Context) (workflow.Version, error) {
+					v, err := workflow.GetVersion(ctx, "change", workflow.DefaultVersion, 1)
+					if err != nil {
+						return 0, err
+					}
+
+					// Do something to force the task to end
+					workflow.Sleep(ctx, time.Millisecond*1)
+
+					return v, nil
+				}
+				register(t, ctx, w, []interface{}{wf}, nil)
+
+				instance := runWorkflow(t, ctx, c, wf)
+
+				r, err := client.GetWorkflowResult[workflow.Version](ctx, c, instance, time.Second*10)
+				require.NoError(t, err)
+				require.Equal(t, workflow.Version(1), r)
+
+				historyContains(ctx, t, b, instance, history.EventType_VersionMarker)
+			},
+		},
 		{
 			name: "Signal_after_completion",
 			f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
@@ -641,6 +666,45 @@ func EndToEndBackendTest(t *testing.T, setup func(options ...backend.BackendOpti
 				require.EqualError(t, err, "workflow history size exceeded 2 events")
 			},
 		},
+		{
+			name: "ContinueAsNewSuggested",
+			options: []backend.BackendOption{
+				backend.WithMaxHistorySize(20),
+				backend.WithContinueAsNewSuggestedThreshold(0.5),
+			},
+			f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+				a := func(ctx context.Context) (int, error) {
+					return 0, nil
+				}
+
+				wf := func(ctx workflow.Context, run int) (int64, error) {
+					if run > 0 {
+						return workflow.HistoryLength(ctx), nil
+					}
+
+					for !workflow.ContinueAsNewSuggested(ctx) {
+						_, err := workflow.ExecuteActivity[int](ctx, workflow.DefaultActivityOptions, a).Get(ctx)
+						if err != nil {
+							return 0, err
+						}
+					}
+
+					return workflow.HistoryLength(ctx), workflow.ContinueAsNew(ctx, run+1)
+				}
+				register(t, ctx, w, []interface{}{wf}, []interface{}{a})
+
+				instance := runWorkflow(t, ctx, c, wf, 0)
+
+				r, err := client.GetWorkflowResult[int64](ctx, c, instance, time.Second*5)
+				require.NoError(t, err)
+				require.GreaterOrEqual(t, r, int64(10))
+				require.Less(t, r, int64(20))
+
+				r, err = client.GetWorkflowResult[int64](ctx, c, continuedExecution(t, ctx, c, b, instance), time.Second*5)
+				require.NoError(t, err)
+				require.Equal(t, int64(2), r)
+			},
+		},
 	}
 
 	tests = append(tests, e2eActivityTests...)
@@ -651,6 +715,23 @@ func EndToEndBackendTest(t *testing.T, setup func(options ...backend.BackendOpti
 	tests = append(tests, e2eRemovalTests...)
 	tests = append(tests, e2eContinueAsNewTests...)
 	tests = append(tests, e2eTracingTests...)
+	tests = append(tests, e2eTerminateTests...)
+	tests = append(tests, e2eQueryTests...)
+	tests = append(tests, e2eWorkflowTimeoutTests...)
+	tests = append(tests, e2eScheduleTests...)
+	tests = append(tests, e2eStartDelayTests...)
+	tests = append(tests, e2eIDReuseTests...)
+	tests = append(tests, e2eSignalWithStartTests...)
+	tests = append(tests, e2eListTests...)
+	tests = append(tests, e2eSearchAttributesTests...)
+	tests = append(tests, e2eUpdateTests...)
+	tests = append(tests, e2eCodecTests...)
+	tests = append(tests, e2eOffloadingTests...)
+	tests = append(tests, e2eLocalActivityTests...)
+	tests = append(tests, e2eAsyncActivityTests...)
+	tests = append(tests, e2eWorkflowRetryTests...)
+	tests = append(tests, e2eParentCloseTests...)
+	tests = append(tests, e2eDetachedSubWorkflowTests...)
 
 	run := func(suffix string, workerOptions worker.Options) {
 		for _, tt := range tests {
@@ -3,6 +3,8 @@ package test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -134,5 +136,352 @@ var e2eActivityTests = []backendTest{
 			_, err := runWorkflowWithResult[any](t, ctx, c, wf)
 			require.NoError(t, err)
 		},
+	}, {
+		name: "Activity/HeartbeatKeepsActivityAlive",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			a := func(ctx context.Context) (int, error) {
+				for i := 0; i < 5; i++ {
+					time.Sleep(50 * time.Millisecond)
+					if err := activity.RecordHeartbeat(ctx, i); err != nil {
+						return 0, err
+					}
+				}
+
+				return 42, nil
+			}
+
+			wf := func(ctx workflow.Context) (int, error) {
+				return workflow.ExecuteActivity[int](ctx, workflow.ActivityOptions{
+					RetryOptions: workflow.RetryOptions{
+						MaxAttempts: 1,
+					},
+					HeartbeatTimeout: 150 * time.Millisecond,
+				}, a).Get(ctx)
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{a})
+
+			r, err := runWorkflowWithResult[int](t, ctx, c, wf)
+			require.NoError(t, err)
+			require.Equal(t, 42, r)
+		},
+	},
+	{
+		name: "Activity/HeartbeatTimeoutResumesFromDetails",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			a := func(ctx context.Context) (int, error) {
+				progress, err := activity.LastHeartbeatDetails[int](ctx)
+				if err != nil {
+					return 0, err
+				}
+
+				if activity.Attempt(ctx) == 0 {
+					if err := activity.RecordHeartbeat(ctx, 3); err != nil {
+						return 0, err
+					}
+
+					// Stop heartbeating, the activity will time out
+					<-ctx.Done()
+					return 0, ctx.Err()
+				}
+
+				return progress, nil
+			}
+
+			wf := func(ctx workflow.Context) (int, error) {
+				return workflow.ExecuteActivity[int](ctx, workflow.ActivityOptions{
+					RetryOptions: workflow.RetryOptions{
+						MaxAttempts:        2,
+						FirstRetryInterval: time.Millisecond,
+					},
+					HeartbeatTimeout: 100 * time.Millisecond,
+				}, a).Get(ctx)
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{a})
+
+			r, err := runWorkflowWithResult[int](t, ctx, c, wf)
+			require.NoError(t, err)
+			require.Equal(t, 3, r)
+		},
+	},
+	{
+		name: "Activity/HeartbeatTimeoutFailsActivity",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			a := func(ctx context.Context) (int, error) {
+				<-ctx.Done()
+				return 0, ctx.Err()
+			}
+
+			wf := func(ctx workflow.Context) (int, error) {
+				return workflow.ExecuteActivity[int](ctx, workflow.ActivityOptions{
+					RetryOptions: workflow.RetryOptions{
+						MaxAttempts: 1,
+					},
+					HeartbeatTimeout: 50 * time.Millisecond,
+				}, a).Get(ctx)
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{a})
+
+			_, err := runWorkflowWithResult[int](t, ctx, c, wf)
+			require.Error(t, err)
+			require.ErrorIs(t, err, activity.ErrHeartbeatTimeout)
+			require.ErrorIs(t, err, workflow.ErrActivityTimeout)
+		},
+	},
+	{
+		name: "Activity/StartToCloseTimeoutRetries",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			a := func(ctx context.Context) (int, error) {
+				if _, ok := ctx.Deadline(); !ok {
+					return 0, errors.New("expected deadline on activity context")
+				}
+
+				if activity.Attempt(ctx) == 0 {
+					<-ctx.Done()
+					return 0, ctx.Err()
+				}
+
+				return 42, nil
+			}
+
+			wf := func(ctx workflow.Context) (int, error) {
+				return workflow.ExecuteActivity[int](ctx, workflow.ActivityOptions{
+					RetryOptions: workflow.RetryOptions{
+						MaxAttempts:        2,
+						FirstRetryInterval: time.Millisecond,
+					},
+					StartToCloseTimeout: 100 * time.Millisecond,
+				}, a).Get(ctx)
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{a})
+
+			r, err := runWorkflowWithResult[int](t, ctx, c, wf)
+			require.NoError(t, err)
+			require.Equal(t, 42, r)
+		},
+	},
+	{
+		name: "Activity/StartToCloseTimeout",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			a := func(ctx context.Context) (int, error) {
+				time.Sleep(200 * time.Millisecond)
+				return 42, nil
+			}
+
+			wf := func(ctx workflow.Context) (bool, error) {
+				_, err := workflow.ExecuteActivity[int](ctx, workflow.ActivityOptions{
+					RetryOptions: workflow.RetryOptions{
+						MaxAttempts: 1,
+					},
+					StartToCloseTimeout: 50 * time.Millisecond,
+				}, a).Get(ctx)
+
+				var te *workflow.ActivityTimeoutError
+				if errors.As(err, &te) {
+					return errors.Is(err, workflow.ErrActivityTimeout) && te.TimeoutType == workflow.TimeoutTypeStartToClose, nil
+				}
+
+				return false, err
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{a})
+
+			r, err := runWorkflowWithResult[bool](t, ctx, c, wf)
+			require.NoError(t, err)
+			require.True(t, r)
+		},
+	},
+	{
+		name: "Activity/ScheduleToCloseTimeout",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			a := func(ctx context.Context) (int, error) {
+				return 0, errors.New("always fails")
+			}
+
+			wf := func(ctx workflow.Context) (bool, error) {
+				_, err := workflow.ExecuteActivity[int](ctx, workflow.ActivityOptions{
+					RetryOptions: workflow.RetryOptions{
+						MaxAttempts:        100,
+						FirstRetryInterval: 100 * time.Millisecond,
+					},
+					ScheduleToCloseTimeout: 500 * time.Millisecond,
+				}, a).Get(ctx)
+
+				var te *workflow.ActivityTimeoutError
+				if errors.As(err, &te) {
+					return te.TimeoutType == workflow.TimeoutTypeScheduleToClose, nil
+				}
+
+				return false, err
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{a})
+
+			r, err := runWorkflowWithResult[bool](t, ctx, c, wf)
+			require.NoError(t, err)
+			require.True(t, r)
+		},
+	},
+	{
+		name: "Activity/ScheduleToStartTimeoutFiresWhileQueued",
+		customWorkerOptions: func(w *worker.Options) {
+			w.MaxParallelActivityTasks = 1
+		},
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			blocker := func(ctx context.Context) error {
+				time.Sleep(2 * time.Second)
+				return nil
+			}
+
+			a := func(ctx context.Context) (int, error) {
+				return 42, nil
+			}
+
+			wf := func(ctx workflow.Context) (time.Duration, error) {
+				bf := workflow.ExecuteActivity[any](ctx, workflow.DefaultActivityOptions, blocker)
+
+				// Give the blocking activity a chance to occupy the only activity slot
+				if err := workflow.Sleep(ctx, 100*time.Millisecond); err != nil {
+					return 0, err
+				}
+
+				start := workflow.Now(ctx)
+
+				_, err := workflow.ExecuteActivity[int](ctx, workflow.ActivityOptions{
+					RetryOptions: workflow.RetryOptions{
+						MaxAttempts: 1,
+					},
+					ScheduleToStartTimeout: 100 * time.Millisecond,
+				}, a).Get(ctx)
+
+				var te *workflow.ActivityTimeoutError
+				if !errors.As(err, &te) || te.TimeoutType != workflow.TimeoutTypeScheduleToStart {
+					return 0, fmt.Errorf("expected schedule to start timeout, got: %w", err)
+				}
+
+				elapsed := workflow.Now(ctx).Sub(start)
+
+				if _, err := bf.Get(ctx); err != nil {
+					return 0, err
+				}
+
+				return elapsed, nil
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{a, blocker})
+
+			r, err := runWorkflowWithResult[time.Duration](t, ctx, c, wf)
+			require.NoError(t, err)
+			// The timeout is reported while the blocking activity is still running
+			require.Less(t, r, time.Second)
+		},
+	},
+	{
+		name: "Activity/ScheduleToStartTimeout",
+		customWorkerOptions: func(w *worker.Options) {
+			w.MaxParallelActivityTasks = 1
+		},
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			blocker := func(ctx context.Context) error {
+				time.Sleep(500 * time.Millisecond)
+				return nil
+			}
+
+			a := func(ctx context.Context) (int, error) {
+				return 42, nil
+			}
+
+			wf := func(ctx workflow.Context) (bool, error) {
+				bf := workflow.ExecuteActivity[any](ctx, workflow.DefaultActivityOptions, blocker)
+
+				// Give the blocking activity a chance to occupy the only activity slot
+				if err := workflow.Sleep(ctx, 100*time.Millisecond); err != nil {
+					return false, err
+				}
+
+				_, err := workflow.ExecuteActivity[int](ctx, workflow.ActivityOptions{
+					RetryOptions: workflow.RetryOptions{
+						MaxAttempts: 3,
+					},
+					ScheduleToStartTimeout: 100 * time.Millisecond,
+				}, a).Get(ctx)
+
+				if _, err := bf.Get(ctx); err != nil {
+					return false, err
+				}
+
+				var te *workflow.ActivityTimeoutError
+				if errors.As(err, &te) {
+					return te.TimeoutType == workflow.TimeoutTypeScheduleToStart, nil
+				}
+
+				return false, err
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{a, blocker})
+
+			r, err := runWorkflowWithResult[bool](t, ctx, c, wf)
+			require.NoError(t, err)
+			require.True(t, r)
+		},
+	},
+	{
+		name: "Activity/NonRetryableErrorTypes",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			var attempts int32
+			a := func(ctx context.Context) error {
+				atomic.AddInt32(&attempts, 1)
+				return fmt.Errorf("calling service: %w", &CustomError{msg: "custom error"})
+			}
+
+			wf := func(ctx workflow.Context) error {
+				_, err := workflow.ExecuteActivity[any](ctx, workflow.ActivityOptions{
+					RetryOptions: workflow.RetryOptions{
+						MaxAttempts:            3,
+						NonRetryableErrorTypes: []string{"CustomError"},
+					},
+				}, a).Get(ctx)
+
+				return err
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{a})
+
+			_, err := runWorkflowWithResult[any](t, ctx, c, wf)
+			require.ErrorContains(t, err, "custom error")
+
+			// Wrapped errors are matched as well
+			require.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+		},
+	},
+	{
+		name: "Activity/RetryBackoff",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			a := func(ctx context.Context) (int, error) {
+				if activity.Attempt(ctx) < 2 {
+					return 0, &CustomError{msg: "custom error"}
+				}
+
+				return activity.Attempt(ctx), nil
+			}
+
+			wf := func(ctx workflow.Context) ([]string, error) {
+				var backoffs []string
+
+				r, err := workflow.ExecuteActivity[int](ctx, workflow.ActivityOptions{
+					RetryOptions: workflow.RetryOptions{
+						MaxAttempts: 3,
+						Backoff: func(attempt int, err error) time.Duration {
+							backoffs = append(backoffs, fmt.Sprintf("%d: %s", attempt, err))
+							return time.Millisecond
+						},
+					},
+				}, a).Get(ctx)
+				if err != nil {
+					return nil, err
+				}
+
+				return append(backoffs, fmt.Sprint(r)), nil
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{a})
+
+			r, err := runWorkflowWithResult[[]string](t, ctx, c, wf)
+			require.NoError(t, err)
+			require.Equal(t, []string{"1: custom error", "2: custom error", "2"}, r)
+		},
 	},
 }
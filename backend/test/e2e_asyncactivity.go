@@ -0,0 +1,168 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/activity"
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/client"
+	"github.com/cschleiden/go-workflows/worker"
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/stretchr/testify/require"
+)
+
+var e2eAsyncActivityTests = []backendTest{
+	{
+		name: "AsyncActivity/Complete",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			tokens := make(chan string, 1)
+
+			var calls int32
+			act := func(ctx context.Context, name string) (string, error) {
+				atomic.AddInt32(&calls, 1)
+
+				token, err := activity.TaskToken(ctx)
+				if err != nil {
+					return "", err
+				}
+
+				tokens <- token
+
+				return "", activity.ErrResultPending
+			}
+
+			wf := func(ctx workflow.Context) (string, error) {
+				return workflow.ExecuteActivity[string](ctx, workflow.DefaultActivityOptions, act, "test").Get(ctx)
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{act})
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			var token string
+			select {
+			case token = <-tokens:
+			case <-time.After(time.Second * 10):
+				require.FailNow(t, "activity did not request a task token")
+			}
+
+			require.NoError(t, c.CompleteActivity(ctx, token, "hello async", nil))
+
+			r, err := client.GetWorkflowResult[string](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+			require.Equal(t, "hello async", r)
+			require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+			// Activities can only be completed once
+			require.Error(t, c.CompleteActivity(ctx, token, "again", nil))
+		},
+	},
+	{
+		name: "AsyncActivity/CompleteTwiceWhileWaiting",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			tokens := make(chan string, 1)
+
+			act := func(ctx context.Context) (string, error) {
+				token, err := activity.TaskToken(ctx)
+				if err != nil {
+					return "", err
+				}
+
+				tokens <- token
+
+				return "", activity.ErrResultPending
+			}
+
+			wf := func(ctx workflow.Context) (string, error) {
+				return workflow.ExecuteActivity[string](ctx, workflow.DefaultActivityOptions, act).Get(ctx)
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{act})
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			var token string
+			select {
+			case token = <-tokens:
+			case <-time.After(time.Second * 10):
+				require.FailNow(t, "activity did not request a task token")
+			}
+
+			// The second completion is rejected before the workflow has processed the first one
+			require.NoError(t, c.CompleteActivity(ctx, token, "first", nil))
+			require.Error(t, c.CompleteActivity(ctx, token, "second", nil))
+
+			r, err := client.GetWorkflowResult[string](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+			require.Equal(t, "first", r)
+
+			h, err := b.GetWorkflowInstanceHistory(ctx, instance, nil)
+			require.NoError(t, err)
+
+			completed := 0
+			for _, event := range h {
+				if event.Type == history.EventType_ActivityCompleted {
+					completed++
+				}
+			}
+			require.Equal(t, 1, completed)
+		},
+	},
+	{
+		name: "AsyncActivity/Error",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			tokens := make(chan string, 1)
+
+			act := func(ctx context.Context) (string, error) {
+				token, err := activity.TaskToken(ctx)
+				if err != nil {
+					return "", err
+				}
+
+				tokens <- token
+
+				return "", activity.ErrResultPending
+			}
+
+			wf := func(ctx workflow.Context) (string, error) {
+				return workflow.ExecuteActivity[string](ctx, workflow.DefaultActivityOptions, act).Get(ctx)
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{act})
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			var token string
+			select {
+			case token = <-tokens:
+			case <-time.After(time.Second * 10):
+				require.FailNow(t, "activity did not request a task token")
+			}
+
+			require.NoError(t, c.CompleteActivity(ctx, token, nil, workflow.NewPermanentError(errors.New("rejected"))))
+
+			_, err := client.GetWorkflowResult[string](ctx, c, instance, time.Second*10)
+			require.ErrorContains(t, err, "rejected")
+		},
+	},
+	{
+		name: "AsyncActivity/LocalActivity",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			act := func(ctx context.Context) (string, error) {
+				_, err := activity.TaskToken(ctx)
+				return "", err
+			}
+
+			wf := func(ctx workflow.Context) (string, error) {
+				return workflow.ExecuteLocalActivity[string](ctx, workflow.DefaultLocalActivityOptions, act).Get(ctx)
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{act})
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			_, err := client.GetWorkflowResult[string](ctx, c, instance, time.Second*10)
+			require.ErrorContains(t, err, "activity cannot be completed asynchronously")
+		},
+	},
+}
@@ -0,0 +1,131 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/backend/converter"
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/cschleiden/go-workflows/client"
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
+	"github.com/cschleiden/go-workflows/worker"
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/stretchr/testify/require"
+)
+
+var e2eCodecTests = []backendTest{
+	{
+		name:    "Codec/EncodedPayloads",
+		options: []backend.BackendOption{backend.WithConverter(newTestCodecConverter())},
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			act := func(ctx context.Context, email string) (string, error) {
+				return "sent to " + email, nil
+			}
+
+			wf := func(ctx workflow.Context, email string) (string, error) {
+				name, _ := workflow.NewSignalChannel[string](ctx, "name").Receive(ctx)
+
+				suffix, err := workflow.SideEffect(ctx, func(ctx workflow.Context) string {
+					return " for " + name
+				}).Get(ctx)
+				if err != nil {
+					return "", err
+				}
+
+				r, err := workflow.ExecuteActivity[string](ctx, workflow.DefaultActivityOptions, act, email).Get(ctx)
+				if err != nil {
+					return "", err
+				}
+
+				return r + suffix, nil
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{act})
+
+			instance := runWorkflow(t, ctx, c, wf, "jane@example.com")
+
+			require.NoError(t, c.SignalWorkflow(ctx, instance.InstanceID, "name", "Jane Doe"))
+
+			r, err := client.GetWorkflowResult[string](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+			require.Equal(t, "sent to jane@example.com for Jane Doe", r)
+
+			requireNotInHistory(t, ctx, b, instance, "jane@example.com", "Jane Doe")
+		},
+	},
+	{
+		name:    "Codec/EncodedErrors",
+		options: []backend.BackendOption{backend.WithConverter(newTestCodecConverter())},
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			act := func(ctx context.Context) error {
+				return workflow.NewPermanentError(errors.New("card 4242 declined"))
+			}
+
+			wf := func(ctx workflow.Context) error {
+				_, err := workflow.ExecuteActivity[any](ctx, workflow.DefaultActivityOptions, act).Get(ctx)
+				return err
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{act})
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			_, err := client.GetWorkflowResult[any](ctx, c, instance, time.Second*10)
+			require.ErrorContains(t, err, "card 4242 declined")
+
+			requireNotInHistory(t, ctx, b, instance, "4242")
+		},
+	},
+}
+
+func newTestCodecConverter() converter.Converter {
+	compression, err := converter.NewZstdCodec()
+	if err != nil {
+		panic(err)
+	}
+
+	encryption, err := converter.NewAESGCMCodec("key-1", map[string][]byte{
+		"key-1": bytes.Repeat([]byte{1}, 32),
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return converter.NewCodecConverter(converter.DefaultConverter, compression, encryption)
+}
+
+// requireNotInHistory ensures none of the given values is stored in plaintext in the payloads or errors
+// recorded in the history of the instance
+func requireNotInHistory(t *testing.T, ctx context.Context, b TestBackend, instance *workflow.Instance, values ...string) {
+	events, err := b.GetWorkflowInstanceHistory(ctx, instance, nil)
+	require.NoError(t, err)
+
+	for _, event := range events {
+		var recorded []string
+
+		a := reflect.ValueOf(event.Attributes).Elem()
+		for i := 0; i < a.NumField(); i++ {
+			switch f := a.Field(i).Interface().(type) {
+			case payload.Payload:
+				recorded = append(recorded, string(f))
+			case []payload.Payload:
+				for _, p := range f {
+					recorded = append(recorded, string(p))
+				}
+			case *workflowerrors.Error:
+				if f != nil {
+					recorded = append(recorded, f.Message)
+				}
+			}
+		}
+
+		for _, r := range recorded {
+			for _, v := range values {
+				require.NotContains(t, r, v, "event %v", event.Type)
+			}
+		}
+	}
+}
@@ -2,10 +2,12 @@ package test
 
 import (
 	"context"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/backend/history"
 	"github.com/cschleiden/go-workflows/client"
 	"github.com/cschleiden/go-workflows/core"
 	"github.com/cschleiden/go-workflows/worker"
@@ -117,4 +119,110 @@ var e2eContinueAsNewTests = []backendTest{
 			}
 		},
 	},
+	{
+		name: "ContinueAsNew/DifferentWorkflow",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wfV2 := func(ctx workflow.Context, run int) (int, error) {
+				return run * 10, nil
+			}
+			wf := func(ctx workflow.Context, run int) (int, error) {
+				return run, workflow.ContinueAsNewWithOptions(ctx, workflow.ContinueAsNewOptions{
+					Workflow: wfV2,
+				}, run+1)
+			}
+			register(t, ctx, w, []interface{}{wf, wfV2}, nil)
+
+			instance := runWorkflow(t, ctx, c, wf, 1)
+
+			continuedInstance := continuedExecution(t, ctx, c, b, instance)
+
+			r, err := client.GetWorkflowResult[int](ctx, c, continuedInstance, time.Second*10)
+			require.NoError(t, err)
+			require.Equal(t, 20, r)
+		},
+	},
+	{
+		name: "ContinueAsNew/DifferentQueue",
+		customWorkerOptions: func(options *worker.Options) {
+			options.WorkflowQueues = []core.Queue{workflow.QueueDefault, workflow.Queue("custom")}
+		},
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context, run int) (int, error) {
+				if run > 0 {
+					return run, nil
+				}
+
+				return run, workflow.ContinueAsNewWithOptions(ctx, workflow.ContinueAsNewOptions{
+					Queue: workflow.Queue("custom"),
+				}, run+1)
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			instance := runWorkflow(t, ctx, c, wf, 0)
+
+			continuedInstance := continuedExecution(t, ctx, c, b, instance)
+
+			r, err := client.GetWorkflowResult[int](ctx, c, continuedInstance, time.Second*10)
+			require.NoError(t, err)
+			require.Equal(t, 1, r)
+
+			instances, err := c.ListWorkflowInstances(ctx, &client.WorkflowInstanceQuery{Queue: workflow.Queue("custom")})
+			require.NoError(t, err)
+			require.True(t, slices.ContainsFunc(instances.Instances, func(i *backend.WorkflowInstanceInfo) bool {
+				return *i.Instance == *continuedInstance
+			}))
+		},
+	},
+	{
+		name: "ContinueAsNew/PendingSignalsAreCarriedOver",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			signal := func(ctx context.Context, instanceID string) error {
+				return c.SignalWorkflow(ctx, instanceID, "signal", "hello")
+			}
+			wf := func(ctx workflow.Context, run int) (string, error) {
+				if run > 0 {
+					s, _ := workflow.NewSignalChannel[string](ctx, "signal").Receive(ctx)
+					return s, nil
+				}
+
+				// The signal arrives while the current workflow task is executed
+				if _, err := workflow.ExecuteLocalActivity[any](ctx, workflow.DefaultLocalActivityOptions, signal,
+					workflow.WorkflowInstance(ctx).InstanceID).Get(ctx); err != nil {
+					return "", err
+				}
+
+				return "", workflow.ContinueAsNew(ctx, run+1)
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{signal})
+
+			instance := runWorkflow(t, ctx, c, wf, 0)
+
+			continuedInstance := continuedExecution(t, ctx, c, b, instance)
+
+			r, err := client.GetWorkflowResult[string](ctx, c, continuedInstance, time.Second*10)
+			require.NoError(t, err)
+			require.Equal(t, "hello", r)
+
+			historyContains(ctx, t, b, continuedInstance, history.EventType_SignalReceived)
+		},
+	},
+}
+
+// continuedExecution waits for the given execution to continue as new and returns the new execution
+func continuedExecution(t *testing.T, ctx context.Context, c *client.Client, b TestBackend, instance *workflow.Instance) *workflow.Instance {
+	_, err := client.GetWorkflowResult[any](ctx, c, instance, time.Second*10)
+	require.NoError(t, err)
+
+	var continuedInstance *workflow.Instance
+	historyIterate(ctx, t, b, instance, func(event *history.Event) bool {
+		if a, ok := event.Attributes.(*history.ExecutionContinuedAsNewAttributes); ok {
+			continuedInstance = core.NewWorkflowInstance(instance.InstanceID, a.ContinuedExecutionID)
+			return false
+		}
+
+		return true
+	})
+	require.NotNil(t, continuedInstance)
+
+	return continuedInstance
 }
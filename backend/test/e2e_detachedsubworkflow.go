@@ -0,0 +1,191 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/client"
+	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/worker"
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var e2eDetachedSubWorkflowTests = []backendTest{
+	{
+		name: "DetachedSubWorkflow/Start",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			swf := func(ctx workflow.Context) (string, error) {
+				name, _ := workflow.NewSignalChannel[string](ctx, "name").Receive(ctx)
+				return "hello " + name, nil
+			}
+			wf := func(ctx workflow.Context) (*workflow.Instance, error) {
+				return workflow.StartSubWorkflow(ctx, workflow.DefaultSubWorkflowOptions, swf).Get(ctx)
+			}
+			register(t, ctx, w, []interface{}{wf, swf}, nil)
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			// The parent finishes without waiting for the sub-workflow, which is abandoned by default
+			subInstance, err := client.GetWorkflowResult[*workflow.Instance](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+			require.Equal(t, instance.InstanceID, subInstance.Parent.InstanceID)
+
+			require.NoError(t, c.SignalWorkflow(ctx, subInstance.InstanceID, "name", "world"))
+
+			r, err := client.GetWorkflowResult[string](ctx, c, subInstance, time.Second*10)
+			require.NoError(t, err)
+			require.Equal(t, "hello world", r)
+
+			historyContains(ctx, t, b, instance,
+				history.EventType_SubWorkflowScheduled,
+				history.EventType_SubWorkflowStarted,
+				history.EventType_WorkflowExecutionFinished,
+			)
+		},
+	},
+	{
+		name: "DetachedSubWorkflow/ResultNotDelivered",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			swf := func(ctx workflow.Context) (int, error) {
+				return 42, nil
+			}
+			wf := func(ctx workflow.Context) error {
+				if _, err := workflow.StartSubWorkflow(ctx, workflow.DefaultSubWorkflowOptions, swf).Get(ctx); err != nil {
+					return err
+				}
+
+				workflow.NewSignalChannel[any](ctx, "done").Receive(ctx)
+
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf, swf}, nil)
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			var subInstance *workflow.Instance
+			require.Eventually(t, func() bool {
+				r, err := c.ListWorkflowInstances(ctx, &client.WorkflowInstanceQuery{ParentInstanceID: instance.InstanceID})
+				require.NoError(t, err)
+				if len(r.Instances) == 0 || r.Instances[0].State != core.WorkflowInstanceStateFinished {
+					return false
+				}
+
+				subInstance = r.Instances[0].Instance
+				return true
+			}, time.Second*10, time.Millisecond*50)
+
+			r, err := client.GetWorkflowResult[int](ctx, c, subInstance, time.Second*10)
+			require.NoError(t, err)
+			require.Equal(t, 42, r)
+
+			require.NoError(t, c.SignalWorkflow(ctx, instance.InstanceID, "done", nil))
+
+			_, err = client.GetWorkflowResult[any](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+
+			historyIterate(ctx, t, b, instance, func(event *history.Event) bool {
+				require.NotEqual(t, history.EventType_SubWorkflowCompleted, event.Type)
+				return true
+			})
+		},
+	},
+	{
+		name: "DetachedSubWorkflow/FireAndForget",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			swf := func(ctx workflow.Context) (int, error) {
+				if err := workflow.Sleep(ctx, 100*time.Millisecond); err != nil {
+					return 0, err
+				}
+
+				return 42, nil
+			}
+			wf := func(ctx workflow.Context) error {
+				// Don't wait for the sub-workflow to be started
+				workflow.StartSubWorkflow(ctx, workflow.DefaultSubWorkflowOptions, swf)
+
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf, swf}, nil)
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			_, err := client.GetWorkflowResult[any](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+
+			r, err := c.ListWorkflowInstances(ctx, &client.WorkflowInstanceQuery{ParentInstanceID: instance.InstanceID})
+			require.NoError(t, err)
+			require.Len(t, r.Instances, 1)
+
+			subInstance := r.Instances[0].Instance
+
+			sr, err := client.GetWorkflowResult[int](ctx, c, subInstance, time.Second*10)
+			require.NoError(t, err)
+			require.Equal(t, 42, sr)
+		},
+	},
+	{
+		name: "DetachedSubWorkflow/DuplicateInstanceID",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			swf := func(ctx workflow.Context) error {
+				workflow.NewSignalChannel[any](ctx, "done").Receive(ctx)
+				return nil
+			}
+			wf := func(ctx workflow.Context, instanceID string) error {
+				options := workflow.SubWorkflowOptions{
+					InstanceID: instanceID,
+				}
+
+				if _, err := workflow.StartSubWorkflow(ctx, options, swf).Get(ctx); err != nil {
+					return err
+				}
+
+				_, err := workflow.StartSubWorkflow(ctx, options, swf).Get(ctx)
+				return err
+			}
+			register(t, ctx, w, []interface{}{wf, swf}, nil)
+
+			subInstanceID := uuid.NewString()
+			_, err := runWorkflowWithResult[any](t, ctx, c, wf, subInstanceID)
+			require.ErrorContains(t, err, backend.ErrInstanceAlreadyExists.Error())
+
+			require.NoError(t, c.SignalWorkflow(ctx, subInstanceID, "done", nil))
+		},
+	},
+	{
+		name: "DetachedSubWorkflow/DuplicateInstanceIDSameTask",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			swf := func(ctx workflow.Context) error {
+				workflow.NewSignalChannel[any](ctx, "done").Receive(ctx)
+				return nil
+			}
+			wf := func(ctx workflow.Context, instanceID string) error {
+				options := workflow.SubWorkflowOptions{
+					InstanceID: instanceID,
+				}
+
+				// Both sub-workflows are scheduled in the same workflow task
+				f1 := workflow.StartSubWorkflow(ctx, options, swf)
+				f2 := workflow.StartSubWorkflow(ctx, options, swf)
+
+				if _, err := f1.Get(ctx); err != nil {
+					return err
+				}
+
+				_, err := f2.Get(ctx)
+				return err
+			}
+			register(t, ctx, w, []interface{}{wf, swf}, nil)
+
+			subInstanceID := uuid.NewString()
+			_, err := runWorkflowWithResult[any](t, ctx, c, wf, subInstanceID)
+			require.ErrorContains(t, err, backend.ErrInstanceAlreadyExists.Error())
+
+			require.NoError(t, c.SignalWorkflow(ctx, subInstanceID, "done", nil))
+		},
+	},
+}
@@ -2,7 +2,11 @@ package test
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
 	"testing"
+	"time"
 
 	"github.com/cschleiden/go-workflows/client"
 	"github.com/cschleiden/go-workflows/diag"
@@ -84,4 +88,41 @@ var e2eDiagTests = []backendTest{
 			require.Equal(t, "custom-queue", wfState2.Queue)
 		},
 	},
+	{
+		name: "Diag/QueryWorkflow",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			diagBackend, ok := b.(diag.Backend)
+			if !ok {
+				t.Skip("Backend does not implement diag.Backend")
+			}
+
+			wf := func(ctx workflow.Context) error {
+				if err := workflow.SetQueryHandler(ctx, "add", func(a, b int) (int, error) {
+					return a + b, nil
+				}); err != nil {
+					return err
+				}
+
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			instance := runWorkflow(t, ctx, c, wf)
+			_, err := client.GetWorkflowResult[any](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+
+			mux := diag.NewServeMux(diagBackend, diag.WithClient(client.New(b, client.WithRegistry(w.Registry()))))
+
+			url := "/api/" + instance.InstanceID + "/" + instance.ExecutionID + "/query/"
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url+"add?args="+neturl.QueryEscape("[1,2]"), nil))
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.JSONEq(t, "3", rec.Body.String())
+
+			rec = httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url+"unknown", nil))
+			require.Equal(t, http.StatusNotFound, rec.Code)
+		},
+	},
 }
@@ -0,0 +1,202 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/client"
+	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/worker"
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var e2eIDReuseTests = []backendTest{
+	{
+		name: "IDReuse/AllowDuplicate",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context, msg string) (string, error) {
+				return msg, nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			id := uuid.NewString()
+			first, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{InstanceID: id}, wf, "first")
+			require.NoError(t, err)
+
+			_, err = client.GetWorkflowResult[string](ctx, c, first, time.Second*5)
+			require.NoError(t, err)
+
+			second, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{InstanceID: id}, wf, "second")
+			require.NoError(t, err)
+			require.NotEqual(t, first.ExecutionID, second.ExecutionID)
+
+			r, err := client.GetWorkflowResult[string](ctx, c, second, time.Second*5)
+			require.NoError(t, err)
+			require.Equal(t, "second", r)
+
+			latest, err := b.GetLatestWorkflowInstance(ctx, id)
+			require.NoError(t, err)
+			require.Equal(t, second.ExecutionID, latest.ExecutionID)
+		},
+	},
+	{
+		name: "IDReuse/RejectDuplicate",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			id := uuid.NewString()
+			instance, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+				InstanceID:    id,
+				IDReusePolicy: client.IDReusePolicyRejectDuplicate,
+			}, wf)
+			require.NoError(t, err)
+
+			_, err = client.GetWorkflowResult[any](ctx, c, instance, time.Second*5)
+			require.NoError(t, err)
+
+			_, err = c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+				InstanceID:    id,
+				IDReusePolicy: client.IDReusePolicyRejectDuplicate,
+			}, wf)
+			require.ErrorIs(t, err, backend.ErrInstanceAlreadyExists)
+		},
+	},
+	{
+		name: "IDReuse/AllowDuplicateFailedOnly",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context, fail bool) error {
+				if fail {
+					return errors.New("failed")
+				}
+
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			options := client.WorkflowInstanceOptions{
+				InstanceID:    uuid.NewString(),
+				IDReusePolicy: client.IDReusePolicyAllowDuplicateFailedOnly,
+			}
+
+			instance, err := c.CreateWorkflowInstance(ctx, options, wf, true)
+			require.NoError(t, err)
+
+			_, err = client.GetWorkflowResult[any](ctx, c, instance, time.Second*5)
+			require.Error(t, err)
+
+			// Previous execution failed, so a new one can be started
+			instance, err = c.CreateWorkflowInstance(ctx, options, wf, false)
+			require.NoError(t, err)
+
+			_, err = client.GetWorkflowResult[any](ctx, c, instance, time.Second*5)
+			require.NoError(t, err)
+
+			// Previous execution succeeded
+			_, err = c.CreateWorkflowInstance(ctx, options, wf, false)
+			require.ErrorIs(t, err, backend.ErrInstanceAlreadyExists)
+		},
+	},
+	{
+		name: "IDReuse/CancelIfRunning",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context, msg string) (string, error) {
+				if msg == "first" {
+					// Blocks until canceled
+					return "", workflow.Sleep(ctx, time.Hour)
+				}
+
+				return msg, nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			id := uuid.NewString()
+			first, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{InstanceID: id}, wf, "first")
+			require.NoError(t, err)
+
+			_, err = c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{InstanceID: id}, wf, "second")
+			require.ErrorIs(t, err, backend.ErrInstanceAlreadyExists)
+
+			second, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+				InstanceID:    id,
+				IDReusePolicy: client.IDReusePolicyCancelIfRunning,
+			}, wf, "second")
+			require.NoError(t, err)
+
+			_, err = client.GetWorkflowResult[string](ctx, c, first, time.Second*5)
+			require.ErrorContains(t, err, workflow.Canceled.Error())
+
+			r, err := client.GetWorkflowResult[string](ctx, c, second, time.Second*5)
+			require.NoError(t, err)
+			require.Equal(t, "second", r)
+		},
+	},
+	{
+		name: "IDReuse/CancelIfRunning_NotFinished",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context, msg string) (string, error) {
+				if msg == "first" {
+					// Ignores the cancellation
+					return "", workflow.Sleep(workflow.NewDisconnectedContext(ctx), time.Hour)
+				}
+
+				return msg, nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			id := uuid.NewString()
+			first, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{InstanceID: id}, wf, "first")
+			require.NoError(t, err)
+
+			cctx, cancel := context.WithTimeout(ctx, time.Second)
+			defer cancel()
+
+			_, err = c.CreateWorkflowInstance(cctx, client.WorkflowInstanceOptions{
+				InstanceID:    id,
+				IDReusePolicy: client.IDReusePolicyCancelIfRunning,
+			}, wf, "second")
+			require.ErrorIs(t, err, client.ErrPreviousExecutionRunning)
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+
+			latest, err := b.GetLatestWorkflowInstance(ctx, id)
+			require.NoError(t, err)
+			require.Equal(t, first.ExecutionID, latest.ExecutionID)
+		},
+	},
+	{
+		name: "IDReuse/GetStateOfLatestExecution",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				workflow.NewSignalChannel[any](ctx, "continue").Receive(ctx)
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			id := uuid.NewString()
+			_, err := c.GetWorkflowInstanceState(ctx, &workflow.Instance{InstanceID: id})
+			require.ErrorIs(t, err, backend.ErrInstanceNotFound)
+
+			instance, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{InstanceID: id}, wf)
+			require.NoError(t, err)
+
+			state, err := c.GetWorkflowInstanceState(ctx, &workflow.Instance{InstanceID: id})
+			require.NoError(t, err)
+			require.Equal(t, core.WorkflowInstanceStateActive, state)
+
+			require.NoError(t, c.SignalWorkflow(ctx, id, "continue", nil))
+			_, err = client.GetWorkflowResult[any](ctx, c, instance, time.Second*5)
+			require.NoError(t, err)
+
+			state, err = c.GetWorkflowInstanceState(ctx, &workflow.Instance{InstanceID: id})
+			require.NoError(t, err)
+			require.Equal(t, core.WorkflowInstanceStateFinished, state)
+		},
+	},
+}
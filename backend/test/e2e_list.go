@@ -0,0 +1,217 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/client"
+	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/internal/fn"
+	"github.com/cschleiden/go-workflows/worker"
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/stretchr/testify/require"
+)
+
+var e2eListTests = []backendTest{
+	{
+		name: "List/Filters",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wfA := func(ctx workflow.Context) error {
+				return nil
+			}
+			wfB := func(ctx workflow.Context, fail bool) error {
+				if fail {
+					return errors.New("failed")
+				}
+
+				workflow.NewSignalChannel[any](ctx, "continue").Receive(ctx)
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wfA, wfB}, nil)
+
+			a1 := runWorkflow(t, ctx, c, wfA)
+			_, err := client.GetWorkflowResult[any](ctx, c, a1, time.Second*5)
+			require.NoError(t, err)
+
+			a2 := runWorkflow(t, ctx, c, wfA)
+			_, err = client.GetWorkflowResult[any](ctx, c, a2, time.Second*5)
+			require.NoError(t, err)
+
+			b1 := runWorkflow(t, ctx, c, wfB, true)
+			_, err = client.GetWorkflowResult[any](ctx, c, b1, time.Second*5)
+			require.Error(t, err)
+
+			b2 := runWorkflow(t, ctx, c, wfB, false)
+
+			list := func(query *client.WorkflowInstanceQuery) []string {
+				r, err := c.ListWorkflowInstances(ctx, query)
+				require.NoError(t, err)
+				if r.TotalCount != backend.TotalCountUnknown {
+					require.Equal(t, int64(len(r.Instances)), r.TotalCount)
+				}
+				require.Empty(t, r.NextCursor)
+
+				ids := []string{}
+				for _, i := range r.Instances {
+					ids = append(ids, i.Instance.InstanceID)
+				}
+
+				return ids
+			}
+
+			active := core.WorkflowInstanceStateActive
+			finished := core.WorkflowInstanceStateFinished
+			failed, succeeded := true, false
+
+			// Newest first
+			require.Equal(t, []string{b2.InstanceID, b1.InstanceID, a2.InstanceID, a1.InstanceID}, list(nil))
+
+			require.Equal(t, []string{a2.InstanceID, a1.InstanceID}, list(&client.WorkflowInstanceQuery{WorkflowName: fn.Name(wfA)}))
+			require.Equal(t, []string{b2.InstanceID}, list(&client.WorkflowInstanceQuery{State: &active}))
+			require.Equal(t, []string{b1.InstanceID, a2.InstanceID, a1.InstanceID}, list(&client.WorkflowInstanceQuery{State: &finished}))
+			require.Equal(t, []string{b1.InstanceID}, list(&client.WorkflowInstanceQuery{Failed: &failed}))
+			require.Equal(t, []string{a2.InstanceID, a1.InstanceID}, list(&client.WorkflowInstanceQuery{Failed: &succeeded}))
+			require.Equal(t, []string{b1.InstanceID}, list(&client.WorkflowInstanceQuery{WorkflowName: fn.Name(wfB), Failed: &failed}))
+			require.Len(t, list(&client.WorkflowInstanceQuery{Queue: workflow.QueueDefault}), 4)
+			require.Empty(t, list(&client.WorkflowInstanceQuery{Queue: "other"}))
+
+			r, err := c.ListWorkflowInstances(ctx, &client.WorkflowInstanceQuery{WorkflowName: fn.Name(wfB)})
+			require.NoError(t, err)
+			require.Len(t, r.Instances, 2)
+			require.Equal(t, fn.Name(wfB), r.Instances[0].WorkflowName)
+			require.Equal(t, workflow.QueueDefault, r.Instances[0].Queue)
+			require.Equal(t, core.WorkflowInstanceStateActive, r.Instances[0].State)
+			require.Nil(t, r.Instances[0].CompletedAt)
+			require.True(t, r.Instances[1].Failed)
+			require.NotNil(t, r.Instances[1].CompletedAt)
+
+			require.NoError(t, c.SignalWorkflow(ctx, b2.InstanceID, "continue", nil))
+			_, err = client.GetWorkflowResult[any](ctx, c, b2, time.Second*5)
+			require.NoError(t, err)
+		},
+	},
+	{
+		name: "List/TimeRanges",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context, wait bool) error {
+				if wait {
+					workflow.NewSignalChannel[any](ctx, "continue").Receive(ctx)
+				}
+
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			start := time.Now()
+
+			completed := runWorkflow(t, ctx, c, wf, false)
+			_, err := client.GetWorkflowResult[any](ctx, c, completed, time.Second*5)
+			require.NoError(t, err)
+
+			waiting := runWorkflow(t, ctx, c, wf, true)
+
+			count := func(query *client.WorkflowInstanceQuery) int64 {
+				r, err := c.ListWorkflowInstances(ctx, query)
+				require.NoError(t, err)
+				return r.TotalCount
+			}
+
+			require.Equal(t, int64(2), count(&client.WorkflowInstanceQuery{CreatedAfter: start.Add(-time.Minute)}))
+			require.Equal(t, int64(2), count(&client.WorkflowInstanceQuery{CreatedBefore: start.Add(time.Minute)}))
+			require.Equal(t, int64(0), count(&client.WorkflowInstanceQuery{CreatedAfter: start.Add(time.Minute)}))
+			require.Equal(t, int64(0), count(&client.WorkflowInstanceQuery{CreatedBefore: start.Add(-time.Minute)}))
+
+			r, err := c.ListWorkflowInstances(ctx, &client.WorkflowInstanceQuery{
+				CompletedAfter:  start.Add(-time.Minute),
+				CompletedBefore: start.Add(time.Minute),
+			})
+			require.NoError(t, err)
+			require.Len(t, r.Instances, 1)
+			require.Equal(t, completed.InstanceID, r.Instances[0].Instance.InstanceID)
+
+			r, err = c.ListWorkflowInstances(ctx, &client.WorkflowInstanceQuery{CompletedAfter: start.Add(time.Minute)})
+			require.NoError(t, err)
+			require.Empty(t, r.Instances)
+
+			require.NoError(t, c.SignalWorkflow(ctx, waiting.InstanceID, "continue", nil))
+			_, err = client.GetWorkflowResult[any](ctx, c, waiting, time.Second*5)
+			require.NoError(t, err)
+		},
+	},
+	{
+		name: "List/Pagination",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			expected := []string{}
+			for i := 0; i < 5; i++ {
+				instance := runWorkflow(t, ctx, c, wf)
+				expected = append([]string{instance.InstanceID}, expected...)
+			}
+
+			ids := []string{}
+			query := &client.WorkflowInstanceQuery{PageSize: 2}
+			pages := 0
+			for {
+				r, err := c.ListWorkflowInstances(ctx, query)
+				require.NoError(t, err)
+				require.Equal(t, int64(5), r.TotalCount)
+				require.LessOrEqual(t, len(r.Instances), 2)
+				pages++
+
+				for _, i := range r.Instances {
+					ids = append(ids, i.Instance.InstanceID)
+				}
+
+				if r.NextCursor == "" {
+					break
+				}
+
+				query.Cursor = r.NextCursor
+			}
+
+			require.Equal(t, 3, pages)
+			require.Equal(t, expected, ids)
+		},
+	},
+	{
+		name: "List/SubWorkflows",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			swf := func(ctx workflow.Context) error {
+				return nil
+			}
+			wf := func(ctx workflow.Context) error {
+				for i := 0; i < 2; i++ {
+					if _, err := workflow.CreateSubWorkflowInstance[any](ctx, workflow.DefaultSubWorkflowOptions, swf).Get(ctx); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf, swf}, nil)
+
+			instance := runWorkflow(t, ctx, c, wf)
+			_, err := client.GetWorkflowResult[any](ctx, c, instance, time.Second*5)
+			require.NoError(t, err)
+
+			r, err := c.ListWorkflowInstances(ctx, &client.WorkflowInstanceQuery{ParentInstanceID: instance.InstanceID})
+			require.NoError(t, err)
+			require.Equal(t, int64(2), r.TotalCount)
+			for _, i := range r.Instances {
+				require.Equal(t, fn.Name(swf), i.WorkflowName)
+				require.Equal(t, instance.InstanceID, i.Instance.Parent.InstanceID)
+			}
+
+			r, err = c.ListWorkflowInstances(ctx, &client.WorkflowInstanceQuery{WorkflowName: fn.Name(wf)})
+			require.NoError(t, err)
+			require.Equal(t, int64(1), r.TotalCount)
+		},
+	},
+}
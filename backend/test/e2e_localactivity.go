@@ -0,0 +1,146 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/client"
+	"github.com/cschleiden/go-workflows/worker"
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/stretchr/testify/require"
+)
+
+var e2eLocalActivityTests = []backendTest{
+	{
+		name: "LocalActivity/Result",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			act := func(ctx context.Context, a, b int) (int, error) {
+				return a + b, nil
+			}
+
+			wf := func(ctx workflow.Context) (int, error) {
+				return workflow.ExecuteLocalActivity[int](ctx, workflow.DefaultLocalActivityOptions, act, 35, 12).Get(ctx)
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{act})
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			r, err := client.GetWorkflowResult[int](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+			require.Equal(t, 47, r)
+
+			historyContains(ctx, t, b, instance, history.EventType_LocalActivityResult)
+			historyIterate(ctx, t, b, instance, func(event *history.Event) bool {
+				require.NotEqual(t, history.EventType_ActivityScheduled, event.Type)
+				return true
+			})
+		},
+	},
+	{
+		name: "LocalActivity/Retries",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			var calls int32
+			act := func(ctx context.Context) (int32, error) {
+				if n := atomic.AddInt32(&calls, 1); n < 3 {
+					return 0, errors.New("not yet")
+				}
+
+				return calls, nil
+			}
+
+			wf := func(ctx workflow.Context) (int32, error) {
+				return workflow.ExecuteLocalActivity[int32](ctx, workflow.LocalActivityOptions{
+					RetryOptions: workflow.RetryOptions{
+						MaxAttempts:        3,
+						FirstRetryInterval: time.Millisecond,
+						BackoffCoefficient: 2,
+					},
+				}, act).Get(ctx)
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{act})
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			r, err := client.GetWorkflowResult[int32](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+			require.Equal(t, int32(3), r)
+
+			// Only the final result is recorded
+			results := 0
+			historyIterate(ctx, t, b, instance, func(event *history.Event) bool {
+				if event.Type == history.EventType_LocalActivityResult {
+					results++
+					require.Equal(t, 3, event.Attributes.(*history.LocalActivityResultAttributes).Attempts)
+				}
+
+				return true
+			})
+			require.Equal(t, 1, results)
+		},
+	},
+	{
+		name: "LocalActivity/Error",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			var calls int32
+			act := func(ctx context.Context) error {
+				atomic.AddInt32(&calls, 1)
+				return workflow.NewPermanentError(errors.New("config not found"))
+			}
+
+			wf := func(ctx workflow.Context) error {
+				_, err := workflow.ExecuteLocalActivity[any](ctx, workflow.DefaultLocalActivityOptions, act).Get(ctx)
+				return err
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{act})
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			_, err := client.GetWorkflowResult[any](ctx, c, instance, time.Second*10)
+			require.ErrorContains(t, err, "config not found")
+
+			// Permanent errors are not retried
+			require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		},
+	},
+	{
+		name: "LocalActivity/Replay",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			var calls int32
+			act := func(ctx context.Context) (string, error) {
+				atomic.AddInt32(&calls, 1)
+				return "config", nil
+			}
+
+			wf := func(ctx workflow.Context) (string, error) {
+				config, err := workflow.ExecuteLocalActivity[string](ctx, workflow.DefaultLocalActivityOptions, act).Get(ctx)
+				if err != nil {
+					return "", err
+				}
+
+				name, _ := workflow.NewSignalChannel[string](ctx, "name").Receive(ctx)
+
+				return config + " for " + name, nil
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{act})
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			require.Eventually(t, func() bool {
+				return atomic.LoadInt32(&calls) == 1
+			}, time.Second*10, time.Millisecond*10)
+
+			require.NoError(t, c.SignalWorkflow(ctx, instance.InstanceID, "name", "test"))
+
+			r, err := client.GetWorkflowResult[string](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+			require.Equal(t, "config for test", r)
+
+			// The local activity is not executed again when the workflow is replayed
+			require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		},
+	},
+}
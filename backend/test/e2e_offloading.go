@@ -0,0 +1,162 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/backend/converter"
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/cschleiden/go-workflows/client"
+	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/worker"
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/stretchr/testify/require"
+)
+
+var offloadingBlobStore = &memoryBlobStore{blobs: map[string][]byte{}}
+
+var offloadingConverter = converter.NewOffloadingConverter(converter.DefaultConverter, offloadingBlobStore, 64)
+
+var e2eOffloadingTests = []backendTest{
+	{
+		name:    "Offloading/LargePayloads",
+		options: []backend.BackendOption{backend.WithConverter(offloadingConverter)},
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			act := func(ctx context.Context, s string) (string, error) {
+				return strings.ToUpper(s), nil
+			}
+
+			wf := func(ctx workflow.Context, s string) (string, error) {
+				return workflow.ExecuteActivity[string](ctx, workflow.DefaultActivityOptions, act, s).Get(ctx)
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{act})
+
+			blobs := offloadingBlobStore.keys()
+
+			input := strings.Repeat("large payload ", 100)
+			instance := runWorkflow(t, ctx, c, wf, input)
+
+			r, err := client.GetWorkflowResult[string](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+			require.Equal(t, strings.ToUpper(input), r)
+
+			// Inputs, activity results, and the workflow result are stored in the blob store
+			stored := storedPayloads(t, ctx, b, instance)
+			require.Len(t, stored, 4)
+			for _, p := range stored {
+				require.Less(t, len(p), 64)
+				require.True(t, offloadingBlobStore.has(p))
+			}
+
+			require.NoError(t, c.RemoveWorkflowInstance(ctx, instance))
+
+			for _, p := range stored {
+				require.False(t, offloadingBlobStore.has(p))
+			}
+
+			// No payloads are left behind, also when the history is replayed with the cache disabled
+			require.ElementsMatch(t, blobs, offloadingBlobStore.keys())
+		},
+	},
+	{
+		name:    "Offloading/SubWorkflowResult",
+		options: []backend.BackendOption{backend.WithConverter(offloadingConverter)},
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			swf := func(ctx workflow.Context, s string) (string, error) {
+				return strings.Repeat(s, 100), nil
+			}
+
+			wf := func(ctx workflow.Context) (string, error) {
+				return workflow.CreateSubWorkflowInstance[string](ctx, workflow.DefaultSubWorkflowOptions, swf, "sub").Get(ctx)
+			}
+			register(t, ctx, w, []interface{}{wf, swf}, nil)
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			r, err := client.GetWorkflowResult[string](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+			require.Equal(t, strings.Repeat("sub", 100), r)
+
+			children, err := c.ListWorkflowInstances(ctx, &client.WorkflowInstanceQuery{ParentInstanceID: instance.InstanceID})
+			require.NoError(t, err)
+			require.Len(t, children.Instances, 1)
+
+			// Removing the sub-workflow instance does not affect the result recorded for the parent
+			require.NoError(t, c.RemoveWorkflowInstance(ctx, children.Instances[0].Instance))
+
+			r, err = client.GetWorkflowResult[string](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+			require.Equal(t, strings.Repeat("sub", 100), r)
+		},
+	},
+}
+
+func storedPayloads(t *testing.T, ctx context.Context, b TestBackend, instance *core.WorkflowInstance) []payload.Payload {
+	t.Helper()
+
+	h, err := b.GetWorkflowInstanceHistory(ctx, instance, nil)
+	require.NoError(t, err)
+
+	return backend.StoredPayloads(offloadingConverter, h)
+}
+
+type memoryBlobStore struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+var _ converter.BlobStore = (*memoryBlobStore)(nil)
+
+func (s *memoryBlobStore) Put(ctx context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blobs[key] = append([]byte{}, data...)
+	return nil
+}
+
+func (s *memoryBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, converter.ErrBlobNotFound
+	}
+
+	return data, nil
+}
+
+func (s *memoryBlobStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.blobs, key)
+	}
+
+	return nil
+}
+
+// keys returns the keys of all stored blobs
+func (s *memoryBlobStore) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.blobs))
+	for key := range s.blobs {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// has returns true if the blob referenced by the given payload exists
+func (s *memoryBlobStore) has(p payload.Payload) bool {
+	var v interface{}
+	return offloadingConverter.From(p, &v) == nil
+}
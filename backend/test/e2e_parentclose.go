@@ -0,0 +1,193 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/client"
+	"github.com/cschleiden/go-workflows/worker"
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var e2eParentCloseTests = []backendTest{
+	{
+		name: "ParentClose/Terminate",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			swf := func(ctx workflow.Context) (string, error) {
+				workflow.NewSignalChannel[string](ctx, "never").Receive(ctx)
+				return "", nil
+			}
+			wf := func(ctx workflow.Context) error {
+				// Block until timed out
+				_, err := workflow.CreateSubWorkflowInstance[string](ctx, workflow.SubWorkflowOptions{
+					ParentClosePolicy: workflow.ParentClosePolicyTerminate,
+				}, swf).Get(ctx)
+				return err
+			}
+			register(t, ctx, w, []interface{}{wf, swf}, nil)
+
+			subInstance := runParentCloseWorkflow(t, ctx, c, wf)
+
+			_, err := client.GetWorkflowResult[string](ctx, c, subInstance, time.Second*5)
+			require.ErrorIs(t, err, client.ErrWorkflowTerminated)
+
+			historyContains(ctx, t, b, subInstance,
+				history.EventType_WorkflowExecutionStarted,
+				history.EventType_WorkflowExecutionTerminated,
+			)
+		},
+	},
+	{
+		name: "ParentClose/TerminateParent",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			swf := func(ctx workflow.Context) (string, error) {
+				workflow.NewSignalChannel[string](ctx, "never").Receive(ctx)
+				return "", nil
+			}
+			wf := func(ctx workflow.Context) error {
+				_, err := workflow.CreateSubWorkflowInstance[string](ctx, workflow.SubWorkflowOptions{
+					ParentClosePolicy: workflow.ParentClosePolicyTerminate,
+				}, swf).Get(ctx)
+				return err
+			}
+			register(t, ctx, w, []interface{}{wf, swf}, nil)
+
+			instance := runWorkflow(t, ctx, c, wf)
+			subInstance := waitForSubWorkflow(t, ctx, c, instance)
+
+			require.NoError(t, c.TerminateWorkflowInstance(ctx, instance, ""))
+
+			_, err := client.GetWorkflowResult[string](ctx, c, subInstance, time.Second*5)
+			require.ErrorIs(t, err, client.ErrWorkflowTerminated)
+		},
+	},
+	{
+		name: "ParentClose/ContinueAsNew",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			swf := func(ctx workflow.Context) (string, error) {
+				name, _ := workflow.NewSignalChannel[string](ctx, "name").Receive(ctx)
+				return "hello " + name, nil
+			}
+			wf := func(ctx workflow.Context, run int) error {
+				if run > 0 {
+					return nil
+				}
+
+				if _, err := workflow.StartSubWorkflow(ctx, workflow.SubWorkflowOptions{
+					ParentClosePolicy: workflow.ParentClosePolicyTerminate,
+				}, swf).Get(ctx); err != nil {
+					return err
+				}
+
+				return workflow.ContinueAsNew(ctx, run+1)
+			}
+			register(t, ctx, w, []interface{}{wf, swf}, nil)
+
+			instance := runWorkflow(t, ctx, c, wf, 0)
+			subInstance := waitForSubWorkflow(t, ctx, c, instance)
+
+			_, err := client.GetWorkflowResult[any](ctx, c, instance, time.Second*5)
+			require.NoError(t, err)
+
+			// The policy is not applied when continuing as new
+			require.NoError(t, c.SignalWorkflow(ctx, subInstance.InstanceID, "name", "world"))
+
+			r, err := client.GetWorkflowResult[string](ctx, c, subInstance, time.Second*5)
+			require.NoError(t, err)
+			require.Equal(t, "hello world", r)
+		},
+	},
+	{
+		name: "ParentClose/RequestCancel",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			swf := func(ctx workflow.Context) (string, error) {
+				if err := workflow.Sleep(ctx, time.Hour); err != nil {
+					if err == workflow.Canceled {
+						return "canceled", nil
+					}
+
+					return "", err
+				}
+
+				return "", nil
+			}
+			wf := func(ctx workflow.Context) error {
+				_, err := workflow.CreateSubWorkflowInstance[string](ctx, workflow.SubWorkflowOptions{
+					ParentClosePolicy: workflow.ParentClosePolicyRequestCancel,
+				}, swf).Get(ctx)
+				return err
+			}
+			register(t, ctx, w, []interface{}{wf, swf}, nil)
+
+			subInstance := runParentCloseWorkflow(t, ctx, c, wf)
+
+			r, err := client.GetWorkflowResult[string](ctx, c, subInstance, time.Second*5)
+			require.NoError(t, err)
+			require.Equal(t, "canceled", r)
+		},
+	},
+	{
+		name: "ParentClose/Abandon",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			swf := func(ctx workflow.Context) (string, error) {
+				name, _ := workflow.NewSignalChannel[string](ctx, "name").Receive(ctx)
+				return "hello " + name, nil
+			}
+			wf := func(ctx workflow.Context) error {
+				// Sub-workflow instances are abandoned by default
+				_, err := workflow.CreateSubWorkflowInstance[string](ctx, workflow.DefaultSubWorkflowOptions, swf).Get(ctx)
+				return err
+			}
+			register(t, ctx, w, []interface{}{wf, swf}, nil)
+
+			subInstance := runParentCloseWorkflow(t, ctx, c, wf)
+
+			// The sub-workflow instance keeps running after its parent finished
+			require.NoError(t, c.SignalWorkflow(ctx, subInstance.InstanceID, "name", "world"))
+
+			r, err := client.GetWorkflowResult[string](ctx, c, subInstance, time.Second*5)
+			require.NoError(t, err)
+			require.Equal(t, "hello world", r)
+		},
+	},
+}
+
+// runParentCloseWorkflow runs the given parent workflow until it times out, and returns its sub-workflow instance
+func runParentCloseWorkflow(t *testing.T, ctx context.Context, c *client.Client, wf interface{}) *workflow.Instance {
+	instance, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+		InstanceID: uuid.NewString(),
+		RunTimeout: 500 * time.Millisecond,
+	}, wf)
+	require.NoError(t, err)
+
+	_, err = client.GetWorkflowResult[any](ctx, c, instance, time.Second*5)
+	require.ErrorIs(t, err, workflow.ErrWorkflowTimeout)
+
+	r, err := c.ListWorkflowInstances(ctx, &client.WorkflowInstanceQuery{ParentInstanceID: instance.InstanceID})
+	require.NoError(t, err)
+	require.Len(t, r.Instances, 1)
+
+	return r.Instances[0].Instance
+}
+
+// waitForSubWorkflow waits until the sub-workflow instance of the given instance has been created and returns it
+func waitForSubWorkflow(t *testing.T, ctx context.Context, c *client.Client, instance *workflow.Instance) *workflow.Instance {
+	var subInstance *workflow.Instance
+	require.Eventually(t, func() bool {
+		r, err := c.ListWorkflowInstances(ctx, &client.WorkflowInstanceQuery{ParentInstanceID: instance.InstanceID})
+		require.NoError(t, err)
+
+		if len(r.Instances) == 0 {
+			return false
+		}
+
+		subInstance = r.Instances[0].Instance
+		return true
+	}, time.Second*5, time.Millisecond*50)
+
+	return subInstance
+}
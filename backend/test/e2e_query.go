@@ -0,0 +1,159 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/client"
+	"github.com/cschleiden/go-workflows/worker"
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/stretchr/testify/require"
+)
+
+var e2eQueryTests = []backendTest{
+	{
+		name: "Query/RunningWorkflow",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) (int, error) {
+				progress := 0
+				if err := workflow.SetQueryHandler(ctx, "progress", func() (int, error) {
+					return progress, nil
+				}); err != nil {
+					return 0, err
+				}
+
+				s := workflow.NewSignalChannel[int](ctx, "step")
+				for i := 0; i < 3; i++ {
+					v, _ := s.Receive(ctx)
+					progress += v
+				}
+
+				return progress, nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			qc := client.New(b, client.WithRegistry(w.Registry()))
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			require.Eventually(t, func() bool {
+				p, err := client.QueryWorkflow[int](ctx, qc, instance, "progress")
+				return err == nil && p == 0
+			}, time.Second*5, time.Millisecond*50)
+
+			require.NoError(t, c.SignalWorkflow(ctx, instance.InstanceID, "step", 2))
+
+			require.Eventually(t, func() bool {
+				p, err := client.QueryWorkflow[int](ctx, qc, instance, "progress")
+				require.NoError(t, err)
+				return p == 2
+			}, time.Second*5, time.Millisecond*50)
+
+			historyIterate(ctx, t, b, instance, func(event *history.Event) bool {
+				require.NotEqual(t, history.EventType_WorkflowExecutionFinished, event.Type)
+				return true
+			})
+		},
+	},
+	{
+		name: "Query/Arguments",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				values := map[string]string{"step": "download"}
+				if err := workflow.SetQueryHandler(ctx, "value", func(ctx workflow.Context, key string) (string, error) {
+					return values[key], nil
+				}); err != nil {
+					return err
+				}
+
+				workflow.NewSignalChannel[string](ctx, "never").Receive(ctx)
+
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			qc := client.New(b, client.WithRegistry(w.Registry()))
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			require.Eventually(t, func() bool {
+				v, err := client.QueryWorkflow[string](ctx, qc, instance, "value", "step")
+				return err == nil && v == "download"
+			}, time.Second*5, time.Millisecond*50)
+		},
+	},
+	{
+		name: "Query/FinishedWorkflow",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				step := "started"
+				if err := workflow.SetQueryHandler(ctx, "step", func() (string, error) {
+					return step, nil
+				}); err != nil {
+					return err
+				}
+
+				workflow.Sleep(ctx, time.Millisecond)
+
+				step = "done"
+
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			qc := client.New(b, client.WithRegistry(w.Registry()))
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			_, err := client.GetWorkflowResult[any](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+
+			events, err := b.GetWorkflowInstanceHistory(ctx, instance, nil)
+			require.NoError(t, err)
+
+			step, err := client.QueryWorkflow[string](ctx, qc, instance, "step")
+			require.NoError(t, err)
+			require.Equal(t, "done", step)
+
+			// Queries do not add to the history
+			eventsAfter, err := b.GetWorkflowInstanceHistory(ctx, instance, nil)
+			require.NoError(t, err)
+			require.Len(t, eventsAfter, len(events))
+		},
+	},
+	{
+		name: "Query/UnknownQuery",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			qc := client.New(b, client.WithRegistry(w.Registry()))
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			_, err := client.GetWorkflowResult[any](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+
+			_, err = client.QueryWorkflow[string](ctx, qc, instance, "unknown")
+			require.ErrorIs(t, err, workflow.ErrUnknownQuery)
+		},
+	},
+	{
+		name: "Query/ClientWithoutRegistry",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			_, err := client.QueryWorkflow[string](ctx, c, instance, "unknown")
+			require.ErrorIs(t, err, client.ErrNoRegistry)
+		},
+	},
+}
@@ -0,0 +1,322 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/client"
+	"github.com/cschleiden/go-workflows/diag"
+	"github.com/cschleiden/go-workflows/internal/workflows"
+	"github.com/cschleiden/go-workflows/worker"
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// describeSchedule queries the state of the given schedule, it returns an empty description if the schedule
+// workflow hasn't started yet.
+func describeSchedule(t *testing.T, ctx context.Context, b TestBackend, w *worker.Worker, instance *workflow.Instance) *client.ScheduleDescription {
+	qc := client.New(b, client.WithRegistry(w.Registry()))
+
+	d, err := client.QueryWorkflow[*client.ScheduleDescription](ctx, qc, instance, workflows.DescribeScheduleQuery)
+	if err != nil {
+		require.ErrorIs(t, err, workflow.ErrUnknownQuery)
+		return &client.ScheduleDescription{}
+	}
+
+	return d
+}
+
+var e2eScheduleTests = []backendTest{
+	{
+		name: "Schedule/Interval",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			var runs int32
+			a := func(ctx context.Context, msg string) error {
+				require.Equal(t, "hello", msg)
+				atomic.AddInt32(&runs, 1)
+				return nil
+			}
+			wf := func(ctx workflow.Context, msg string) error {
+				_, err := workflow.ExecuteActivity[any](ctx, workflow.DefaultActivityOptions, a, msg).Get(ctx)
+				return err
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{a})
+
+			id := uuid.NewString()
+			instance, err := c.CreateSchedule(ctx, id, client.ScheduleSpec{
+				Interval: time.Millisecond * 500,
+			}, wf, "hello")
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				return atomic.LoadInt32(&runs) >= 2
+			}, time.Second*10, time.Millisecond*50)
+
+			d := describeSchedule(t, ctx, b, w, instance)
+			require.Equal(t, id, d.ID)
+			require.NotNil(t, d.NextFireTime)
+			require.NotNil(t, d.LastInstance)
+			require.True(t, strings.HasPrefix(d.LastInstance.InstanceID, id+"-"))
+
+			require.NoError(t, c.DeleteSchedule(ctx, id))
+
+			// The schedule workflow stops once the signal has been processed
+			latest, err := b.GetLatestWorkflowInstance(ctx, instance.InstanceID)
+			require.NoError(t, err)
+			require.NoError(t, c.WaitForWorkflowInstance(ctx, latest, time.Second*10))
+		},
+	},
+	{
+		name: "Schedule/DuplicateID",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			id := uuid.NewString()
+			_, err := c.CreateSchedule(ctx, id, client.ScheduleSpec{Interval: time.Hour}, wf)
+			require.NoError(t, err)
+
+			_, err = c.CreateSchedule(ctx, id, client.ScheduleSpec{Interval: time.Hour}, wf)
+			require.ErrorIs(t, err, backend.ErrInstanceAlreadyExists)
+
+			require.NoError(t, c.DeleteSchedule(ctx, id))
+		},
+	},
+	{
+		name: "Schedule/InvalidSpec",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			_, err := c.CreateSchedule(ctx, uuid.NewString(), client.ScheduleSpec{}, wf)
+			require.Error(t, err)
+
+			_, err = c.CreateSchedule(ctx, uuid.NewString(), client.ScheduleSpec{Cron: "* * * * *", Interval: time.Hour}, wf)
+			require.Error(t, err)
+
+			_, err = c.CreateSchedule(ctx, uuid.NewString(), client.ScheduleSpec{Cron: "61 * * * *"}, wf)
+			require.Error(t, err)
+		},
+	},
+	{
+		name: "Schedule/PauseResumeTrigger",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			var runs int32
+			a := func(ctx context.Context) error {
+				atomic.AddInt32(&runs, 1)
+				return nil
+			}
+			wf := func(ctx workflow.Context) error {
+				_, err := workflow.ExecuteActivity[any](ctx, workflow.DefaultActivityOptions, a).Get(ctx)
+				return err
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{a})
+
+			id := uuid.NewString()
+			instance, err := c.CreateSchedule(ctx, id, client.ScheduleSpec{
+				Cron: "0 0 1 1 *",
+			}, wf)
+			require.NoError(t, err)
+
+			require.NoError(t, c.PauseSchedule(ctx, id))
+			require.Eventually(t, func() bool {
+				return describeSchedule(t, ctx, b, w, instance).Paused
+			}, time.Second*5, time.Millisecond*50)
+			require.Nil(t, describeSchedule(t, ctx, b, w, instance).NextFireTime)
+
+			// Triggering starts an execution even when the schedule is paused
+			require.NoError(t, c.TriggerSchedule(ctx, id))
+			require.Eventually(t, func() bool {
+				return atomic.LoadInt32(&runs) == 1
+			}, time.Second*5, time.Millisecond*50)
+
+			require.NoError(t, c.ResumeSchedule(ctx, id))
+			require.Eventually(t, func() bool {
+				d := describeSchedule(t, ctx, b, w, instance)
+				return !d.Paused && d.NextFireTime != nil
+			}, time.Second*5, time.Millisecond*50)
+
+			d := describeSchedule(t, ctx, b, w, instance)
+			require.Equal(t, time.January, d.NextFireTime.Month())
+			require.Equal(t, 1, d.NextFireTime.Day())
+			require.NotNil(t, d.LastInstance)
+
+			require.NoError(t, c.DeleteSchedule(ctx, id))
+
+			latest, err := b.GetLatestWorkflowInstance(ctx, instance.InstanceID)
+			require.NoError(t, err)
+			require.NoError(t, c.WaitForWorkflowInstance(ctx, latest, time.Second*10))
+		},
+	},
+	{
+		name: "Schedule/OverlapSkip",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			var runs int32
+			a := func(ctx context.Context) error {
+				atomic.AddInt32(&runs, 1)
+				return nil
+			}
+			wf := func(ctx workflow.Context) error {
+				if _, err := workflow.ExecuteActivity[any](ctx, workflow.DefaultActivityOptions, a).Get(ctx); err != nil {
+					return err
+				}
+
+				// Block until the test is done
+				workflow.NewSignalChannel[any](ctx, "never").Receive(ctx)
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{a})
+
+			id := uuid.NewString()
+			_, err := c.CreateSchedule(ctx, id, client.ScheduleSpec{
+				Interval:      time.Millisecond * 300,
+				OverlapPolicy: client.OverlapPolicySkip,
+			}, wf)
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				return atomic.LoadInt32(&runs) == 1
+			}, time.Second*5, time.Millisecond*50)
+
+			// Give the schedule a chance to fire a few more times
+			time.Sleep(time.Second * 2)
+			require.Equal(t, int32(1), atomic.LoadInt32(&runs))
+
+			require.NoError(t, c.DeleteSchedule(ctx, id))
+		},
+	},
+	{
+		name: "Schedule/OverlapBuffer",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			var runs int32
+			a := func(ctx context.Context) error {
+				atomic.AddInt32(&runs, 1)
+				return nil
+			}
+			wf := func(ctx workflow.Context) error {
+				if _, err := workflow.ExecuteActivity[any](ctx, workflow.DefaultActivityOptions, a).Get(ctx); err != nil {
+					return err
+				}
+
+				workflow.NewSignalChannel[any](ctx, "continue").Receive(ctx)
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{a})
+
+			id := uuid.NewString()
+			instance, err := c.CreateSchedule(ctx, id, client.ScheduleSpec{
+				Interval:      time.Millisecond * 300,
+				OverlapPolicy: client.OverlapPolicyBuffer,
+			}, wf)
+			require.NoError(t, err)
+
+			var first *workflow.Instance
+			require.Eventually(t, func() bool {
+				d := describeSchedule(t, ctx, b, w, instance)
+				first = d.LastInstance
+				return first != nil && d.Buffered > 0
+			}, time.Second*10, time.Millisecond*50)
+			require.Equal(t, int32(1), atomic.LoadInt32(&runs))
+
+			// Stop firing, buffered executions are still started
+			require.NoError(t, c.PauseSchedule(ctx, id))
+			require.NoError(t, c.SignalWorkflow(ctx, first.InstanceID, "continue", nil))
+
+			require.Eventually(t, func() bool {
+				return atomic.LoadInt32(&runs) == 2
+			}, time.Second*10, time.Millisecond*50)
+
+			require.NoError(t, c.DeleteSchedule(ctx, id))
+		},
+	},
+	{
+		name: "Schedule/OverlapAllow",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			var runs int32
+			a := func(ctx context.Context) error {
+				atomic.AddInt32(&runs, 1)
+				return nil
+			}
+			wf := func(ctx workflow.Context) error {
+				if _, err := workflow.ExecuteActivity[any](ctx, workflow.DefaultActivityOptions, a).Get(ctx); err != nil {
+					return err
+				}
+
+				workflow.NewSignalChannel[any](ctx, "never").Receive(ctx)
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{a})
+
+			id := uuid.NewString()
+			_, err := c.CreateSchedule(ctx, id, client.ScheduleSpec{
+				Interval:      time.Millisecond * 300,
+				Jitter:        time.Millisecond * 100,
+				OverlapPolicy: client.OverlapPolicyAllow,
+			}, wf)
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				return atomic.LoadInt32(&runs) >= 3
+			}, time.Second*10, time.Millisecond*50)
+
+			require.NoError(t, c.DeleteSchedule(ctx, id))
+		},
+	},
+	{
+		name: "Schedule/Diag",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			diagBackend, ok := b.(diag.Backend)
+			if !ok {
+				t.Skip("Backend does not implement diag.Backend")
+			}
+
+			wf := func(ctx workflow.Context) error {
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			id := uuid.NewString()
+			instance, err := c.CreateSchedule(ctx, id, client.ScheduleSpec{Cron: "@daily"}, wf)
+			require.NoError(t, err)
+
+			mux := diag.NewServeMux(diagBackend)
+
+			var schedule *diag.Schedule
+			require.Eventually(t, func() bool {
+				rec := httptest.NewRecorder()
+				mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/schedules", nil))
+				require.Equal(t, http.StatusOK, rec.Code)
+
+				var schedules []*diag.Schedule
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &schedules))
+
+				for _, s := range schedules {
+					if s.ID == id {
+						schedule = s
+						return s.NextFireTime != nil
+					}
+				}
+
+				return false
+			}, time.Second*5, time.Millisecond*50)
+
+			require.Equal(t, instance.InstanceID, schedule.Instance.InstanceID)
+			require.Equal(t, "@daily", schedule.Spec.Cron)
+			require.Equal(t, 0, schedule.NextFireTime.Hour())
+
+			require.NoError(t, c.DeleteSchedule(ctx, id))
+		},
+	},
+}
@@ -0,0 +1,164 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/client"
+	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/worker"
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var e2eSearchAttributesTests = []backendTest{
+	{
+		name: "SearchAttributes/CreateAndList",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) (workflow.SearchAttributes, error) {
+				return workflow.GetSearchAttributes(ctx)
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			first, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+				InstanceID:       uuid.NewString(),
+				SearchAttributes: workflow.SearchAttributes{"CustomerID": "c-1", "Priority": 2},
+			}, wf)
+			require.NoError(t, err)
+
+			r, err := client.GetWorkflowResult[workflow.SearchAttributes](ctx, c, first, time.Second*5)
+			require.NoError(t, err)
+			require.Equal(t, workflow.SearchAttributes{"CustomerID": "c-1", "Priority": float64(2)}, r)
+
+			second, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+				InstanceID:       uuid.NewString(),
+				SearchAttributes: workflow.SearchAttributes{"CustomerID": "c-2", "Priority": 2},
+			}, wf)
+			require.NoError(t, err)
+
+			_, err = client.GetWorkflowResult[workflow.SearchAttributes](ctx, c, second, time.Second*5)
+			require.NoError(t, err)
+
+			l, err := c.ListWorkflowInstances(ctx, &client.WorkflowInstanceQuery{
+				SearchAttributes: workflow.SearchAttributes{"CustomerID": "c-1"},
+			})
+			require.NoError(t, err)
+			require.Len(t, l.Instances, 1)
+			require.Equal(t, first.InstanceID, l.Instances[0].Instance.InstanceID)
+			require.Equal(t, workflow.SearchAttributes{"CustomerID": "c-1", "Priority": float64(2)}, l.Instances[0].SearchAttributes)
+
+			l, err = c.ListWorkflowInstances(ctx, &client.WorkflowInstanceQuery{
+				SearchAttributes: workflow.SearchAttributes{"Priority": 2},
+			})
+			require.NoError(t, err)
+			require.Equal(t, int64(2), l.TotalCount)
+
+			l, err = c.ListWorkflowInstances(ctx, &client.WorkflowInstanceQuery{
+				SearchAttributes: workflow.SearchAttributes{"CustomerID": "c-2", "Priority": 1},
+			})
+			require.NoError(t, err)
+			require.Empty(t, l.Instances)
+		},
+	},
+	{
+		name: "SearchAttributes/InvalidValue",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			_, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+				InstanceID:       uuid.NewString(),
+				SearchAttributes: workflow.SearchAttributes{"Tags": []string{"a"}},
+			}, wf)
+			require.Error(t, err)
+		},
+	},
+	{
+		name: "SearchAttributes/Upsert",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) (workflow.SearchAttributes, error) {
+				if err := workflow.UpsertSearchAttributes(ctx, workflow.SearchAttributes{"Region": "eu"}); err != nil {
+					return nil, err
+				}
+
+				workflow.NewSignalChannel[any](ctx, "continue").Receive(ctx)
+
+				if err := workflow.UpsertSearchAttributes(ctx, workflow.SearchAttributes{"Region": "us"}); err != nil {
+					return nil, err
+				}
+
+				return workflow.GetSearchAttributes(ctx)
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			instance, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+				InstanceID:       uuid.NewString(),
+				SearchAttributes: workflow.SearchAttributes{"CustomerID": "c-1"},
+			}, wf)
+			require.NoError(t, err)
+
+			count := func(region string) int64 {
+				l, err := c.ListWorkflowInstances(ctx, &client.WorkflowInstanceQuery{
+					SearchAttributes: workflow.SearchAttributes{"Region": region},
+				})
+				require.NoError(t, err)
+				return l.TotalCount
+			}
+
+			require.Eventually(t, func() bool {
+				return count("eu") == 1
+			}, time.Second*5, time.Millisecond*50)
+
+			require.NoError(t, c.SignalWorkflow(ctx, instance.InstanceID, "continue", nil))
+
+			r, err := client.GetWorkflowResult[workflow.SearchAttributes](ctx, c, instance, time.Second*5)
+			require.NoError(t, err)
+			require.Equal(t, workflow.SearchAttributes{"CustomerID": "c-1", "Region": "us"}, r)
+
+			require.Equal(t, int64(0), count("eu"))
+			require.Equal(t, int64(1), count("us"))
+
+			historyContains(ctx, t, b, instance, history.EventType_SearchAttributesUpserted, history.EventType_SearchAttributesUpserted)
+		},
+	},
+	{
+		name: "SearchAttributes/ContinueAsNew",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context, run int) (workflow.SearchAttributes, error) {
+				if run == 0 {
+					if err := workflow.UpsertSearchAttributes(ctx, workflow.SearchAttributes{"Region": "eu"}); err != nil {
+						return nil, err
+					}
+
+					return nil, workflow.ContinueAsNew(ctx, run+1)
+				}
+
+				return workflow.GetSearchAttributes(ctx)
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			instance, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+				InstanceID:       uuid.NewString(),
+				SearchAttributes: workflow.SearchAttributes{"CustomerID": "c-1"},
+			}, wf, 0)
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				state, err := c.GetWorkflowInstanceState(ctx, &workflow.Instance{InstanceID: instance.InstanceID})
+				return err == nil && state == core.WorkflowInstanceStateFinished
+			}, time.Second*5, time.Millisecond*50)
+
+			latest, err := b.GetLatestWorkflowInstance(ctx, instance.InstanceID)
+			require.NoError(t, err)
+
+			r, err := client.GetWorkflowResult[workflow.SearchAttributes](ctx, c, latest, time.Second*5)
+			require.NoError(t, err)
+			require.Equal(t, workflow.SearchAttributes{"CustomerID": "c-1", "Region": "eu"}, r)
+		},
+	},
+}
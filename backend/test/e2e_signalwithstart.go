@@ -0,0 +1,161 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/client"
+	"github.com/cschleiden/go-workflows/worker"
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var e2eSignalWithStartTests = []backendTest{
+	{
+		name: "SignalWithStart/CreatesInstance",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context, prefix string) (string, error) {
+				v, _ := workflow.NewSignalChannel[string](ctx, "signal").Receive(ctx)
+				return prefix + v, nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			instance, err := c.SignalWithStartWorkflow(ctx, client.WorkflowInstanceOptions{
+				InstanceID: uuid.NewString(),
+			}, "signal", "world", wf, "hello ")
+			require.NoError(t, err)
+
+			r, err := client.GetWorkflowResult[string](ctx, c, instance, time.Second*5)
+			require.NoError(t, err)
+			require.Equal(t, "hello world", r)
+
+			// The signal is the first event after the workflow has been started
+			var types []history.EventType
+			historyIterate(ctx, t, b, instance, func(event *history.Event) bool {
+				if event.Type != history.EventType_WorkflowTaskStarted {
+					types = append(types, event.Type)
+				}
+
+				return true
+			})
+			require.GreaterOrEqual(t, len(types), 2)
+			require.Equal(t, history.EventType_WorkflowExecutionStarted, types[0])
+			require.Equal(t, history.EventType_SignalReceived, types[1])
+		},
+	},
+	{
+		name: "SignalWithStart/SignalsActiveInstance",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) ([]string, error) {
+				sc := workflow.NewSignalChannel[string](ctx, "signal")
+				r := []string{}
+				for i := 0; i < 2; i++ {
+					v, _ := sc.Receive(ctx)
+					r = append(r, v)
+				}
+
+				return r, nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			options := client.WorkflowInstanceOptions{
+				InstanceID: uuid.NewString(),
+			}
+
+			first, err := c.SignalWithStartWorkflow(ctx, options, "signal", "a", wf)
+			require.NoError(t, err)
+
+			second, err := c.SignalWithStartWorkflow(ctx, options, "signal", "b", wf)
+			require.NoError(t, err)
+			require.Equal(t, first.ExecutionID, second.ExecutionID)
+
+			r, err := client.GetWorkflowResult[[]string](ctx, c, first, time.Second*5)
+			require.NoError(t, err)
+			require.Equal(t, []string{"a", "b"}, r)
+
+			// Once the execution has finished, a new one is started
+			third, err := c.SignalWithStartWorkflow(ctx, options, "signal", "c", wf)
+			require.NoError(t, err)
+			require.NotEqual(t, first.ExecutionID, third.ExecutionID)
+			require.NoError(t, c.SignalWorkflow(ctx, options.InstanceID, "signal", "d"))
+
+			r, err = client.GetWorkflowResult[[]string](ctx, c, third, time.Second*5)
+			require.NoError(t, err)
+			require.Equal(t, []string{"c", "d"}, r)
+		},
+	},
+	{
+		name: "SignalWithStart/Concurrent",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			const signals = 5
+
+			wf := func(ctx workflow.Context) (int, error) {
+				sc := workflow.NewSignalChannel[int](ctx, "signal")
+				sum := 0
+				for i := 0; i < signals; i++ {
+					v, _ := sc.Receive(ctx)
+					sum += v
+				}
+
+				return sum, nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			options := client.WorkflowInstanceOptions{
+				InstanceID: uuid.NewString(),
+			}
+
+			instances := make([]*workflow.Instance, signals)
+			errs := make([]error, signals)
+
+			var wg sync.WaitGroup
+			for i := 0; i < signals; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					instances[i], errs[i] = c.SignalWithStartWorkflow(ctx, options, "signal", i+1, wf)
+				}(i)
+			}
+			wg.Wait()
+
+			for i := 0; i < signals; i++ {
+				require.NoError(t, errs[i], fmt.Sprintf("signal %d", i))
+				require.Equal(t, instances[0].ExecutionID, instances[i].ExecutionID)
+			}
+
+			r, err := client.GetWorkflowResult[int](ctx, c, instances[0], time.Second*10)
+			require.NoError(t, err)
+			require.Equal(t, 15, r)
+		},
+	},
+	{
+		name: "SignalWithStart/RejectDuplicate",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				workflow.NewSignalChannel[string](ctx, "signal").Receive(ctx)
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			options := client.WorkflowInstanceOptions{
+				InstanceID:    uuid.NewString(),
+				IDReusePolicy: client.IDReusePolicyRejectDuplicate,
+			}
+
+			instance, err := c.SignalWithStartWorkflow(ctx, options, "signal", "a", wf)
+			require.NoError(t, err)
+
+			_, err = client.GetWorkflowResult[any](ctx, c, instance, time.Second*5)
+			require.NoError(t, err)
+
+			_, err = c.SignalWithStartWorkflow(ctx, options, "signal", "b", wf)
+			require.ErrorIs(t, err, backend.ErrInstanceAlreadyExists)
+		},
+	},
+}
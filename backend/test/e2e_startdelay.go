@@ -0,0 +1,133 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/client"
+	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/worker"
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var e2eStartDelayTests = []backendTest{
+	{
+		name: "StartDelay/DelaysExecution",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			started := make(chan time.Time, 1)
+			a := func(ctx context.Context) error {
+				started <- time.Now()
+				return nil
+			}
+			wf := func(ctx workflow.Context) (time.Time, error) {
+				_, err := workflow.ExecuteActivity[any](ctx, workflow.DefaultActivityOptions, a).Get(ctx)
+				return workflow.Now(ctx), err
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{a})
+
+			createdAt := time.Now()
+			instance, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+				InstanceID: uuid.NewString(),
+				StartDelay: time.Second,
+			}, wf)
+			require.NoError(t, err)
+
+			state, err := c.GetWorkflowInstanceState(ctx, instance)
+			require.NoError(t, err)
+			require.Equal(t, core.WorkflowInstanceStateActive, state)
+
+			select {
+			case <-started:
+				require.Fail(t, "workflow started before the delay")
+			case <-time.After(time.Millisecond * 500):
+			}
+
+			now, err := client.GetWorkflowResult[time.Time](ctx, c, instance, time.Second*5)
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, now.Sub(createdAt), time.Second)
+			require.GreaterOrEqual(t, (<-started).Sub(createdAt), time.Second)
+		},
+	},
+	{
+		name: "StartDelay/ReservesInstanceID",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			id := uuid.NewString()
+			instance, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+				InstanceID: id,
+				StartAt:    time.Now().Add(time.Millisecond * 500),
+			}, wf)
+			require.NoError(t, err)
+
+			_, err = c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+				InstanceID: id,
+			}, wf)
+			require.ErrorIs(t, err, backend.ErrInstanceAlreadyExists)
+
+			_, err = client.GetWorkflowResult[any](ctx, c, instance, time.Second*5)
+			require.NoError(t, err)
+		},
+	},
+	{
+		name: "StartDelay/SignalBeforeStart",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) (string, error) {
+				v, _ := workflow.NewSignalChannel[string](ctx, "signal").Receive(ctx)
+				return v, nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			instance, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+				InstanceID: uuid.NewString(),
+				StartDelay: time.Millisecond * 500,
+			}, wf)
+			require.NoError(t, err)
+
+			require.NoError(t, c.SignalWorkflow(ctx, instance.InstanceID, "signal", "hello"))
+
+			r, err := client.GetWorkflowResult[string](ctx, c, instance, time.Second*5)
+			require.NoError(t, err)
+			require.Equal(t, "hello", r)
+
+			// The signal is executed after the workflow has been started
+			historyContains(ctx, t, b, instance,
+				history.EventType_WorkflowExecutionStarted,
+				history.EventType_SignalReceived,
+			)
+		},
+	},
+	{
+		name: "StartDelay/StartAtInPastStartsImmediately",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			instance, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+				InstanceID: uuid.NewString(),
+				StartAt:    time.Now().Add(-time.Hour),
+			}, wf)
+			require.NoError(t, err)
+
+			_, err = client.GetWorkflowResult[any](ctx, c, instance, time.Second*5)
+			require.NoError(t, err)
+
+			_, err = c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+				InstanceID: uuid.NewString(),
+				StartAt:    time.Now().Add(time.Hour),
+				StartDelay: time.Hour,
+			}, wf)
+			require.Error(t, err)
+		},
+	},
+}
@@ -0,0 +1,225 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/client"
+	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/worker"
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var e2eTerminateTests = []backendTest{
+	{
+		name: "Terminate/BlockedWorkflow",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				// Block until terminated
+				workflow.NewSignalChannel[string](ctx, "never").Receive(ctx)
+
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			// Allow some time for the workflow to start
+			time.Sleep(time.Millisecond * 200)
+
+			require.NoError(t, c.TerminateWorkflowInstance(ctx, instance, "stuck"))
+
+			_, err := client.GetWorkflowResult[any](ctx, c, instance, time.Second*5)
+			require.ErrorIs(t, err, client.ErrWorkflowTerminated)
+
+			historyContains(ctx, t, b, instance,
+				history.EventType_WorkflowExecutionStarted,
+				history.EventType_WorkflowExecutionTerminated,
+			)
+
+			events, err := b.GetWorkflowInstanceHistory(ctx, instance, nil)
+			require.NoError(t, err)
+			last := events[len(events)-1]
+			require.Equal(t, history.EventType_WorkflowExecutionTerminated, last.Type)
+			require.Equal(t, "stuck", last.Attributes.(*history.ExecutionTerminatedAttributes).Reason)
+		},
+	},
+	{
+		name: "Terminate/FinishedWorkflowErrors",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			_, err := client.GetWorkflowResult[any](ctx, c, instance, time.Second*5)
+			require.NoError(t, err)
+
+			err = c.TerminateWorkflowInstance(ctx, instance, "")
+			require.ErrorIs(t, err, backend.ErrInstanceNotActive)
+		},
+	},
+	{
+		name: "Terminate/UnknownWorkflowErrors",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			register(t, ctx, w, nil, nil)
+
+			err := c.TerminateWorkflowInstance(ctx, core.NewWorkflowInstance(uuid.NewString(), uuid.NewString()), "")
+			require.ErrorIs(t, err, backend.ErrInstanceNotFound)
+		},
+	},
+	{
+		name: "Terminate/AbandonsTimers",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				_, err := workflow.ScheduleTimer(ctx, time.Second*10).Get(ctx)
+				return err
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			// Allow some time for the timer to get scheduled
+			time.Sleep(time.Millisecond * 200)
+
+			futureEvents, err := b.GetFutureEvents(ctx)
+			require.NoError(t, err)
+			require.Len(t, futureEvents, 1)
+
+			require.NoError(t, c.TerminateWorkflowInstance(ctx, instance, ""))
+
+			_, err = client.GetWorkflowResult[any](ctx, c, instance, time.Second*5)
+			require.ErrorIs(t, err, client.ErrWorkflowTerminated)
+
+			futureEvents, err = b.GetFutureEvents(ctx)
+			require.NoError(t, err)
+			require.Len(t, futureEvents, 0)
+		},
+	},
+	{
+		name: "Terminate/AbandonsActivities",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			started := make(chan struct{}, 1)
+			a := func(ctx context.Context) (int, error) {
+				started <- struct{}{}
+				time.Sleep(time.Millisecond * 500)
+				return 42, nil
+			}
+
+			wf := func(ctx workflow.Context) (int, error) {
+				return workflow.ExecuteActivity[int](ctx, workflow.DefaultActivityOptions, a).Get(ctx)
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{a})
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			<-started
+			require.NoError(t, c.TerminateWorkflowInstance(ctx, instance, ""))
+
+			// Give the activity a chance to complete
+			time.Sleep(time.Second)
+
+			_, err := client.GetWorkflowResult[int](ctx, c, instance, time.Second*5)
+			require.ErrorIs(t, err, client.ErrWorkflowTerminated)
+
+			historyIterate(ctx, t, b, instance, func(event *history.Event) bool {
+				require.NotEqual(t, history.EventType_ActivityCompleted, event.Type)
+				return true
+			})
+		},
+	},
+	{
+		name: "Terminate/SubWorkflowFailsParent",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			subWorkflowInstanceID := uuid.NewString()
+
+			swf := func(ctx workflow.Context) error {
+				workflow.NewSignalChannel[string](ctx, "never").Receive(ctx)
+				return nil
+			}
+			wf := func(ctx workflow.Context) error {
+				_, err := workflow.CreateSubWorkflowInstance[any](ctx, workflow.SubWorkflowOptions{
+					InstanceID: subWorkflowInstanceID,
+				}, swf).Get(ctx)
+				return err
+			}
+			register(t, ctx, w, []interface{}{wf, swf}, nil)
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			// Wait for the sub-workflow to be started
+			var subWorkflowInstance *workflow.Instance
+			require.Eventually(t, func() bool {
+				events, err := b.GetWorkflowInstanceHistory(ctx, instance, nil)
+				require.NoError(t, err)
+
+				for _, event := range events {
+					if event.Type == history.EventType_SubWorkflowScheduled {
+						subWorkflowInstance = event.Attributes.(*history.SubWorkflowScheduledAttributes).SubWorkflowInstance
+						return true
+					}
+				}
+
+				return false
+			}, time.Second*5, time.Millisecond*50)
+
+			require.NoError(t, c.TerminateWorkflowInstance(ctx, subWorkflowInstance, ""))
+
+			_, err := client.GetWorkflowResult[any](ctx, c, instance, time.Second*5)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), backend.ErrInstanceTerminated.Error())
+		},
+	},
+	{
+		name: "Terminate/SubWorkflows",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			swf := func(ctx workflow.Context) error {
+				workflow.NewSignalChannel[string](ctx, "never").Receive(ctx)
+				return nil
+			}
+			wf := func(ctx workflow.Context) error {
+				_, err := workflow.CreateSubWorkflowInstance[any](ctx, workflow.DefaultSubWorkflowOptions, swf).Get(ctx)
+				return err
+			}
+			register(t, ctx, w, []interface{}{wf, swf}, nil)
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			var subWorkflowInstance *workflow.Instance
+			require.Eventually(t, func() bool {
+				events, err := b.GetWorkflowInstanceHistory(ctx, instance, nil)
+				require.NoError(t, err)
+
+				for _, event := range events {
+					if event.Type == history.EventType_SubWorkflowScheduled {
+						subWorkflowInstance = event.Attributes.(*history.SubWorkflowScheduledAttributes).SubWorkflowInstance
+						return true
+					}
+				}
+
+				return false
+			}, time.Second*5, time.Millisecond*50)
+
+			// Ensure the sub-workflow instance has been created
+			require.Eventually(t, func() bool {
+				_, err := b.GetWorkflowInstanceState(ctx, subWorkflowInstance)
+				return err == nil
+			}, time.Second*5, time.Millisecond*50)
+
+			require.NoError(t, c.TerminateWorkflowInstance(ctx, instance, "", client.TerminateSubWorkflows()))
+
+			_, err := client.GetWorkflowResult[any](ctx, c, instance, time.Second*5)
+			require.ErrorIs(t, err, client.ErrWorkflowTerminated)
+
+			_, err = client.GetWorkflowResult[any](ctx, c, subWorkflowInstance, time.Second*5)
+			require.ErrorIs(t, err, client.ErrWorkflowTerminated)
+		},
+	},
+}
@@ -0,0 +1,236 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/client"
+	"github.com/cschleiden/go-workflows/worker"
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/stretchr/testify/require"
+)
+
+var e2eUpdateTests = []backendTest{
+	{
+		name: "Update/Handler",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) (int, error) {
+				total := 0
+				if err := workflow.SetUpdateHandler(ctx, "add", nil, func(ctx workflow.Context, v int) (int, error) {
+					total += v
+					return total, nil
+				}); err != nil {
+					return 0, err
+				}
+
+				workflow.NewSignalChannel[any](ctx, "done").Receive(ctx)
+
+				return total, nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			uc := client.New(b, client.WithRegistry(w.Registry()))
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			r, err := updateWorkflow[int](t, ctx, uc, instance, "add", 2)
+			require.NoError(t, err)
+			require.Equal(t, 2, r)
+
+			r, err = client.UpdateWorkflow[int](ctx, uc, instance.InstanceID, "add", 3)
+			require.NoError(t, err)
+			require.Equal(t, 5, r)
+
+			require.NoError(t, c.SignalWorkflow(ctx, instance.InstanceID, "done", nil))
+
+			result, err := client.GetWorkflowResult[int](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+			require.Equal(t, 5, result)
+
+			historyContains(ctx, t, b, instance,
+				history.EventType_UpdateRequested,
+				history.EventType_UpdateCompleted,
+				history.EventType_UpdateRequested,
+				history.EventType_UpdateCompleted,
+				history.EventType_WorkflowExecutionFinished,
+			)
+		},
+	},
+	{
+		name: "Update/Rejected",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				if err := workflow.SetUpdateHandler(ctx, "add",
+					func(v int) error {
+						if v < 0 {
+							return errors.New("value must not be negative")
+						}
+
+						return nil
+					},
+					func(v int) error {
+						return nil
+					}); err != nil {
+					return err
+				}
+
+				workflow.NewSignalChannel[any](ctx, "done").Receive(ctx)
+
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			uc := client.New(b, client.WithRegistry(w.Registry()))
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			_, err := updateWorkflow[any](t, ctx, uc, instance, "add", -1)
+			require.ErrorIs(t, err, workflow.ErrUpdateRejected)
+
+			var ure *workflow.UpdateRejectedError
+			require.ErrorAs(t, err, &ure)
+			require.Equal(t, "value must not be negative", ure.Reason)
+
+			require.NoError(t, c.SignalWorkflow(ctx, instance.InstanceID, "done", nil))
+
+			_, err = client.GetWorkflowResult[any](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+
+			// The rejection is recorded as the result of the update
+			historyContains(ctx, t, b, instance, history.EventType_UpdateRequested, history.EventType_UpdateCompleted)
+		},
+	},
+	{
+		name: "Update/BlockingHandler",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			act := func(ctx context.Context, name string) (string, error) {
+				return "hello " + name, nil
+			}
+
+			wf := func(ctx workflow.Context) error {
+				if err := workflow.SetUpdateHandler(ctx, "greet", nil, func(ctx workflow.Context, name string) (string, error) {
+					return workflow.ExecuteActivity[string](ctx, workflow.DefaultActivityOptions, act, name).Get(ctx)
+				}); err != nil {
+					return err
+				}
+
+				workflow.NewSignalChannel[any](ctx, "done").Receive(ctx)
+
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, []interface{}{act})
+
+			uc := client.New(b, client.WithRegistry(w.Registry()))
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			r, err := updateWorkflow[string](t, ctx, uc, instance, "greet", "gopher")
+			require.NoError(t, err)
+			require.Equal(t, "hello gopher", r)
+
+			require.NoError(t, c.SignalWorkflow(ctx, instance.InstanceID, "done", nil))
+
+			_, err = client.GetWorkflowResult[any](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+
+			historyContains(ctx, t, b, instance,
+				history.EventType_UpdateRequested,
+				history.EventType_ActivityScheduled,
+				history.EventType_ActivityCompleted,
+				history.EventType_UpdateCompleted,
+			)
+		},
+	},
+	{
+		name: "Update/HandlerError",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				if err := workflow.SetUpdateHandler(ctx, "fail", nil, func() error {
+					return errors.New("handler failed")
+				}); err != nil {
+					return err
+				}
+
+				workflow.NewSignalChannel[any](ctx, "done").Receive(ctx)
+
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			uc := client.New(b, client.WithRegistry(w.Registry()))
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			_, err := updateWorkflow[any](t, ctx, uc, instance, "fail", nil)
+			require.ErrorContains(t, err, "handler failed")
+
+			historyContains(ctx, t, b, instance, history.EventType_UpdateRequested, history.EventType_UpdateCompleted)
+		},
+	},
+	{
+		name: "Update/FinishedWorkflow",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				return workflow.SetUpdateHandler(ctx, "noop", nil, func() error {
+					return nil
+				})
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			uc := client.New(b, client.WithRegistry(w.Registry()))
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			_, err := client.GetWorkflowResult[any](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+
+			_, err = client.UpdateWorkflow[any](ctx, uc, instance.InstanceID, "noop", nil)
+			require.Error(t, err)
+		},
+	},
+	{
+		name: "Update/ClientWithoutRegistry",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				if err := workflow.SetUpdateHandler(ctx, "echo", nil, func(v string) (string, error) {
+					return v, nil
+				}); err != nil {
+					return err
+				}
+
+				workflow.NewSignalChannel[any](ctx, "done").Receive(ctx)
+
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			instance := runWorkflow(t, ctx, c, wf)
+
+			// Updates are validated by the worker, the client doesn't need the workflow code
+			r, err := updateWorkflow[string](t, ctx, c, instance, "echo", "hello")
+			require.NoError(t, err)
+			require.Equal(t, "hello", r)
+
+			require.NoError(t, c.SignalWorkflow(ctx, instance.InstanceID, "done", nil))
+
+			_, err = client.GetWorkflowResult[any](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+		},
+	},
+}
+
+// updateWorkflow sends the update once the workflow has registered its update handlers
+func updateWorkflow[T any](t *testing.T, ctx context.Context, c *client.Client, instance *workflow.Instance, name string, arg any) (T, error) {
+	var r T
+	var err error
+
+	require.Eventually(t, func() bool {
+		r, err = client.UpdateWorkflow[T](ctx, c, instance.InstanceID, name, arg)
+		return !errors.Is(err, workflow.ErrUnknownUpdate)
+	}, time.Second*5, time.Millisecond*50)
+
+	return r, err
+}
@@ -0,0 +1,146 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/client"
+	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/worker"
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var e2eWorkflowRetryTests = []backendTest{
+	{
+		name: "WorkflowRetry/RetriesFailedExecution",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context, name string) (string, error) {
+				if attempt := workflow.Attempt(ctx); attempt < 2 {
+					return "", fmt.Errorf("attempt %d failed", attempt)
+				}
+
+				return fmt.Sprintf("hello %s, attempt %d", name, workflow.Attempt(ctx)), nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			instance, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+				InstanceID: uuid.NewString(),
+				RetryOptions: workflow.RetryOptions{
+					MaxAttempts:        3,
+					FirstRetryInterval: time.Millisecond,
+					BackoffCoefficient: 1,
+				},
+			}, wf, "retry")
+			require.NoError(t, err)
+
+			r, err := client.GetWorkflowResult[string](ctx, c, instance, time.Second*10)
+			require.NoError(t, err)
+			require.Equal(t, "hello retry, attempt 2", r)
+
+			// The failed execution is continued by the retry
+			state, err := b.GetWorkflowInstanceState(ctx, instance)
+			require.NoError(t, err)
+			require.Equal(t, core.WorkflowInstanceStateContinuedAsNew, state)
+
+			historyIterate(ctx, t, b, instance, func(event *history.Event) bool {
+				if event.Type == history.EventType_WorkflowExecutionContinuedAsNew {
+					a := event.Attributes.(*history.ExecutionContinuedAsNewAttributes)
+					require.Equal(t, "attempt 0 failed", a.Error.Message)
+				}
+
+				return true
+			})
+
+			latest, err := b.GetLatestWorkflowInstance(ctx, instance.InstanceID)
+			require.NoError(t, err)
+			require.NotEqual(t, instance.ExecutionID, latest.ExecutionID)
+		},
+	},
+	{
+		name: "WorkflowRetry/MaxAttempts",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			var executions int32
+			wf := func(ctx workflow.Context) error {
+				if !workflow.Replaying(ctx) {
+					atomic.AddInt32(&executions, 1)
+				}
+
+				return errors.New("always fails")
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			instance, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+				InstanceID: uuid.NewString(),
+				RetryOptions: workflow.RetryOptions{
+					MaxAttempts:        2,
+					FirstRetryInterval: time.Millisecond,
+					BackoffCoefficient: 1,
+				},
+			}, wf)
+			require.NoError(t, err)
+
+			_, err = client.GetWorkflowResult[any](ctx, c, instance, time.Second*10)
+			require.ErrorContains(t, err, "always fails")
+			require.Equal(t, int32(2), atomic.LoadInt32(&executions))
+		},
+	},
+	{
+		name: "WorkflowRetry/NonRetryableError",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				return &CustomError{msg: "invalid input"}
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			instance, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+				InstanceID: uuid.NewString(),
+				RetryOptions: workflow.RetryOptions{
+					MaxAttempts:            3,
+					NonRetryableErrorTypes: []string{"CustomError"},
+				},
+			}, wf)
+			require.NoError(t, err)
+
+			_, err = client.GetWorkflowResult[any](ctx, c, instance, time.Second*10)
+			require.ErrorContains(t, err, "invalid input")
+
+			state, err := b.GetWorkflowInstanceState(ctx, instance)
+			require.NoError(t, err)
+			require.Equal(t, core.WorkflowInstanceStateFinished, state)
+		},
+	},
+	{
+		name: "WorkflowRetry/SubWorkflowAttempt",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			swf := func(ctx workflow.Context) (int, error) {
+				if attempt := workflow.Attempt(ctx); attempt < 1 {
+					return 0, fmt.Errorf("attempt %d failed", attempt)
+				}
+
+				return workflow.Attempt(ctx), nil
+			}
+
+			wf := func(ctx workflow.Context) (int, error) {
+				return workflow.CreateSubWorkflowInstance[int](ctx, workflow.SubWorkflowOptions{
+					RetryOptions: workflow.RetryOptions{
+						MaxAttempts:        2,
+						FirstRetryInterval: time.Millisecond,
+						BackoffCoefficient: 1,
+					},
+				}, swf).Get(ctx)
+			}
+			register(t, ctx, w, []interface{}{wf, swf}, nil)
+
+			r, err := runWorkflowWithResult[int](t, ctx, c, wf)
+			require.NoError(t, err)
+			require.Equal(t, 1, r)
+		},
+	},
+}
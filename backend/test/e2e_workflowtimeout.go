@@ -0,0 +1,129 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/client"
+	"github.com/cschleiden/go-workflows/worker"
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var e2eWorkflowTimeoutTests = []backendTest{
+	{
+		name: "WorkflowTimeout/RunTimeout",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) error {
+				// Block until timed out
+				workflow.NewSignalChannel[string](ctx, "never").Receive(ctx)
+
+				return nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			instance, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+				InstanceID: uuid.NewString(),
+				RunTimeout: 200 * time.Millisecond,
+			}, wf)
+			require.NoError(t, err)
+
+			_, err = client.GetWorkflowResult[any](ctx, c, instance, time.Second*5)
+			require.ErrorIs(t, err, workflow.ErrWorkflowTimeout)
+
+			var te *workflow.WorkflowTimeoutError
+			require.ErrorAs(t, err, &te)
+			require.Equal(t, workflow.TimeoutTypeRun, te.TimeoutType)
+
+			historyContains(ctx, t, b, instance,
+				history.EventType_WorkflowExecutionStarted,
+				history.EventType_WorkflowExecutionTimedOut,
+				history.EventType_WorkflowExecutionFinished,
+			)
+		},
+	},
+	{
+		name: "WorkflowTimeout/FinishedBeforeTimeout",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			wf := func(ctx workflow.Context) (int, error) {
+				return 42, nil
+			}
+			register(t, ctx, w, []interface{}{wf}, nil)
+
+			instance, err := c.CreateWorkflowInstance(ctx, client.WorkflowInstanceOptions{
+				InstanceID:       uuid.NewString(),
+				ExecutionTimeout: time.Minute,
+				RunTimeout:       time.Minute,
+			}, wf)
+			require.NoError(t, err)
+
+			r, err := client.GetWorkflowResult[int](ctx, c, instance, time.Second*5)
+			require.NoError(t, err)
+			require.Equal(t, 42, r)
+
+			// The timeout event has been removed
+			futureEvents, err := b.GetFutureEvents(ctx)
+			require.NoError(t, err)
+			require.Len(t, futureEvents, 0)
+		},
+	},
+	{
+		name: "WorkflowTimeout/SubWorkflowRunTimeout",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			swf := func(ctx workflow.Context) error {
+				workflow.NewSignalChannel[string](ctx, "never").Receive(ctx)
+				return nil
+			}
+			wf := func(ctx workflow.Context) (bool, error) {
+				_, err := workflow.CreateSubWorkflowInstance[any](ctx, workflow.SubWorkflowOptions{
+					RunTimeout: 200 * time.Millisecond,
+				}, swf).Get(ctx)
+
+				var te *workflow.WorkflowTimeoutError
+				if errors.As(err, &te) {
+					return te.TimeoutType == workflow.TimeoutTypeRun, nil
+				}
+
+				return false, err
+			}
+			register(t, ctx, w, []interface{}{wf, swf}, nil)
+
+			r, err := runWorkflowWithResult[bool](t, ctx, c, wf)
+			require.NoError(t, err)
+			require.True(t, r)
+		},
+	},
+	{
+		name: "WorkflowTimeout/ExecutionTimeoutSpansContinueAsNew",
+		f: func(t *testing.T, ctx context.Context, c *client.Client, w *worker.Worker, b TestBackend) {
+			swf := func(ctx workflow.Context, run int) (int, error) {
+				if err := workflow.Sleep(ctx, 50*time.Millisecond); err != nil {
+					return 0, err
+				}
+
+				return run, workflow.ContinueAsNew(ctx, run+1)
+			}
+			wf := func(ctx workflow.Context) (bool, error) {
+				_, err := workflow.CreateSubWorkflowInstance[int](ctx, workflow.SubWorkflowOptions{
+					ExecutionTimeout: 500 * time.Millisecond,
+				}, swf, 0).Get(ctx)
+
+				var te *workflow.WorkflowTimeoutError
+				if errors.As(err, &te) {
+					return te.TimeoutType == workflow.TimeoutTypeExecution, nil
+				}
+
+				return false, err
+			}
+			register(t, ctx, w, []interface{}{wf, swf}, nil)
+
+			r, err := runWorkflowWithResult[bool](t, ctx, c, wf)
+			require.NoError(t, err)
+			require.True(t, r)
+		},
+	},
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/backend/converter"
 	"github.com/cschleiden/go-workflows/backend/monoprocess"
 	"github.com/cschleiden/go-workflows/backend/mysql"
 	"github.com/cschleiden/go-workflows/backend/redis"
@@ -32,10 +33,18 @@ var activities = flag.Int("activities", 2, "Number of activities to execute per
 var resultSize = flag.Int("resultsize", 100, "Size of activity result payload in bytes")
 var format = flag.String("format", "text", "Output format. Supported formats are:\n- text\n- csv\n")
 var cacheSize = flag.Int("cachesize", 128, "Size of the workflow executor cache")
+var conv = flag.String("converter", "json", "Converter to use for payloads. Supported converters are:\n- json\n- msgpack\n- proto\n")
 
 func main() {
 	flag.Parse()
 
+	cv, err := getConverter(*conv)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
 	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(*timeout).Add(time.Second*5))
 	defer cancel()
 
@@ -43,6 +52,7 @@ func main() {
 	ba := getBackend(*b,
 		backend.WithLogger(slog.New(&nullHandler{})),
 		backend.WithMetrics(mm),
+		backend.WithConverter(cv),
 	)
 	defer ba.Close()
 
@@ -111,6 +121,22 @@ func main() {
 	}
 }
 
+func getConverter(c string) (converter.Converter, error) {
+	switch c {
+	case "json":
+		return converter.DefaultConverter, nil
+
+	case "msgpack":
+		return converter.NewMessagePackConverter(), nil
+
+	case "proto":
+		return converter.NewProtoConverter(), nil
+
+	default:
+		return nil, fmt.Errorf("unknown converter %q, supported converters are: json, msgpack, proto", c)
+	}
+}
+
 func getBackend(b string, opt ...backend.BackendOption) backend.Backend {
 	switch b {
 	case "memory":
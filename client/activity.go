@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/internal/activity"
+	"github.com/cschleiden/go-workflows/internal/log"
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CompleteActivity completes an activity that returned activity.ErrResultPending, identified by the token it
+// obtained with activity.TaskToken. If err is not nil, the activity fails with the given error and is retried
+// according to its RetryOptions, otherwise result is returned to the workflow.
+func (c *Client) CompleteActivity(ctx context.Context, token string, result any, err error) error {
+	task, tokenErr := activity.DecodeTaskToken(token)
+	if tokenErr != nil {
+		return tokenErr
+	}
+
+	ctx, span := c.backend.Tracer().Start(ctx, "CompleteActivity", trace.WithAttributes(
+		attribute.String(log.InstanceIDKey, task.WorkflowInstance.InstanceID),
+		attribute.String(log.ActivityIDKey, task.ActivityID),
+	))
+	defer span.End()
+
+	cv := c.converter(ctx)
+
+	var event *history.Event
+	if err != nil {
+		wfErr, encErr := workflowerrors.EncodeError(cv, workflowerrors.FromError(err))
+		if encErr != nil {
+			return fmt.Errorf("encoding activity error: %w", encErr)
+		}
+
+		event = history.NewPendingEvent(
+			c.clock.Now(),
+			history.EventType_ActivityFailed,
+			&history.ActivityFailedAttributes{
+				Error: wfErr,
+			},
+			history.ScheduleEventID(task.Event.ScheduleEventID),
+		)
+	} else {
+		r, convErr := cv.To(result)
+		if convErr != nil {
+			return fmt.Errorf("converting activity result: %w", convErr)
+		}
+
+		event = history.NewPendingEvent(
+			c.clock.Now(),
+			history.EventType_ActivityCompleted,
+			&history.ActivityCompletedAttributes{
+				Result: r,
+			},
+			history.ScheduleEventID(task.Event.ScheduleEventID),
+		)
+	}
+
+	if err := c.backend.CompleteActivityTask(ctx, task, event); err != nil {
+		return fmt.Errorf("completing activity: %w", err)
+	}
+
+	return nil
+}
@@ -9,8 +9,10 @@ import (
 	"github.com/benbjohnson/clock"
 	"github.com/cenkalti/backoff/v4"
 	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/backend/converter"
 	"github.com/cschleiden/go-workflows/backend/history"
 	"github.com/cschleiden/go-workflows/backend/metrics"
+	"github.com/cschleiden/go-workflows/backend/payload"
 	"github.com/cschleiden/go-workflows/core"
 	a "github.com/cschleiden/go-workflows/internal/args"
 	"github.com/cschleiden/go-workflows/internal/fn"
@@ -18,6 +20,7 @@ import (
 	"github.com/cschleiden/go-workflows/internal/metrickeys"
 	"github.com/cschleiden/go-workflows/internal/tracing"
 	"github.com/cschleiden/go-workflows/internal/workflowerrors"
+	"github.com/cschleiden/go-workflows/registry"
 	"github.com/cschleiden/go-workflows/workflow"
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/attribute"
@@ -30,29 +33,199 @@ var ErrWorkflowCanceled = errors.New("workflow canceled")
 // ErrWorkflowTerminated is returned when a workflow was already terminated.
 var ErrWorkflowTerminated = errors.New("workflow terminated")
 
+// ErrPreviousExecutionRunning is returned by CreateWorkflowInstance with IDReusePolicyCancelIfRunning if the
+// canceled execution did not finish before the context was done.
+var ErrPreviousExecutionRunning = errors.New("previous workflow execution is still running")
+
+// IDReusePolicy controls whether a workflow instance can be created when an execution with the same instance ID
+// already exists.
+type IDReusePolicy = core.IDReusePolicy
+
+const (
+	IDReusePolicyAllowDuplicate           = core.IDReusePolicyAllowDuplicate
+	IDReusePolicyRejectDuplicate          = core.IDReusePolicyRejectDuplicate
+	IDReusePolicyAllowDuplicateFailedOnly = core.IDReusePolicyAllowDuplicateFailedOnly
+	IDReusePolicyCancelIfRunning          = core.IDReusePolicyCancelIfRunning
+)
+
 type WorkflowInstanceOptions struct {
 	// Queue is the queue the workflow instance will be created in. Must be a valid queue
 	// for the given backend. If not set, will default to the default queue
 	Queue workflow.Queue
 
 	InstanceID string
+
+	// ExecutionTimeout is the maximum time the workflow instance can run, including all executions
+	// continued via ContinueAsNew. Defaults to 0, which means no timeout.
+	ExecutionTimeout time.Duration
+
+	// RunTimeout is the maximum time a single execution of the workflow instance can run. Defaults to 0,
+	// which means no timeout.
+	RunTimeout time.Duration
+
+	// StartDelay delays the start of the workflow instance. The instance is created right away and its ID is
+	// reserved, but the workflow only starts executing after the delay. Cannot be combined with StartAt.
+	StartDelay time.Duration
+
+	// StartAt is the time the workflow instance starts executing. See StartDelay.
+	StartAt time.Time
+
+	// IDReusePolicy controls what happens if an execution with the same InstanceID already exists. Independent
+	// of the policy, there can only ever be one active execution for an instance ID. Defaults to
+	// IDReusePolicyAllowDuplicate.
+	IDReusePolicy IDReusePolicy
+
+	// SearchAttributes are custom, indexed attributes the workflow instance is created with. Instances can be
+	// listed by them with ListWorkflowInstances, and workflows can update them with
+	// workflow.UpsertSearchAttributes.
+	SearchAttributes workflow.SearchAttributes
+
+	// RetryOptions define whether a new execution of the workflow instance is started, with the same inputs,
+	// if an execution fails. Each attempt is a separate execution continued from the failed one, the attempt
+	// is available to the workflow via workflow.Attempt. The retry timeout includes all executions of the
+	// instance. Backoff is not supported. Defaults to no retries.
+	RetryOptions workflow.RetryOptions
 }
 
 type Client struct {
-	backend backend.Backend
-	clock   clock.Clock
+	backend  backend.Backend
+	clock    clock.Clock
+	registry *registry.Registry
 }
 
 // New creates a new client for the given backend.
-func New(backend backend.Backend) *Client {
-	return &Client{
+func New(backend backend.Backend, opts ...Option) *Client {
+	c := &Client{
 		backend: backend,
 		clock:   clock.New(),
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
 
 // CreateWorkflowInstance creates a new workflow instance of the given workflow.
 func (c *Client) CreateWorkflowInstance(ctx context.Context, options WorkflowInstanceOptions, wf workflow.Workflow, args ...any) (*workflow.Instance, error) {
+	workflowName, inputs, err := c.workflowInputs(ctx, wf, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateWorkflowInstanceOptions(&options); err != nil {
+		return nil, err
+	}
+
+	if err := c.applyIDReusePolicy(ctx, options.InstanceID, options.IDReusePolicy); err != nil {
+		return nil, err
+	}
+
+	wfi := core.NewWorkflowInstance(options.InstanceID, uuid.NewString())
+
+	// Span for creating the workflow instance
+	ctx, span := c.backend.Tracer().Start(ctx, "CreateWorkflowInstance", trace.WithAttributes(
+		attribute.String(log.InstanceIDKey, wfi.InstanceID),
+		attribute.String(log.ExecutionIDKey, wfi.ExecutionID),
+		attribute.String(log.WorkflowNameKey, workflowName),
+	), trace.WithSpanKind(trace.SpanKindProducer))
+	defer span.End()
+
+	startedEvent, err := c.newStartedEvent(ctx, options, workflowName, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.backend.CreateWorkflowInstance(ctx, wfi, startedEvent); err != nil {
+		return nil, fmt.Errorf("creating workflow instance: %w", err)
+	}
+
+	c.backend.Options().Logger.Debug(
+		"Created workflow instance",
+		log.InstanceIDKey, wfi.InstanceID,
+		log.ExecutionIDKey, wfi.ExecutionID,
+		log.WorkflowNameKey, workflowName,
+	)
+
+	c.backend.Metrics().Counter(metrickeys.WorkflowInstanceCreated, metrics.Tags{}, 1)
+
+	return wfi, nil
+}
+
+// SignalWithStartWorkflow signals the active execution of the workflow instance with options.InstanceID. If
+// there is no active execution, a new instance of the given workflow is created and receives the signal as its
+// first event after starting. Both happen atomically in the backend, unlike calling SignalWorkflow and
+// CreateWorkflowInstance one after the other.
+//
+// Returns the instance that received the signal. IDReusePolicyCancelIfRunning is not supported here.
+func (c *Client) SignalWithStartWorkflow(ctx context.Context, options WorkflowInstanceOptions, signalName string, signalArg any, wf workflow.Workflow, args ...any) (*workflow.Instance, error) {
+	workflowName, inputs, err := c.workflowInputs(ctx, wf, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateWorkflowInstanceOptions(&options); err != nil {
+		return nil, err
+	}
+
+	// The policy only applies if a new execution is created, an active execution always receives the signal
+	if options.IDReusePolicy == IDReusePolicyCancelIfRunning {
+		return nil, errors.New("SignalWithStartWorkflow does not support IDReusePolicyCancelIfRunning")
+	}
+
+	wfi := core.NewWorkflowInstance(options.InstanceID, uuid.NewString())
+
+	ctx, span := c.backend.Tracer().Start(ctx, "SignalWithStartWorkflow", trace.WithAttributes(
+		attribute.String(log.InstanceIDKey, wfi.InstanceID),
+		attribute.String(log.WorkflowNameKey, workflowName),
+		attribute.String(log.SignalNameKey, signalName),
+	), trace.WithSpanKind(trace.SpanKindProducer))
+	defer span.End()
+
+	startedEvent, err := c.newStartedEvent(ctx, options, workflowName, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := c.converter(ctx).To(signalArg)
+	if err != nil {
+		return nil, fmt.Errorf("converting signal argument: %w", err)
+	}
+
+	signalEvent := history.NewPendingEvent(
+		c.clock.Now(),
+		history.EventType_SignalReceived,
+		&history.SignalReceivedAttributes{
+			Name: signalName,
+			Arg:  input,
+		},
+	)
+
+	instance, err := c.backend.SignalWithStartWorkflowInstance(ctx, wfi, startedEvent, signalEvent)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("signaling workflow instance: %w", err)
+	}
+
+	if instance.ExecutionID == wfi.ExecutionID {
+		c.backend.Options().Logger.Debug(
+			"Created workflow instance",
+			log.InstanceIDKey, wfi.InstanceID,
+			log.ExecutionIDKey, wfi.ExecutionID,
+			log.WorkflowNameKey, workflowName,
+		)
+
+		c.backend.Metrics().Counter(metrickeys.WorkflowInstanceCreated, metrics.Tags{}, 1)
+	}
+
+	c.backend.Options().Logger.Debug("Signaled workflow instance", log.InstanceIDKey, instance.InstanceID)
+
+	return instance, nil
+}
+
+// workflowInputs returns the name of the given workflow and the converted arguments.
+func (c *Client) workflowInputs(ctx context.Context, wf workflow.Workflow, args ...any) (string, []payload.Payload, error) {
 	var workflowName string
 
 	if name, ok := wf.(string); ok {
@@ -62,33 +235,46 @@ func (c *Client) CreateWorkflowInstance(ctx context.Context, options WorkflowIns
 
 		// Check arguments if actual workflow function given here
 		if err := a.ParamsMatch(wf, args...); err != nil {
-			return nil, err
+			return "", nil, err
 		}
 	}
 
-	inputs, err := a.ArgsToInputs(c.backend.Options().Converter, args...)
+	inputs, err := a.ArgsToInputs(c.converter(ctx), args...)
 	if err != nil {
-		return nil, fmt.Errorf("converting arguments: %w", err)
+		return "", nil, fmt.Errorf("converting arguments: %w", err)
 	}
 
+	return workflowName, inputs, nil
+}
+
+// converter returns the converter of the backend, using the given context for I/O during conversion.
+func (c *Client) converter(ctx context.Context) converter.Converter {
+	return converter.WithContext(ctx, c.backend.Options().Converter)
+}
+
+func validateWorkflowInstanceOptions(options *WorkflowInstanceOptions) error {
 	if options.InstanceID == "" {
-		return nil, errors.New("InstanceID must be set")
+		return errors.New("InstanceID must be set")
 	}
 
 	if options.Queue == "" {
 		options.Queue = workflow.QueueDefault
 	}
 
-	wfi := core.NewWorkflowInstance(options.InstanceID, uuid.NewString())
-	metadata := &workflow.Metadata{}
+	if options.StartDelay != 0 && !options.StartAt.IsZero() {
+		return errors.New("only one of StartDelay and StartAt can be set")
+	}
 
-	// Span for creating the workflow instance
-	ctx, span := c.backend.Tracer().Start(ctx, "CreateWorkflowInstance", trace.WithAttributes(
-		attribute.String(log.InstanceIDKey, wfi.InstanceID),
-		attribute.String(log.ExecutionIDKey, wfi.ExecutionID),
-		attribute.String(log.WorkflowNameKey, workflowName),
-	), trace.WithSpanKind(trace.SpanKindProducer))
-	defer span.End()
+	if options.RetryOptions.Backoff != nil {
+		return errors.New("custom backoff is not supported for workflow instances")
+	}
+
+	return nil
+}
+
+// newStartedEvent returns the event starting a new execution with the given options.
+func (c *Client) newStartedEvent(ctx context.Context, options WorkflowInstanceOptions, workflowName string, inputs []payload.Payload) (*history.Event, error) {
+	metadata := &workflow.Metadata{}
 
 	// Inject state from any propagators
 	for _, propagator := range c.backend.Options().ContextPropagators {
@@ -97,33 +283,127 @@ func (c *Client) CreateWorkflowInstance(ctx context.Context, options WorkflowIns
 		}
 	}
 
+	searchAttributes, err := options.SearchAttributes.Encode()
+	if err != nil {
+		return nil, fmt.Errorf("invalid search attributes: %w", err)
+	}
+
 	workflowSpanID := tracing.GetNewSpanID(c.backend.Tracer())
 
-	startedEvent := history.NewPendingEvent(
-		c.clock.Now(),
+	now := c.clock.Now()
+
+	startAt := options.StartAt
+	if options.StartDelay > 0 {
+		startAt = now.Add(options.StartDelay)
+	}
+
+	// The workflow starts executing once the started event becomes visible
+	startedAt := now
+	var eventOpts []history.HistoryEventOption
+	if startAt.After(now) {
+		startedAt = startAt
+		eventOpts = append(eventOpts, history.VisibleAt(startedAt))
+	}
+
+	var executionDeadline *time.Time
+	if options.ExecutionTimeout > 0 {
+		d := startedAt.Add(options.ExecutionTimeout)
+		executionDeadline = &d
+	}
+
+	var retryPolicy *history.RetryPolicy
+	if ro := options.RetryOptions; ro.MaxAttempts > 1 {
+		retryPolicy = &history.RetryPolicy{
+			MaxAttempts:            ro.MaxAttempts,
+			FirstRetryInterval:     ro.FirstRetryInterval,
+			MaxRetryInterval:       ro.MaxRetryInterval,
+			BackoffCoefficient:     ro.BackoffCoefficient,
+			NonRetryableErrorTypes: ro.NonRetryableErrorTypes,
+			Jitter:                 ro.Jitter,
+		}
+
+		if ro.RetryTimeout > 0 {
+			d := startedAt.Add(ro.RetryTimeout)
+			retryPolicy.Deadline = &d
+		}
+	}
+
+	return history.NewPendingEvent(
+		startedAt,
 		history.EventType_WorkflowExecutionStarted,
 		&history.ExecutionStartedAttributes{
-			Queue:          options.Queue,
-			Metadata:       metadata,
-			Name:           workflowName,
-			Inputs:         inputs,
-			WorkflowSpanID: workflowSpanID,
-		})
+			Queue:             options.Queue,
+			Metadata:          metadata,
+			Name:              workflowName,
+			Inputs:            inputs,
+			WorkflowSpanID:    workflowSpanID,
+			RunTimeout:        options.RunTimeout,
+			ExecutionDeadline: executionDeadline,
+			SearchAttributes:  searchAttributes,
+			RetryPolicy:       retryPolicy,
+			IDReusePolicy:     options.IDReusePolicy,
+		},
+		eventOpts...), nil
+}
 
-	if err := c.backend.CreateWorkflowInstance(ctx, wfi, startedEvent); err != nil {
-		return nil, fmt.Errorf("creating workflow instance: %w", err)
+// applyIDReusePolicy cancels the running execution of the given instance ID and waits for it to finish, if the
+// policy asks for it. All other policies are checked by the backend when the new execution is created.
+//
+// Canceling is not atomic with creating the new execution. It needs a worker to process the cancellation and
+// waits until ctx is done, another execution created in the meantime is still rejected by the backend.
+func (c *Client) applyIDReusePolicy(ctx context.Context, instanceID string, policy IDReusePolicy) error {
+	if policy != IDReusePolicyCancelIfRunning {
+		return nil
 	}
 
-	c.backend.Options().Logger.Debug(
-		"Created workflow instance",
-		log.InstanceIDKey, wfi.InstanceID,
-		log.ExecutionIDKey, wfi.ExecutionID,
-		log.WorkflowNameKey, workflowName,
-	)
+	latest, err := c.backend.GetLatestWorkflowInstance(ctx, instanceID)
+	if err != nil {
+		if errors.Is(err, backend.ErrInstanceNotFound) {
+			return nil
+		}
 
-	c.backend.Metrics().Counter(metrickeys.WorkflowInstanceCreated, metrics.Tags{}, 1)
+		return fmt.Errorf("getting latest workflow instance: %w", err)
+	}
 
-	return wfi, nil
+	state, err := c.backend.GetWorkflowInstanceState(ctx, latest)
+	if err != nil {
+		return fmt.Errorf("getting workflow instance state: %w", err)
+	}
+
+	if state != core.WorkflowInstanceStateActive {
+		return nil
+	}
+
+	if err := c.CancelWorkflowInstance(ctx, latest); err != nil {
+		return fmt.Errorf("canceling running workflow instance: %w", err)
+	}
+
+	// Wait as long as the caller allows, without a timeout of our own
+	b := backoff.ExponentialBackOff{
+		InitialInterval:     time.Millisecond * 1,
+		MaxInterval:         time.Second * 1,
+		Multiplier:          1.5,
+		RandomizationFactor: 0.5,
+		Stop:                backoff.Stop,
+		Clock:               c.clock,
+	}
+	b.Reset()
+
+	ticker := backoff.NewTicker(backoff.WithContext(&b, ctx))
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s, err := c.backend.GetWorkflowInstanceState(ctx, latest)
+		if err != nil {
+			return fmt.Errorf("getting workflow instance state: %w", err)
+		}
+
+		if s != core.WorkflowInstanceStateActive {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %w", ErrPreviousExecutionRunning, ctx.Err())
 }
 
 // CancelWorkflowInstance cancels a running workflow instance.
@@ -137,6 +417,86 @@ func (c *Client) CancelWorkflowInstance(ctx context.Context, instance *workflow.
 	return c.backend.CancelWorkflowInstance(ctx, instance, cancellationEvent)
 }
 
+// TerminateWorkflowInstance terminates a running workflow instance.
+//
+// Unlike cancellation, termination does not execute any more workflow code. The instance is finished
+// immediately and pending activities and timers are abandoned. Use TerminateSubWorkflows to also terminate
+// any sub-workflow instances that are still running.
+func (c *Client) TerminateWorkflowInstance(ctx context.Context, instance *workflow.Instance, reason string, opts ...TerminateOption) error {
+	options := TerminateOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	ctx, span := c.backend.Tracer().Start(ctx, "TerminateWorkflowInstance", trace.WithAttributes(
+		attribute.String(log.InstanceIDKey, instance.InstanceID),
+	))
+	defer span.End()
+
+	// Collect running sub-workflows before terminating the instance, the history does not change afterwards
+	var subWorkflows []*workflow.Instance
+	if options.SubWorkflows {
+		var err error
+		subWorkflows, err = c.runningSubWorkflows(ctx, instance)
+		if err != nil {
+			return fmt.Errorf("getting sub-workflows: %w", err)
+		}
+	}
+
+	terminationEvent := history.NewWorkflowTerminationEvent(c.clock.Now(), reason)
+	if err := c.backend.TerminateWorkflowInstance(ctx, instance, terminationEvent); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	c.backend.Options().Logger.Debug("Terminated workflow instance", log.InstanceIDKey, instance.InstanceID)
+
+	for _, subWorkflow := range subWorkflows {
+		if err := c.TerminateWorkflowInstance(ctx, subWorkflow, reason, opts...); err != nil {
+			// Sub-workflow might have finished in the meantime
+			if errors.Is(err, backend.ErrInstanceNotActive) || errors.Is(err, backend.ErrInstanceNotFound) {
+				continue
+			}
+
+			return fmt.Errorf("terminating sub-workflow instance %v: %w", subWorkflow.InstanceID, err)
+		}
+	}
+
+	return nil
+}
+
+// runningSubWorkflows returns the sub-workflow instances scheduled by the given instance which have not
+// yet completed or failed.
+func (c *Client) runningSubWorkflows(ctx context.Context, instance *workflow.Instance) ([]*workflow.Instance, error) {
+	h, err := c.backend.GetWorkflowInstanceHistory(ctx, instance, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled := map[int64]*workflow.Instance{}
+	order := []int64{}
+	for _, event := range h {
+		switch event.Type {
+		case history.EventType_SubWorkflowScheduled:
+			a := event.Attributes.(*history.SubWorkflowScheduledAttributes)
+			scheduled[event.ScheduleEventID] = a.SubWorkflowInstance
+			order = append(order, event.ScheduleEventID)
+
+		case history.EventType_SubWorkflowCompleted, history.EventType_SubWorkflowFailed:
+			delete(scheduled, event.ScheduleEventID)
+		}
+	}
+
+	running := make([]*workflow.Instance, 0, len(scheduled))
+	for _, id := range order {
+		if i, ok := scheduled[id]; ok {
+			running = append(running, i)
+		}
+	}
+
+	return running, nil
+}
+
 // SignalWorkflow signals a running workflow instance.
 func (c *Client) SignalWorkflow(ctx context.Context, instanceID string, name string, arg any) error {
 	ctx, span := c.backend.Tracer().Start(ctx, "SignalWorkflow", trace.WithAttributes(
@@ -145,7 +505,7 @@ func (c *Client) SignalWorkflow(ctx context.Context, instanceID string, name str
 	))
 	defer span.End()
 
-	input, err := c.backend.Options().Converter.To(arg)
+	input, err := c.converter(ctx).To(arg)
 	if err != nil {
 		return fmt.Errorf("converting arguments: %w", err)
 	}
@@ -170,8 +530,18 @@ func (c *Client) SignalWorkflow(ctx context.Context, instanceID string, name str
 	return nil
 }
 
-// GetWorkflowInstanceState returns the current state of the given workflow instance
+// GetWorkflowInstanceState returns the current state of the given workflow instance. If no ExecutionID is
+// given, the state of the latest execution of the instance is returned.
 func (c *Client) GetWorkflowInstanceState(ctx context.Context, instance *workflow.Instance) (core.WorkflowInstanceState, error) {
+	if instance.ExecutionID == "" {
+		latest, err := c.backend.GetLatestWorkflowInstance(ctx, instance.InstanceID)
+		if err != nil {
+			return core.WorkflowInstanceStateActive, err
+		}
+
+		instance = latest
+	}
+
 	return c.backend.GetWorkflowInstanceState(ctx, instance)
 }
 
@@ -228,6 +598,8 @@ func GetWorkflowResult[T any](ctx context.Context, c *Client, instance *workflow
 		return *new(T), fmt.Errorf("workflow did not finish in time: %w", err)
 	}
 
+	cv := c.converter(ctx)
+
 	h, err := b.GetWorkflowInstanceHistory(ctx, instance, nil) // future: could optimize this by retriving only the very last entry in the history
 	if err != nil {
 		return *new(T), fmt.Errorf("getting workflow history: %w", err)
@@ -240,11 +612,16 @@ func GetWorkflowResult[T any](ctx context.Context, c *Client, instance *workflow
 		case history.EventType_WorkflowExecutionFinished:
 			a := event.Attributes.(*history.ExecutionCompletedAttributes)
 			if a.Error != nil {
-				return *new(T), workflowerrors.ToError(a.Error)
+				decodedErr, err := workflowerrors.DecodeError(cv, a.Error)
+				if err != nil {
+					return *new(T), fmt.Errorf("decoding error: %w", err)
+				}
+
+				return *new(T), workflowerrors.ToError(decodedErr)
 			}
 
 			var r T
-			if err := b.Options().Converter.From(a.Result, &r); err != nil {
+			if err := cv.From(a.Result, &r); err != nil {
 				return *new(T), fmt.Errorf("converting result: %w", err)
 			}
 
@@ -252,9 +629,14 @@ func GetWorkflowResult[T any](ctx context.Context, c *Client, instance *workflow
 
 		case history.EventType_WorkflowExecutionContinuedAsNew:
 			a := event.Attributes.(*history.ExecutionContinuedAsNewAttributes)
+			if a.Error != nil {
+				// The execution failed and was retried, the result is the result of the retry
+				return GetWorkflowResult[T](
+					ctx, c, core.NewWorkflowInstance(instance.InstanceID, a.ContinuedExecutionID), timeout)
+			}
 
 			var r T
-			if err := b.Options().Converter.From(a.Result, &r); err != nil {
+			if err := cv.From(a.Result, &r); err != nil {
 				return *new(T), fmt.Errorf("converting result: %w", err)
 			}
 
@@ -290,3 +672,31 @@ func (c *Client) RemoveWorkflowInstances(ctx context.Context, options ...backend
 
 	return c.backend.RemoveWorkflowInstances(ctx, options...)
 }
+
+type (
+	// WorkflowInstanceQuery filters the workflow instances returned by ListWorkflowInstances.
+	WorkflowInstanceQuery = backend.WorkflowInstanceQuery
+
+	// WorkflowInstanceInfo describes a workflow instance returned by ListWorkflowInstances.
+	WorkflowInstanceInfo = backend.WorkflowInstanceInfo
+
+	// WorkflowInstanceQueryResult is a page of workflow instances returned by ListWorkflowInstances.
+	WorkflowInstanceQueryResult = backend.WorkflowInstanceQueryResult
+)
+
+// ListWorkflowInstances returns the workflow instances matching the given query, newest first. Pass the
+// NextCursor of a result as the Cursor of the query to retrieve the next page.
+//
+// The TotalCount of the result is backend.TotalCountUnknown if the backend can't count the matching instances
+// without scanning them. The Redis backend only counts instances for queries filtering by at most one of
+// workflow name, queue, parent instance, or search attribute, plus the creation time.
+func (c *Client) ListWorkflowInstances(ctx context.Context, query *WorkflowInstanceQuery) (*WorkflowInstanceQueryResult, error) {
+	ctx, span := c.backend.Tracer().Start(ctx, "ListWorkflowInstances")
+	defer span.End()
+
+	if query == nil {
+		query = &WorkflowInstanceQuery{}
+	}
+
+	return c.backend.ListWorkflowInstances(ctx, query)
+}
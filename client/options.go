@@ -0,0 +1,13 @@
+package client
+
+import "github.com/cschleiden/go-workflows/registry"
+
+type Option func(c *Client)
+
+// WithRegistry sets the registry of workflows the client uses to replay workflow instances, for example
+// when querying them. Usually this is the registry of a worker, see worker.Worker.Registry.
+func WithRegistry(r *registry.Registry) Option {
+	return func(c *Client) {
+		c.registry = r
+	}
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/cschleiden/go-workflows/backend/converter"
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/backend/metadata"
+	a "github.com/cschleiden/go-workflows/internal/args"
+	"github.com/cschleiden/go-workflows/internal/log"
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/cschleiden/go-workflows/workflow/executor"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrNoRegistry is returned when an operation requires the workflow code, but the client was created
+// without a registry.
+var ErrNoRegistry = errors.New("client has no registry, see WithRegistry")
+
+// QueryWorkflow queries the state of the given workflow instance using the query handler registered by the
+// workflow for the given name.
+//
+// The query is answered by replaying the history of the workflow instance in this process, the workflow has
+// to be registered with the registry of the client. Nothing is added to the history of the instance.
+func QueryWorkflow[T any](ctx context.Context, c *Client, instance *workflow.Instance, name string, args ...any) (T, error) {
+	var z T
+
+	if c.registry == nil {
+		return z, ErrNoRegistry
+	}
+
+	ctx, span := c.backend.Tracer().Start(ctx, "QueryWorkflow", trace.WithAttributes(
+		attribute.String(log.InstanceIDKey, instance.InstanceID),
+		attribute.String(log.QueryNameKey, name),
+	))
+	defer span.End()
+
+	cv := c.converter(ctx)
+
+	// Query arguments are not recorded in the history, don't offload them
+	inputs, err := a.ArgsToInputs(converter.WithoutOffloading(cv), args...)
+	if err != nil {
+		return z, fmt.Errorf("converting query arguments: %w", err)
+	}
+
+	h, err := c.backend.GetWorkflowInstanceHistory(ctx, instance, nil)
+	if err != nil {
+		return z, fmt.Errorf("getting workflow history: %w", err)
+	}
+
+	e, err := c.newReplayExecutor(instance, h)
+	if err != nil {
+		return z, err
+	}
+	defer e.Close()
+
+	result, err := e.Query(ctx, h, name, inputs)
+	if err != nil {
+		return z, fmt.Errorf("querying workflow: %w", err)
+	}
+
+	var r T
+	if err := cv.From(result, &r); err != nil {
+		return z, fmt.Errorf("converting query result: %w", err)
+	}
+
+	return r, nil
+}
+
+// newReplayExecutor creates an executor for replaying the given history of the workflow instance in this process
+func (c *Client) newReplayExecutor(instance *workflow.Instance, h []*history.Event) (executor.WorkflowExecutor, error) {
+	md := &metadata.WorkflowMetadata{}
+	if len(h) > 0 && h[0].Type == history.EventType_WorkflowExecutionStarted {
+		if a := h[0].Attributes.(*history.ExecutionStartedAttributes); a.Metadata != nil {
+			md = a.Metadata
+		}
+	}
+
+	logger := c.backend.Options().Logger.With(
+		slog.String(log.InstanceIDKey, instance.InstanceID),
+		slog.String(log.ExecutionIDKey, instance.ExecutionID),
+	)
+
+	e, err := executor.NewExecutor(
+		logger,
+		c.backend.Tracer(),
+		c.registry,
+		c.backend.Options().Converter,
+		c.backend.Options().ContextPropagators,
+		c.backend,
+		instance,
+		md,
+		c.clock,
+		c.backend.Options().MaxHistorySize,
+		executor.NonDeterminismPolicyFailWorkflow,
+		executor.Options{
+			MaxHistorySizeBytes:             c.backend.Options().MaxHistorySizeBytes,
+			ContinueAsNewSuggestedThreshold: c.backend.Options().ContinueAsNewSuggestedThreshold,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating workflow executor: %w", err)
+	}
+
+	return e, nil
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cschleiden/go-workflows/core"
+	a "github.com/cschleiden/go-workflows/internal/args"
+	"github.com/cschleiden/go-workflows/internal/fn"
+	"github.com/cschleiden/go-workflows/internal/workflows"
+	"github.com/cschleiden/go-workflows/workflow"
+)
+
+type (
+	ScheduleSpec        = workflows.ScheduleSpec
+	OverlapPolicy       = workflows.OverlapPolicy
+	ScheduleDescription = workflows.ScheduleDescription
+)
+
+const (
+	OverlapPolicySkip   = workflows.OverlapPolicySkip
+	OverlapPolicyBuffer = workflows.OverlapPolicyBuffer
+	OverlapPolicyAllow  = workflows.OverlapPolicyAllow
+)
+
+// ScheduleInstanceID returns the instance ID of the system workflow backing the schedule with the given id.
+func ScheduleInstanceID(id string) string {
+	return workflows.ScheduleInstanceIDPrefix + id
+}
+
+// CreateSchedule creates a schedule that starts a new instance of the given workflow with the given arguments
+// every time the schedule fires. It returns the instance of the system workflow backing the schedule.
+//
+// Schedules are implemented as a system workflow running on the system queue, so a worker needs to be running
+// for them to fire. Instances started by the schedule have the ID "<id>-<fire time in unix milliseconds>".
+func (c *Client) CreateSchedule(ctx context.Context, id string, spec ScheduleSpec, wf workflow.Workflow, args ...any) (*workflow.Instance, error) {
+	if id == "" {
+		return nil, errors.New("schedule id must be set")
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid schedule spec: %w", err)
+	}
+
+	var workflowName string
+	if name, ok := wf.(string); ok {
+		workflowName = name
+	} else {
+		workflowName = fn.Name(wf)
+
+		if err := a.ParamsMatch(wf, args...); err != nil {
+			return nil, err
+		}
+	}
+
+	inputs, err := a.ArgsToInputs(c.converter(ctx), args...)
+	if err != nil {
+		return nil, fmt.Errorf("converting arguments: %w", err)
+	}
+
+	state := &workflows.ScheduleState{
+		ID:        id,
+		Spec:      spec,
+		Workflow:  workflowName,
+		Inputs:    inputs,
+		CreatedAt: c.clock.Now(),
+	}
+
+	instance, err := c.CreateWorkflowInstance(ctx, WorkflowInstanceOptions{
+		InstanceID: ScheduleInstanceID(id),
+		Queue:      core.QueueSystem,
+	}, workflows.ScheduleWorkflowInstances, state)
+	if err != nil {
+		return nil, fmt.Errorf("creating schedule: %w", err)
+	}
+
+	return instance, nil
+}
+
+// PauseSchedule pauses the schedule with the given id. A paused schedule does not fire until it's resumed.
+func (c *Client) PauseSchedule(ctx context.Context, id string) error {
+	return c.signalSchedule(ctx, id, workflows.PauseScheduleSignal)
+}
+
+// ResumeSchedule resumes a paused schedule. Executions missed while the schedule was paused are skipped.
+func (c *Client) ResumeSchedule(ctx context.Context, id string) error {
+	return c.signalSchedule(ctx, id, workflows.ResumeScheduleSignal)
+}
+
+// TriggerSchedule starts a new instance of the scheduled workflow right away, regardless of the overlap policy
+// and whether the schedule is paused. The regular schedule is not affected.
+func (c *Client) TriggerSchedule(ctx context.Context, id string) error {
+	return c.signalSchedule(ctx, id, workflows.TriggerScheduleSignal)
+}
+
+// DeleteSchedule deletes the schedule with the given id. It signals the system workflow backing the schedule to
+// stop and returns without waiting for it, the schedule does not fire anymore once a worker has processed the
+// signal. The finished workflow instance is removed like any other instance, a schedule with the same id can be
+// created once it has finished. Workflow instances started by the schedule are not affected.
+func (c *Client) DeleteSchedule(ctx context.Context, id string) error {
+	return c.signalSchedule(ctx, id, workflows.DeleteScheduleSignal)
+}
+
+func (c *Client) signalSchedule(ctx context.Context, id, signal string) error {
+	if err := c.SignalWorkflow(ctx, ScheduleInstanceID(id), signal, nil); err != nil {
+		return fmt.Errorf("signaling schedule: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package client
+
+type TerminateOptions struct {
+	// SubWorkflows determines whether running sub-workflow instances are terminated as well
+	SubWorkflows bool
+}
+
+type TerminateOption func(o *TerminateOptions)
+
+// TerminateSubWorkflows also terminates all running sub-workflow instances of the terminated
+// workflow instance, recursively.
+func TerminateSubWorkflows() TerminateOption {
+	return func(o *TerminateOptions) {
+		o.SubWorkflows = true
+	}
+}
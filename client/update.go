@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/internal/log"
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrUpdateNotCompleted is returned when a workflow instance finished before an update sent to it completed.
+var ErrUpdateNotCompleted = errors.New("workflow instance finished before update completed")
+
+// UpdateWorkflow sends an update with the given name and argument to the latest execution of the given workflow
+// instance, and waits until the result of the update handler has been recorded in the history of the instance.
+//
+// The update is validated by the workflow worker when it processes the update, against the state of the workflow
+// at that point. If the validator registered by the workflow rejects the update, the handler is not run and a
+// *workflow.UpdateRejectedError matching workflow.ErrUpdateRejected is returned.
+//
+// Use the given context to limit how long to wait for the update to complete.
+func UpdateWorkflow[TResult any](ctx context.Context, c *Client, instanceID string, name string, arg any) (TResult, error) {
+	var z TResult
+
+	ctx, span := c.backend.Tracer().Start(ctx, "UpdateWorkflow", trace.WithAttributes(
+		attribute.String(log.InstanceIDKey, instanceID),
+		attribute.String(log.UpdateNameKey, name),
+	))
+	defer span.End()
+
+	cv := c.converter(ctx)
+
+	input, err := cv.To(arg)
+	if err != nil {
+		return z, fmt.Errorf("converting update argument: %w", err)
+	}
+
+	instance, err := c.backend.GetLatestWorkflowInstance(ctx, instanceID)
+	if err != nil {
+		return z, fmt.Errorf("getting latest workflow instance: %w", err)
+	}
+
+	// Only wait for events recorded after the update was sent
+	h, err := c.backend.GetWorkflowInstanceHistory(ctx, instance, nil)
+	if err != nil {
+		return z, fmt.Errorf("getting workflow history: %w", err)
+	}
+
+	updateID := uuid.NewString()
+	span.SetAttributes(attribute.String(log.UpdateIDKey, updateID))
+
+	updateEvent := history.NewPendingEvent(
+		c.clock.Now(),
+		history.EventType_UpdateRequested,
+		&history.UpdateRequestedAttributes{
+			UpdateID: updateID,
+			Name:     name,
+			Arg:      input,
+		},
+	)
+
+	if err := c.backend.SignalWorkflow(ctx, instanceID, updateEvent); err != nil {
+		span.RecordError(err)
+		return z, err
+	}
+
+	c.backend.Options().Logger.Debug("Sent update to workflow instance",
+		log.InstanceIDKey, instanceID, log.UpdateNameKey, name, log.UpdateIDKey, updateID)
+
+	var lastSequenceID int64
+	if len(h) > 0 {
+		lastSequenceID = h[len(h)-1].SequenceID
+	}
+
+	b := backoff.ExponentialBackOff{
+		InitialInterval:     time.Millisecond * 1,
+		MaxInterval:         time.Second * 1,
+		Multiplier:          1.5,
+		RandomizationFactor: 0.5,
+		Stop:                backoff.Stop,
+		Clock:               c.clock,
+	}
+	b.Reset()
+
+	ticker := backoff.NewTicker(backoff.WithContext(&b, ctx))
+	defer ticker.Stop()
+
+	for range ticker.C {
+		// Read the state before the history, so that the history is complete once the instance is not active
+		s, err := c.backend.GetWorkflowInstanceState(ctx, instance)
+		if err != nil {
+			return z, fmt.Errorf("getting workflow state: %w", err)
+		}
+
+		events, err := c.backend.GetWorkflowInstanceHistory(ctx, instance, &lastSequenceID)
+		if err != nil {
+			return z, fmt.Errorf("getting workflow history: %w", err)
+		}
+
+		for _, event := range events {
+			lastSequenceID = event.SequenceID
+
+			if event.Type != history.EventType_UpdateCompleted {
+				continue
+			}
+
+			a := event.Attributes.(*history.UpdateCompletedAttributes)
+			if a.UpdateID != updateID {
+				continue
+			}
+
+			if a.Error != nil {
+				decodedErr, err := workflowerrors.DecodeError(cv, a.Error)
+				if err != nil {
+					return z, fmt.Errorf("decoding error: %w", err)
+				}
+
+				return z, workflowerrors.ToError(decodedErr)
+			}
+
+			var r TResult
+			if err := cv.From(a.Result, &r); err != nil {
+				return z, fmt.Errorf("converting update result: %w", err)
+			}
+
+			return r, nil
+		}
+
+		if s != core.WorkflowInstanceStateActive {
+			return z, ErrUpdateNotCompleted
+		}
+	}
+
+	return z, ctx.Err()
+}
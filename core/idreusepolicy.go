@@ -0,0 +1,56 @@
+package core
+
+import "fmt"
+
+// IDReusePolicy controls whether a workflow instance can be created when an execution with the same instance ID
+// already exists.
+type IDReusePolicy int
+
+const (
+	// IDReusePolicyAllowDuplicate allows a new execution if the previous execution has finished. This is the default.
+	IDReusePolicyAllowDuplicate IDReusePolicy = iota
+
+	// IDReusePolicyRejectDuplicate rejects a new execution if any execution with the same instance ID exists,
+	// regardless of its state.
+	IDReusePolicyRejectDuplicate
+
+	// IDReusePolicyAllowDuplicateFailedOnly allows a new execution only if the previous execution has finished
+	// with an error or was terminated.
+	IDReusePolicyAllowDuplicateFailedOnly
+
+	// IDReusePolicyCancelIfRunning cancels a running execution and starts the new one once it has finished. Unlike
+	// the other policies, it's applied by the client and not atomically with creating the new execution.
+	IDReusePolicyCancelIfRunning
+)
+
+func (p IDReusePolicy) String() string {
+	switch p {
+	case IDReusePolicyAllowDuplicate:
+		return "AllowDuplicate"
+	case IDReusePolicyRejectDuplicate:
+		return "RejectDuplicate"
+	case IDReusePolicyAllowDuplicateFailedOnly:
+		return "AllowDuplicateFailedOnly"
+	case IDReusePolicyCancelIfRunning:
+		return "CancelIfRunning"
+	default:
+		return fmt.Sprintf("IDReusePolicy(%d)", int(p))
+	}
+}
+
+// Allows returns whether the policy allows a new execution if the latest existing execution of the instance is in
+// the given state. Independent of the policy, there can only be one active execution.
+func (p IDReusePolicy) Allows(state WorkflowInstanceState, failed bool) bool {
+	if state == WorkflowInstanceStateActive {
+		return false
+	}
+
+	switch p {
+	case IDReusePolicyRejectDuplicate:
+		return false
+	case IDReusePolicyAllowDuplicateFailedOnly:
+		return failed
+	default:
+		return true
+	}
+}
@@ -0,0 +1,31 @@
+package core
+
+import "fmt"
+
+// ParentClosePolicy determines what happens to a running sub-workflow instance when its parent finishes
+type ParentClosePolicy int
+
+const (
+	// ParentClosePolicyAbandon keeps the sub-workflow instance running. This is the default.
+	ParentClosePolicyAbandon ParentClosePolicy = iota
+
+	// ParentClosePolicyTerminate terminates the sub-workflow instance.
+	ParentClosePolicyTerminate
+
+	// ParentClosePolicyRequestCancel requests cancellation of the sub-workflow instance, it can run any cleanup
+	// logic before it finishes.
+	ParentClosePolicyRequestCancel
+)
+
+func (p ParentClosePolicy) String() string {
+	switch p {
+	case ParentClosePolicyAbandon:
+		return "Abandon"
+	case ParentClosePolicyTerminate:
+		return "Terminate"
+	case ParentClosePolicyRequestCancel:
+		return "RequestCancel"
+	default:
+		return fmt.Sprintf("ParentClosePolicy(%d)", int(p))
+	}
+}
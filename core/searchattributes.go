@@ -0,0 +1,81 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MaxSearchAttributeLength is the maximum length of a search attribute name and of its encoded value.
+const MaxSearchAttributeLength = 255
+
+// SearchAttributes are custom, indexed attributes of a workflow instance that instances can be listed by.
+//
+// Values have to be strings, booleans, integers, floats, or time.Time. They are stored in their JSON
+// representation, so when read back, numbers are float64 and times are RFC 3339 strings.
+type SearchAttributes map[string]any
+
+// Encode validates the search attributes and returns the JSON representation of each value.
+func (sa SearchAttributes) Encode() (map[string]string, error) {
+	if len(sa) == 0 {
+		return nil, nil
+	}
+
+	r := make(map[string]string, len(sa))
+	for name, value := range sa {
+		if name == "" || len(name) > MaxSearchAttributeLength {
+			return nil, fmt.Errorf("invalid search attribute name %q", name)
+		}
+
+		switch value.(type) {
+		case string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, time.Time:
+		default:
+			return nil, fmt.Errorf("search attribute %q has unsupported type %T", name, value)
+		}
+
+		v, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("encoding search attribute %q: %w", name, err)
+		}
+
+		if len(v) > MaxSearchAttributeLength {
+			return nil, fmt.Errorf("value of search attribute %q is too long", name)
+		}
+
+		r[name] = string(v)
+	}
+
+	return r, nil
+}
+
+// Names returns the names of the search attributes in sorted order.
+func (sa SearchAttributes) Names() []string {
+	names := make([]string, 0, len(sa))
+	for name := range sa {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// DecodeSearchAttributes decodes search attributes encoded with SearchAttributes.Encode.
+func DecodeSearchAttributes(encoded map[string]string) (SearchAttributes, error) {
+	if len(encoded) == 0 {
+		return nil, nil
+	}
+
+	r := make(SearchAttributes, len(encoded))
+	for name, value := range encoded {
+		var v any
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return nil, fmt.Errorf("decoding search attribute %q: %w", name, err)
+		}
+
+		r[name] = v
+	}
+
+	return r, nil
+}
@@ -3,12 +3,14 @@ import { Route, Routes } from "react-router-dom";
 import Home from "./Home";
 import Instance from "./Instance";
 import Layout from "./Layout";
+import Schedules from "./Schedules";
 
 function App() {
   return (
     <Routes>
       <Route path="/" element={<Layout />}>
         <Route index element={<Home />} />
+        <Route path="schedules" element={<Schedules />} />
 
         <Route path=":instanceId/:executionId" element={<Instance />} />
       </Route>
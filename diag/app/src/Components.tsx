@@ -127,11 +127,13 @@ function eventColor(event: string): [Color, string] {
     case "SubWorkflowCancellationRequested":
     case "SubWorkflowCompleted":
     case "SubWorkflowFailed":
+    case "SubWorkflowStarted":
       return ["light", "success"];
 
     case "ActivityScheduled":
     case "ActivityCompleted":
     case "ActivityFailed":
+    case "LocalActivityResult":
       return ["dark", "warning"];
 
     case "TimerScheduled":
@@ -140,9 +142,13 @@ function eventColor(event: string): [Color, string] {
       return ["light", "primary"];
 
     case "SignalReceived":
+    case "UpdateRequested":
+    case "UpdateCompleted":
       return ["light", "dark"];
 
     case "SideEffectResult":
+    case "VersionMarker":
+    case "SearchAttributesUpserted":
       return ["dark", "secondary"];
 
     case "WorkflowTaskStarted":
@@ -26,6 +26,13 @@ function Home() {
       (afterId ? `&after=${afterId}` : "")
   );
 
+  // Show a column for every search attribute set on any of the instances
+  const searchAttributes = Array.from(
+    new Set(
+      (data || []).flatMap((i) => Object.keys(i.search_attributes || {}))
+    )
+  ).sort();
+
   return (
     <div className="App">
       <header className="App-header">
@@ -44,6 +51,9 @@ function Home() {
                 <th>Created At</th>
                 <th>Completed At</th>
                 <th style={{ textAlign: "center" }}>State</th>
+                {searchAttributes.map((name) => (
+                  <th key={name}>{name}</th>
+                ))}
               </tr>
             </thead>
             <tbody>
@@ -74,6 +84,13 @@ function Home() {
                   <td style={{ textAlign: "center" }}>
                     <WorkflowInstanceState state={i.state} />
                   </td>
+                  {searchAttributes.map((name) => (
+                    <td key={name}>
+                      {i.search_attributes && name in i.search_attributes && (
+                        <code>{JSON.stringify(i.search_attributes[name])}</code>
+                      )}
+                    </td>
+                  ))}
                 </tr>
               ))}
             </tbody>
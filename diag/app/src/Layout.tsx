@@ -35,6 +35,9 @@ function Layout() {
                 <LinkContainer to="/">
                   <Nav.Link>List</Nav.Link>
                 </LinkContainer>
+                <LinkContainer to="/schedules">
+                  <Nav.Link>Schedules</Nav.Link>
+                </LinkContainer>
               </Nav>
               <Form className="d-flex">
                 <FormControl
@@ -0,0 +1,86 @@
+import { Badge, Table } from "react-bootstrap";
+
+import { Link } from "react-router-dom";
+import useFetch from "react-fetch-hook";
+import { WorkflowInstance } from "./Components";
+import { Schedule, ScheduleSpec } from "./client";
+
+const overlapPolicies = ["Skip", "Buffer", "Allow"];
+
+function formatSpec(spec: ScheduleSpec): string {
+  if (spec.cron) {
+    return spec.cron;
+  }
+
+  // Durations are serialized as nanoseconds
+  return `every ${(spec.interval || 0) / 1e9}s`;
+}
+
+function Schedules() {
+  const { isLoading, data } = useFetch<Schedule[]>(
+    document.location.pathname.replace(/schedules\/?$/, "") + `api/schedules`
+  );
+
+  return (
+    <div className="App">
+      <header className="App-header">
+        <h2>Schedules</h2>
+      </header>
+
+      {isLoading && <div>Loading...</div>}
+
+      {!isLoading && (
+        <Table striped bordered hover size="sm">
+          <thead>
+            <tr>
+              <th>Schedule ID</th>
+              <th>Workflow</th>
+              <th>Spec</th>
+              <th>Overlap</th>
+              <th>Next Fire Time</th>
+              <th>Last Instance</th>
+            </tr>
+          </thead>
+          <tbody>
+            {(data || []).map((s) => (
+              <tr key={s.id}>
+                <td>
+                  <Link
+                    to={`/${s.instance.instance_id}/${s.instance.execution_id}`}
+                  >
+                    {s.id}
+                  </Link>
+                </td>
+                <td>
+                  <code>{s.workflow}</code>
+                </td>
+                <td>
+                  <code>{formatSpec(s.spec)}</code>
+                </td>
+                <td>{overlapPolicies[s.spec.overlap_policy || 0]}</td>
+                <td>
+                  {s.paused ? (
+                    <Badge bg="secondary">Paused</Badge>
+                  ) : (
+                    <code>{s.next_fire_time}</code>
+                  )}
+                </td>
+                <td>
+                  {s.last_instance && (
+                    <Link
+                      to={`/${s.last_instance.instance_id}/${s.last_instance.execution_id}`}
+                    >
+                      <WorkflowInstance instance={s.last_instance} />
+                    </Link>
+                  )}
+                </td>
+              </tr>
+            ))}
+          </tbody>
+        </Table>
+      )}
+    </div>
+  );
+}
+
+export default Schedules;
@@ -12,6 +12,8 @@ export interface WorkflowInstanceRef {
 
   state: number;
   queue: string;
+
+  search_attributes?: { [name: string]: any };
 }
 
 export type WorkflowInstanceInfo = WorkflowInstanceRef & {
@@ -46,3 +48,27 @@ export type WorkflowInstanceTree = WorkflowInstanceRef & {
   workflow_name: string;
   children: WorkflowInstanceTree[];
 };
+
+export interface ScheduleSpec {
+  cron?: string;
+  interval?: number;
+  jitter?: number;
+  overlap_policy?: number;
+  queue?: string;
+}
+
+export interface Schedule {
+  instance: WorkflowInstance;
+
+  id: string;
+  workflow: string;
+  spec: ScheduleSpec;
+
+  created_at: string;
+  paused?: boolean;
+
+  next_fire_time?: string;
+  last_fire_time?: string;
+  last_instance?: WorkflowInstance;
+  buffered?: number;
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/client"
 	"github.com/cschleiden/go-workflows/core"
 )
 
@@ -16,6 +17,8 @@ type WorkflowInstanceRef struct {
 	CompletedAt *time.Time                 `json:"completed_at,omitempty"`
 	State       core.WorkflowInstanceState `json:"state"`
 	Queue       string                     `json:"queue"`
+
+	SearchAttributes core.SearchAttributes `json:"search_attributes,omitempty"`
 }
 
 type Event struct {
@@ -42,6 +45,12 @@ type WorkflowInstanceTree struct {
 	Children []*WorkflowInstanceTree `json:"children,omitempty"`
 }
 
+type Schedule struct {
+	Instance *core.WorkflowInstance `json:"instance,omitempty"`
+
+	*client.ScheduleDescription
+}
+
 type Backend interface {
 	backend.Backend
 
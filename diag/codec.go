@@ -0,0 +1,62 @@
+package diag
+
+import (
+	"reflect"
+
+	"github.com/cschleiden/go-workflows/backend/converter"
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
+)
+
+var (
+	payloadType  = reflect.TypeOf(payload.Payload(nil))
+	payloadsType = reflect.TypeOf([]payload.Payload(nil))
+	errorType    = reflect.TypeOf((*workflowerrors.Error)(nil))
+)
+
+// decodeAttributes returns a copy of the given event attributes with all payloads and errors decoded using the
+// given codec. Values that cannot be decoded are kept as is.
+func decodeAttributes(codec converter.Codec, attributes interface{}) interface{} {
+	v := reflect.ValueOf(attributes)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return attributes
+	}
+
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+
+	decoder := converter.NewCodecConverter(nil, codec)
+
+	s := c.Elem()
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+		if !f.CanSet() {
+			continue
+		}
+
+		switch f.Type() {
+		case payloadType:
+			if d, err := codec.Decode(f.Interface().(payload.Payload)); err == nil {
+				f.Set(reflect.ValueOf(d))
+			}
+
+		case payloadsType:
+			ps := f.Interface().([]payload.Payload)
+			decoded := make([]payload.Payload, len(ps))
+			for j, p := range ps {
+				decoded[j] = p
+				if d, err := codec.Decode(p); err == nil {
+					decoded[j] = d
+				}
+			}
+			f.Set(reflect.ValueOf(decoded))
+
+		case errorType:
+			if d, err := workflowerrors.DecodeError(decoder, f.Interface().(*workflowerrors.Error)); err == nil {
+				f.Set(reflect.ValueOf(d))
+			}
+		}
+	}
+
+	return c.Interface()
+}
@@ -3,12 +3,15 @@ package diag
 import (
 	"embed"
 	"encoding/json"
+	"errors"
 	"io/fs"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/cschleiden/go-workflows/client"
 	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/workflow"
 )
 
 //go:embed app/build
@@ -16,7 +19,14 @@ var embeddedFiles embed.FS
 
 // NewServeMux returns an *http.ServeMux that serves the diagnostics web app at / and the diagnostics API at /api which is
 // used by the web app.
-func NewServeMux(backend Backend) *http.ServeMux {
+func NewServeMux(backend Backend, opts ...Option) *http.ServeMux {
+	options := &options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	scheduleClient := newScheduleClient(backend)
+
 	mux := http.NewServeMux()
 
 	// API
@@ -67,6 +77,23 @@ func NewServeMux(backend Backend) *http.ServeMux {
 			return
 		}
 
+		// /api/schedules
+		if relativeURL == "schedules" {
+			schedules, err := getSchedules(r.Context(), backend, scheduleClient)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Add("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(schedules); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			return
+		}
+
 		segments := strings.Split(relativeURL, "/")
 
 		// /api/{instanceID}/{executionID}
@@ -94,13 +121,18 @@ func NewServeMux(backend Backend) *http.ServeMux {
 
 			newHistory := make([]*Event, 0)
 			for _, event := range history {
+				attributes := event.Attributes
+				if options.codec != nil {
+					attributes = decodeAttributes(options.codec, attributes)
+				}
+
 				newHistory = append(newHistory, &Event{
 					ID:              event.ID,
 					SequenceID:      event.SequenceID,
 					Type:            event.Type.String(),
 					Timestamp:       event.Timestamp,
 					ScheduleEventID: event.ScheduleEventID,
-					Attributes:      event.Attributes,
+					Attributes:      attributes,
 					VisibleAt:       event.VisibleAt,
 				})
 			}
@@ -148,6 +180,55 @@ func NewServeMux(backend Backend) *http.ServeMux {
 
 			return
 		}
+
+		// /api/{instanceID}/{executionID}/query/{name}?args=[...]
+		if len(segments) == 4 {
+			instanceID := segments[0]
+			executionID := segments[1]
+			op := segments[2]
+			if op != "query" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			if options.client == nil {
+				w.WriteHeader(http.StatusNotImplemented)
+				return
+			}
+
+			var args []any
+			if argsStr := r.URL.Query().Get("args"); argsStr != "" {
+				var rawArgs []json.RawMessage
+				if err := json.Unmarshal([]byte(argsStr), &rawArgs); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+
+				for _, arg := range rawArgs {
+					args = append(args, arg)
+				}
+			}
+
+			instance := core.NewWorkflowInstance(instanceID, executionID)
+			result, err := client.QueryWorkflow[json.RawMessage](r.Context(), options.client, instance, segments[3], args...)
+			if err != nil {
+				if errors.Is(err, workflow.ErrUnknownQuery) {
+					http.Error(w, err.Error(), http.StatusNotFound)
+					return
+				}
+
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Add("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(result); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			return
+		}
 	})
 
 	// App
@@ -0,0 +1,30 @@
+package diag
+
+import (
+	"github.com/cschleiden/go-workflows/backend/converter"
+	"github.com/cschleiden/go-workflows/client"
+)
+
+type options struct {
+	client *client.Client
+	codec  converter.Codec
+}
+
+type Option func(o *options)
+
+// WithClient sets the client used to query workflow instances. The client needs to be created with a registry
+// containing the workflows, see client.WithRegistry. Without a client, queries are not supported.
+func WithClient(c *client.Client) Option {
+	return func(o *options) {
+		o.client = c
+	}
+}
+
+// WithCodec sets a codec to decode payloads and errors in the history of workflow instances for display, for
+// example when payloads are encrypted, see converter.NewCodecConverter. Payloads the codec cannot decode, for
+// example because the key is not available, are shown as is.
+func WithCodec(codec converter.Codec) Option {
+	return func(o *options) {
+		o.codec = codec
+	}
+}
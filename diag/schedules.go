@@ -0,0 +1,65 @@
+package diag
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/client"
+	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/internal/fn"
+	"github.com/cschleiden/go-workflows/internal/workflows"
+	"github.com/cschleiden/go-workflows/registry"
+)
+
+const schedulesPageSize = 100
+
+// newScheduleClient returns a client which can query schedule workflows, independent of the
+// client passed in the options.
+func newScheduleClient(b Backend) *client.Client {
+	r := registry.New()
+	if err := r.RegisterWorkflow(workflows.ScheduleWorkflowInstances); err != nil {
+		panic(fmt.Errorf("registering schedule workflow: %w", err))
+	}
+
+	return client.New(b, client.WithRegistry(r))
+}
+
+// getSchedules returns all active schedules by looking for running schedule workflows and querying
+// their current state.
+func getSchedules(ctx context.Context, b Backend, c *client.Client) ([]*Schedule, error) {
+	schedules := make([]*Schedule, 0)
+
+	active := core.WorkflowInstanceStateActive
+	query := &backend.WorkflowInstanceQuery{
+		WorkflowName: fn.Name(workflows.ScheduleWorkflowInstances),
+		State:        &active,
+		Queue:        core.QueueSystem,
+		PageSize:     schedulesPageSize,
+	}
+
+	for {
+		r, err := b.ListWorkflowInstances(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("listing schedule instances: %w", err)
+		}
+
+		for _, i := range r.Instances {
+			d, err := client.QueryWorkflow[*client.ScheduleDescription](ctx, c, i.Instance, workflows.DescribeScheduleQuery)
+			if err != nil {
+				return nil, fmt.Errorf("querying schedule %v: %w", i.Instance.InstanceID, err)
+			}
+
+			schedules = append(schedules, &Schedule{
+				Instance:            i.Instance,
+				ScheduleDescription: d,
+			})
+		}
+
+		if r.NextCursor == "" {
+			return schedules, nil
+		}
+
+		query.Cursor = r.NextCursor
+	}
+}
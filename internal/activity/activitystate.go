@@ -3,7 +3,10 @@ package activity
 import (
 	"context"
 	"log/slog"
+	"sync"
 
+	"github.com/cschleiden/go-workflows/backend/converter"
+	"github.com/cschleiden/go-workflows/backend/payload"
 	"github.com/cschleiden/go-workflows/internal/log"
 	"github.com/cschleiden/go-workflows/workflow"
 )
@@ -13,19 +16,82 @@ type ActivityState struct {
 	Attempt    int
 	Instance   *workflow.Instance
 	Logger     *slog.Logger
+
+	// Converter is used to convert heartbeat details
+	Converter converter.Converter
+
+	// LastHeartbeatDetails are the details of the last heartbeat recorded by a previous attempt
+	LastHeartbeatDetails payload.Payload
+
+	// PersistHeartbeat stores heartbeat details in the backend, so that they survive the loss of this worker.
+	// It is nil if details cannot be persisted.
+	PersistHeartbeat func(details payload.Payload) error
+
+	// TaskToken returns a token for completing the activity asynchronously. It is nil if the activity cannot be
+	// completed asynchronously.
+	TaskToken func() (string, error)
+
+	mu               sync.Mutex
+	heartbeatDetails payload.Payload
+	heartbeats       chan struct{}
 }
 
 func NewActivityState(activityID string, attempt int, instance *workflow.Instance, logger *slog.Logger) *ActivityState {
 	return &ActivityState{
-		activityID,
-		attempt,
-		instance,
-		logger.With(
+		ActivityID: activityID,
+		Attempt:    attempt,
+		Instance:   instance,
+		Logger: logger.With(
 			log.ActivityIDKey, activityID,
 			log.InstanceIDKey, instance.InstanceID,
 			log.ExecutionIDKey, instance.ExecutionID,
 			log.AttemptKey, attempt,
-		)}
+		),
+		Converter:  converter.DefaultConverter,
+		heartbeats: make(chan struct{}, 1),
+	}
+}
+
+// RecordHeartbeat records a heartbeat for the activity. If details is nil, the previously recorded details
+// are kept.
+func (as *ActivityState) RecordHeartbeat(details payload.Payload) error {
+	if details != nil {
+		if as.PersistHeartbeat != nil {
+			if err := as.PersistHeartbeat(details); err != nil {
+				return err
+			}
+		}
+
+		as.mu.Lock()
+		as.heartbeatDetails = details
+		as.mu.Unlock()
+	}
+
+	// Notify without blocking, a pending notification is sufficient
+	select {
+	case as.heartbeats <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// HeartbeatDetails returns the details of the last heartbeat recorded by this attempt, or the details of the
+// previous attempt if no heartbeat has been recorded yet.
+func (as *ActivityState) HeartbeatDetails() payload.Payload {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	if as.heartbeatDetails == nil {
+		return as.LastHeartbeatDetails
+	}
+
+	return as.heartbeatDetails
+}
+
+// Heartbeats returns a channel that receives a value whenever a heartbeat is recorded
+func (as *ActivityState) Heartbeats() <-chan struct{} {
+	return as.heartbeats
 }
 
 type key int
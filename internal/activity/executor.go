@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"reflect"
+	"time"
 
 	"github.com/cschleiden/go-workflows/backend"
 	"github.com/cschleiden/go-workflows/backend/converter"
@@ -27,6 +28,8 @@ type Executor struct {
 	converter   converter.Converter
 	propagators []wf.ContextPropagator
 	r           *registry.Registry
+	setPending  func(ctx context.Context, task *backend.ActivityTask) error
+	heartbeat   func(ctx context.Context, task *backend.ActivityTask, details payload.Payload) error
 }
 
 func NewExecutor(
@@ -35,6 +38,8 @@ func NewExecutor(
 	converter converter.Converter,
 	propagators []wf.ContextPropagator,
 	r *registry.Registry,
+	setPending func(ctx context.Context, task *backend.ActivityTask) error,
+	heartbeat func(ctx context.Context, task *backend.ActivityTask, details payload.Payload) error,
 ) *Executor {
 	return &Executor{
 		logger:      logger,
@@ -42,25 +47,74 @@ func NewExecutor(
 		converter:   converter,
 		propagators: propagators,
 		r:           r,
+		setPending:  setPending,
+		heartbeat:   heartbeat,
 	}
 }
 
-func (e *Executor) ExecuteActivity(ctx context.Context, task *backend.ActivityTask) (payload.Payload, error) {
+// ErrHeartbeatTimeout matches the error returned when an activity did not record a heartbeat within its
+// heartbeat timeout
+var ErrHeartbeatTimeout = workflowerrors.ErrHeartbeatTimeout
+
+// ExecuteActivity executes the activity for the given task. In addition to the result, it returns the details
+// of the last heartbeat recorded by the activity.
+func (e *Executor) ExecuteActivity(ctx context.Context, task *backend.ActivityTask) (payload.Payload, payload.Payload, error) {
 	a := task.Event.Attributes.(*history.ActivityScheduledAttributes)
 
+	// Apply timeouts, the activity's context carries the earliest deadline
+	if a.ScheduleToCloseDeadline != nil {
+		var cancelDeadline context.CancelFunc
+		ctx, cancelDeadline = context.WithDeadlineCause(
+			ctx, *a.ScheduleToCloseDeadline, workflowerrors.NewActivityTimeoutError(workflowerrors.TimeoutTypeScheduleToClose))
+		defer cancelDeadline()
+	}
+
+	if a.StartToCloseTimeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeoutCause(
+			ctx, a.StartToCloseTimeout, workflowerrors.NewActivityTimeoutError(workflowerrors.TimeoutTypeStartToClose))
+		defer cancelTimeout()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Add activity state to context
 	as := NewActivityState(
 		task.Event.ID,
 		a.Attempt,
 		task.WorkflowInstance,
 		e.logger)
+	cv := converter.WithContext(ctx, e.converter)
+	as.Converter = cv
+	as.LastHeartbeatDetails = a.LastHeartbeatDetails
+	if task.LastHeartbeatDetails != nil {
+		// A previous delivery of this attempt already recorded progress
+		as.LastHeartbeatDetails = task.LastHeartbeatDetails
+	}
+	if e.heartbeat != nil {
+		as.PersistHeartbeat = func(details payload.Payload) error {
+			return e.heartbeat(ctx, task, details)
+		}
+	}
+	if e.setPending != nil {
+		as.TaskToken = func() (string, error) {
+			// Mark the task as pending before handing out the token, so that it can be completed by anyone
+			// holding the token as soon as the activity returns
+			if err := e.setPending(ctx, task); err != nil {
+				return "", err
+			}
+
+			return EncodeTaskToken(task)
+		}
+	}
 	activityCtx := WithActivityState(ctx, as)
 
 	for _, propagator := range e.propagators {
 		var err error
 		activityCtx, err = propagator.Extract(activityCtx, a.Metadata)
 		if err != nil {
-			return nil, workflowerrors.NewPermanentError(fmt.Errorf("extracting context from propagator: %w", err))
+			return nil, as.HeartbeatDetails(), workflowerrors.NewPermanentError(fmt.Errorf("extracting context from propagator: %w", err))
 		}
 	}
 
@@ -73,17 +127,17 @@ func (e *Executor) ExecuteActivity(ctx context.Context, task *backend.ActivityTa
 
 	activity, err := e.r.GetActivity(a.Name)
 	if err != nil {
-		return nil, workflowerrors.NewPermanentError(tracing.WithSpanError(span, fmt.Errorf("activity not found: %w", err)))
+		return nil, as.HeartbeatDetails(), workflowerrors.NewPermanentError(tracing.WithSpanError(span, fmt.Errorf("activity not found: %w", err)))
 	}
 
 	activityFn := reflect.ValueOf(activity)
 	if activityFn.Type().Kind() != reflect.Func {
-		return nil, workflowerrors.NewPermanentError(tracing.WithSpanError(span, errors.New("activity not a function")))
+		return nil, as.HeartbeatDetails(), workflowerrors.NewPermanentError(tracing.WithSpanError(span, errors.New("activity not a function")))
 	}
 
-	args, addContext, err := args.InputsToArgs(e.converter, activityFn, a.Inputs)
+	args, addContext, err := args.InputsToArgs(cv, activityFn, a.Inputs)
 	if err != nil {
-		return nil, workflowerrors.NewPermanentError(tracing.WithSpanError(span, fmt.Errorf("converting activity inputs: %w", err)))
+		return nil, as.HeartbeatDetails(), workflowerrors.NewPermanentError(tracing.WithSpanError(span, fmt.Errorf("converting activity inputs: %w", err)))
 	}
 
 	defer span.End()
@@ -110,10 +164,15 @@ func (e *Executor) ExecuteActivity(ctx context.Context, task *backend.ActivityTa
 		rv = activityFn.Call(args)
 	}()
 
-	<-done
+	if err := waitForActivity(ctx, done, as, a.HeartbeatTimeout); err != nil {
+		// Abandon the activity, cancel its context to give it a chance to stop
+		cancel()
+
+		return nil, as.HeartbeatDetails(), workflowerrors.FromError(tracing.WithSpanError(span, err))
+	}
 
 	if len(rv) < 1 || len(rv) > 2 {
-		return nil, workflowerrors.NewPermanentError(
+		return nil, as.HeartbeatDetails(), workflowerrors.NewPermanentError(
 			tracing.WithSpanError(span, errors.New("activity has to return either (error) or (<result>, error)")))
 	}
 
@@ -122,9 +181,9 @@ func (e *Executor) ExecuteActivity(ctx context.Context, task *backend.ActivityTa
 	// Convert activity result to payload. We always expect at least an error
 	if len(rv) > 1 {
 		var err error
-		result, err = e.converter.To(rv[0].Interface())
+		result, err = cv.To(rv[0].Interface())
 		if err != nil {
-			return nil, workflowerrors.NewPermanentError(tracing.WithSpanError(span, fmt.Errorf("converting activity result: %w", err)))
+			return nil, as.HeartbeatDetails(), workflowerrors.NewPermanentError(tracing.WithSpanError(span, fmt.Errorf("converting activity result: %w", err)))
 		}
 	}
 
@@ -132,14 +191,76 @@ func (e *Executor) ExecuteActivity(ctx context.Context, task *backend.ActivityTa
 	errResult := rv[len(rv)-1]
 	if errResult.IsNil() {
 		// No error from activity execution
-		return result, nil
+		return result, nil, nil
 	}
 
 	err, ok := errResult.Interface().(error)
 	if !ok {
-		return nil, workflowerrors.NewPermanentError(
+		return nil, as.HeartbeatDetails(), workflowerrors.NewPermanentError(
 			tracing.WithSpanError(span, fmt.Errorf("activity error result does not satisfy error interface (%T): %v", errResult, errResult)))
 	}
 
-	return result, workflowerrors.FromError(tracing.WithSpanError(span, err))
+	// The activity will be completed asynchronously
+	if errors.Is(err, ErrResultPending) {
+		return nil, nil, ErrResultPending
+	}
+
+	// The activity might have returned as a result of a timeout, report the timeout in that case
+	if te := timeoutCause(ctx); te != nil {
+		err = te
+	}
+
+	return result, as.HeartbeatDetails(), workflowerrors.FromError(tracing.WithSpanError(span, err))
+}
+
+// waitForActivity waits for the activity to finish. If a heartbeat timeout is given, it returns an
+// ActivityTimeoutError if the activity does not record a heartbeat within the timeout. If one of the activity's timeouts expires, it
+// returns the corresponding ActivityTimeoutError.
+func waitForActivity(ctx context.Context, done <-chan struct{}, as *ActivityState, heartbeatTimeout time.Duration) error {
+	var t *time.Timer
+	var heartbeatTimer <-chan time.Time
+	if heartbeatTimeout > 0 {
+		t = time.NewTimer(heartbeatTimeout)
+		defer t.Stop()
+
+		heartbeatTimer = t.C
+	}
+
+	ctxDone := ctx.Done()
+
+	for {
+		select {
+		case <-done:
+			return nil
+
+		case <-as.Heartbeats():
+			if t != nil {
+				if !t.Stop() {
+					<-t.C
+				}
+				t.Reset(heartbeatTimeout)
+			}
+
+		case <-heartbeatTimer:
+			return workflowerrors.NewActivityTimeoutError(workflowerrors.TimeoutTypeHeartbeat)
+
+		case <-ctxDone:
+			if te := timeoutCause(ctx); te != nil {
+				return te
+			}
+
+			// Context was canceled for another reason, keep waiting for the activity
+			ctxDone = nil
+		}
+	}
+}
+
+// timeoutCause returns the activity timeout error if the given context expired due to one of the activity's timeouts
+func timeoutCause(ctx context.Context) *workflowerrors.ActivityTimeoutError {
+	var te *workflowerrors.ActivityTimeoutError
+	if errors.As(context.Cause(ctx), &te) {
+		return te
+	}
+
+	return nil
 }
@@ -104,6 +104,57 @@ func TestExecutor_ExecuteActivity(t *testing.T) {
 				require.Equal(t, e.Type, "PanicError")
 			},
 		},
+		{
+			name: "heartbeat timeout",
+			setup: func(t *testing.T, r *registry.Registry) *history.ActivityScheduledAttributes {
+				a := func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				}
+				require.NoError(t, r.RegisterActivity(a))
+
+				return &history.ActivityScheduledAttributes{
+					Name:             fn.Name(a),
+					HeartbeatTimeout: time.Millisecond * 50,
+				}
+			},
+			result: func(t *testing.T, result payload.Payload, err error) {
+				require.Nil(t, result)
+				require.Error(t, err)
+
+				var expectedErr *workflowerrors.Error
+				require.ErrorAs(t, err, &expectedErr)
+				require.ErrorIs(t, workflowerrors.ToError(expectedErr), ErrHeartbeatTimeout)
+			},
+		},
+		{
+			name: "heartbeats keep activity alive",
+			setup: func(t *testing.T, r *registry.Registry) *history.ActivityScheduledAttributes {
+				a := func(ctx context.Context) (int, error) {
+					for i := 0; i < 5; i++ {
+						time.Sleep(time.Millisecond * 25)
+						if err := GetActivityState(ctx).RecordHeartbeat(nil); err != nil {
+							return 0, err
+						}
+					}
+
+					return 42, nil
+				}
+				require.NoError(t, r.RegisterActivity(a))
+
+				return &history.ActivityScheduledAttributes{
+					Name:             fn.Name(a),
+					HeartbeatTimeout: time.Millisecond * 50,
+				}
+			},
+			result: func(t *testing.T, result payload.Payload, err error) {
+				require.NoError(t, err)
+
+				var r int
+				require.NoError(t, converter.DefaultConverter.From(result, &r))
+				require.Equal(t, 42, r)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -116,7 +167,7 @@ func TestExecutor_ExecuteActivity(t *testing.T) {
 				converter: converter.DefaultConverter,
 				tracer:    noop.NewTracerProvider().Tracer(""),
 			}
-			got, err := e.ExecuteActivity(context.Background(), &backend.ActivityTask{
+			got, _, err := e.ExecuteActivity(context.Background(), &backend.ActivityTask{
 				ID:               uuid.NewString(),
 				WorkflowInstance: core.NewWorkflowInstance("instanceID", "executionID"),
 				Event:            history.NewHistoryEvent(1, time.Now(), history.EventType_ActivityScheduled, attr),
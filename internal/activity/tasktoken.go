@@ -0,0 +1,71 @@
+package activity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/workflow"
+)
+
+// ErrResultPending is returned by activities that will be completed asynchronously
+var ErrResultPending = errors.New("activity result pending")
+
+type taskToken struct {
+	InstanceID      string         `json:"iid"`
+	ExecutionID     string         `json:"eid"`
+	ActivityID      string         `json:"aid"`
+	TaskID          string         `json:"tid"`
+	Queue           workflow.Queue `json:"q"`
+	ScheduleEventID int64          `json:"sid"`
+}
+
+// EncodeTaskToken encodes the information required to complete the given activity task into an opaque token
+func EncodeTaskToken(task *backend.ActivityTask) (string, error) {
+	b, err := json.Marshal(&taskToken{
+		InstanceID:      task.WorkflowInstance.InstanceID,
+		ExecutionID:     task.WorkflowInstance.ExecutionID,
+		ActivityID:      task.ActivityID,
+		TaskID:          task.ID,
+		Queue:           task.Queue,
+		ScheduleEventID: task.Event.ScheduleEventID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("encoding task token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodeTaskToken decodes a token created by EncodeTaskToken. The event of the returned task only carries the
+// activity and schedule event IDs.
+func DecodeTaskToken(token string) (*backend.ActivityTask, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("decoding task token: %w", err)
+	}
+
+	var t taskToken
+	if err := json.Unmarshal(b, &t); err != nil {
+		return nil, fmt.Errorf("decoding task token: %w", err)
+	}
+
+	if t.InstanceID == "" || t.ActivityID == "" {
+		return nil, errors.New("invalid task token")
+	}
+
+	return &backend.ActivityTask{
+		ID:               t.TaskID,
+		ActivityID:       t.ActivityID,
+		Queue:            t.Queue,
+		WorkflowInstance: core.NewWorkflowInstance(t.InstanceID, t.ExecutionID),
+		Event: &history.Event{
+			ID:              t.ActivityID,
+			ScheduleEventID: t.ScheduleEventID,
+		},
+	}, nil
+}
@@ -0,0 +1,33 @@
+package activity
+
+import (
+	"testing"
+
+	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskToken(t *testing.T) {
+	task := &backend.ActivityTask{
+		ID:               "task-id",
+		ActivityID:       "activity-id",
+		Queue:            "queue",
+		WorkflowInstance: core.NewWorkflowInstance("instance-id", "execution-id"),
+		Event: &history.Event{
+			ID:              "activity-id",
+			ScheduleEventID: 42,
+		},
+	}
+
+	token, err := EncodeTaskToken(task)
+	require.NoError(t, err)
+
+	decoded, err := DecodeTaskToken(token)
+	require.NoError(t, err)
+	require.Equal(t, task, decoded)
+
+	_, err = DecodeTaskToken("invalid")
+	require.Error(t, err)
+}
@@ -2,12 +2,16 @@ package args
 
 import (
 	"context"
+	"math"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/cschleiden/go-workflows/backend/converter"
 	"github.com/cschleiden/go-workflows/backend/payload"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 func TestInputsToArgs(t *testing.T) {
@@ -227,3 +231,39 @@ func TestParamsMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestArgsRoundTrip(t *testing.T) {
+	type input struct {
+		Name  string `json:"name"`
+		Count int64  `json:"count"`
+	}
+
+	at := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	fn := func(ctx context.Context, i input, n int64, m *wrapperspb.StringValue, ts *timestamppb.Timestamp) error {
+		return nil
+	}
+
+	converters := []struct {
+		name string
+		c    converter.Converter
+	}{
+		{"json", converter.DefaultConverter},
+		{"proto", converter.NewProtoConverter()},
+		{"msgpack", converter.NewMessagePackConverter()},
+	}
+	for _, tt := range converters {
+		t.Run(tt.name, func(t *testing.T) {
+			inputs, err := ArgsToInputs(tt.c, input{Name: "test", Count: 42}, int64(math.MaxInt64), wrapperspb.String("message"), timestamppb.New(at))
+			require.NoError(t, err)
+
+			args, addContext, err := InputsToArgs(tt.c, reflect.ValueOf(fn), inputs)
+			require.NoError(t, err)
+			require.True(t, addContext)
+
+			require.Equal(t, input{Name: "test", Count: 42}, args[1].Interface())
+			require.Equal(t, int64(math.MaxInt64), args[2].Interface())
+			require.Equal(t, "message", args[3].Interface().(*wrapperspb.StringValue).GetValue())
+			require.True(t, at.Equal(args[4].Interface().(*timestamppb.Timestamp).AsTime()))
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package command
+
+import (
+	"github.com/benbjohnson/clock"
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
+)
+
+type CompleteUpdateCommand struct {
+	command
+
+	UpdateID string
+	Result   payload.Payload
+	Error    *workflowerrors.Error
+}
+
+var _ Command = (*CompleteUpdateCommand)(nil)
+
+// Command transitions are
+// Pending -> Done : Update result has been recorded in the history
+
+func NewCompleteUpdateCommand(id int64, updateID string, result payload.Payload, err *workflowerrors.Error) *CompleteUpdateCommand {
+	return &CompleteUpdateCommand{
+		command: command{
+			id:    id,
+			name:  "CompleteUpdate",
+			state: CommandState_Pending,
+		},
+		UpdateID: updateID,
+		Result:   result,
+		Error:    err,
+	}
+}
+
+func (c *CompleteUpdateCommand) Commit() {
+	switch c.state {
+	case CommandState_Pending:
+		c.state = CommandState_Done
+
+	default:
+		c.invalidStateTransition(CommandState_Done)
+	}
+}
+
+func (c *CompleteUpdateCommand) Execute(clock clock.Clock) *CommandResult {
+	switch c.state {
+	case CommandState_Pending:
+		// The update result is only added to the history, transition to Done
+		c.state = CommandState_Done
+
+		return &CommandResult{
+			Events: []*history.Event{
+				history.NewPendingEvent(
+					clock.Now(),
+					history.EventType_UpdateCompleted,
+					&history.UpdateCompletedAttributes{
+						UpdateID: c.UpdateID,
+						Result:   c.Result,
+						Error:    c.Error,
+					},
+					history.ScheduleEventID(c.id),
+				),
+			},
+		}
+	}
+
+	return nil
+}
+
+func (c *CompleteUpdateCommand) Done() {
+	switch c.state {
+	case CommandState_Pending, CommandState_Committed:
+		c.state = CommandState_Done
+		if c.whenDone != nil {
+			c.whenDone()
+		}
+
+	default:
+		c.invalidStateTransition(CommandState_Done)
+	}
+}
@@ -0,0 +1,48 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/benbjohnson/clock"
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompleteUpdateCommand_StateTransitions(t *testing.T) {
+	tests := []struct {
+		name string
+		f    func(t *testing.T, c *CompleteUpdateCommand, clock clock.Clock)
+	}{
+		{"Execute records update result", func(t *testing.T, c *CompleteUpdateCommand, clock clock.Clock) {
+			r := assertExecuteWithEvent(t, c, CommandState_Done, history.EventType_UpdateCompleted)
+
+			a := r.Events[0].Attributes.(*history.UpdateCompletedAttributes)
+			require.Equal(t, "update-1", a.UpdateID)
+			require.Equal(t, "invalid", a.Error.Message)
+			require.Equal(t, int64(1), r.Events[0].ScheduleEventID)
+		}},
+		{"Commit", func(t *testing.T, c *CompleteUpdateCommand, _ clock.Clock) {
+			require.Equal(t, CommandState_Pending, c.State())
+
+			c.Commit()
+			require.Equal(t, CommandState_Done, c.State())
+
+			assertExecuteNoEvent(t, c, CommandState_Done)
+		}},
+		{"Done", func(t *testing.T, c *CompleteUpdateCommand, _ clock.Clock) {
+			c.Done()
+			require.Equal(t, CommandState_Done, c.State())
+
+			assertExecuteNoEvent(t, c, CommandState_Done)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := clock.NewMock()
+			cmd := NewCompleteUpdateCommand(1, "update-1", nil, &workflowerrors.Error{Message: "invalid"})
+
+			tt.f(t, cmd, clock)
+		})
+	}
+}
@@ -14,6 +14,9 @@ type CompleteWorkflowCommand struct {
 	Instance *core.WorkflowInstance
 	Result   payload.Payload
 	Error    *workflowerrors.Error
+
+	// Detached sub-workflows don't report their result back to the parent workflow instance
+	Detached bool
 }
 
 var _ Command = (*CompleteWorkflowCommand)(nil)
@@ -61,7 +64,7 @@ func (c *CompleteWorkflowCommand) Execute(clock clock.Clock) *CommandResult {
 			},
 		}
 
-		if c.Instance.SubWorkflow() {
+		if c.Instance.SubWorkflow() && !c.Detached {
 			// Send completion message back to parent workflow instance
 			var historyEvent *history.Event
 
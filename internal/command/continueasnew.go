@@ -1,11 +1,14 @@
 package command
 
 import (
+	"time"
+
 	"github.com/benbjohnson/clock"
 	"github.com/cschleiden/go-workflows/backend/history"
 	"github.com/cschleiden/go-workflows/backend/metadata"
 	"github.com/cschleiden/go-workflows/backend/payload"
 	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
 	"github.com/google/uuid"
 )
 
@@ -13,12 +16,31 @@ type ContinueAsNewCommand struct {
 	command
 
 	Instance *core.WorkflowInstance
+	Queue    core.Queue
 	Name     string
 	Metadata *metadata.WorkflowMetadata
 	Inputs   []payload.Payload
 	Result   payload.Payload
 
 	ContinuedExecutionID string
+
+	// Timeouts carried over to the new execution
+	RunTimeout        time.Duration
+	ExecutionDeadline *time.Time
+
+	// SearchAttributes carried over to the new execution
+	SearchAttributes map[string]string
+
+	// RetryPolicy and ParentClosePolicy carried over to the new execution
+	RetryPolicy       *history.RetryPolicy
+	ParentClosePolicy core.ParentClosePolicy
+
+	// Detached is carried over to the new execution of a detached sub-workflow
+	Detached bool
+
+	// Attempt of the new execution and the error of the current one, if the workflow is retried
+	Attempt int
+	Error   *workflowerrors.Error
 }
 
 var _ Command = (*ContinueAsNewCommand)(nil)
@@ -63,6 +85,7 @@ func (c *ContinueAsNewCommand) Execute(clock clock.Clock) *CommandResult {
 					&history.ExecutionContinuedAsNewAttributes{
 						Result:               c.Result,
 						ContinuedExecutionID: c.ContinuedExecutionID,
+						Error:                c.Error,
 					},
 				),
 			},
@@ -74,9 +97,17 @@ func (c *ContinueAsNewCommand) Execute(clock clock.Clock) *CommandResult {
 						clock.Now(),
 						history.EventType_WorkflowExecutionStarted,
 						&history.ExecutionStartedAttributes{
-							Name:     c.Name,
-							Metadata: c.Metadata,
-							Inputs:   c.Inputs,
+							Queue:             c.Queue,
+							Name:              c.Name,
+							Metadata:          c.Metadata,
+							Inputs:            c.Inputs,
+							RunTimeout:        c.RunTimeout,
+							ExecutionDeadline: c.ExecutionDeadline,
+							SearchAttributes:  c.SearchAttributes,
+							RetryPolicy:       c.RetryPolicy,
+							ParentClosePolicy: c.ParentClosePolicy,
+							Detached:          c.Detached,
+							Attempt:           c.Attempt,
 						},
 					),
 				},
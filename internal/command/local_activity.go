@@ -0,0 +1,85 @@
+package command
+
+import (
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
+)
+
+type LocalActivityCommand struct {
+	command
+
+	Name string
+
+	// RetryAfter is set if the local activity is retried after a timer
+	RetryAfter time.Duration
+
+	attempts int
+	result   payload.Payload
+	err      *workflowerrors.Error
+}
+
+var _ Command = (*LocalActivityCommand)(nil)
+
+// Command transitions are
+// Pending -> Done : Result of the local activity has been recorded in the history
+
+func NewLocalActivityCommand(id int64, name string) *LocalActivityCommand {
+	return &LocalActivityCommand{
+		command: command{
+			id:    id,
+			name:  "LocalActivity",
+			state: CommandState_Pending,
+		},
+		Name: name,
+	}
+}
+
+func (c *LocalActivityCommand) SetResult(attempts int, result payload.Payload, err *workflowerrors.Error) {
+	c.attempts = attempts
+	c.result = result
+	c.err = err
+}
+
+func (c *LocalActivityCommand) Execute(clock clock.Clock) *CommandResult {
+	switch c.state {
+	case CommandState_Pending:
+		// Local activities have been executed already, only add the result to the history
+		c.state = CommandState_Done
+
+		return &CommandResult{
+			Events: []*history.Event{
+				history.NewPendingEvent(
+					clock.Now(),
+					history.EventType_LocalActivityResult,
+					&history.LocalActivityResultAttributes{
+						Name:       c.Name,
+						Attempts:   c.attempts,
+						Result:     c.result,
+						Error:      c.err,
+						RetryAfter: c.RetryAfter,
+					},
+					history.ScheduleEventID(c.id),
+				),
+			},
+		}
+	}
+
+	return nil
+}
+
+func (c *LocalActivityCommand) Done() {
+	switch c.state {
+	case CommandState_Pending:
+		c.state = CommandState_Done
+		if c.whenDone != nil {
+			c.whenDone()
+		}
+
+	default:
+		c.invalidStateTransition(CommandState_Done)
+	}
+}
@@ -0,0 +1,48 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/benbjohnson/clock"
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalActivityCommand_StateTransitions(t *testing.T) {
+	tests := []struct {
+		name string
+		f    func(t *testing.T, c *LocalActivityCommand, clock clock.Clock)
+	}{
+		{"Execute records local activity result", func(t *testing.T, c *LocalActivityCommand, clock clock.Clock) {
+			c.SetResult(2, []byte("42"), nil)
+
+			cr := assertExecuteWithEvent(t, c, CommandState_Done, history.EventType_LocalActivityResult)
+
+			a := cr.Events[0].Attributes.(*history.LocalActivityResultAttributes)
+			require.Equal(t, "activity", a.Name)
+			require.Equal(t, 2, a.Attempts)
+			require.Equal(t, []byte("42"), []byte(a.Result))
+		}},
+		{"Done", func(t *testing.T, c *LocalActivityCommand, clock clock.Clock) {
+			c.Done()
+			require.Equal(t, CommandState_Done, c.State())
+
+			assertExecuteNoEvent(t, c, CommandState_Done)
+		}},
+		{"Done_after_execute", func(t *testing.T, c *LocalActivityCommand, clock clock.Clock) {
+			c.Execute(clock)
+
+			require.PanicsWithError(t, "invalid state transition for command LocalActivity: Done -> Done", func() {
+				c.Done()
+			})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := clock.NewMock()
+			cmd := NewLocalActivityCommand(1, "activity")
+
+			tt.f(t, cmd, clock)
+		})
+	}
+}
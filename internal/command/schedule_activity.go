@@ -1,11 +1,14 @@
 package command
 
 import (
+	"time"
+
 	"github.com/benbjohnson/clock"
 	"github.com/cschleiden/go-workflows/backend/history"
 	"github.com/cschleiden/go-workflows/backend/metadata"
 	"github.com/cschleiden/go-workflows/backend/payload"
 	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
 )
 
 type ScheduleActivityCommand struct {
@@ -16,6 +19,13 @@ type ScheduleActivityCommand struct {
 	Attempt  int
 	Metadata *metadata.WorkflowMetadata
 	Queue    core.Queue
+
+	HeartbeatTimeout     time.Duration
+	LastHeartbeatDetails payload.Payload
+
+	ScheduleToStartTimeout  time.Duration
+	StartToCloseTimeout     time.Duration
+	ScheduleToCloseDeadline *time.Time
 }
 
 var _ Command = (*ScheduleActivityCommand)(nil)
@@ -49,13 +59,39 @@ func (c *ScheduleActivityCommand) Execute(clock clock.Clock) *CommandResult {
 				Attempt:  c.Attempt,
 				Metadata: c.Metadata,
 				Queue:    c.Queue,
+
+				HeartbeatTimeout:     c.HeartbeatTimeout,
+				LastHeartbeatDetails: c.LastHeartbeatDetails,
+
+				ScheduleToStartTimeout:  c.ScheduleToStartTimeout,
+				StartToCloseTimeout:     c.StartToCloseTimeout,
+				ScheduleToCloseDeadline: c.ScheduleToCloseDeadline,
 			},
 			history.ScheduleEventID(c.id))
 
-		return &CommandResult{
+		r := &CommandResult{
 			Events:         []*history.Event{event},
 			ActivityEvents: []*history.Event{event},
 		}
+
+		// Fail the activity if it's not picked up in time. The backend removes this event when a worker
+		// starts the activity.
+		if c.ScheduleToStartTimeout > 0 {
+			r.TimerEvents = []*history.Event{
+				history.NewPendingEvent(
+					clock.Now(),
+					history.EventType_ActivityFailed,
+					&history.ActivityFailedAttributes{
+						Error: workflowerrors.FromError(
+							workflowerrors.NewActivityTimeoutError(workflowerrors.TimeoutTypeScheduleToStart)),
+					},
+					history.ScheduleEventID(c.id),
+					history.VisibleAt(clock.Now().Add(c.ScheduleToStartTimeout)),
+				),
+			}
+		}
+
+		return r
 	}
 
 	return nil
@@ -2,6 +2,7 @@ package command
 
 import (
 	"testing"
+	"time"
 
 	"github.com/benbjohnson/clock"
 	"github.com/cschleiden/go-workflows/backend/history"
@@ -19,6 +20,19 @@ func TestScheduleActivityCommand_StateTransitions(t *testing.T) {
 		{"Execute schedules activity", func(t *testing.T, c *ScheduleActivityCommand, clock clock.Clock) {
 			assertExecuteWithEvent(t, c, CommandState_Committed, history.EventType_ActivityScheduled)
 		}},
+		{"Execute schedules ScheduleToStart timeout", func(t *testing.T, c *ScheduleActivityCommand, clock clock.Clock) {
+			c.ScheduleToStartTimeout = time.Second
+
+			r := c.Execute(clock)
+			require.NotNil(t, r)
+			require.Len(t, r.ActivityEvents, 1)
+			require.Len(t, r.TimerEvents, 1)
+
+			e := r.TimerEvents[0]
+			require.Equal(t, history.EventType_ActivityFailed, e.Type)
+			require.Equal(t, int64(1), e.ScheduleEventID)
+			require.Equal(t, clock.Now().Add(time.Second), *e.VisibleAt)
+		}},
 		{"Commit", func(t *testing.T, c *ScheduleActivityCommand, _ clock.Clock) {
 			require.Equal(t, CommandState_Pending, c.State())
 
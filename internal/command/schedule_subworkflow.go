@@ -1,6 +1,8 @@
 package command
 
 import (
+	"time"
+
 	"github.com/benbjohnson/clock"
 	"github.com/cschleiden/go-workflows/backend/history"
 	"github.com/cschleiden/go-workflows/backend/metadata"
@@ -19,6 +21,20 @@ type ScheduleSubWorkflowCommand struct {
 
 	Name   string
 	Inputs []payload.Payload
+
+	ExecutionTimeout time.Duration
+	RunTimeout       time.Duration
+
+	// Attempt of the sub-workflow, if it is retried by the parent
+	Attempt int
+
+	ParentClosePolicy core.ParentClosePolicy
+
+	// Detached sub-workflows don't report their result back to the parent workflow instance. Instead, the
+	// backend notifies the parent with a SubWorkflowStarted event once it has created the sub-workflow instance.
+	Detached bool
+
+	whenStarted func(err error)
 }
 
 var _ CancelableCommand = (*ScheduleSubWorkflowCommand)(nil)
@@ -54,6 +70,13 @@ func (c *ScheduleSubWorkflowCommand) Execute(clock clock.Clock) *CommandResult {
 	switch c.state {
 	case CommandState_Pending:
 		c.state = CommandState_Committed
+
+		var executionDeadline *time.Time
+		if c.ExecutionTimeout > 0 {
+			d := clock.Now().Add(c.ExecutionTimeout)
+			executionDeadline = &d
+		}
+
 		return &CommandResult{
 			// Record scheduled sub-workflow for source workflow instance
 			Events: []*history.Event{
@@ -82,6 +105,12 @@ func (c *ScheduleSubWorkflowCommand) Execute(clock clock.Clock) *CommandResult {
 							Inputs:         c.Inputs,
 							Metadata:       c.Metadata,
 							WorkflowSpanID: c.WorkflowSpanID,
+
+							RunTimeout:        c.RunTimeout,
+							ExecutionDeadline: executionDeadline,
+							Attempt:           c.Attempt,
+							ParentClosePolicy: c.ParentClosePolicy,
+							Detached:          c.Detached,
 						},
 					),
 				},
@@ -116,3 +145,18 @@ func (c *ScheduleSubWorkflowCommand) Execute(clock clock.Clock) *CommandResult {
 
 	return nil
 }
+
+// WhenStarted registers a function that is called when a detached sub-workflow has been started, or has
+// failed to start.
+func (c *ScheduleSubWorkflowCommand) WhenStarted(fn func(err error)) {
+	c.whenStarted = fn
+}
+
+// Started marks a detached sub-workflow as started, err is set if the sub-workflow could not be started.
+func (c *ScheduleSubWorkflowCommand) Started(err error) {
+	c.Done()
+
+	if c.whenStarted != nil {
+		c.whenStarted(err)
+	}
+}
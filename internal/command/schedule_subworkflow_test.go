@@ -76,6 +76,23 @@ func TestScheduleSubWorkflowCommand_StateTransitions(t *testing.T) {
 			c.Done()
 			require.Equal(t, CommandState_Done, c.State())
 		}},
+		{"Detached", func(t *testing.T, c *ScheduleSubWorkflowCommand, clock clock.Clock) {
+			c.Detached = true
+
+			r := assertExecuteWithEvent(t, c, CommandState_Committed, history.EventType_SubWorkflowScheduled)
+			require.Len(t, r.WorkflowEvents, 1)
+			require.True(t, r.WorkflowEvents[0].HistoryEvent.Attributes.(*history.ExecutionStartedAttributes).Detached)
+
+			var started bool
+			c.WhenStarted(func(err error) {
+				require.NoError(t, err)
+				started = true
+			})
+
+			c.Started(nil)
+			require.True(t, started)
+			require.Equal(t, CommandState_Done, c.State())
+		}},
 		{"Invalid_HandleCancel", func(t *testing.T, c *ScheduleSubWorkflowCommand, clock clock.Clock) {
 			c.Commit()
 
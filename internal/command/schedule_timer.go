@@ -33,6 +33,11 @@ func NewScheduleTimerCommand(id int64, at time.Time, name string, traceContext t
 	}
 }
 
+// Name returns the name of the timer
+func (c *ScheduleTimerCommand) Name() string {
+	return c.name
+}
+
 func (c *ScheduleTimerCommand) Execute(clock clock.Clock) *CommandResult {
 	switch c.state {
 	case CommandState_Pending:
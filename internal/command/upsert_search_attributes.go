@@ -0,0 +1,74 @@
+package command
+
+import (
+	"github.com/benbjohnson/clock"
+	"github.com/cschleiden/go-workflows/backend/history"
+)
+
+type UpsertSearchAttributesCommand struct {
+	command
+
+	SearchAttributes map[string]string
+}
+
+var _ Command = (*UpsertSearchAttributesCommand)(nil)
+
+// Command transitions are
+// Pending -> Done : Search attributes have been recorded in the history
+
+func NewUpsertSearchAttributesCommand(id int64, searchAttributes map[string]string) *UpsertSearchAttributesCommand {
+	return &UpsertSearchAttributesCommand{
+		command: command{
+			id:    id,
+			name:  "UpsertSearchAttributes",
+			state: CommandState_Pending,
+		},
+		SearchAttributes: searchAttributes,
+	}
+}
+
+func (c *UpsertSearchAttributesCommand) Commit() {
+	switch c.state {
+	case CommandState_Pending:
+		c.state = CommandState_Done
+
+	default:
+		c.invalidStateTransition(CommandState_Done)
+	}
+}
+
+func (c *UpsertSearchAttributesCommand) Execute(clock clock.Clock) *CommandResult {
+	switch c.state {
+	case CommandState_Pending:
+		// Search attributes are only added to the history, transition to Done
+		c.state = CommandState_Done
+
+		return &CommandResult{
+			Events: []*history.Event{
+				history.NewPendingEvent(
+					clock.Now(),
+					history.EventType_SearchAttributesUpserted,
+					&history.SearchAttributesUpsertedAttributes{
+						SearchAttributes: c.SearchAttributes,
+					},
+					history.ScheduleEventID(c.id),
+				),
+			},
+		}
+	}
+
+	return nil
+}
+
+func (c *UpsertSearchAttributesCommand) Done() {
+	switch c.state {
+	case CommandState_Pending, CommandState_Committed:
+		c.state = CommandState_Done
+		if c.whenDone != nil {
+			c.whenDone()
+		}
+
+	default:
+		c.invalidStateTransition(CommandState_Done)
+	}
+}
@@ -0,0 +1,77 @@
+package command
+
+import (
+	"github.com/benbjohnson/clock"
+	"github.com/cschleiden/go-workflows/backend/history"
+)
+
+type VersionMarkerCommand struct {
+	command
+
+	ChangeID string
+	Version  int
+}
+
+var _ Command = (*VersionMarkerCommand)(nil)
+
+// Command transitions are
+// Pending -> Done : Version has been recorded in the history
+
+func NewVersionMarkerCommand(id int64, changeID string, version int) *VersionMarkerCommand {
+	return &VersionMarkerCommand{
+		command: command{
+			id:    id,
+			name:  "VersionMarker",
+			state: CommandState_Pending,
+		},
+		ChangeID: changeID,
+		Version:  version,
+	}
+}
+
+func (c *VersionMarkerCommand) Commit() {
+	switch c.state {
+	case CommandState_Pending:
+		c.state = CommandState_Done
+
+	default:
+		c.invalidStateTransition(CommandState_Done)
+	}
+}
+
+func (c *VersionMarkerCommand) Execute(clock clock.Clock) *CommandResult {
+	switch c.state {
+	case CommandState_Pending:
+		// Version markers are only added to the history, transition to Done
+		c.state = CommandState_Done
+
+		return &CommandResult{
+			Events: []*history.Event{
+				history.NewPendingEvent(
+					clock.Now(),
+					history.EventType_VersionMarker,
+					&history.VersionMarkerAttributes{
+						ChangeID: c.ChangeID,
+						Version:  c.Version,
+					},
+					history.ScheduleEventID(c.id),
+				),
+			},
+		}
+	}
+
+	return nil
+}
+
+func (c *VersionMarkerCommand) Done() {
+	switch c.state {
+	case CommandState_Pending, CommandState_Committed:
+		c.state = CommandState_Done
+		if c.whenDone != nil {
+			c.whenDone()
+		}
+
+	default:
+		c.invalidStateTransition(CommandState_Done)
+	}
+}
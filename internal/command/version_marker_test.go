@@ -0,0 +1,54 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/benbjohnson/clock"
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionMarkerCommand_StateTransitions(t *testing.T) {
+	tests := []struct {
+		name string
+		f    func(t *testing.T, c *VersionMarkerCommand, clock clock.Clock)
+	}{
+		{"Execute records version marker", func(t *testing.T, c *VersionMarkerCommand, clock clock.Clock) {
+			r := assertExecuteWithEvent(t, c, CommandState_Done, history.EventType_VersionMarker)
+
+			a := r.Events[0].Attributes.(*history.VersionMarkerAttributes)
+			require.Equal(t, "change", a.ChangeID)
+			require.Equal(t, 2, a.Version)
+			require.Equal(t, int64(1), r.Events[0].ScheduleEventID)
+		}},
+		{"Commit", func(t *testing.T, c *VersionMarkerCommand, _ clock.Clock) {
+			require.Equal(t, CommandState_Pending, c.State())
+
+			c.Commit()
+			require.Equal(t, CommandState_Done, c.State())
+
+			assertExecuteNoEvent(t, c, CommandState_Done)
+		}},
+		{"Done", func(t *testing.T, c *VersionMarkerCommand, _ clock.Clock) {
+			c.Done()
+			require.Equal(t, CommandState_Done, c.State())
+
+			assertExecuteNoEvent(t, c, CommandState_Done)
+		}},
+		{"Done_after_commit", func(t *testing.T, c *VersionMarkerCommand, clock clock.Clock) {
+			c.Commit()
+
+			require.PanicsWithError(t, "invalid state transition for command VersionMarker: Done -> Done", func() {
+				c.Done()
+			})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := clock.NewMock()
+			cmd := NewVersionMarkerCommand(1, "change", 2)
+
+			tt.f(t, cmd, clock)
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package contextvalue
+
+import (
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/cschleiden/go-workflows/internal/sync"
+)
+
+// LocalActivityExecutor executes a single attempt of a local activity in the workflow worker
+type LocalActivityExecutor func(a *history.ActivityScheduledAttributes) (payload.Payload, error)
+
+type localActivityExecutorKey struct{}
+
+func WithLocalActivityExecutor(ctx sync.Context, executor LocalActivityExecutor) sync.Context {
+	return sync.WithValue(ctx, localActivityExecutorKey{}, executor)
+}
+
+func GetLocalActivityExecutor(ctx sync.Context) LocalActivityExecutor {
+	e, _ := ctx.Value(localActivityExecutorKey{}).(LocalActivityExecutor)
+	return e
+}
@@ -3,11 +3,20 @@ package continueasnew
 import (
 	"github.com/cschleiden/go-workflows/backend/metadata"
 	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/cschleiden/go-workflows/core"
 )
 
 type Error struct {
 	Metadata *metadata.WorkflowMetadata
 	Inputs   []payload.Payload
+
+	// Name and Queue of the new execution, if they differ from the current one
+	Name  string
+	Queue core.Queue
+
+	// Attempt is set if the workflow is restarted because the previous execution failed with Cause
+	Attempt int
+	Cause   error
 }
 
 var _ error = (*Error)(nil)
@@ -16,9 +25,11 @@ func (e *Error) Error() string {
 	return "ContinueAsNew"
 }
 
-func NewError(metadata *metadata.WorkflowMetadata, inputs []payload.Payload) error {
+func NewError(metadata *metadata.WorkflowMetadata, inputs []payload.Payload, name string, queue core.Queue) error {
 	return &Error{
 		Metadata: metadata,
 		Inputs:   inputs,
+		Name:     name,
+		Queue:    queue,
 	}
 }
@@ -16,6 +16,11 @@ const (
 
 	SignalNameKey = NamespaceKey + ".signal.name"
 
+	QueryNameKey = NamespaceKey + ".query.name"
+
+	UpdateNameKey = NamespaceKey + ".update.name"
+	UpdateIDKey   = NamespaceKey + ".update.id"
+
 	SeqIDKey       = NamespaceKey + ".seq_id"
 	IsReplayingKey = NamespaceKey + ".is_replaying"
 
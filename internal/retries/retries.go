@@ -0,0 +1,74 @@
+package retries
+
+import (
+	"hash/fnv"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
+)
+
+// Policy determines whether and when failed activities, sub-workflows, and workflow instances are retried. It's
+// shared by the retry options of workflows and the retry policies recorded for workflow instances.
+type Policy struct {
+	FirstRetryInterval     time.Duration
+	MaxRetryInterval       time.Duration
+	BackoffCoefficient     float64
+	NonRetryableErrorTypes []string
+	Jitter                 float64
+
+	// Backoff, if set, replaces the calculation of delays
+	Backoff func(attempt int, err error) time.Duration
+}
+
+// Delay returns the time to wait before the given attempt, after the previous attempt failed with err. seed
+// is used to derive the jitter and has to be deterministic, see JitterSeed.
+func (p Policy) Delay(attempt int, err error, seed uint64) time.Duration {
+	if p.Backoff != nil {
+		return p.Backoff(attempt, err)
+	}
+
+	backoffDuration := time.Duration(float64(p.FirstRetryInterval) * math.Pow(p.BackoffCoefficient, float64(attempt)))
+	if p.MaxRetryInterval > 0 {
+		backoffDuration = time.Duration(math.Min(float64(backoffDuration), float64(p.MaxRetryInterval)))
+	}
+
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1) * float64(seed%10_000) / 10_000
+		backoffDuration -= time.Duration(float64(backoffDuration) * jitter)
+	}
+
+	return backoffDuration
+}
+
+// CanRetry returns true if the given error can be retried with this policy
+func (p Policy) CanRetry(err error) bool {
+	if !workflowerrors.CanRetry(err) {
+		return false
+	}
+
+	if len(p.NonRetryableErrorTypes) > 0 {
+		for e := workflowerrors.FromError(err); e != nil; e, _ = e.Cause.(*workflowerrors.Error) {
+			if slices.Contains(p.NonRetryableErrorTypes, e.Type) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// JitterSeed returns a value that is stable across replays of the given workflow instance, for the given attempt
+// of the operation retried at the given schedule event id.
+func JitterSeed(instance *core.WorkflowInstance, scheduleEventID int64, attempt int) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(instance.InstanceID))
+	h.Write([]byte(instance.ExecutionID))
+	h.Write([]byte(strconv.FormatInt(scheduleEventID, 10)))
+	h.Write([]byte(strconv.Itoa(attempt)))
+
+	return h.Sum64()
+}
@@ -2,6 +2,7 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
@@ -25,7 +26,7 @@ func NewActivityWorker(
 	clock clock.Clock,
 	options WorkerOptions,
 ) *Worker[backend.ActivityTask, history.Event] {
-	ae := activity.NewExecutor(b.Options().Logger, b.Tracer(), b.Options().Converter, b.Options().ContextPropagators, registry)
+	ae := activity.NewExecutor(b.Options().Logger, b.Tracer(), b.Options().Converter, b.Options().ContextPropagators, registry, b.SetActivityTaskPending, b.RecordActivityHeartbeat)
 
 	tw := &ActivityTaskWorker{
 		backend:              b,
@@ -45,6 +46,11 @@ type ActivityTaskWorker struct {
 }
 
 func (atw *ActivityTaskWorker) Complete(ctx context.Context, result *history.Event, task *backend.ActivityTask) error {
+	if result == nil {
+		// Activity will be completed asynchronously
+		return nil
+	}
+
 	if err := atw.backend.CompleteActivityTask(ctx, task, result); err != nil {
 		atw.backend.Options().Logger.Error("completing activity task", "error", err)
 	}
@@ -69,11 +75,26 @@ func (atw *ActivityTaskWorker) Execute(ctx context.Context, task *backend.Activi
 	timeInQueue := time.Since(scheduledAt)
 	ametrics.Distribution(metrickeys.ActivityTaskDelay, metrics.Tags{}, float64(timeInQueue/time.Millisecond))
 
+	// Fail the activity without executing it if it has waited too long to be picked up
+	if a.ScheduleToStartTimeout > 0 && timeInQueue > a.ScheduleToStartTimeout {
+		return atw.resultToEvent(
+			task.Event.ScheduleEventID, nil, nil, workflowerrors.NewActivityTimeoutError(workflowerrors.TimeoutTypeScheduleToStart)), nil
+	}
+
 	timer := im.NewTimer(ametrics, metrickeys.ActivityTaskProcessed, metrics.Tags{})
 	defer timer.Stop()
 
-	result, err := atw.activityTaskExecutor.ExecuteActivity(ctx, task)
-	event := atw.resultToEvent(task.Event.ScheduleEventID, result, err)
+	result, heartbeatDetails, err := atw.activityTaskExecutor.ExecuteActivity(ctx, task)
+	if errors.Is(err, activity.ErrResultPending) {
+		// Ensure the task is not picked up again, even if the activity did not request a task token
+		if err := atw.backend.SetActivityTaskPending(ctx, task); err != nil {
+			return nil, fmt.Errorf("setting activity task pending: %w", err)
+		}
+
+		return nil, nil
+	}
+
+	event := atw.resultToEvent(task.Event.ScheduleEventID, result, heartbeatDetails, err)
 
 	return event, nil
 }
@@ -86,13 +107,14 @@ func (atw *ActivityTaskWorker) Get(ctx context.Context, queues []workflow.Queue)
 	return atw.backend.GetActivityTask(ctx, queues)
 }
 
-func (atw *ActivityTaskWorker) resultToEvent(scheduleEventID int64, result payload.Payload, err error) *history.Event {
+func (atw *ActivityTaskWorker) resultToEvent(scheduleEventID int64, result, heartbeatDetails payload.Payload, err error) *history.Event {
 	if err != nil {
 		return history.NewPendingEvent(
 			atw.clock.Now(),
 			history.EventType_ActivityFailed,
 			&history.ActivityFailedAttributes{
-				Error: workflowerrors.FromError(err),
+				Error:                workflowerrors.FromErrorEncoded(atw.backend.Options().Converter, err),
+				LastHeartbeatDetails: heartbeatDetails,
 			},
 			history.ScheduleEventID(scheduleEventID),
 		)
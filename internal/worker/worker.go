@@ -29,6 +29,9 @@ type Worker[Task, TaskResult any] struct {
 
 	taskQueue chan *Task
 
+	// slots limits the number of tasks being processed in parallel, nil if unlimited
+	slots chan struct{}
+
 	logger *slog.Logger
 
 	pollersWg sync.WaitGroup
@@ -61,10 +64,16 @@ func NewWorker[Task, TaskResult any](
 		options.Queues = append(options.Queues, core.QueueSystem)
 	}
 
+	var slots chan struct{}
+	if options.MaxParallelTasks > 0 {
+		slots = make(chan struct{}, options.MaxParallelTasks)
+	}
+
 	return &Worker[Task, TaskResult]{
 		tw:             tw,
 		options:        options,
 		taskQueue:      make(chan *Task),
+		slots:          slots,
 		logger:         b.Options().Logger,
 		dispatcherDone: make(chan struct{}, 1),
 	}
@@ -114,6 +123,16 @@ func (w *Worker[Task, TaskResult]) poller(ctx context.Context) {
 		default:
 		}
 
+		// Only dequeue tasks that can be processed right away, tasks waiting for a free slot would count
+		// against their lock and timeouts
+		if w.slots != nil {
+			select {
+			case w.slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+
 		task, err := w.poll(ctx, 30*time.Second)
 		if err != nil {
 			w.logger.ErrorContext(ctx, "error polling task", "error", err)
@@ -122,6 +141,8 @@ func (w *Worker[Task, TaskResult]) poller(ctx context.Context) {
 			continue // check for new tasks right away
 		}
 
+		w.releaseSlot()
+
 		if w.options.PollingInterval > 0 {
 			select {
 			case <-ticker.C:
@@ -133,20 +154,9 @@ func (w *Worker[Task, TaskResult]) poller(ctx context.Context) {
 }
 
 func (w *Worker[Task, TaskResult]) dispatcher() {
-	var sem chan struct{}
-
-	if w.options.MaxParallelTasks > 0 {
-		sem = make(chan struct{}, w.options.MaxParallelTasks)
-	}
-
 	var wg sync.WaitGroup
 
 	for t := range w.taskQueue {
-		// If limited max tasks, wait for a slot to open up
-		if sem != nil {
-			sem <- struct{}{}
-		}
-
 		wg.Add(1)
 
 		t := t
@@ -159,9 +169,7 @@ func (w *Worker[Task, TaskResult]) dispatcher() {
 				w.logger.ErrorContext(taskCtx, "error handling task", "error", err)
 			}
 
-			if sem != nil {
-				<-sem
-			}
+			w.releaseSlot()
 		}()
 	}
 
@@ -170,6 +178,13 @@ func (w *Worker[Task, TaskResult]) dispatcher() {
 	w.dispatcherDone <- struct{}{}
 }
 
+// releaseSlot frees up the slot acquired by a poller before dequeuing a task
+func (w *Worker[Task, TaskResult]) releaseSlot() {
+	if w.slots != nil {
+		<-w.slots
+	}
+}
+
 func (w *Worker[Task, TaskResult]) handle(ctx context.Context, t *Task) error {
 	if w.options.HeartbeatInterval > 0 {
 		// Start heartbeat while processing task
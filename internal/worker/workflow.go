@@ -27,6 +27,8 @@ type WorkflowWorkerOptions struct {
 	WorkflowExecutorCache     executor.Cache
 	WorkflowExecutorCacheSize int
 	WorkflowExecutorCacheTTL  time.Duration
+
+	NonDeterminismPolicy executor.NonDeterminismPolicy
 }
 
 func NewWorkflowWorker(
@@ -39,10 +41,11 @@ func NewWorkflowWorker(
 	}
 
 	tw := &WorkflowTaskWorker{
-		backend:  b,
-		registry: registry,
-		cache:    options.WorkflowExecutorCache,
-		logger:   b.Options().Logger,
+		backend:              b,
+		registry:             registry,
+		cache:                options.WorkflowExecutorCache,
+		nonDeterminismPolicy: options.NonDeterminismPolicy,
+		logger:               b.Options().Logger,
 	}
 
 	return NewWorker(b, tw, &options.WorkerOptions)
@@ -53,6 +56,8 @@ type WorkflowTaskWorker struct {
 	registry *registry.Registry
 	cache    executor.Cache
 	logger   *slog.Logger
+
+	nonDeterminismPolicy executor.NonDeterminismPolicy
 }
 
 func (wtw *WorkflowTaskWorker) Start(ctx context.Context, queues []workflow.Queue) error {
@@ -98,12 +103,33 @@ func (wtw *WorkflowTaskWorker) Complete(ctx context.Context, result *executor.Ex
 	if err := wtw.backend.CompleteWorkflowTask(
 		ctx, t, state, result.Executed, result.ActivityEvents, result.TimerEvents, result.WorkflowEvents); err != nil {
 		logger.ErrorContext(ctx, "could not complete workflow task", "error", err)
+
+		// Payloads copied for other workflow instances are not referenced by any history, the task will be
+		// executed again and copy them again
+		if err := wtw.releaseCopiedPayloads(ctx, result.WorkflowEvents); err != nil {
+			logger.ErrorContext(ctx, "could not release copied payloads", "error", err)
+		}
+
 		return fmt.Errorf("completing workflow task: %w", err)
 	}
 
 	return nil
 }
 
+func (wtw *WorkflowTaskWorker) releaseCopiedPayloads(ctx context.Context, workflowEvents []*history.WorkflowEvent) error {
+	cv := wtw.backend.Options().Converter
+	if !backend.StoresPayloads(cv) {
+		return nil
+	}
+
+	events := make([]*history.Event, 0, len(workflowEvents))
+	for _, we := range workflowEvents {
+		events = append(events, we.HistoryEvent)
+	}
+
+	return backend.ReleasePayloads(ctx, cv, backend.StoredPayloads(cv, events))
+}
+
 func (wtw *WorkflowTaskWorker) Execute(ctx context.Context, t *backend.WorkflowTask) (*executor.ExecutionResult, error) {
 	// Record how long this task was in the queue
 	firstEvent := t.NewEvents[0]
@@ -133,6 +159,11 @@ func (wtw *WorkflowTaskWorker) Execute(ctx context.Context, t *backend.WorkflowT
 
 	result, err := executor.ExecuteTask(ctx, t)
 	if err != nil {
+		// The executor might be in an inconsistent state, don't re-use it for the next task
+		if err := wtw.cache.Evict(ctx, t.WorkflowInstance); err != nil {
+			wtw.logger.ErrorContext(ctx, "could not evict workflow executor from cache", "error", err)
+		}
+
 		return nil, fmt.Errorf("executing task: %w", err)
 	}
 
@@ -181,6 +212,14 @@ func (wtw *WorkflowTaskWorker) getExecutor(ctx context.Context, t *backend.Workf
 			t.Metadata,
 			clock.New(),
 			wtw.backend.Options().MaxHistorySize,
+			wtw.nonDeterminismPolicy,
+			executor.Options{
+				// Stay well within the lock of the workflow task when retrying local activities
+				LocalActivityRetryTimeout: wtw.backend.Options().WorkflowLockTimeout / 2,
+
+				MaxHistorySizeBytes:             wtw.backend.Options().MaxHistorySizeBytes,
+				ContinueAsNewSuggestedThreshold: wtw.backend.Options().ContinueAsNewSuggestedThreshold,
+			},
 		)
 		if err != nil {
 			return nil, fmt.Errorf("creating workflow task executor: %w", err)
@@ -0,0 +1,112 @@
+package workflowerrors
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/cschleiden/go-workflows/backend/converter"
+)
+
+// EncodeError encodes the message and stack trace of the given error and its causes, if the given converter
+// implements converter.Codec. The type of the error is kept as is, so that known errors can be restored.
+func EncodeError(c converter.Converter, err *Error) (*Error, error) {
+	codec, ok := c.(converter.Codec)
+	if !ok || err == nil || err.Encoded {
+		return err, nil
+	}
+
+	e := *err
+	e.Encoded = true
+
+	var cerr error
+	if e.Message, cerr = encodeString(codec, e.Message); cerr != nil {
+		return nil, cerr
+	}
+
+	if e.Stacktrace, cerr = encodeString(codec, e.Stacktrace); cerr != nil {
+		return nil, cerr
+	}
+
+	if cause, ok := e.Cause.(*Error); ok && cause != nil {
+		if e.Cause, cerr = EncodeError(c, cause); cerr != nil {
+			return nil, cerr
+		}
+	}
+
+	return &e, nil
+}
+
+// FromErrorEncoded converts the given error for recording it in the history, encoding it if the converter
+// implements converter.Codec. If encoding fails, the encoding error is returned in its place, the original error is
+// never recorded without encoding it.
+func FromErrorEncoded(c converter.Converter, err error) *Error {
+	wfErr, encErr := EncodeError(c, FromError(err))
+	if encErr != nil {
+		return FromError(encErr)
+	}
+
+	return wfErr
+}
+
+// DecodeError decodes an error encoded by EncodeError. Errors that have not been encoded are returned as is.
+func DecodeError(c converter.Converter, err *Error) (*Error, error) {
+	if err == nil || !err.Encoded {
+		return err, nil
+	}
+
+	codec, ok := c.(converter.Codec)
+	if !ok {
+		return nil, fmt.Errorf("error is encoded, but converter does not implement converter.Codec")
+	}
+
+	e := *err
+	e.Encoded = false
+
+	var cerr error
+	if e.Message, cerr = decodeString(codec, e.Message); cerr != nil {
+		return nil, cerr
+	}
+
+	if e.Stacktrace, cerr = decodeString(codec, e.Stacktrace); cerr != nil {
+		return nil, cerr
+	}
+
+	if cause, ok := e.Cause.(*Error); ok && cause != nil {
+		if e.Cause, cerr = DecodeError(c, cause); cerr != nil {
+			return nil, cerr
+		}
+	}
+
+	return &e, nil
+}
+
+func encodeString(codec converter.Codec, s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+
+	p, err := codec.Encode([]byte(s))
+	if err != nil {
+		return "", fmt.Errorf("encoding error: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(p), nil
+}
+
+func decodeString(codec converter.Codec, s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+
+	p, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("decoding error: %w", err)
+	}
+
+	p, err = codec.Decode(p)
+	if err != nil {
+		return "", fmt.Errorf("decoding error: %w", err)
+	}
+
+	return string(p), nil
+}
@@ -0,0 +1,71 @@
+package workflowerrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cschleiden/go-workflows/backend/converter"
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EncodeError_RoundTrip(t *testing.T) {
+	codec, err := converter.NewGzipCodec(-1)
+	require.NoError(t, err)
+	c := converter.NewCodecConverter(converter.DefaultConverter, codec)
+
+	input := FromError(fmt.Errorf("charging card: %w", errors.New("card declined")))
+
+	encoded, err := EncodeError(c, input)
+	require.NoError(t, err)
+	require.True(t, encoded.Encoded)
+	require.NotContains(t, encoded.Message, "card")
+	require.NotContains(t, encoded.Cause.Error(), "card")
+	require.Equal(t, input.Type, encoded.Type)
+
+	// The input is not modified
+	require.Equal(t, "charging card: card declined", input.Message)
+
+	decoded, err := DecodeError(c, encoded)
+	require.NoError(t, err)
+	require.Equal(t, input, decoded)
+}
+
+func Test_EncodeError_WithoutCodec(t *testing.T) {
+	input := FromError(errors.New("foo"))
+
+	encoded, err := EncodeError(converter.DefaultConverter, input)
+	require.NoError(t, err)
+	require.Same(t, input, encoded)
+
+	decoded, err := DecodeError(converter.DefaultConverter, encoded)
+	require.NoError(t, err)
+	require.Same(t, input, decoded)
+}
+
+func Test_DecodeError_EncodedWithoutCodec(t *testing.T) {
+	_, err := DecodeError(converter.DefaultConverter, &Error{Message: "Zm9v", Encoded: true})
+	require.Error(t, err)
+}
+
+type failingCodec struct{}
+
+func (failingCodec) Encode(p payload.Payload) (payload.Payload, error) {
+	return nil, errors.New("codec unavailable")
+}
+
+func (failingCodec) Decode(p payload.Payload) (payload.Payload, error) {
+	return p, nil
+}
+
+func Test_FromErrorEncoded(t *testing.T) {
+	require.Nil(t, FromErrorEncoded(converter.DefaultConverter, nil))
+
+	c := converter.NewCodecConverter(converter.DefaultConverter, failingCodec{})
+
+	// The original error is never returned unencoded
+	e := FromErrorEncoded(c, errors.New("card declined"))
+	require.NotContains(t, e.Message, "card")
+	require.Contains(t, e.Message, "codec unavailable")
+}
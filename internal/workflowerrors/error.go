@@ -12,6 +12,9 @@ type Error struct {
 	Permanent  bool   `json:"permanent,omitempty"`
 	Cause      error  `json:"cause,omitempty"`
 	Stacktrace string `json:"stacktrace,omitempty"`
+
+	// Encoded is true if Message and Stacktrace have been encoded with a codec, see EncodeError
+	Encoded bool `json:"encoded,omitempty"`
 }
 
 func (e *Error) UnmarshalJSON(b []byte) error {
@@ -89,6 +92,18 @@ func ToError(err *Error) error {
 	case getErrorType(&PanicError{}):
 		return &PanicError{message: e.Message, stacktrace: e.Stacktrace}
 
+	case getErrorType(&NonDeterminismError{}):
+		return &NonDeterminismError{message: e.Message}
+
+	case getErrorType(&ActivityTimeoutError{}):
+		return activityTimeoutErrorFromMessage(e.Message)
+
+	case getErrorType(&WorkflowTimeoutError{}):
+		return workflowTimeoutErrorFromMessage(e.Message)
+
+	case getErrorType(&UpdateRejectedError{}):
+		return updateRejectedErrorFromMessage(e.Message)
+
 	default:
 		// Keep *Error
 		return &e
@@ -103,6 +118,17 @@ func NewPermanentError(err error) *Error {
 
 // CanRetry returns true if the given error is retryable
 func CanRetry(err error) bool {
+	// Only individual attempts timing out can be retried
+	var te *ActivityTimeoutError
+	if errors.As(err, &te) {
+		return te.TimeoutType.retryable()
+	}
+
+	var wte *WorkflowTimeoutError
+	if errors.As(err, &wte) {
+		return wte.TimeoutType.retryable()
+	}
+
 	if e, ok := err.(*Error); ok {
 		return !e.Permanent
 	}
@@ -51,6 +51,17 @@ func Test_RoundTrip(t *testing.T) {
 	require.Equal(t, input, output)
 }
 
+func Test_RoundTrip_NonDeterminismError(t *testing.T) {
+	input := &NonDeterminismError{SequenceID: 3, Expected: "ActivityScheduled(a)", Actual: "ScheduleActivity(b)"}
+	e := FromError(input)
+
+	output := ToError(e)
+
+	var nde *NonDeterminismError
+	require.ErrorAs(t, output, &nde)
+	require.Equal(t, input.Error(), output.Error())
+}
+
 func TestCanRetry(t *testing.T) {
 	tests := []struct {
 		name string
@@ -76,3 +87,54 @@ func TestCanRetry(t *testing.T) {
 		})
 	}
 }
+
+func Test_RoundTrip_ActivityTimeoutError(t *testing.T) {
+	input := NewActivityTimeoutError(TimeoutTypeStartToClose)
+	e := FromError(input)
+
+	output := ToError(e)
+	require.Equal(t, input, output)
+	require.ErrorIs(t, output, ErrActivityTimeout)
+	require.NotErrorIs(t, output, ErrHeartbeatTimeout)
+}
+
+func Test_RoundTrip_HeartbeatTimeoutError(t *testing.T) {
+	input := NewActivityTimeoutError(TimeoutTypeHeartbeat)
+	e := FromError(input)
+
+	output := ToError(e)
+	require.Equal(t, input, output)
+	require.ErrorIs(t, output, ErrActivityTimeout)
+	require.ErrorIs(t, output, ErrHeartbeatTimeout)
+}
+
+func Test_CanRetry_ActivityTimeoutError(t *testing.T) {
+	require.True(t, CanRetry(NewActivityTimeoutError(TimeoutTypeStartToClose)))
+	require.True(t, CanRetry(NewActivityTimeoutError(TimeoutTypeHeartbeat)))
+	require.False(t, CanRetry(NewActivityTimeoutError(TimeoutTypeScheduleToStart)))
+	require.False(t, CanRetry(NewActivityTimeoutError(TimeoutTypeScheduleToClose)))
+}
+
+func Test_RoundTrip_WorkflowTimeoutError(t *testing.T) {
+	input := NewWorkflowTimeoutError(TimeoutTypeExecution)
+	e := FromError(input)
+
+	output := ToError(e)
+	require.Equal(t, input, output)
+	require.ErrorIs(t, output, ErrWorkflowTimeout)
+	require.False(t, CanRetry(output))
+}
+
+func Test_RoundTrip_UpdateRejectedError(t *testing.T) {
+	cause := errors.New("value must not be negative")
+	input := NewUpdateRejectedError(cause)
+	require.ErrorIs(t, input, ErrUpdateRejected)
+	require.ErrorIs(t, input, cause)
+
+	output := ToError(FromError(input))
+	require.ErrorIs(t, output, ErrUpdateRejected)
+
+	var ure *UpdateRejectedError
+	require.ErrorAs(t, output, &ure)
+	require.Equal(t, "value must not be negative", ure.Reason)
+}
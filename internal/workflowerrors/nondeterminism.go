@@ -0,0 +1,29 @@
+package workflowerrors
+
+import "fmt"
+
+// NonDeterminismError indicates that the workflow code did not produce the same commands when replaying
+// the history of a workflow instance.
+type NonDeterminismError struct {
+	// SequenceID of the history event that did not match
+	SequenceID int64
+
+	// Expected describes what was recorded in the history
+	Expected string
+
+	// Actual describes what the workflow code produced during replay
+	Actual string
+
+	// message is set when the error is restored from a workflow error, the other fields are not available
+	// in that case.
+	message string
+}
+
+func (e *NonDeterminismError) Error() string {
+	if e.message != "" {
+		return e.message
+	}
+
+	return fmt.Sprintf(
+		"non-deterministic workflow: history event %d recorded %s, but workflow produced %s", e.SequenceID, e.Expected, e.Actual)
+}
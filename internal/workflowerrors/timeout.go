@@ -0,0 +1,95 @@
+package workflowerrors
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrActivityTimeout matches all activity timeout errors when using errors.Is
+var ErrActivityTimeout = errors.New("activity timed out")
+
+// ErrHeartbeatTimeout matches activity timeout errors caused by a missed heartbeat when using errors.Is
+var ErrHeartbeatTimeout = errors.New("activity heartbeat timeout")
+
+// ErrWorkflowTimeout matches all workflow timeout errors when using errors.Is
+var ErrWorkflowTimeout = errors.New("workflow timed out")
+
+type TimeoutType string
+
+const (
+	// TimeoutTypeScheduleToStart indicates that the activity was not picked up by a worker in time
+	TimeoutTypeScheduleToStart TimeoutType = "ScheduleToStart"
+
+	// TimeoutTypeStartToClose indicates that a single attempt of the activity did not finish in time
+	TimeoutTypeStartToClose TimeoutType = "StartToClose"
+
+	// TimeoutTypeScheduleToClose indicates that the activity, including all retries, did not finish in time
+	TimeoutTypeScheduleToClose TimeoutType = "ScheduleToClose"
+
+	// TimeoutTypeHeartbeat indicates that a single attempt of the activity did not record a heartbeat in time
+	TimeoutTypeHeartbeat TimeoutType = "Heartbeat"
+
+	// TimeoutTypeExecution indicates that the workflow instance, including all continued executions, did not
+	// finish in time
+	TimeoutTypeExecution TimeoutType = "Execution"
+
+	// TimeoutTypeRun indicates that a single execution of the workflow instance did not finish in time
+	TimeoutTypeRun TimeoutType = "Run"
+)
+
+// retryable returns whether the timed out operation can be retried, this is only the case for timeouts
+// covering a single attempt.
+func (t TimeoutType) retryable() bool {
+	return t == TimeoutTypeStartToClose || t == TimeoutTypeHeartbeat || t == TimeoutTypeRun
+}
+
+const (
+	activityTimeoutPrefix = "activity timed out: "
+	workflowTimeoutPrefix = "workflow timed out: "
+)
+
+// ActivityTimeoutError is returned when an activity exceeds one of its configured timeouts.
+type ActivityTimeoutError struct {
+	TimeoutType TimeoutType
+}
+
+func NewActivityTimeoutError(timeoutType TimeoutType) *ActivityTimeoutError {
+	return &ActivityTimeoutError{TimeoutType: timeoutType}
+}
+
+func (e *ActivityTimeoutError) Error() string {
+	return activityTimeoutPrefix + string(e.TimeoutType)
+}
+
+func (e *ActivityTimeoutError) Is(target error) bool {
+	return target == ErrActivityTimeout || (target == ErrHeartbeatTimeout && e.TimeoutType == TimeoutTypeHeartbeat)
+}
+
+func activityTimeoutErrorFromMessage(message string) *ActivityTimeoutError {
+	return &ActivityTimeoutError{TimeoutType: TimeoutType(strings.TrimPrefix(message, activityTimeoutPrefix))}
+}
+
+var _ error = (*ActivityTimeoutError)(nil)
+
+// WorkflowTimeoutError is returned when a workflow instance exceeds its execution or run timeout.
+type WorkflowTimeoutError struct {
+	TimeoutType TimeoutType
+}
+
+func NewWorkflowTimeoutError(timeoutType TimeoutType) *WorkflowTimeoutError {
+	return &WorkflowTimeoutError{TimeoutType: timeoutType}
+}
+
+func (e *WorkflowTimeoutError) Error() string {
+	return workflowTimeoutPrefix + string(e.TimeoutType)
+}
+
+func (e *WorkflowTimeoutError) Is(target error) bool {
+	return target == ErrWorkflowTimeout
+}
+
+func workflowTimeoutErrorFromMessage(message string) *WorkflowTimeoutError {
+	return &WorkflowTimeoutError{TimeoutType: TimeoutType(strings.TrimPrefix(message, workflowTimeoutPrefix))}
+}
+
+var _ error = (*WorkflowTimeoutError)(nil)
@@ -0,0 +1,41 @@
+package workflowerrors
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrUpdateRejected matches all update rejection errors when using errors.Is
+var ErrUpdateRejected = errors.New("update rejected")
+
+const updateRejectedPrefix = "update rejected: "
+
+// UpdateRejectedError is returned when the validator of an update handler rejects an update.
+type UpdateRejectedError struct {
+	// Reason is the message of the error returned by the validator
+	Reason string
+
+	cause error
+}
+
+func NewUpdateRejectedError(cause error) *UpdateRejectedError {
+	return &UpdateRejectedError{Reason: cause.Error(), cause: cause}
+}
+
+func (e *UpdateRejectedError) Error() string {
+	return updateRejectedPrefix + e.Reason
+}
+
+func (e *UpdateRejectedError) Is(target error) bool {
+	return target == ErrUpdateRejected
+}
+
+func (e *UpdateRejectedError) Unwrap() error {
+	return e.cause
+}
+
+func updateRejectedErrorFromMessage(message string) *UpdateRejectedError {
+	return &UpdateRejectedError{Reason: strings.TrimPrefix(message, updateRejectedPrefix)}
+}
+
+var _ error = (*UpdateRejectedError)(nil)
@@ -0,0 +1,65 @@
+package workflows
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronSchedule_Next(t *testing.T) {
+	// Wednesday
+	base := time.Date(2024, time.January, 10, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		expr     string
+		after    time.Time
+		expected time.Time
+	}{
+		{"every minute", "* * * * *", base, time.Date(2024, 1, 10, 10, 31, 0, 0, time.UTC)},
+		{"step", "*/15 * * * *", base, time.Date(2024, 1, 10, 10, 45, 0, 0, time.UTC)},
+		{"exact minute is excluded", "30 10 * * *", time.Date(2024, 1, 10, 10, 30, 0, 0, time.UTC), time.Date(2024, 1, 11, 10, 30, 0, 0, time.UTC)},
+		{"list", "0 8,12 * * *", base, time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)},
+		{"range with step", "0 9-17/4 * * *", base, time.Date(2024, 1, 10, 13, 0, 0, 0, time.UTC)},
+		{"start with step", "0 20/2 * * *", base, time.Date(2024, 1, 10, 20, 0, 0, 0, time.UTC)},
+		{"day of week names", "0 9 * * MON-FRI", time.Date(2024, 1, 12, 10, 0, 0, 0, time.UTC), time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)},
+		{"sunday as 7", "0 0 * * 7", base, time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)},
+		{"month names", "0 0 1 mar *", base, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"day of month or day of week", "0 0 20 * 5", base, time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC)},
+		{"leap day", "0 0 29 2 *", base, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"year wrap", "0 0 1 1 *", base, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"hourly", "@hourly", base, time.Date(2024, 1, 10, 11, 0, 0, 0, time.UTC)},
+		{"daily", "@daily", base, time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC)},
+		{"weekly", "@weekly", base, time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)},
+		{"never", "0 0 30 2 *", base, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseCron(tt.expr)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, s.Next(tt.after))
+		})
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every",
+	} {
+		_, err := ParseCron(expr)
+		require.Error(t, err, expr)
+	}
+}
@@ -0,0 +1,391 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend"
+	"github.com/cschleiden/go-workflows/backend/converter"
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/backend/metadata"
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/internal/tracing"
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/google/uuid"
+)
+
+const (
+	maxScheduleIterations = 100
+
+	// bufferedPollInterval is the interval in which a schedule checks whether the last started workflow
+	// instance has finished, while there are buffered executions.
+	bufferedPollInterval = time.Second
+
+	ScheduleInstanceIDPrefix = "schedule-"
+
+	PauseScheduleSignal   = "pause-schedule"
+	ResumeScheduleSignal  = "resume-schedule"
+	TriggerScheduleSignal = "trigger-schedule"
+	DeleteScheduleSignal  = "delete-schedule"
+
+	DescribeScheduleQuery = "describe-schedule"
+)
+
+// OverlapPolicy determines what happens when a schedule fires while the workflow instance started by
+// the previous execution is still running.
+type OverlapPolicy int
+
+const (
+	// OverlapPolicySkip skips the execution.
+	OverlapPolicySkip OverlapPolicy = iota
+
+	// OverlapPolicyBuffer starts the execution once the running workflow instance has finished.
+	OverlapPolicyBuffer
+
+	// OverlapPolicyAllow starts the execution regardless of the running workflow instance.
+	OverlapPolicyAllow
+)
+
+func (p OverlapPolicy) String() string {
+	switch p {
+	case OverlapPolicySkip:
+		return "Skip"
+	case OverlapPolicyBuffer:
+		return "Buffer"
+	case OverlapPolicyAllow:
+		return "Allow"
+	default:
+		return "Unknown"
+	}
+}
+
+type ScheduleSpec struct {
+	// Cron is a cron expression determining when the schedule fires, evaluated in UTC. Exactly one of
+	// Cron and Interval has to be set.
+	Cron string `json:"cron,omitempty"`
+
+	// Interval is the interval in which the schedule fires, starting from its creation.
+	Interval time.Duration `json:"interval,omitempty"`
+
+	// Jitter delays each execution by a random duration up to the given value. The delay is stable for
+	// a given schedule and fire time.
+	Jitter time.Duration `json:"jitter,omitempty"`
+
+	// OverlapPolicy determines what happens when the schedule fires while the previously started workflow
+	// instance is still running. Defaults to OverlapPolicySkip.
+	OverlapPolicy OverlapPolicy `json:"overlap_policy,omitempty"`
+
+	// Queue is the queue scheduled workflow instances are created in. Defaults to the default queue.
+	Queue core.Queue `json:"queue,omitempty"`
+}
+
+// Validate checks that the spec describes a valid schedule.
+func (s *ScheduleSpec) Validate() error {
+	if (s.Cron == "") == (s.Interval == 0) {
+		return errors.New("exactly one of Cron and Interval must be set")
+	}
+
+	if s.Interval < 0 {
+		return errors.New("interval must be positive")
+	}
+
+	if s.Jitter < 0 {
+		return errors.New("jitter must not be negative")
+	}
+
+	if s.Cron != "" {
+		if _, err := ParseCron(s.Cron); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ScheduleState is the state of a schedule, it's carried over when the schedule workflow continues as new.
+type ScheduleState struct {
+	ID       string            `json:"id,omitempty"`
+	Spec     ScheduleSpec      `json:"spec,omitempty"`
+	Workflow string            `json:"workflow,omitempty"`
+	Inputs   []payload.Payload `json:"inputs,omitempty"`
+
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	Paused    bool      `json:"paused,omitempty"`
+
+	// LastFireTime is the nominal time of the last execution, without jitter
+	LastFireTime *time.Time             `json:"last_fire_time,omitempty"`
+	LastInstance *core.WorkflowInstance `json:"last_instance,omitempty"`
+
+	// Buffered is the number of executions waiting for the last workflow instance to finish
+	Buffered int `json:"buffered,omitempty"`
+}
+
+// ScheduleDescription is returned by the describe query of a schedule workflow.
+type ScheduleDescription struct {
+	ID       string       `json:"id,omitempty"`
+	Workflow string       `json:"workflow,omitempty"`
+	Spec     ScheduleSpec `json:"spec,omitempty"`
+
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	Paused    bool      `json:"paused,omitempty"`
+
+	// NextFireTime is the time of the next execution including jitter. It's nil while the schedule is paused.
+	NextFireTime *time.Time             `json:"next_fire_time,omitempty"`
+	LastFireTime *time.Time             `json:"last_fire_time,omitempty"`
+	LastInstance *core.WorkflowInstance `json:"last_instance,omitempty"`
+	Buffered     int                    `json:"buffered,omitempty"`
+}
+
+// ScheduleWorkflowInstances is the system workflow backing a schedule. It starts a new instance of the
+// scheduled workflow every time the schedule fires.
+func ScheduleWorkflowInstances(ctx workflow.Context, state *ScheduleState) error {
+	logger := workflow.Logger(ctx).With(slog.String("schedule_id", state.ID))
+
+	var nextFireTime *time.Time
+	if err := workflow.SetQueryHandler(ctx, DescribeScheduleQuery, func() (*ScheduleDescription, error) {
+		return &ScheduleDescription{
+			ID:           state.ID,
+			Workflow:     state.Workflow,
+			Spec:         state.Spec,
+			CreatedAt:    state.CreatedAt,
+			Paused:       state.Paused,
+			NextFireTime: nextFireTime,
+			LastFireTime: state.LastFireTime,
+			LastInstance: state.LastInstance,
+			Buffered:     state.Buffered,
+		}, nil
+	}); err != nil {
+		return err
+	}
+
+	var cron *CronSchedule
+	if state.Spec.Cron != "" {
+		var err error
+		if cron, err = ParseCron(state.Spec.Cron); err != nil {
+			return fmt.Errorf("parsing cron expression: %w", err)
+		}
+	}
+
+	pause := workflow.NewSignalChannel[any](ctx, PauseScheduleSignal)
+	resume := workflow.NewSignalChannel[any](ctx, ResumeScheduleSignal)
+	trigger := workflow.NewSignalChannel[any](ctx, TriggerScheduleSignal)
+	del := workflow.NewSignalChannel[any](ctx, DeleteScheduleSignal)
+
+	for i := 0; i < maxScheduleIterations; i++ {
+		tctx, cancelTimers := workflow.WithCancel(ctx)
+		deleted := false
+
+		cases := []workflow.SelectCase{
+			workflow.Receive(pause, func(ctx workflow.Context, _ any, _ bool) {
+				state.Paused = true
+			}),
+			workflow.Receive(resume, func(ctx workflow.Context, _ any, _ bool) {
+				state.Paused = false
+			}),
+			workflow.Receive(trigger, func(ctx workflow.Context, _ any, _ bool) {
+				startScheduledWorkflowInstance(ctx, logger, state, workflow.Now(ctx), true)
+			}),
+			workflow.Receive(del, func(ctx workflow.Context, _ any, _ bool) {
+				deleted = true
+			}),
+		}
+
+		nextFireTime = nil
+		if !state.Paused {
+			if next := nextScheduleTime(state, cron, workflow.Now(ctx)); !next.IsZero() {
+				fireAt := next.Add(scheduleJitter(state, next))
+				nextFireTime = &fireAt
+
+				cases = append(cases, workflow.Await(
+					workflow.ScheduleTimer(tctx, fireAt.Sub(workflow.Now(ctx)), workflow.WithTimerName("Schedule")),
+					func(ctx workflow.Context, _ workflow.Future[any]) {
+						state.LastFireTime = &next
+						startScheduledWorkflowInstance(ctx, logger, state, next, false)
+					}))
+			}
+		}
+
+		if state.Buffered > 0 {
+			cases = append(cases, workflow.Await(
+				workflow.ScheduleTimer(tctx, bufferedPollInterval, workflow.WithTimerName("Schedule-Buffered")),
+				func(ctx workflow.Context, _ workflow.Future[any]) {
+					if !isWorkflowInstanceActive(ctx, logger, state.LastInstance) {
+						state.Buffered--
+						startScheduledWorkflowInstance(ctx, logger, state, workflow.Now(ctx), true)
+					}
+				}))
+		}
+
+		workflow.Select(ctx, cases...)
+		cancelTimers()
+
+		if deleted {
+			logger.Info("schedule deleted")
+			return nil
+		}
+	}
+
+	return workflow.ContinueAsNew(ctx, state)
+}
+
+// nextScheduleTime returns the nominal time the schedule fires next, after the last fire time and now.
+func nextScheduleTime(state *ScheduleState, cron *CronSchedule, now time.Time) time.Time {
+	after := now
+	if state.LastFireTime != nil && state.LastFireTime.After(after) {
+		after = *state.LastFireTime
+	}
+
+	if cron != nil {
+		return cron.Next(after)
+	}
+
+	// Intervals are anchored at the creation of the schedule, missed executions are skipped
+	interval := state.Spec.Interval
+	if after.Before(state.CreatedAt) {
+		return state.CreatedAt.Add(interval)
+	}
+
+	return state.CreatedAt.Add((after.Sub(state.CreatedAt)/interval + 1) * interval)
+}
+
+// scheduleJitter returns a deterministic delay for the given fire time, so that replaying the schedule
+// workflow results in the same timers.
+func scheduleJitter(state *ScheduleState, next time.Time) time.Duration {
+	if state.Spec.Jitter <= 0 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(state.ID))
+	h.Write([]byte(next.UTC().Format(time.RFC3339Nano)))
+
+	return time.Duration(h.Sum64() % uint64(state.Spec.Jitter))
+}
+
+// startScheduledWorkflowInstance starts a new instance of the scheduled workflow, honoring the overlap
+// policy unless force is set.
+func startScheduledWorkflowInstance(ctx workflow.Context, logger *slog.Logger, state *ScheduleState, at time.Time, force bool) {
+	if !force && state.Spec.OverlapPolicy != OverlapPolicyAllow && isWorkflowInstanceActive(ctx, logger, state.LastInstance) {
+		if state.Spec.OverlapPolicy == OverlapPolicyBuffer {
+			state.Buffered++
+			logger.Debug("buffering scheduled execution", slog.Int("buffered", state.Buffered))
+		} else {
+			logger.Debug("skipping scheduled execution, previous workflow instance still running")
+		}
+
+		return
+	}
+
+	// Instance IDs are derived from the fire time, so retrying the activity does not start the workflow twice
+	executionID := workflow.SideEffect(ctx, func(ctx workflow.Context) string {
+		return uuid.NewString()
+	})
+	id, err := executionID.Get(ctx)
+	if err != nil {
+		logger.Error("generating execution id", slog.Any("error", err))
+		return
+	}
+
+	instance := core.NewWorkflowInstance(fmt.Sprintf("%s-%d", state.ID, at.UnixMilli()), id)
+
+	var a *Activities
+	if _, err := workflow.ExecuteActivity[any](ctx, workflow.ActivityOptions{
+		Queue: core.QueueSystem,
+		RetryOptions: workflow.RetryOptions{
+			MaxAttempts: 3,
+		},
+	}, a.StartScheduledWorkflowInstance, instance, state.Workflow, state.Spec.Queue, state.Inputs).Get(ctx); err != nil {
+		logger.Error("starting scheduled workflow instance", slog.Any("error", err))
+		return
+	}
+
+	state.LastInstance = instance
+}
+
+func isWorkflowInstanceActive(ctx workflow.Context, logger *slog.Logger, instance *core.WorkflowInstance) bool {
+	if instance == nil {
+		return false
+	}
+
+	var a *Activities
+	active, err := workflow.ExecuteActivity[bool](ctx, workflow.ActivityOptions{
+		Queue: core.QueueSystem,
+		RetryOptions: workflow.RetryOptions{
+			MaxAttempts: 3,
+		},
+	}, a.IsWorkflowInstanceActive, instance).Get(ctx)
+	if err != nil {
+		logger.Error("getting state of scheduled workflow instance", slog.Any("error", err))
+
+		// Err on the side of not starting overlapping executions
+		return true
+	}
+
+	return active
+}
+
+func (a *Activities) StartScheduledWorkflowInstance(
+	ctx context.Context, instance *core.WorkflowInstance, name string, queue core.Queue, inputs []payload.Payload,
+) error {
+	if queue == "" {
+		queue = core.QueueDefault
+	}
+
+	// Every run owns the payloads in its history, don't share stored payloads with the schedule
+	if ps, ok := a.Backend.Options().Converter.(converter.PayloadStore); ok {
+		copied := make([]payload.Payload, len(inputs))
+		for i, input := range inputs {
+			var err error
+			if copied[i], err = ps.CopyPayload(ctx, input); err != nil {
+				return fmt.Errorf("copying stored payload: %w", err)
+			}
+		}
+
+		inputs = copied
+	}
+
+	startedEvent := history.NewPendingEvent(
+		time.Now(),
+		history.EventType_WorkflowExecutionStarted,
+		&history.ExecutionStartedAttributes{
+			Queue:          queue,
+			Metadata:       &metadata.WorkflowMetadata{},
+			Name:           name,
+			Inputs:         inputs,
+			WorkflowSpanID: tracing.GetNewSpanID(a.Backend.Tracer()),
+		})
+
+	if err := a.Backend.CreateWorkflowInstance(ctx, instance, startedEvent); err != nil {
+		// The instance has not been created with the copied payloads
+		if err := backend.ReleasePayloads(ctx, a.Backend.Options().Converter, inputs); err != nil {
+			return err
+		}
+
+		// A previous attempt might have created the instance already
+		if errors.Is(err, backend.ErrInstanceAlreadyExists) {
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (a *Activities) IsWorkflowInstanceActive(ctx context.Context, instance *core.WorkflowInstance) (bool, error) {
+	state, err := a.Backend.GetWorkflowInstanceState(ctx, instance)
+	if err != nil {
+		if errors.Is(err, backend.ErrInstanceNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return state == core.WorkflowInstanceStateActive, nil
+}
@@ -48,6 +48,16 @@ func AsDecodingSettable[T any](cv converter.Converter, name string, f sync.Setta
 	}
 }
 
+type QueryHandler func(args []payload.Payload) (payload.Payload, error)
+
+type UpdateHandler struct {
+	// Validate rejects an update before it is handled, can be nil
+	Validate func(arg payload.Payload) error
+
+	// Handle is invoked in its own coroutine and may block
+	Handle func(ctx sync.Context, arg payload.Payload) (payload.Payload, error)
+}
+
 type signalChannel struct {
 	receive func(payload.Payload)
 	channel interface{}
@@ -63,6 +73,38 @@ type WfState struct {
 	pendingSignals map[string][]payload.Payload
 	signalChannels map[string]*signalChannel
 
+	// versions determined during this execution
+	versions map[string]int
+
+	// versions recorded in the history that is being replayed
+	recordedVersions map[string]int
+
+	queryHandlers map[string]QueryHandler
+
+	updateHandlers map[string]*UpdateHandler
+
+	// current encoded search attributes of the workflow instance
+	searchAttributes map[string]string
+
+	// attempt of the current execution, if the workflow instance is retried
+	attempt int
+
+	// heartbeat details of failed activities by their schedule event id
+	activityHeartbeatDetails map[int64]payload.Payload
+
+	// local activities to retry after a timer by their schedule event id, and the time until which local
+	// activities can be retried within the current workflow task
+	localActivityRetries       map[int64]LocalActivityRetry
+	localActivityRetryDeadline time.Time
+
+	// number of events and approximate size of the history of the current execution
+	historyLength    int64
+	historySizeBytes int64
+
+	// history length and size at which continuing as new is suggested, 0 if never
+	continueAsNewSuggestedLength    int64
+	continueAsNewSuggestedSizeBytes int64
+
 	logger *slog.Logger
 	tracer trace.Tracer
 
@@ -80,6 +122,19 @@ func NewWorkflowState(instance *core.WorkflowInstance, logger *slog.Logger, trac
 		pendingSignals: map[string][]payload.Payload{},
 		signalChannels: make(map[string]*signalChannel),
 
+		versions:         map[string]int{},
+		recordedVersions: map[string]int{},
+
+		queryHandlers: map[string]QueryHandler{},
+
+		updateHandlers: map[string]*UpdateHandler{},
+
+		searchAttributes: map[string]string{},
+
+		activityHeartbeatDetails: map[int64]payload.Payload{},
+
+		localActivityRetries: map[int64]LocalActivityRetry{},
+
 		tracer: tracer,
 
 		clock: clock,
@@ -106,6 +161,11 @@ func (wf *WfState) GetNextScheduleEventID() int64 {
 	return scheduleEventID
 }
 
+// PeekNextScheduleEventID returns the schedule event ID the next call to GetNextScheduleEventID will return
+func (wf *WfState) PeekNextScheduleEventID() int64 {
+	return wf.scheduleEventID
+}
+
 func (wf *WfState) TrackFuture(scheduleEventID int64, f *DecodingSettable) {
 	wf.pendingFutures[scheduleEventID] = f
 }
@@ -150,6 +210,93 @@ func (wf *WfState) CommandByScheduleEventID(scheduleEventID int64) command.Comma
 	return nil
 }
 
+func (wf *WfState) Version(changeID string) (int, bool) {
+	v, ok := wf.versions[changeID]
+	return v, ok
+}
+
+func (wf *WfState) SetVersion(changeID string, version int) {
+	wf.versions[changeID] = version
+}
+
+func (wf *WfState) RecordedVersion(changeID string) (int, bool) {
+	v, ok := wf.recordedVersions[changeID]
+	return v, ok
+}
+
+func (wf *WfState) RecordVersion(changeID string, version int) {
+	wf.recordedVersions[changeID] = version
+}
+
+func (wf *WfState) SearchAttributes() map[string]string {
+	return wf.searchAttributes
+}
+
+func (wf *WfState) UpsertSearchAttributes(searchAttributes map[string]string) {
+	for name, value := range searchAttributes {
+		wf.searchAttributes[name] = value
+	}
+}
+
+func (wf *WfState) SetQueryHandler(name string, handler QueryHandler) {
+	wf.queryHandlers[name] = handler
+}
+
+func (wf *WfState) QueryHandler(name string) (QueryHandler, bool) {
+	h, ok := wf.queryHandlers[name]
+	return h, ok
+}
+
+func (wf *WfState) SetUpdateHandler(name string, handler *UpdateHandler) {
+	wf.updateHandlers[name] = handler
+}
+
+func (wf *WfState) UpdateHandler(name string) (*UpdateHandler, bool) {
+	h, ok := wf.updateHandlers[name]
+	return h, ok
+}
+
+func (wf *WfState) SetActivityHeartbeatDetails(scheduleEventID int64, details payload.Payload) {
+	wf.activityHeartbeatDetails[scheduleEventID] = details
+}
+
+// TakeActivityHeartbeatDetails returns and removes the heartbeat details recorded for the given activity
+func (wf *WfState) TakeActivityHeartbeatDetails(scheduleEventID int64) payload.Payload {
+	details := wf.activityHeartbeatDetails[scheduleEventID]
+	delete(wf.activityHeartbeatDetails, scheduleEventID)
+	return details
+}
+
+// LocalActivityRetry describes a local activity that is retried after a timer
+type LocalActivityRetry struct {
+	// Attempts is the number of attempts made before the timer
+	Attempts int
+
+	// RetryAfter is the delay of the timer
+	RetryAfter time.Duration
+}
+
+func (wf *WfState) SetLocalActivityRetry(scheduleEventID int64, retry LocalActivityRetry) {
+	wf.localActivityRetries[scheduleEventID] = retry
+}
+
+// TakeLocalActivityRetry returns and removes the retry recorded for the given local activity, if any
+func (wf *WfState) TakeLocalActivityRetry(scheduleEventID int64) (LocalActivityRetry, bool) {
+	retry, ok := wf.localActivityRetries[scheduleEventID]
+	delete(wf.localActivityRetries, scheduleEventID)
+	return retry, ok
+}
+
+func (wf *WfState) SetLocalActivityRetryDeadline(deadline time.Time) {
+	wf.localActivityRetryDeadline = deadline
+}
+
+// LocalActivityRetryDeadline returns the time until which local activities can be retried within the current
+// workflow task. The zero time means there is no limit.
+func (wf *WfState) LocalActivityRetryDeadline() time.Time {
+	return wf.localActivityRetryDeadline
+}
+
 func (wf *WfState) SetReplaying(replaying bool) {
 	wf.replaying = replaying
 }
@@ -166,6 +313,37 @@ func (wf *WfState) Time() time.Time {
 	return wf.time
 }
 
+func (wf *WfState) SetAttempt(attempt int) {
+	wf.attempt = attempt
+}
+
+func (wf *WfState) Attempt() int {
+	return wf.attempt
+}
+
+func (wf *WfState) AddHistoryEvent(sizeBytes int64) {
+	wf.historyLength++
+	wf.historySizeBytes += sizeBytes
+}
+
+func (wf *WfState) HistoryLength() int64 {
+	return wf.historyLength
+}
+
+func (wf *WfState) HistorySizeBytes() int64 {
+	return wf.historySizeBytes
+}
+
+func (wf *WfState) SetContinueAsNewSuggestedAt(length, sizeBytes int64) {
+	wf.continueAsNewSuggestedLength = length
+	wf.continueAsNewSuggestedSizeBytes = sizeBytes
+}
+
+func (wf *WfState) ContinueAsNewSuggested() bool {
+	return (wf.continueAsNewSuggestedLength > 0 && wf.historyLength >= wf.continueAsNewSuggestedLength) ||
+		(wf.continueAsNewSuggestedSizeBytes > 0 && wf.historySizeBytes >= wf.continueAsNewSuggestedSizeBytes)
+}
+
 func (wf *WfState) Instance() *core.WorkflowInstance {
 	return wf.instance
 }
@@ -15,6 +15,9 @@ type options struct {
 	Propagators    []workflow.ContextPropagator
 	InitialTime    time.Time
 	MaxHistorySize int64
+
+	MaxHistorySizeBytes             int64
+	ContinueAsNewSuggestedThreshold float64
 }
 
 type WorkflowTesterOption func(*options)
@@ -54,3 +57,15 @@ func WithMaxHistorySize(size int64) WorkflowTesterOption {
 		o.MaxHistorySize = size
 	}
 }
+
+func WithMaxHistorySizeBytes(size int64) WorkflowTesterOption {
+	return func(o *options) {
+		o.MaxHistorySizeBytes = size
+	}
+}
+
+func WithContinueAsNewSuggestedThreshold(threshold float64) WorkflowTesterOption {
+	return func(o *options) {
+		o.ContinueAsNewSuggestedThreshold = threshold
+	}
+}
@@ -198,6 +198,8 @@ func NewWorkflowTester[TResult any](workflow workflow.Workflow, opts ...Workflow
 		Logger:         slog.Default(),
 		Converter:      converter.DefaultConverter,
 		MaxHistorySize: 10_000,
+
+		ContinueAsNewSuggestedThreshold: 0.8,
 	}
 
 	for _, o := range opts {
@@ -369,6 +371,11 @@ func (wt *workflowTester[TResult]) Execute(ctx context.Context, args ...any) {
 				tw.metadata,
 				wt.clock,
 				wt.options.MaxHistorySize,
+				executor.NonDeterminismPolicyFailWorkflow,
+				executor.Options{
+					MaxHistorySizeBytes:             wt.options.MaxHistorySizeBytes,
+					ContinueAsNewSuggestedThreshold: wt.options.ContinueAsNewSuggestedThreshold,
+				},
 			)
 			if err != nil {
 				panic(fmt.Errorf("could not create workflow executor: %v", err))
@@ -398,7 +405,12 @@ func (wt *workflowTester[TResult]) Execute(ctx context.Context, args ...any) {
 					}
 
 				case history.EventType_TimerCanceled:
-					wt.cancelTimer(tw.instance, event)
+					wt.cancelTimer(tw.instance, event.ScheduleEventID)
+				}
+
+				// Remove the workflow timeout timer, if any, once the execution is done
+				if event.Type == history.EventType_WorkflowExecutionFinished || event.Type == history.EventType_WorkflowExecutionContinuedAsNew {
+					wt.cancelTimer(tw.instance, 0)
 				}
 			}
 
@@ -428,6 +440,11 @@ func (wt *workflowTester[TResult]) Execute(ctx context.Context, args ...any) {
 
 			// Schedule timers
 			for _, timerEvent := range result.TimerEvents {
+				if timerEvent.Type == history.EventType_ActivityFailed {
+					// Activities are started right away, their ScheduleToStart timeout cannot expire
+					continue
+				}
+
 				gotNewEvents = true
 				wt.logger.Debug("Timer future event", log.EventTypeKey, timerEvent.Type, log.AtKey, *timerEvent.VisibleAt)
 
@@ -658,6 +675,7 @@ func (wt *workflowTester[TResult]) scheduleActivity(wfi *core.WorkflowInstance,
 
 		var activityErr error
 		var activityResult payload.Payload
+		var heartbeatDetails payload.Payload
 
 		// Execute mocked activity. If an activity is mocked once, we'll never fall back to the original implementation
 		if wt.mockedActivities[e.Name] {
@@ -716,8 +734,8 @@ func (wt *workflowTester[TResult]) scheduleActivity(wfi *core.WorkflowInstance,
 			}
 
 		} else {
-			executor := activity.NewExecutor(wt.logger, wt.tracer, wt.converter, wt.propagators, wt.registry)
-			activityResult, activityErr = executor.ExecuteActivity(context.Background(), &backend.ActivityTask{
+			executor := activity.NewExecutor(wt.logger, wt.tracer, wt.converter, wt.propagators, wt.registry, nil, nil)
+			activityResult, heartbeatDetails, activityErr = executor.ExecuteActivity(context.Background(), &backend.ActivityTask{
 				ID:               uuid.NewString(),
 				WorkflowInstance: wfi,
 				Event:            event,
@@ -734,7 +752,8 @@ func (wt *workflowTester[TResult]) scheduleActivity(wfi *core.WorkflowInstance,
 					wt.clock.Now(),
 					history.EventType_ActivityFailed,
 					&history.ActivityFailedAttributes{
-						Error: aerr,
+						Error:                aerr,
+						LastHeartbeatDetails: heartbeatDetails,
 					},
 					history.ScheduleEventID(event.ScheduleEventID),
 				)
@@ -758,12 +777,10 @@ func (wt *workflowTester[TResult]) scheduleActivity(wfi *core.WorkflowInstance,
 }
 
 func (wt *workflowTester[TResult]) scheduleTimer(instance *core.WorkflowInstance, event *history.Event) {
-	e := event.Attributes.(*history.TimerFiredAttributes)
-
 	wt.timers = append(wt.timers, &testTimer{
 		Instance:        instance,
 		ScheduleEventID: event.ScheduleEventID,
-		At:              e.At,
+		At:              *event.VisibleAt,
 		TimerEvent: &history.WorkflowEvent{
 			WorkflowInstance: instance,
 			HistoryEvent:     event,
@@ -775,9 +792,9 @@ func (wt *workflowTester[TResult]) scheduleTimer(instance *core.WorkflowInstance
 	})
 }
 
-func (wt *workflowTester[TResult]) cancelTimer(instance *core.WorkflowInstance, event *history.Event) {
+func (wt *workflowTester[TResult]) cancelTimer(instance *core.WorkflowInstance, scheduleEventID int64) {
 	for i, t := range wt.timers {
-		if t.Instance != nil && t.Instance.InstanceID == instance.InstanceID && t.ScheduleEventID == event.ScheduleEventID {
+		if t.Instance != nil && t.Instance.InstanceID == instance.InstanceID && t.ScheduleEventID == scheduleEventID {
 			// If this was the next timer to fire, stop the timer
 			if t.wallClockTimer != nil {
 				t.wallClockTimer.Stop()
@@ -809,6 +826,11 @@ func (wt *workflowTester[TResult]) addWorkflow(instance *core.WorkflowInstance,
 	wt.testWorkflows = append(wt.testWorkflows, tw)
 	wt.testWorkflowsByInstanceID[instance.InstanceID] = tw
 
+	// Enforce the timeouts of the new execution, like backends do when creating it
+	if timeoutEvent := history.NewWorkflowTimeoutEvent(initialEvent); timeoutEvent != nil {
+		wt.scheduleTimer(instance, timeoutEvent)
+	}
+
 	return tw
 }
 
@@ -820,6 +842,11 @@ func (wt *workflowTester[TResult]) scheduleSubWorkflow(event *history.WorkflowEv
 		wt.subWorkflowListener(event.WorkflowInstance, a.Name)
 	}
 
+	// Notify the parent of a detached sub-workflow instance once it has been created, like the backends do
+	if a.Detached {
+		wt.sendEvent(event.WorkflowInstance.Parent, history.NewSubWorkflowStartedEvent(wt.clock.Now(), event.WorkflowInstance))
+	}
+
 	wfn, err := wt.registry.GetWorkflow(a.Name)
 	if err != nil {
 		panic("Could not find workflow " + a.Name + " in registry")
@@ -2,6 +2,7 @@ package tester
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/cschleiden/go-workflows/workflow"
@@ -58,3 +59,87 @@ func Test_ContinueAsNew_SubWorkflow(t *testing.T) {
 	require.Equal(t, 3, wfR)
 	tester.AssertExpectations(t)
 }
+
+func Test_ContinueAsNew_DifferentWorkflow(t *testing.T) {
+	wfV2 := func(ctx workflow.Context, run int) (int, error) {
+		return run * 10, nil
+	}
+
+	wf := func(ctx workflow.Context, run int) (int, error) {
+		return run, workflow.ContinueAsNewWithOptions(ctx, workflow.ContinueAsNewOptions{
+			Workflow: wfV2,
+		}, run+1)
+	}
+
+	tester := NewWorkflowTester[int](wf)
+
+	tester.registry.RegisterWorkflow(wfV2)
+
+	tester.Execute(context.Background(), 1)
+
+	require.True(t, tester.WorkflowFinished())
+
+	wfR, _ := tester.WorkflowResult()
+	require.Equal(t, 20, wfR)
+	tester.AssertExpectations(t)
+}
+
+func Test_ContinueAsNewSuggested(t *testing.T) {
+	a := func(ctx context.Context) (int, error) {
+		return 0, nil
+	}
+
+	wf := func(ctx workflow.Context) (int64, error) {
+		for !workflow.ContinueAsNewSuggested(ctx) {
+			if _, err := workflow.ExecuteActivity[int](ctx, workflow.DefaultActivityOptions, a).Get(ctx); err != nil {
+				return 0, err
+			}
+		}
+
+		return workflow.HistoryLength(ctx), nil
+	}
+
+	tester := NewWorkflowTester[int64](wf, WithMaxHistorySize(20), WithContinueAsNewSuggestedThreshold(0.5))
+
+	tester.Registry().RegisterActivity(a)
+
+	tester.Execute(context.Background())
+
+	require.True(t, tester.WorkflowFinished())
+
+	wfR, wfErr := tester.WorkflowResult()
+	require.NoError(t, wfErr)
+	require.GreaterOrEqual(t, wfR, int64(10))
+	require.Less(t, wfR, int64(20))
+	tester.AssertExpectations(t)
+}
+
+func Test_ContinueAsNewSuggested_HistorySizeBytes(t *testing.T) {
+	a := func(ctx context.Context) (string, error) {
+		return strings.Repeat("a", 100), nil
+	}
+
+	wf := func(ctx workflow.Context) (int64, error) {
+		for !workflow.ContinueAsNewSuggested(ctx) {
+			if _, err := workflow.ExecuteActivity[string](ctx, workflow.DefaultActivityOptions, a).Get(ctx); err != nil {
+				return 0, err
+			}
+		}
+
+		return workflow.HistorySizeBytes(ctx), nil
+	}
+
+	tester := NewWorkflowTester[int64](wf, WithMaxHistorySizeBytes(1_000), WithContinueAsNewSuggestedThreshold(0.5))
+
+	tester.Registry().RegisterActivity(a)
+
+	tester.Execute(context.Background())
+
+	require.True(t, tester.WorkflowFinished())
+
+	wfR, wfErr := tester.WorkflowResult()
+	require.NoError(t, wfErr)
+	require.GreaterOrEqual(t, wfR, int64(500))
+	require.Less(t, wfR, int64(1_000))
+	tester.AssertExpectations(t)
+}
@@ -44,6 +44,38 @@ func Test_SubWorkflow(t *testing.T) {
 	tester.AssertExpectations(t)
 }
 
+func Test_SubWorkflow_Detached(t *testing.T) {
+	subWorkflow := func(ctx workflow.Context, input string) (string, error) {
+		return input + "sresult", nil
+	}
+
+	workflowWithSub := func(ctx workflow.Context, input string) (string, error) {
+		instance, err := workflow.StartSubWorkflow(
+			ctx,
+			workflow.DefaultSubWorkflowOptions,
+			subWorkflow,
+			input,
+		).Get(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		return instance.InstanceID, nil
+	}
+
+	tester := NewWorkflowTester[string](workflowWithSub)
+	tester.Registry().RegisterWorkflow(subWorkflow)
+
+	tester.Execute(context.Background(), "hello")
+
+	require.True(t, tester.WorkflowFinished())
+
+	wfR, wfErr := tester.WorkflowResult()
+	require.NoError(t, wfErr)
+	require.NotEmpty(t, wfR)
+	tester.AssertExpectations(t)
+}
+
 func Test_SubWorkflow_Mocked(t *testing.T) {
 	subWorkflow := func(ctx workflow.Context, input string) (string, error) {
 		panic("should not call this")
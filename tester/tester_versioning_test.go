@@ -0,0 +1,59 @@
+package tester
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/workflow"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetVersion(t *testing.T) {
+	tester := NewWorkflowTester[workflow.Version](workflowVersion)
+
+	tester.Execute(context.Background())
+
+	require.True(t, tester.WorkflowFinished())
+	wr, werr := tester.WorkflowResult()
+	require.NoError(t, werr)
+	require.Equal(t, workflow.Version(2), wr)
+}
+
+func workflowVersion(ctx workflow.Context) (workflow.Version, error) {
+	v, err := workflow.GetVersion(ctx, "change", workflow.DefaultVersion, 2)
+	if err != nil {
+		return 0, err
+	}
+
+	// Replays the history on the next task
+	workflow.ScheduleTimer(ctx, time.Second).Get(ctx)
+
+	// Version stays the same for the execution
+	v2, err := workflow.GetVersion(ctx, "change", workflow.DefaultVersion, 2)
+	if err != nil {
+		return 0, err
+	}
+
+	if v != v2 {
+		return 0, errors.New("version changed")
+	}
+
+	return v, nil
+}
+
+func Test_GetVersion_Unsupported(t *testing.T) {
+	wf := func(ctx workflow.Context) error {
+		_, err := workflow.GetVersion(ctx, "change", 3, 2)
+		return err
+	}
+
+	tester := NewWorkflowTester[any](wf)
+
+	tester.Execute(context.Background())
+
+	require.True(t, tester.WorkflowFinished())
+	_, werr := tester.WorkflowResult()
+	require.ErrorContains(t, werr, `version 2 for change "change" is not supported`)
+}
@@ -36,6 +36,12 @@ type WorkflowWorkerOptions struct {
 
 	// WorkflowQueues are the queue the worker listens to
 	WorkflowQueues []workflow.Queue
+
+	// WorkflowNonDeterminismPolicy determines what happens when workflow code does not match the history
+	// of an instance during replay. By default the workflow instance is failed with a NonDeterminismError.
+	// Use executor.NonDeterminismPolicyBlockWorkflow to keep retrying the workflow task instead, for example
+	// to be able to roll back a faulty deployment.
+	WorkflowNonDeterminismPolicy executor.NonDeterminismPolicy
 }
 
 type Options struct {
@@ -74,6 +80,8 @@ var DefaultOptions = Options{
 		WorkflowExecutorCacheSize: 128,
 		WorkflowExecutorCacheTTL:  time.Second * 10,
 		WorkflowExecutorCache:     nil,
+
+		WorkflowNonDeterminismPolicy: executor.NonDeterminismPolicyFailWorkflow,
 	},
 
 	ActivityWorkerOptions: ActivityWorkerOptions{
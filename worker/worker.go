@@ -70,6 +70,10 @@ func newWorker(backend backend.Backend, registry *registry.Registry, workers []w
 		panic(fmt.Errorf("registering internal workflow: %w", err))
 	}
 
+	if err := registry.RegisterWorkflow(workflows.ScheduleWorkflowInstances); err != nil {
+		panic(fmt.Errorf("registering internal workflow: %w", err))
+	}
+
 	return &Worker{
 		backend: backend,
 
@@ -110,6 +114,7 @@ func newWorkflowWorker(backend backend.Backend, registry *registry.Registry, opt
 		WorkflowExecutorCache:     options.WorkflowExecutorCache,
 		WorkflowExecutorCacheSize: options.WorkflowExecutorCacheSize,
 		WorkflowExecutorCacheTTL:  options.WorkflowExecutorCacheTTL,
+		NonDeterminismPolicy:      options.WorkflowNonDeterminismPolicy,
 	})
 
 	return workflowWorker
@@ -140,6 +145,12 @@ func (w *Worker) WaitForCompletion() error {
 	return nil
 }
 
+// Registry returns the registry of workflows and activities of the worker. Use it with client.WithRegistry
+// to allow a client to replay workflow instances, for example to query them.
+func (w *Worker) Registry() *registry.Registry {
+	return w.registry
+}
+
 // RegisterWorkflow registers a workflow with the worker's registry.
 func (w *Worker) RegisterWorkflow(wf workflow.Workflow, opts ...registry.RegisterOption) error {
 	return w.registry.RegisterWorkflow(wf, opts...)
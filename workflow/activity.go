@@ -2,13 +2,16 @@ package workflow
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/cschleiden/go-workflows/backend/payload"
 	"github.com/cschleiden/go-workflows/core"
 	a "github.com/cschleiden/go-workflows/internal/args"
 	"github.com/cschleiden/go-workflows/internal/command"
 	"github.com/cschleiden/go-workflows/internal/contextvalue"
 	"github.com/cschleiden/go-workflows/internal/fn"
 	"github.com/cschleiden/go-workflows/internal/sync"
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
 	"github.com/cschleiden/go-workflows/internal/workflowstate"
 )
 
@@ -19,6 +22,25 @@ type ActivityOptions struct {
 
 	// RetryOptions defines how to retry the activity in case of failure.
 	RetryOptions RetryOptions
+
+	// HeartbeatTimeout is the maximum time between heartbeats recorded by the activity using
+	// activity.RecordHeartbeat. If it is exceeded, the activity is failed with activity.ErrHeartbeatTimeout
+	// and retried according to RetryOptions. Defaults to 0, which disables heartbeat timeouts.
+	HeartbeatTimeout time.Duration
+
+	// ScheduleToStartTimeout is the maximum time an activity task can wait in the queue before it is picked
+	// up by a worker. If it is exceeded, the activity is failed with ErrActivityTimeout and not retried. Defaults
+	// to 0, which means no timeout.
+	ScheduleToStartTimeout time.Duration
+
+	// StartToCloseTimeout is the maximum time a single attempt of the activity can take. If it is exceeded, the
+	// attempt is failed with ErrActivityTimeout and retried according to RetryOptions. Defaults to 0, which
+	// means no timeout.
+	StartToCloseTimeout time.Duration
+
+	// ScheduleToCloseTimeout is the maximum time the activity can take including all retries. If it is exceeded,
+	// the activity is failed with ErrActivityTimeout. Defaults to 0, which means no timeout.
+	ScheduleToCloseTimeout time.Duration
 }
 
 var DefaultActivityOptions = ActivityOptions{
@@ -27,36 +49,88 @@ var DefaultActivityOptions = ActivityOptions{
 
 // ExecuteActivity schedules the given activity to be executed
 func ExecuteActivity[TResult any](ctx Context, options ActivityOptions, activity Activity, args ...any) Future[TResult] {
+	if options.ScheduleToCloseTimeout <= 0 {
+		return executeActivityWithRetries[TResult](ctx, options, nil, activity, args...)
+	}
+
+	deadline := Now(ctx).Add(options.ScheduleToCloseTimeout)
+
+	f := sync.NewFuture[TResult]()
+
+	Go(ctx, func(ctx Context) {
+		actx, cancelActivity := WithCancel(ctx)
+		defer cancelActivity()
+
+		tctx, cancelTimer := WithCancel(ctx)
+		defer cancelTimer()
+
+		af := executeActivityWithRetries[TResult](actx, options, &deadline, activity, args...)
+		t := ScheduleTimer(tctx, options.ScheduleToCloseTimeout, WithTimerName("ScheduleToCloseTimeout"))
+
+		Select(ctx,
+			Await(af, func(ctx Context, af Future[TResult]) {
+				f.Set(af.Get(ctx))
+			}),
+			Await(t, func(ctx Context, t Future[any]) {
+				if _, err := t.Get(ctx); err != nil {
+					f.Set(*new(TResult), err)
+					return
+				}
+
+				f.Set(*new(TResult), workflowerrors.NewActivityTimeoutError(workflowerrors.TimeoutTypeScheduleToClose))
+			}),
+		)
+	})
+
+	return f
+}
+
+func executeActivityWithRetries[TResult any](
+	ctx Context, options ActivityOptions, deadline *time.Time, activity Activity, args ...any,
+) Future[TResult] {
+	var lastScheduleEventID int64
+
 	return WithRetries(ctx, options.RetryOptions, func(ctx Context, attempt int) Future[TResult] {
-		return executeActivity[TResult](ctx, options, attempt, activity, args...)
+		// Pass on heartbeat details from the previous attempt
+		var heartbeatDetails payload.Payload
+		if lastScheduleEventID != 0 {
+			heartbeatDetails = workflowstate.WorkflowState(ctx).TakeActivityHeartbeatDetails(lastScheduleEventID)
+		}
+
+		var f Future[TResult]
+		f, lastScheduleEventID = executeActivity[TResult](ctx, options, attempt, heartbeatDetails, deadline, activity, args...)
+		return f
 	})
 }
 
-func executeActivity[TResult any](ctx Context, options ActivityOptions, attempt int, activity Activity, args ...any) Future[TResult] {
+func executeActivity[TResult any](
+	ctx Context, options ActivityOptions, attempt int, heartbeatDetails payload.Payload, deadline *time.Time,
+	activity Activity, args ...any,
+) (Future[TResult], int64) {
 	f := sync.NewFuture[TResult]()
 
 	if ctx.Err() != nil {
 		f.Set(*new(TResult), ctx.Err())
-		return f
+		return f, 0
 	}
 
 	// Check return type
 	if err := a.ReturnTypeMatch[TResult](activity); err != nil {
 		f.Set(*new(TResult), err)
-		return f
+		return f, 0
 	}
 
 	// Check arguments
 	if err := a.ParamsMatch(activity, args...); err != nil {
 		f.Set(*new(TResult), err)
-		return f
+		return f, 0
 	}
 
 	cv := contextvalue.Converter(ctx)
 	inputs, err := a.ArgsToInputs(cv, args...)
 	if err != nil {
 		f.Set(*new(TResult), fmt.Errorf("converting activity input: %w", err))
-		return f
+		return f, 0
 	}
 
 	wfState := workflowstate.WorkflowState(ctx)
@@ -69,10 +143,15 @@ func executeActivity[TResult any](ctx Context, options ActivityOptions, attempt
 	metadata := &Metadata{}
 	if err := injectFromWorkflow(ctx, metadata, propagators); err != nil {
 		f.Set(*new(TResult), fmt.Errorf("injecting workflow context: %w", err))
-		return f
+		return f, 0
 	}
 
 	cmd := command.NewScheduleActivityCommand(scheduleEventID, name, inputs, attempt, metadata, options.Queue)
+	cmd.HeartbeatTimeout = options.HeartbeatTimeout
+	cmd.LastHeartbeatDetails = heartbeatDetails
+	cmd.ScheduleToStartTimeout = options.ScheduleToStartTimeout
+	cmd.StartToCloseTimeout = options.StartToCloseTimeout
+	cmd.ScheduleToCloseDeadline = deadline
 	wfState.AddCommand(cmd)
 	wfState.TrackFuture(scheduleEventID, workflowstate.AsDecodingSettable(cv, fmt.Sprintf("activity: %s", name), f))
 
@@ -90,5 +169,5 @@ func executeActivity[TResult any](ctx Context, options ActivityOptions, attempt
 		}
 	}
 
-	return f
+	return f, scheduleEventID
 }
@@ -0,0 +1,13 @@
+package workflow
+
+import (
+	"github.com/cschleiden/go-workflows/internal/workflowstate"
+)
+
+// Attempt returns the attempt of the current workflow execution, starting at 0. It is incremented when a failed
+// workflow instance is retried according to the RetryOptions it was created with, or when a failed sub-workflow
+// is retried by its parent.
+func Attempt(ctx Context) int {
+	wfState := workflowstate.WorkflowState(ctx)
+	return wfState.Attempt()
+}
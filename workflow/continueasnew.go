@@ -7,10 +7,42 @@ import (
 	a "github.com/cschleiden/go-workflows/internal/args"
 	"github.com/cschleiden/go-workflows/internal/contextvalue"
 	"github.com/cschleiden/go-workflows/internal/continueasnew"
+	"github.com/cschleiden/go-workflows/internal/fn"
 )
 
+// ContinueAsNewOptions configure the new execution started by ContinueAsNewWithOptions.
+type ContinueAsNewOptions struct {
+	// Workflow to run in the new execution, for example a newer version of the current workflow. If not set,
+	// the current workflow will be used.
+	Workflow Workflow
+
+	// Queue to use for the new execution, if not set, the queue of the current execution will be used.
+	Queue Queue
+}
+
 // ContinueAsNew restarts the current workflow with the given arguments.
 func ContinueAsNew(ctx Context, args ...any) error {
+	return ContinueAsNewWithOptions(ctx, ContinueAsNewOptions{}, args...)
+}
+
+// ContinueAsNewWithOptions restarts the current workflow with the given arguments. Depending on the options,
+// the new execution runs a different workflow or is scheduled on a different queue.
+func ContinueAsNewWithOptions(ctx Context, options ContinueAsNewOptions, args ...any) error {
+	var workflowName string
+	switch wf := options.Workflow.(type) {
+	case nil:
+		// Continue with the current workflow
+	case string:
+		workflowName = wf
+	default:
+		workflowName = fn.Name(wf)
+
+		// Check arguments
+		if err := a.ParamsMatch(wf, args...); err != nil {
+			return err
+		}
+	}
+
 	// Capture context
 	propagators := propagators(ctx)
 	metadata := &metadata.WorkflowMetadata{}
@@ -24,5 +56,5 @@ func ContinueAsNew(ctx Context, args ...any) error {
 		return fmt.Errorf("converting inputs for continuing workflow execution: %w", err)
 	}
 
-	return continueasnew.NewError(metadata, inputs)
+	return continueasnew.NewError(metadata, inputs, workflowName, options.Queue)
 }
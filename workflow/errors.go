@@ -3,10 +3,34 @@ package workflow
 import "github.com/cschleiden/go-workflows/internal/workflowerrors"
 
 type (
-	Error      = workflowerrors.Error
-	PanicError = workflowerrors.PanicError
+	Error               = workflowerrors.Error
+	PanicError          = workflowerrors.PanicError
+	NonDeterminismError = workflowerrors.NonDeterminismError
+
+	ActivityTimeoutError = workflowerrors.ActivityTimeoutError
+	WorkflowTimeoutError = workflowerrors.WorkflowTimeoutError
+	TimeoutType          = workflowerrors.TimeoutType
+
+	UpdateRejectedError = workflowerrors.UpdateRejectedError
+)
+
+const (
+	TimeoutTypeScheduleToStart = workflowerrors.TimeoutTypeScheduleToStart
+	TimeoutTypeStartToClose    = workflowerrors.TimeoutTypeStartToClose
+	TimeoutTypeScheduleToClose = workflowerrors.TimeoutTypeScheduleToClose
+	TimeoutTypeHeartbeat       = workflowerrors.TimeoutTypeHeartbeat
+	TimeoutTypeExecution       = workflowerrors.TimeoutTypeExecution
+	TimeoutTypeRun             = workflowerrors.TimeoutTypeRun
 )
 
+// ErrActivityTimeout is matched by errors returned from activities that exceeded one of their timeouts. Use
+// errors.As with *ActivityTimeoutError to find out which timeout was exceeded.
+var ErrActivityTimeout = workflowerrors.ErrActivityTimeout
+
+// ErrWorkflowTimeout is matched by errors of workflow instances that exceeded their execution or run timeout.
+// Use errors.As with *WorkflowTimeoutError to find out which timeout was exceeded.
+var ErrWorkflowTimeout = workflowerrors.ErrWorkflowTimeout
+
 // NewError wraps the given error into a workflow error which will be automatically retried
 func NewError(err error) error {
 	return workflowerrors.FromError(err)
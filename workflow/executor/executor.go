@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"reflect"
 	"slices"
 	"testing"
@@ -17,16 +18,19 @@ import (
 	"github.com/cschleiden/go-workflows/backend/metadata"
 	"github.com/cschleiden/go-workflows/backend/payload"
 	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/internal/activity"
 	"github.com/cschleiden/go-workflows/internal/command"
 	"github.com/cschleiden/go-workflows/internal/contextvalue"
 	"github.com/cschleiden/go-workflows/internal/continueasnew"
 	"github.com/cschleiden/go-workflows/internal/log"
+	"github.com/cschleiden/go-workflows/internal/retries"
 	"github.com/cschleiden/go-workflows/internal/sync"
 	"github.com/cschleiden/go-workflows/internal/tracing"
 	"github.com/cschleiden/go-workflows/internal/workflowerrors"
 	"github.com/cschleiden/go-workflows/internal/workflowstate"
 	"github.com/cschleiden/go-workflows/registry"
 	wf "github.com/cschleiden/go-workflows/workflow"
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -55,9 +59,29 @@ type WorkflowHistoryProvider interface {
 type WorkflowExecutor interface {
 	ExecuteTask(ctx context.Context, t *backend.WorkflowTask) (*ExecutionResult, error)
 
+	// Query replays the given history and invokes the query handler registered by the workflow. Commands
+	// produced by the workflow are not executed and no events are recorded.
+	Query(ctx context.Context, h []*history.Event, name string, args []payload.Payload) (payload.Payload, error)
+
 	Close()
 }
 
+// Options are optional settings of an executor.
+type Options struct {
+	// LocalActivityRetryTimeout caps the time local activities are retried within a workflow task, measured
+	// from the start of the task. Once it would be exceeded, local activities are retried after a timer in a
+	// later workflow task instead. Defaults to 0, which means no limit.
+	LocalActivityRetryTimeout time.Duration
+
+	// MaxHistorySizeBytes is the maximum approximate size of the history of an execution, see
+	// workflow.HistorySizeBytes. If it is exceeded, the workflow is failed. Defaults to 0, which means no limit.
+	MaxHistorySizeBytes int64
+
+	// ContinueAsNewSuggestedThreshold is the fraction of the maximum history length and size at which continuing
+	// as new is suggested to the workflow. Defaults to 0, which means it's never suggested.
+	ContinueAsNewSuggestedThreshold float64
+}
+
 type executor struct {
 	registry          *registry.Registry
 	historyProvider   WorkflowHistoryProvider
@@ -76,6 +100,26 @@ type executor struct {
 	workflowSpan trace.Span
 
 	maxHistorySize int64
+	nonDeterminism NonDeterminismPolicy
+	options        Options
+
+	// Timeouts of the workflow instance, carried over when continuing as new
+	runTimeout        time.Duration
+	executionDeadline *time.Time
+
+	// retryPolicy and parentClosePolicy of the workflow instance, carried over when continuing as new
+	retryPolicy       *history.RetryPolicy
+	parentClosePolicy core.ParentClosePolicy
+
+	// detached is set for sub-workflows which don't report their result back to the parent
+	detached bool
+
+	// activityExecutor executes local activities, taskCtx is the context of the workflow task being executed
+	activityExecutor *activity.Executor
+	taskCtx          *taskContext
+
+	// timedOut is set when the workflow instance has exceeded one of its timeouts
+	timedOut bool
 }
 
 func NewExecutor(
@@ -89,11 +133,17 @@ func NewExecutor(
 	metadata *metadata.WorkflowMetadata,
 	clock clock.Clock,
 	maxHistorySize int64,
+	nonDeterminismPolicy NonDeterminismPolicy,
+	options ...Options,
 ) (WorkflowExecutor, error) {
 	s := workflowstate.NewWorkflowState(instance, logger, tracer, clock)
 
+	// Conversions in workflow code use the context of the task being executed for I/O
+	taskCtx := &taskContext{context.Background()}
+	cv = converter.WithContext(taskCtx, cv)
+
 	wfCtx := sync.Background()
-	wfCtx = contextvalue.WithConverter(wfCtx, cv)
+	wfCtx = contextvalue.WithConverter(wfCtx, &replayConverter{cv, converter.WithoutOffloading(cv), s})
 	wfCtx = workflowstate.WithWorkflowState(wfCtx, s)
 	wfCtx = sync.WithValue(wfCtx, contextvalue.PropagatorsCtxKey, propagators)
 	wfCtx, cancel := sync.WithCancel(wfCtx)
@@ -113,18 +163,65 @@ func NewExecutor(
 		slog.String(log.ExecutionIDKey, instance.ExecutionID),
 	)
 
-	return &executor{
+	e := &executor{
 		registry:          registry,
 		historyProvider:   historyProvider,
 		workflowState:     s,
-		workflowCtx:       wfCtx,
 		workflowCtxCancel: cancel,
 		cv:                cv,
 		clock:             clock,
 		maxHistorySize:    maxHistorySize,
+		nonDeterminism:    nonDeterminismPolicy,
 		logger:            logger,
 		tracer:            tracer,
-	}, nil
+		activityExecutor:  activity.NewExecutor(logger, tracer, cv, propagators, registry, nil, nil),
+		taskCtx:           taskCtx,
+	}
+
+	if len(options) > 0 {
+		e.options = options[0]
+	}
+
+	threshold := e.options.ContinueAsNewSuggestedThreshold
+	s.SetContinueAsNewSuggestedAt(
+		int64(float64(maxHistorySize)*threshold), int64(float64(e.options.MaxHistorySizeBytes)*threshold),
+	)
+
+	e.workflowCtx = contextvalue.WithLocalActivityExecutor(wfCtx, e.executeLocalActivity)
+
+	return e, nil
+}
+
+// taskContext is the context of the task the executor is currently working on. It's set for the duration of
+// every task, workflow code only runs during tasks.
+type taskContext struct {
+	context.Context
+}
+
+// replayConverter is the converter used by workflow code. The payloads of commands recreated while the history
+// is replayed are discarded once the commands are matched with their events, so they are not offloaded.
+type replayConverter struct {
+	converter.Converter
+
+	inline converter.Converter
+	state  *workflowstate.WfState
+}
+
+func (c *replayConverter) To(v interface{}) (payload.Payload, error) {
+	if c.state.Replaying() {
+		return c.inline.To(v)
+	}
+
+	return c.Converter.To(v)
+}
+
+// useTaskContext sets the context of the current task, the returned function resets it.
+func (e *executor) useTaskContext(ctx context.Context) func() {
+	e.taskCtx.Context = ctx
+
+	return func() {
+		e.taskCtx.Context = context.Background()
+	}
 }
 
 func (e *executor) ExecuteTask(ctx context.Context, t *backend.WorkflowTask) (*ExecutionResult, error) {
@@ -134,6 +231,12 @@ func (e *executor) ExecuteTask(ctx context.Context, t *backend.WorkflowTask) (*E
 
 	logger.Debug("Executing workflow task", slog.Int64(log.TaskLastSequenceIDKey, t.LastSequenceID))
 
+	defer e.useTaskContext(ctx)()
+
+	if e.options.LocalActivityRetryTimeout > 0 {
+		e.workflowState.SetLocalActivityRetryDeadline(time.Now().Add(e.options.LocalActivityRetryTimeout))
+	}
+
 	if t.WorkflowInstanceState == core.WorkflowInstanceStateFinished {
 		// This could happen if signals are delivered after the workflow is finished
 		logger.Error("Received workflow task for finished workflow instance, discarding events")
@@ -160,7 +263,12 @@ func (e *executor) ExecuteTask(ctx context.Context, t *backend.WorkflowTask) (*E
 	toExecute := []*history.Event{e.createNewEvent(history.EventType_WorkflowTaskStarted, &history.WorkflowTaskStartedAttributes{})}
 	executedEvents := toExecute
 
-	toExecute = append(toExecute, t.NewEvents...)
+	newEvents := t.NewEvents
+	if e.lastSequenceID == 0 {
+		newEvents = startedEventFirst(newEvents)
+	}
+
+	toExecute = append(toExecute, newEvents...)
 
 	// Execute new events received from the backend
 	if !skipNewEvents {
@@ -177,6 +285,8 @@ func (e *executor) ExecuteTask(ctx context.Context, t *backend.WorkflowTask) (*E
 	// Enforce max history size limit
 	if e.lastSequenceID+int64(len(executedEvents)) >= e.maxHistorySize {
 		e.workflowCompleted(nil, fmt.Errorf("workflow history size exceeded %d events", e.maxHistorySize))
+	} else if e.options.MaxHistorySizeBytes > 0 && e.workflowState.HistorySizeBytes() >= e.options.MaxHistorySizeBytes {
+		e.workflowCompleted(nil, fmt.Errorf("workflow history size exceeded %d bytes", e.options.MaxHistorySizeBytes))
 	}
 
 	// Process any commands added while executing new events
@@ -205,9 +315,18 @@ func (e *executor) ExecuteTask(ctx context.Context, t *backend.WorkflowTask) (*E
 		workflowEvents = append(workflowEvents, r.WorkflowEvents...)
 	}
 
+	if err := e.copyStoredPayloads(ctx, workflowEvents); err != nil {
+		return nil, err
+	}
+
 	// Events from commands don't have to be executed again, add them to the executed events.
 	executedEvents = append(executedEvents, newCommandEvents...)
 
+	// Events from commands are part of the history, as if they had been replayed
+	for _, event := range newCommandEvents {
+		e.workflowState.AddHistoryEvent(eventSize(event))
+	}
+
 	// Set SequenceIDs for all executed events
 	for i := range executedEvents {
 		executedEvents[i].SequenceID = e.nextSequenceID()
@@ -228,6 +347,39 @@ func (e *executor) ExecuteTask(ctx context.Context, t *backend.WorkflowTask) (*E
 	}, nil
 }
 
+// copyStoredPayloads copies the payloads stored outside of the history for events sent to other workflow
+// instances, so that every workflow instance owns the payloads referenced by its history.
+func (e *executor) copyStoredPayloads(ctx context.Context, events []*history.WorkflowEvent) error {
+	ps, ok := e.cv.(converter.PayloadStore)
+	if !ok {
+		return nil
+	}
+
+	var copied []payload.Payload
+	for _, we := range events {
+		a, err := history.MapPayloads(we.HistoryEvent.Attributes, func(p payload.Payload) (payload.Payload, error) {
+			c, err := ps.CopyPayload(ctx, p)
+			if err == nil && ps.IsStored(c) {
+				copied = append(copied, c)
+			}
+
+			return c, err
+		})
+		if err != nil {
+			// Don't leave copies behind that no history references
+			if releaseErr := ps.ReleasePayloads(ctx, copied); releaseErr != nil {
+				e.logger.ErrorContext(ctx, "could not release copied payloads", "error", releaseErr)
+			}
+
+			return fmt.Errorf("copying stored payloads: %w", err)
+		}
+
+		we.HistoryEvent.Attributes = a
+	}
+
+	return nil
+}
+
 func (e *executor) catchupOnHistory(ctx context.Context, t *backend.WorkflowTask, logger *slog.Logger) (bool, error) {
 	if t.LastSequenceID < e.lastSequenceID {
 		return false, fmt.Errorf("task has older history than current state, cannot execute")
@@ -246,6 +398,12 @@ func (e *executor) catchupOnHistory(ctx context.Context, t *backend.WorkflowTask
 		if err := e.replayHistory(h); err != nil {
 			logger.Error("Error while replaying history", "error", err)
 
+			var nde *wf.NonDeterminismError
+			if errors.As(err, &nde) && e.nonDeterminism == NonDeterminismPolicyBlockWorkflow {
+				// Leave the task uncompleted, it will be retried once its lock expires
+				return false, fmt.Errorf("replaying history: %w", err)
+			}
+
 			// Fail workflow with an error. Skip executing new events, but still go through the commands
 			e.workflowCompleted(nil, err)
 
@@ -265,8 +423,39 @@ func (e *executor) catchupOnHistory(ctx context.Context, t *backend.WorkflowTask
 	return false, nil
 }
 
+func (e *executor) Query(ctx context.Context, h []*history.Event, name string, args []payload.Payload) (result payload.Payload, err error) {
+	defer e.useTaskContext(ctx)()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = workflowerrors.NewPanicError(fmt.Sprintf("panic in query handler: %v", r))
+		}
+	}()
+
+	if err := e.replayHistory(h); err != nil {
+		return nil, fmt.Errorf("replaying history: %w", err)
+	}
+
+	handler, ok := e.workflowState.QueryHandler(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", wf.ErrUnknownQuery, name)
+	}
+
+	return handler(args)
+}
+
 func (e *executor) replayHistory(h []*history.Event) error {
 	e.workflowState.SetReplaying(true)
+
+	// Workflow code asks for versions before the version marker events are replayed, make them
+	// available upfront.
+	for _, event := range h {
+		if event.Type == history.EventType_VersionMarker {
+			a := event.Attributes.(*history.VersionMarkerAttributes)
+			e.workflowState.RecordVersion(a.ChangeID, a.Version)
+		}
+	}
+
 	for _, event := range h {
 		if event.SequenceID < e.lastSequenceID {
 			e.logger.Error("history has older events than current state")
@@ -290,6 +479,11 @@ func (e *executor) executeNewEvents(newEvents []*history.Event) ([]*history.Even
 		if err := e.executeEvent(event); err != nil {
 			return newEvents[:i], err
 		}
+
+		if e.timedOut {
+			// Workflow instance has been finished, ignore any remaining events
+			return newEvents[:i+1], nil
+		}
 	}
 
 	if e.workflow.Completed() {
@@ -348,6 +542,8 @@ func (e *executor) executeEvent(event *history.Event) error {
 
 	e.logger.Debug("Executing event", fields...)
 
+	e.workflowState.AddHistoryEvent(eventSize(event))
+
 	var err error
 
 	switch event.Type {
@@ -395,10 +591,29 @@ func (e *executor) executeEvent(event *history.Event) error {
 		err = e.handleSubWorkflowFailed(event, event.Attributes.(*history.SubWorkflowFailedAttributes))
 	case history.EventType_SubWorkflowCompleted:
 		err = e.handleSubWorkflowCompleted(event, event.Attributes.(*history.SubWorkflowCompletedAttributes))
+	case history.EventType_SubWorkflowStarted:
+		err = e.handleSubWorkflowStarted(event, event.Attributes.(*history.SubWorkflowStartedAttributes))
 
 	case history.EventType_TraceStarted:
 		err = e.handleTraceStarted(event, event.Attributes.(*history.TraceStartedAttributes))
 
+	case history.EventType_VersionMarker:
+		err = e.handleVersionMarker(event, event.Attributes.(*history.VersionMarkerAttributes))
+
+	case history.EventType_WorkflowExecutionTimedOut:
+		err = e.handleWorkflowExecutionTimedOut(event, event.Attributes.(*history.ExecutionTimedOutAttributes))
+
+	case history.EventType_SearchAttributesUpserted:
+		err = e.handleSearchAttributesUpserted(event, event.Attributes.(*history.SearchAttributesUpsertedAttributes))
+
+	case history.EventType_UpdateRequested:
+		err = e.handleUpdateRequested(event, event.Attributes.(*history.UpdateRequestedAttributes))
+	case history.EventType_UpdateCompleted:
+		err = e.handleUpdateCompleted(event, event.Attributes.(*history.UpdateCompletedAttributes))
+
+	case history.EventType_LocalActivityResult:
+		err = e.handleLocalActivityResult(event, event.Attributes.(*history.LocalActivityResultAttributes))
+
 	default:
 		return fmt.Errorf("unknown event type: %v", event.Type)
 	}
@@ -429,10 +644,39 @@ func (e *executor) handleWorkflowExecutionStarted(event *history.Event, a *histo
 	e.workflowCtx = tracing.ContextWithSpan(e.workflowCtx, span)
 	e.workflowSpan = span
 
+	e.runTimeout = a.RunTimeout
+	e.executionDeadline = a.ExecutionDeadline
+	e.retryPolicy = a.RetryPolicy
+	e.parentClosePolicy = a.ParentClosePolicy
+	e.detached = a.Detached
+	e.workflowState.SetAttempt(a.Attempt)
+	e.workflowState.UpsertSearchAttributes(a.SearchAttributes)
+
 	e.workflow = newWorkflow(reflect.ValueOf(wfFn))
+	if a.RetryPolicy != nil && !e.workflowState.Instance().SubWorkflow() {
+		// Sub-workflows are retried by their parent
+		e.workflow.retry = func(ctx sync.Context, err error) error {
+			return e.retryWorkflow(ctx, a, err)
+		}
+	}
+
 	return e.workflow.Execute(e.workflowCtx, a.Inputs)
 }
 
+func (e *executor) handleWorkflowExecutionTimedOut(event *history.Event, a *history.ExecutionTimedOutAttributes) error {
+	if e.workflow == nil || e.workflow.Completed() {
+		return nil
+	}
+
+	e.timedOut = true
+
+	if !e.workflowState.Replaying() {
+		e.workflowCompleted(nil, workflowerrors.NewWorkflowTimeoutError(a.TimeoutType))
+	}
+
+	return nil
+}
+
 func (e *executor) handleWorkflowCanceled() error {
 	e.workflowCtxCancel()
 
@@ -447,18 +691,11 @@ func (e *executor) handleWorkflowTaskStarted(event *history.Event, a *history.Wo
 
 func (e *executor) handleActivityScheduled(event *history.Event, a *history.ActivityScheduledAttributes) error {
 	c := e.workflowState.CommandByScheduleEventID(event.ScheduleEventID)
-	if c == nil {
-		return fmt.Errorf("previous workflow execution scheduled an activity which could not be found")
-	}
-
-	sac, ok := c.(*command.ScheduleActivityCommand)
-	if !ok {
-		return fmt.Errorf("previous workflow execution scheduled an activity, not: %v", c.Type())
-	}
 
 	// Ensure the same activity was scheduled again
-	if a.Name != sac.Name {
-		return fmt.Errorf("previous workflow execution scheduled different type of activity: %s, %s", a.Name, sac.Name)
+	sac, ok := c.(*command.ScheduleActivityCommand)
+	if !ok || a.Name != sac.Name {
+		return nonDeterminismError(event, c)
 	}
 
 	sac.Commit()
@@ -469,6 +706,10 @@ func (e *executor) handleActivityScheduled(event *history.Event, a *history.Acti
 func (e *executor) handleActivityCompleted(event *history.Event, a *history.ActivityCompletedAttributes) error {
 	f, ok := e.workflowState.FutureByScheduleEventID(event.ScheduleEventID)
 	if !ok {
+		if activityTimedOut(e.workflowState, event.ScheduleEventID) {
+			return nil
+		}
+
 		return fmt.Errorf("could not find pending future for activity completion")
 	}
 
@@ -497,10 +738,23 @@ func (e *executor) handleActivityCompleted(event *history.Event, a *history.Acti
 func (e *executor) handleActivityFailed(event *history.Event, a *history.ActivityFailedAttributes) error {
 	f, ok := e.workflowState.FutureByScheduleEventID(event.ScheduleEventID)
 	if !ok {
+		if activityTimedOut(e.workflowState, event.ScheduleEventID) {
+			return nil
+		}
+
 		return errors.New("no pending future for activity failed event")
 	}
 
-	actErr := workflowerrors.ToError(a.Error)
+	if a.LastHeartbeatDetails != nil {
+		e.workflowState.SetActivityHeartbeatDetails(event.ScheduleEventID, a.LastHeartbeatDetails)
+	}
+
+	decodedErr, err := workflowerrors.DecodeError(e.cv, a.Error)
+	if err != nil {
+		return fmt.Errorf("decoding activity error: %w", err)
+	}
+
+	actErr := workflowerrors.ToError(decodedErr)
 	if err := f.Set(nil, actErr); err != nil {
 		return fmt.Errorf("setting activity failed result: %w", err)
 	}
@@ -522,14 +776,19 @@ func (e *executor) handleActivityFailed(event *history.Event, a *history.Activit
 	return e.workflow.Continue()
 }
 
+// activityTimedOut returns whether the activity with the given schedule event ID has already been resolved. This
+// happens when its ScheduleToStart timeout fired before a worker reported the result of the activity.
+func activityTimedOut(s *workflowstate.WfState, scheduleEventID int64) bool {
+	sac, ok := s.CommandByScheduleEventID(scheduleEventID).(*command.ScheduleActivityCommand)
+	return ok && sac.State() == command.CommandState_Done
+}
+
 func (e *executor) handleTimerScheduled(event *history.Event, a *history.TimerScheduledAttributes) error {
 	c := e.workflowState.CommandByScheduleEventID(event.ScheduleEventID)
-	if c == nil {
-		return fmt.Errorf("previous workflow execution scheduled a timer")
-	}
 
-	if _, ok := c.(*command.ScheduleTimerCommand); !ok {
-		return fmt.Errorf("previous workflow execution scheduled a timer, not: %v", c.Type())
+	stc, ok := c.(*command.ScheduleTimerCommand)
+	if !ok || a.Name != stc.Name() {
+		return nonDeterminismError(event, c)
 	}
 
 	c.Commit()
@@ -614,17 +873,11 @@ func (e *executor) handleTimerCanceled(event *history.Event, a *history.TimerCan
 
 func (e *executor) handleSubWorkflowScheduled(event *history.Event, a *history.SubWorkflowScheduledAttributes) error {
 	c := e.workflowState.CommandByScheduleEventID(event.ScheduleEventID)
-	if c == nil {
-		return fmt.Errorf("previous workflow execution scheduled a sub workflow")
-	}
 
+	// Ensure the same sub-workflow was scheduled again
 	sswc, ok := c.(*command.ScheduleSubWorkflowCommand)
-	if !ok {
-		return fmt.Errorf("previous workflow execution scheduled a sub workflow, not: %v", c.Type())
-	}
-
-	if a.Name != sswc.Name {
-		return fmt.Errorf("previous workflow execution scheduled different type of sub workflow: %s, %s", a.Name, sswc.Name)
+	if !ok || a.Name != sswc.Name {
+		return nonDeterminismError(event, c)
 	}
 
 	// If we are replaying this event, the command will have generated a new instance ID. Ensure we use the same one as
@@ -653,12 +906,34 @@ func (e *executor) handleSubWorkflowCancellationRequest(event *history.Event, a
 }
 
 func (e *executor) handleSubWorkflowFailed(event *history.Event, a *history.SubWorkflowFailedAttributes) error {
+	if sswc, ok := detachedSubWorkflow(e.workflowState.CommandByScheduleEventID(event.ScheduleEventID)); ok {
+		if sswc.State() == command.CommandState_Done {
+			// The sub-workflow has been started, its result is not reported back
+			return nil
+		}
+
+		// The sub-workflow could not be started
+		decodedErr, err := workflowerrors.DecodeError(e.cv, a.Error)
+		if err != nil {
+			return fmt.Errorf("decoding sub workflow error: %w", err)
+		}
+
+		sswc.Started(workflowerrors.ToError(decodedErr))
+
+		return e.workflow.Continue()
+	}
+
 	f, ok := e.workflowState.FutureByScheduleEventID(event.ScheduleEventID)
 	if !ok {
 		return errors.New("no pending future found for sub workflow failed event")
 	}
 
-	wfErr := workflowerrors.ToError(a.Error)
+	decodedErr, err := workflowerrors.DecodeError(e.cv, a.Error)
+	if err != nil {
+		return fmt.Errorf("decoding sub workflow error: %w", err)
+	}
+
+	wfErr := workflowerrors.ToError(decodedErr)
 
 	if err := f.Set(nil, wfErr); err != nil {
 		return fmt.Errorf("setting sub workflow failed result: %w", err)
@@ -681,6 +956,11 @@ func (e *executor) handleSubWorkflowFailed(event *history.Event, a *history.SubW
 }
 
 func (e *executor) handleSubWorkflowCompleted(event *history.Event, a *history.SubWorkflowCompletedAttributes) error {
+	if _, ok := detachedSubWorkflow(e.workflowState.CommandByScheduleEventID(event.ScheduleEventID)); ok {
+		// Results of detached sub-workflows are not reported back
+		return nil
+	}
+
 	f, ok := e.workflowState.FutureByScheduleEventID(event.ScheduleEventID)
 	if !ok {
 		return errors.New("no pending future found for sub workflow completed event")
@@ -706,6 +986,34 @@ func (e *executor) handleSubWorkflowCompleted(event *history.Event, a *history.S
 	return e.workflow.Continue()
 }
 
+func (e *executor) handleSubWorkflowStarted(event *history.Event, a *history.SubWorkflowStartedAttributes) error {
+	c := e.workflowState.CommandByScheduleEventID(event.ScheduleEventID)
+
+	sswc, ok := detachedSubWorkflow(c)
+	if !ok {
+		return nonDeterminismError(event, c)
+	}
+
+	if sswc.State() == command.CommandState_Done {
+		// The sub-workflow failed to start
+		return nil
+	}
+
+	sswc.Started(nil)
+
+	return e.workflow.Continue()
+}
+
+// detachedSubWorkflow returns the given command if it schedules a detached sub-workflow
+func detachedSubWorkflow(c command.Command) (*command.ScheduleSubWorkflowCommand, bool) {
+	sswc, ok := c.(*command.ScheduleSubWorkflowCommand)
+	if !ok || !sswc.Detached {
+		return nil, false
+	}
+
+	return sswc, true
+}
+
 func (e *executor) handleSignalReceived(event *history.Event, a *history.SignalReceivedAttributes) error {
 	// Send signal to workflow channel
 	workflowstate.ReceiveSignal(e.workflowState, a.Name, a.Arg)
@@ -715,13 +1023,10 @@ func (e *executor) handleSignalReceived(event *history.Event, a *history.SignalR
 
 func (e *executor) handleSideEffectResult(event *history.Event, a *history.SideEffectResultAttributes) error {
 	c := e.workflowState.CommandByScheduleEventID(event.ScheduleEventID)
-	if c == nil {
-		return fmt.Errorf("previous workflow execution scheduled a side effect")
-	}
 
 	sec, ok := c.(*command.SideEffectCommand)
 	if !ok {
-		return fmt.Errorf("previous workflow execution scheduled a side effect, not: %v", c.Type())
+		return nonDeterminismError(event, c)
 	}
 
 	sec.Done()
@@ -740,6 +1045,64 @@ func (e *executor) handleSideEffectResult(event *history.Event, a *history.SideE
 	return e.workflow.Continue()
 }
 
+func (e *executor) handleLocalActivityResult(event *history.Event, a *history.LocalActivityResultAttributes) error {
+	c := e.workflowState.CommandByScheduleEventID(event.ScheduleEventID)
+
+	lac, ok := c.(*command.LocalActivityCommand)
+	if !ok || lac.Name != a.Name {
+		return nonDeterminismError(event, c)
+	}
+
+	lac.Done()
+
+	if a.RetryAfter > 0 {
+		e.workflowState.SetLocalActivityRetry(event.ScheduleEventID, workflowstate.LocalActivityRetry{
+			Attempts:   a.Attempts,
+			RetryAfter: a.RetryAfter,
+		})
+	}
+
+	f, ok := e.workflowState.FutureByScheduleEventID(event.ScheduleEventID)
+	if !ok {
+		return errors.New("no pending future found for local activity result event")
+	}
+
+	var actErr error
+	if a.Error != nil {
+		decodedErr, err := workflowerrors.DecodeError(e.cv, a.Error)
+		if err != nil {
+			return fmt.Errorf("decoding local activity error: %w", err)
+		}
+
+		actErr = workflowerrors.ToError(decodedErr)
+	}
+
+	if err := f.Set(a.Result, actErr); err != nil {
+		return fmt.Errorf("setting local activity result: %w", err)
+	}
+
+	e.workflowState.RemoveFuture(event.ScheduleEventID)
+
+	return e.workflow.Continue()
+}
+
+// executeLocalActivity executes a single attempt of a local activity as part of the current workflow task
+func (e *executor) executeLocalActivity(a *history.ActivityScheduledAttributes) (payload.Payload, error) {
+	task := &backend.ActivityTask{
+		ID:               uuid.NewString(),
+		WorkflowInstance: e.workflowState.Instance(),
+		Event:            history.NewPendingEvent(e.clock.Now(), history.EventType_ActivityScheduled, a),
+	}
+	task.ActivityID = task.Event.ID
+
+	result, _, err := e.activityExecutor.ExecuteActivity(e.taskCtx.Context, task)
+	if errors.Is(err, activity.ErrResultPending) {
+		return nil, workflowerrors.NewPermanentError(errors.New("local activities cannot be completed asynchronously"))
+	}
+
+	return result, err
+}
+
 func (e *executor) handleTraceStarted(event *history.Event, a *history.TraceStartedAttributes) error {
 	c := e.workflowState.CommandByScheduleEventID(event.ScheduleEventID)
 	if c == nil {
@@ -767,18 +1130,136 @@ func (e *executor) handleTraceStarted(event *history.Event, a *history.TraceStar
 	return e.workflow.Continue()
 }
 
+func (e *executor) handleVersionMarker(event *history.Event, a *history.VersionMarkerAttributes) error {
+	c := e.workflowState.CommandByScheduleEventID(event.ScheduleEventID)
+
+	vmc, ok := c.(*command.VersionMarkerCommand)
+	if !ok || a.ChangeID != vmc.ChangeID {
+		return nonDeterminismError(event, c)
+	}
+
+	vmc.Done()
+
+	return e.workflow.Continue()
+}
+
+func (e *executor) handleSearchAttributesUpserted(event *history.Event, a *history.SearchAttributesUpsertedAttributes) error {
+	c := e.workflowState.CommandByScheduleEventID(event.ScheduleEventID)
+
+	usc, ok := c.(*command.UpsertSearchAttributesCommand)
+	if !ok {
+		return nonDeterminismError(event, c)
+	}
+
+	usc.Done()
+
+	return e.workflow.Continue()
+}
+
+func (e *executor) handleUpdateRequested(event *history.Event, a *history.UpdateRequestedAttributes) error {
+	// Validate the update against the state of the workflow at this point of the history, a rejected update is
+	// completed with the rejection without running the handler
+	if err := e.validateUpdate(a); err != nil {
+		scheduleEventID := e.workflowState.GetNextScheduleEventID()
+		cmd := command.NewCompleteUpdateCommand(scheduleEventID, a.UpdateID, nil, workflowerrors.FromErrorEncoded(e.cv, err))
+		e.workflowState.AddCommand(cmd)
+
+		return nil
+	}
+
+	// Run the handler in its own coroutine so that it can block.
+	return e.workflow.Go(e.workflowCtx, func(ctx sync.Context) error {
+		result, err := e.runUpdate(ctx, a)
+
+		scheduleEventID := e.workflowState.GetNextScheduleEventID()
+		cmd := command.NewCompleteUpdateCommand(scheduleEventID, a.UpdateID, result, workflowerrors.FromErrorEncoded(e.cv, err))
+		e.workflowState.AddCommand(cmd)
+
+		return nil
+	})
+}
+
+func (e *executor) validateUpdate(a *history.UpdateRequestedAttributes) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = workflowerrors.NewPanicError(fmt.Sprintf("panic in update validator: %v", r))
+		}
+	}()
+
+	// Unknown updates are reported by runUpdate
+	handler, ok := e.workflowState.UpdateHandler(a.Name)
+	if !ok || handler.Validate == nil {
+		return nil
+	}
+
+	if err := handler.Validate(a.Arg); err != nil {
+		return workflowerrors.NewUpdateRejectedError(err)
+	}
+
+	return nil
+}
+
+func (e *executor) runUpdate(ctx sync.Context, a *history.UpdateRequestedAttributes) (result payload.Payload, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = workflowerrors.NewPanicError(fmt.Sprintf("panic in update handler: %v", r))
+		}
+	}()
+
+	handler, ok := e.workflowState.UpdateHandler(a.Name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", wf.ErrUnknownUpdate, a.Name)
+	}
+
+	return handler.Handle(ctx, a.Arg)
+}
+
+func (e *executor) handleUpdateCompleted(event *history.Event, a *history.UpdateCompletedAttributes) error {
+	c := e.workflowState.CommandByScheduleEventID(event.ScheduleEventID)
+
+	cuc, ok := c.(*command.CompleteUpdateCommand)
+	if !ok || a.UpdateID != cuc.UpdateID {
+		return nonDeterminismError(event, c)
+	}
+
+	cuc.Done()
+
+	return e.workflow.Continue()
+}
+
 func (e *executor) workflowCompleted(result payload.Payload, wfErr error) {
 	eventId := e.workflowState.GetNextScheduleEventID()
 
-	cmd := command.NewCompleteWorkflowCommand(eventId, e.workflowState.Instance(), result, workflowerrors.FromError(wfErr))
+	cmd := command.NewCompleteWorkflowCommand(eventId, e.workflowState.Instance(), result, workflowerrors.FromErrorEncoded(e.cv, wfErr))
+	cmd.Detached = e.detached
 	e.workflowState.AddCommand(cmd)
 }
 
 func (e *executor) workflowRestarted(result payload.Payload, continueAsNew *continueasnew.Error) {
 	eventId := e.workflowState.GetNextScheduleEventID()
 
+	workflowName := e.workflowName
+	if continueAsNew.Name != "" {
+		workflowName = continueAsNew.Name
+	}
+
 	cmd := command.NewContinueAsNewCommand(
-		eventId, e.workflowState.Instance(), result, e.workflowName, continueAsNew.Metadata, continueAsNew.Inputs)
+		eventId, e.workflowState.Instance(), result, workflowName, continueAsNew.Metadata, continueAsNew.Inputs)
+	cmd.Queue = continueAsNew.Queue
+	cmd.RunTimeout = e.runTimeout
+	cmd.ExecutionDeadline = e.executionDeadline
+	cmd.RetryPolicy = e.retryPolicy
+	cmd.ParentClosePolicy = e.parentClosePolicy
+	cmd.Detached = e.detached
+	if continueAsNew.Cause != nil {
+		// The failed execution is retried, it does not have a result
+		cmd.Result = nil
+		cmd.Attempt = continueAsNew.Attempt
+		cmd.Error = workflowerrors.FromErrorEncoded(e.cv, continueAsNew.Cause)
+	}
+	if sa := e.workflowState.SearchAttributes(); len(sa) > 0 {
+		cmd.SearchAttributes = maps.Clone(sa)
+	}
 	e.workflowState.AddCommand(cmd)
 
 	e.workflowSpan.SetAttributes(
@@ -786,6 +1267,57 @@ func (e *executor) workflowRestarted(result payload.Payload, continueAsNew *cont
 	)
 }
 
+// retryWorkflow waits for the backoff of the given retry policy, and restarts the workflow with its original
+// inputs if it can be retried. Otherwise err is returned unchanged.
+func (e *executor) retryWorkflow(ctx sync.Context, a *history.ExecutionStartedAttributes, err error) error {
+	p := a.RetryPolicy
+	attempt := a.Attempt + 1
+
+	// Same policy as for retrying activities and sub-workflows
+	policy := retries.Policy{
+		FirstRetryInterval:     p.FirstRetryInterval,
+		MaxRetryInterval:       p.MaxRetryInterval,
+		BackoffCoefficient:     p.BackoffCoefficient,
+		NonRetryableErrorTypes: p.NonRetryableErrorTypes,
+		Jitter:                 p.Jitter,
+	}
+
+	if attempt >= p.MaxAttempts || errors.Is(err, wf.Canceled) || !policy.CanRetry(err) {
+		return err
+	}
+
+	backoff := policy.Delay(attempt, err, retries.JitterSeed(
+		e.workflowState.Instance(), e.workflowState.PeekNextScheduleEventID(), attempt))
+
+	if p.Deadline != nil && wf.Now(ctx).Add(backoff).After(*p.Deadline) {
+		return err
+	}
+
+	if backoff > 0 {
+		if _, terr := wf.ScheduleTimer(ctx, backoff, wf.WithTimerName("Retry-Backoff")).Get(ctx); terr != nil {
+			return err
+		}
+	}
+
+	return &continueasnew.Error{
+		Metadata: a.Metadata,
+		Inputs:   a.Inputs,
+		Attempt:  attempt,
+		Cause:    err,
+	}
+}
+
+// eventSize returns the approximate size of the given event in the history, the size of the payloads stored
+// with it. Payloads offloaded by the converter only count with the size of their reference.
+func eventSize(event *history.Event) int64 {
+	var size int64
+	for _, p := range history.Payloads(event.Attributes) {
+		size += int64(len(p))
+	}
+
+	return size
+}
+
 func (e *executor) nextSequenceID() int64 {
 	e.lastSequenceID++
 	return e.lastSequenceID
@@ -822,7 +1354,33 @@ func getAttributesLoggingFields(event *history.Event) []any {
 		return []any{
 			log.ActivityNameKey, attributes.Name,
 		}
+	case history.EventType_UpdateRequested:
+		attributes := event.Attributes.(*history.UpdateRequestedAttributes)
+		return []any{
+			log.UpdateNameKey, attributes.Name,
+		}
+	case history.EventType_LocalActivityResult:
+		attributes := event.Attributes.(*history.LocalActivityResultAttributes)
+		return []any{
+			log.ActivityNameKey, attributes.Name,
+		}
 	default:
 		return nil
 	}
 }
+
+// startedEventFirst ensures the started event is executed first. Instances with a delayed start can receive
+// events, e.g. signals, before they are started, and backends might return them in any order.
+func startedEventFirst(events []*history.Event) []*history.Event {
+	idx := slices.IndexFunc(events, func(e *history.Event) bool {
+		return e.Type == history.EventType_WorkflowExecutionStarted
+	})
+	if idx <= 0 {
+		return events
+	}
+
+	r := make([]*history.Event, 0, len(events))
+	r = append(r, events[idx])
+	r = append(r, events[:idx]...)
+	return append(r, events[idx+1:]...)
+}
@@ -2,6 +2,7 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"log"
 	"log/slog"
 	"runtime"
@@ -19,6 +20,7 @@ import (
 	"github.com/cschleiden/go-workflows/internal/command"
 	"github.com/cschleiden/go-workflows/internal/fn"
 	"github.com/cschleiden/go-workflows/internal/sync"
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
 	"github.com/cschleiden/go-workflows/registry"
 	wf "github.com/cschleiden/go-workflows/workflow"
 	"github.com/google/uuid"
@@ -39,7 +41,7 @@ func newExecutor(r *registry.Registry, i *core.WorkflowInstance, historyProvider
 	logger := slog.Default()
 	tracer := noop.NewTracerProvider().Tracer("test")
 
-	e, err := NewExecutor(logger, tracer, r, converter.DefaultConverter, []wf.ContextPropagator{}, historyProvider, i, &metadata.WorkflowMetadata{}, clock.New(), 10_000)
+	e, err := NewExecutor(logger, tracer, r, converter.DefaultConverter, []wf.ContextPropagator{}, historyProvider, i, &metadata.WorkflowMetadata{}, clock.New(), 10_000, NonDeterminismPolicyFailWorkflow)
 
 	return e.(*executor), err
 }
@@ -187,6 +189,537 @@ func Test_Executor(t *testing.T) {
 				require.Len(t, e.workflowState.Commands(), 2)
 			},
 		},
+		{
+			name: "Workflow with version records marker",
+			f: func(t *testing.T, r *registry.Registry, e *executor, i *core.WorkflowInstance, hp *testHistoryProvider) {
+				var version wf.Version
+				workflowWithVersion := func(ctx sync.Context) error {
+					var err error
+					version, err = wf.GetVersion(ctx, "change", wf.DefaultVersion, 1)
+					if err != nil {
+						return err
+					}
+
+					_, err = wf.ExecuteActivity[int](ctx, wf.DefaultActivityOptions, activity1, 42).Get(ctx)
+					return err
+				}
+
+				r.RegisterWorkflow(workflowWithVersion)
+				r.RegisterActivity(activity1)
+
+				task := startWorkflowTask(i.InstanceID, workflowWithVersion)
+
+				result, err := e.ExecuteTask(context.Background(), task)
+				require.NoError(t, err)
+				require.Equal(t, wf.Version(1), version)
+
+				require.Len(t, e.workflowState.Commands(), 2)
+				require.IsType(t, &command.VersionMarkerCommand{}, e.workflowState.Commands()[0])
+
+				var marker *history.Event
+				for _, event := range result.Executed {
+					if event.Type == history.EventType_VersionMarker {
+						marker = event
+					}
+				}
+				require.NotNil(t, marker)
+				require.Equal(t, int64(1), marker.ScheduleEventID)
+				require.Equal(t, &history.VersionMarkerAttributes{ChangeID: "change", Version: 1}, marker.Attributes)
+			},
+		},
+		{
+			name: "Workflow with version replay",
+			f: func(t *testing.T, r *registry.Registry, e *executor, i *core.WorkflowInstance, hp *testHistoryProvider) {
+				var version wf.Version
+				workflowWithVersion := func(ctx sync.Context) error {
+					var err error
+					version, err = wf.GetVersion(ctx, "change", wf.DefaultVersion, 2)
+					if err != nil {
+						return err
+					}
+
+					_, err = wf.ExecuteActivity[int](ctx, wf.DefaultActivityOptions, activity1, 42).Get(ctx)
+					return err
+				}
+
+				r.RegisterWorkflow(workflowWithVersion)
+				r.RegisterActivity(activity1)
+
+				inputs, _ := converter.DefaultConverter.To(42)
+
+				task := continueTask(i.InstanceID, []*history.Event{}, 4)
+
+				hp.history = []*history.Event{
+					history.NewHistoryEvent(
+						1,
+						time.Now(),
+						history.EventType_WorkflowExecutionStarted,
+						&history.ExecutionStartedAttributes{
+							Name:   fn.Name(workflowWithVersion),
+							Inputs: []payload.Payload{},
+						},
+					),
+					history.NewHistoryEvent(
+						2,
+						time.Now(),
+						history.EventType_WorkflowTaskStarted,
+						&history.WorkflowTaskStartedAttributes{},
+					),
+					history.NewHistoryEvent(
+						3,
+						time.Now(),
+						history.EventType_VersionMarker,
+						&history.VersionMarkerAttributes{ChangeID: "change", Version: 1},
+						history.ScheduleEventID(1),
+					),
+					history.NewHistoryEvent(
+						4,
+						time.Now(),
+						history.EventType_ActivityScheduled,
+						&history.ActivityScheduledAttributes{
+							Name:   "activity1",
+							Inputs: []payload.Payload{inputs},
+						},
+						history.ScheduleEventID(2),
+					),
+				}
+
+				_, err := e.ExecuteTask(context.Background(), task)
+				require.NoError(t, err)
+				require.Nil(t, e.workflow.err)
+				require.Equal(t, wf.Version(1), version)
+				require.Empty(t, pendingCommands(e.workflowState.Commands()))
+			},
+		},
+		{
+			name: "Workflow with search attributes replay",
+			f: func(t *testing.T, r *registry.Registry, e *executor, i *core.WorkflowInstance, hp *testHistoryProvider) {
+				var searchAttributes wf.SearchAttributes
+				workflowWithSearchAttributes := func(ctx sync.Context) error {
+					if err := wf.UpsertSearchAttributes(ctx, wf.SearchAttributes{"Region": "eu"}); err != nil {
+						return err
+					}
+
+					var err error
+					searchAttributes, err = wf.GetSearchAttributes(ctx)
+					if err != nil {
+						return err
+					}
+
+					_, err = wf.ExecuteActivity[int](ctx, wf.DefaultActivityOptions, activity1, 42).Get(ctx)
+					return err
+				}
+
+				r.RegisterWorkflow(workflowWithSearchAttributes)
+				r.RegisterActivity(activity1)
+
+				inputs, _ := converter.DefaultConverter.To(42)
+
+				task := continueTask(i.InstanceID, []*history.Event{}, 4)
+
+				hp.history = []*history.Event{
+					history.NewHistoryEvent(
+						1,
+						time.Now(),
+						history.EventType_WorkflowExecutionStarted,
+						&history.ExecutionStartedAttributes{
+							Name:             fn.Name(workflowWithSearchAttributes),
+							Inputs:           []payload.Payload{},
+							SearchAttributes: map[string]string{"CustomerID": `"c-1"`},
+						},
+					),
+					history.NewHistoryEvent(
+						2,
+						time.Now(),
+						history.EventType_WorkflowTaskStarted,
+						&history.WorkflowTaskStartedAttributes{},
+					),
+					history.NewHistoryEvent(
+						3,
+						time.Now(),
+						history.EventType_SearchAttributesUpserted,
+						&history.SearchAttributesUpsertedAttributes{SearchAttributes: map[string]string{"Region": `"eu"`}},
+						history.ScheduleEventID(1),
+					),
+					history.NewHistoryEvent(
+						4,
+						time.Now(),
+						history.EventType_ActivityScheduled,
+						&history.ActivityScheduledAttributes{
+							Name:   "activity1",
+							Inputs: []payload.Payload{inputs},
+						},
+						history.ScheduleEventID(2),
+					),
+				}
+
+				_, err := e.ExecuteTask(context.Background(), task)
+				require.NoError(t, err)
+				require.Nil(t, e.workflow.err)
+				require.Equal(t, wf.SearchAttributes{"CustomerID": "c-1", "Region": "eu"}, searchAttributes)
+				require.Empty(t, pendingCommands(e.workflowState.Commands()))
+			},
+		},
+		{
+			name: "Workflow with update",
+			f: func(t *testing.T, r *registry.Registry, e *executor, i *core.WorkflowInstance, hp *testHistoryProvider) {
+				workflowWithUpdate := func(ctx sync.Context) error {
+					if err := wf.SetUpdateHandler(ctx, "double", nil, func(v int) (int, error) {
+						return v * 2, nil
+					}); err != nil {
+						return err
+					}
+
+					wf.NewSignalChannel[any](ctx, "done").Receive(ctx)
+
+					return nil
+				}
+
+				r.RegisterWorkflow(workflowWithUpdate)
+
+				arg, _ := converter.DefaultConverter.To(21)
+
+				hp.history = []*history.Event{
+					history.NewHistoryEvent(
+						1,
+						time.Now(),
+						history.EventType_WorkflowExecutionStarted,
+						&history.ExecutionStartedAttributes{
+							Name:   fn.Name(workflowWithUpdate),
+							Inputs: []payload.Payload{},
+						},
+					),
+					history.NewHistoryEvent(
+						2,
+						time.Now(),
+						history.EventType_WorkflowTaskStarted,
+						&history.WorkflowTaskStartedAttributes{},
+					),
+				}
+
+				task := continueTask(i.InstanceID, []*history.Event{
+					history.NewPendingEvent(
+						time.Now(),
+						history.EventType_UpdateRequested,
+						&history.UpdateRequestedAttributes{UpdateID: "update-1", Name: "double", Arg: arg},
+					),
+				}, 2)
+
+				result, err := e.ExecuteTask(context.Background(), task)
+				require.NoError(t, err)
+				require.Nil(t, e.workflow.err)
+				require.False(t, e.workflow.Completed())
+
+				completed := result.Executed[len(result.Executed)-1]
+				require.Equal(t, history.EventType_UpdateCompleted, completed.Type)
+
+				a := completed.Attributes.(*history.UpdateCompletedAttributes)
+				require.Equal(t, "update-1", a.UpdateID)
+				require.Nil(t, a.Error)
+
+				var v int
+				require.NoError(t, converter.DefaultConverter.From(a.Result, &v))
+				require.Equal(t, 42, v)
+			},
+		},
+		{
+			name: "Workflow with update rejected after state changed",
+			f: func(t *testing.T, r *registry.Registry, e *executor, i *core.WorkflowInstance, hp *testHistoryProvider) {
+				handled := false
+
+				workflowWithUpdate := func(ctx sync.Context) error {
+					closed := false
+					if err := wf.SetUpdateHandler(ctx, "add",
+						func(v int) error {
+							if closed {
+								return errors.New("closed")
+							}
+
+							return nil
+						},
+						func(v int) error {
+							handled = true
+							return nil
+						}); err != nil {
+						return err
+					}
+
+					wf.NewSignalChannel[any](ctx, "close").Receive(ctx)
+					closed = true
+
+					wf.NewSignalChannel[any](ctx, "done").Receive(ctx)
+
+					return nil
+				}
+
+				r.RegisterWorkflow(workflowWithUpdate)
+
+				arg, _ := converter.DefaultConverter.To(1)
+				signalArg, _ := converter.DefaultConverter.To(nil)
+
+				hp.history = []*history.Event{
+					history.NewHistoryEvent(
+						1,
+						time.Now(),
+						history.EventType_WorkflowExecutionStarted,
+						&history.ExecutionStartedAttributes{
+							Name:   fn.Name(workflowWithUpdate),
+							Inputs: []payload.Payload{},
+						},
+					),
+					history.NewHistoryEvent(
+						2,
+						time.Now(),
+						history.EventType_WorkflowTaskStarted,
+						&history.WorkflowTaskStartedAttributes{},
+					),
+				}
+
+				// The update was valid when it was sent, but the workflow is closed before it's processed
+				task := continueTask(i.InstanceID, []*history.Event{
+					history.NewPendingEvent(
+						time.Now(),
+						history.EventType_SignalReceived,
+						&history.SignalReceivedAttributes{Name: "close", Arg: signalArg},
+					),
+					history.NewPendingEvent(
+						time.Now(),
+						history.EventType_UpdateRequested,
+						&history.UpdateRequestedAttributes{UpdateID: "update-1", Name: "add", Arg: arg},
+					),
+				}, 2)
+
+				result, err := e.ExecuteTask(context.Background(), task)
+				require.NoError(t, err)
+				require.Nil(t, e.workflow.err)
+				require.False(t, handled)
+
+				completed := result.Executed[len(result.Executed)-1]
+				require.Equal(t, history.EventType_UpdateCompleted, completed.Type)
+
+				a := completed.Attributes.(*history.UpdateCompletedAttributes)
+				require.Equal(t, "update-1", a.UpdateID)
+				require.Nil(t, a.Result)
+				require.NotNil(t, a.Error)
+
+				decoded, err := workflowerrors.DecodeError(converter.DefaultConverter, a.Error)
+				require.NoError(t, err)
+
+				updateErr := workflowerrors.ToError(decoded)
+				require.ErrorIs(t, updateErr, wf.ErrUpdateRejected)
+				require.ErrorContains(t, updateErr, "closed")
+			},
+		},
+		{
+			name: "Workflow with local activity",
+			f: func(t *testing.T, r *registry.Registry, e *executor, i *core.WorkflowInstance, hp *testHistoryProvider) {
+				calls := 0
+				localActivity := func(ctx context.Context, v int) (int, error) {
+					calls++
+					return v * 2, nil
+				}
+
+				var result int
+				workflowWithLocalActivity := func(ctx sync.Context) error {
+					var err error
+					result, err = wf.ExecuteLocalActivity[int](ctx, wf.DefaultLocalActivityOptions, localActivity, 21).Get(ctx)
+					return err
+				}
+
+				r.RegisterWorkflow(workflowWithLocalActivity)
+				r.RegisterActivity(localActivity)
+
+				task := startWorkflowTask(i.InstanceID, workflowWithLocalActivity)
+
+				taskResult, err := e.ExecuteTask(context.Background(), task)
+				require.NoError(t, err)
+				require.True(t, e.workflow.Completed())
+				require.Equal(t, 42, result)
+				require.Equal(t, 1, calls)
+				require.Empty(t, taskResult.ActivityEvents)
+
+				require.Equal(t, history.EventType_LocalActivityResult, taskResult.Executed[2].Type)
+				a := taskResult.Executed[2].Attributes.(*history.LocalActivityResultAttributes)
+				require.Equal(t, fn.Name(localActivity), a.Name)
+				require.Equal(t, 1, a.Attempts)
+
+				// Replaying uses the recorded result
+				replayHistory := taskResult.Executed[:3]
+				for idx, event := range replayHistory {
+					event.SequenceID = int64(idx + 1)
+				}
+
+				e, err = newExecutor(r, i, &testHistoryProvider{history: replayHistory})
+				require.NoError(t, err)
+
+				result = 0
+				_, err = e.ExecuteTask(context.Background(), continueTask(i.InstanceID, []*history.Event{}, 3))
+				require.NoError(t, err)
+				require.True(t, e.workflow.Completed())
+				require.Equal(t, 42, result)
+				require.Equal(t, 1, calls)
+			},
+		},
+		{
+			name: "Workflow with local activity retried after timer",
+			f: func(t *testing.T, r *registry.Registry, e *executor, i *core.WorkflowInstance, hp *testHistoryProvider) {
+				calls := 0
+				localActivity := func(ctx context.Context, v int) (int, error) {
+					calls++
+					if calls == 1 {
+						return 0, errors.New("transient")
+					}
+
+					return v * 2, nil
+				}
+
+				var result int
+				workflowWithLocalActivity := func(ctx sync.Context) error {
+					var err error
+					result, err = wf.ExecuteLocalActivity[int](ctx, wf.LocalActivityOptions{
+						RetryOptions: wf.RetryOptions{
+							MaxAttempts:        2,
+							FirstRetryInterval: time.Second,
+							BackoffCoefficient: 1,
+						},
+					}, localActivity, 21).Get(ctx)
+					return err
+				}
+
+				r.RegisterWorkflow(workflowWithLocalActivity)
+				r.RegisterActivity(localActivity)
+
+				// The backoff exceeds the time local activities can be retried within the task
+				e.options.LocalActivityRetryTimeout = time.Millisecond
+
+				taskResult, err := e.ExecuteTask(context.Background(), startWorkflowTask(i.InstanceID, workflowWithLocalActivity))
+				require.NoError(t, err)
+				require.False(t, e.workflow.Completed())
+				require.Equal(t, 1, calls)
+				require.Len(t, taskResult.TimerEvents, 1)
+
+				require.Equal(t, history.EventType_LocalActivityResult, taskResult.Executed[2].Type)
+				a := taskResult.Executed[2].Attributes.(*history.LocalActivityResultAttributes)
+				require.Equal(t, 1, a.Attempts)
+				require.Equal(t, time.Second, a.RetryAfter)
+				require.NotNil(t, a.Error)
+
+				hp.history = append(hp.history, taskResult.Executed...)
+				taskResult, err = e.ExecuteTask(context.Background(), continueTask(i.InstanceID, []*history.Event{
+					taskResult.TimerEvents[0],
+				}, taskResult.Executed[len(taskResult.Executed)-1].SequenceID))
+				require.NoError(t, err)
+				require.True(t, e.workflow.Completed())
+				require.Equal(t, 42, result)
+				require.Equal(t, 2, calls)
+
+				// Replaying uses the recorded results and timer
+				hp.history = append(hp.history, taskResult.Executed...)
+				e, err = newExecutor(r, i, hp)
+				require.NoError(t, err)
+
+				result = 0
+				_, err = e.ExecuteTask(context.Background(), continueTask(i.InstanceID, []*history.Event{}, hp.history[len(hp.history)-1].SequenceID))
+				require.NoError(t, err)
+				require.True(t, e.workflow.Completed())
+				require.Equal(t, 42, result)
+				require.Equal(t, 2, calls)
+			},
+		},
+		{
+			name: "Workflow with version replay without marker",
+			f: func(t *testing.T, r *registry.Registry, e *executor, i *core.WorkflowInstance, hp *testHistoryProvider) {
+				var version wf.Version
+				workflowWithVersion := func(ctx sync.Context) error {
+					var err error
+					version, err = wf.GetVersion(ctx, "change", wf.DefaultVersion, 1)
+					if err != nil {
+						return err
+					}
+
+					_, err = wf.ExecuteActivity[int](ctx, wf.DefaultActivityOptions, activity1, 42).Get(ctx)
+					return err
+				}
+
+				r.RegisterWorkflow(workflowWithVersion)
+				r.RegisterActivity(activity1)
+
+				inputs, _ := converter.DefaultConverter.To(42)
+
+				task := continueTask(i.InstanceID, []*history.Event{}, 3)
+
+				// History written before the change was introduced
+				hp.history = []*history.Event{
+					history.NewHistoryEvent(
+						1,
+						time.Now(),
+						history.EventType_WorkflowExecutionStarted,
+						&history.ExecutionStartedAttributes{
+							Name:   fn.Name(workflowWithVersion),
+							Inputs: []payload.Payload{},
+						},
+					),
+					history.NewHistoryEvent(
+						2,
+						time.Now(),
+						history.EventType_WorkflowTaskStarted,
+						&history.WorkflowTaskStartedAttributes{},
+					),
+					history.NewHistoryEvent(
+						3,
+						time.Now(),
+						history.EventType_ActivityScheduled,
+						&history.ActivityScheduledAttributes{
+							Name:   "activity1",
+							Inputs: []payload.Payload{inputs},
+						},
+						history.ScheduleEventID(1),
+					),
+				}
+
+				_, err := e.ExecuteTask(context.Background(), task)
+				require.NoError(t, err)
+				require.Nil(t, e.workflow.err)
+				require.Equal(t, wf.DefaultVersion, version)
+				require.Empty(t, pendingCommands(e.workflowState.Commands()))
+			},
+		},
+		{
+			name: "Workflow with unsupported version fails",
+			f: func(t *testing.T, r *registry.Registry, e *executor, i *core.WorkflowInstance, hp *testHistoryProvider) {
+				workflowWithVersion := func(ctx sync.Context) error {
+					_, err := wf.GetVersion(ctx, "change", 1, 2)
+					return err
+				}
+
+				r.RegisterWorkflow(workflowWithVersion)
+
+				task := continueTask(i.InstanceID, []*history.Event{}, 2)
+
+				hp.history = []*history.Event{
+					history.NewHistoryEvent(
+						1,
+						time.Now(),
+						history.EventType_WorkflowExecutionStarted,
+						&history.ExecutionStartedAttributes{
+							Name:   fn.Name(workflowWithVersion),
+							Inputs: []payload.Payload{},
+						},
+					),
+					history.NewHistoryEvent(
+						2,
+						time.Now(),
+						history.EventType_WorkflowTaskStarted,
+						&history.WorkflowTaskStartedAttributes{},
+					),
+				}
+
+				_, err := e.ExecuteTask(context.Background(), task)
+				require.NoError(t, err)
+				require.True(t, e.workflow.Completed())
+				require.ErrorContains(t, e.workflow.Error(), `version -1 for change "change" is not supported`)
+			},
+		},
 		{
 			name: "Workflow with new events",
 			f: func(t *testing.T, r *registry.Registry, e *executor, i *core.WorkflowInstance, hp *testHistoryProvider) {
@@ -0,0 +1,62 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/internal/command"
+	wf "github.com/cschleiden/go-workflows/workflow"
+)
+
+// NonDeterminismPolicy determines how the executor handles workflow code that does not produce the same
+// commands when replaying history.
+type NonDeterminismPolicy int
+
+const (
+	// NonDeterminismPolicyFailWorkflow fails the workflow instance with a NonDeterminismError.
+	NonDeterminismPolicyFailWorkflow NonDeterminismPolicy = iota
+
+	// NonDeterminismPolicyBlockWorkflow fails the workflow task without completing it. The task will be retried
+	// until the workflow code is fixed, or the instance is terminated.
+	NonDeterminismPolicyBlockWorkflow
+)
+
+func nonDeterminismError(event *history.Event, c command.Command) error {
+	return &wf.NonDeterminismError{
+		SequenceID: event.SequenceID,
+		Expected:   describeEvent(event),
+		Actual:     describeCommand(c),
+	}
+}
+
+func describeEvent(event *history.Event) string {
+	switch a := event.Attributes.(type) {
+	case *history.ActivityScheduledAttributes:
+		return fmt.Sprintf("%v(%s)", event.Type, a.Name)
+	case *history.SubWorkflowScheduledAttributes:
+		return fmt.Sprintf("%v(%s)", event.Type, a.Name)
+	case *history.TimerScheduledAttributes:
+		return fmt.Sprintf("%v(%s)", event.Type, a.Name)
+	case *history.VersionMarkerAttributes:
+		return fmt.Sprintf("%v(%s)", event.Type, a.ChangeID)
+	default:
+		return event.Type.String()
+	}
+}
+
+func describeCommand(c command.Command) string {
+	switch c := c.(type) {
+	case nil:
+		return "no command"
+	case *command.ScheduleActivityCommand:
+		return fmt.Sprintf("%s(%s)", c.Type(), c.Name)
+	case *command.ScheduleSubWorkflowCommand:
+		return fmt.Sprintf("%s(%s)", c.Type(), c.Name)
+	case *command.ScheduleTimerCommand:
+		return fmt.Sprintf("%s(%s)", c.Type(), c.Name())
+	case *command.VersionMarkerCommand:
+		return fmt.Sprintf("%s(%s)", c.Type(), c.ChangeID)
+	default:
+		return c.Type()
+	}
+}
@@ -0,0 +1,135 @@
+package executor
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/cschleiden/go-workflows/backend/converter"
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/backend/metadata"
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/internal/fn"
+	"github.com/cschleiden/go-workflows/internal/sync"
+	"github.com/cschleiden/go-workflows/registry"
+	wf "github.com/cschleiden/go-workflows/workflow"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func activity2(ctx context.Context, r int) (int, error) {
+	return r, nil
+}
+
+func Test_Executor_NonDeterminism(t *testing.T) {
+	tests := []struct {
+		name     string
+		workflow func(ctx sync.Context) error
+		expected string
+		actual   string
+	}{
+		{
+			name: "Different activity",
+			workflow: func(ctx sync.Context) error {
+				_, err := wf.ExecuteActivity[int](ctx, wf.DefaultActivityOptions, activity2, 42).Get(ctx)
+				return err
+			},
+			expected: "ActivityScheduled(activity1)",
+			actual:   "ScheduleActivity(activity2)",
+		},
+		{
+			name: "Timer instead of activity",
+			workflow: func(ctx sync.Context) error {
+				return wf.Sleep(ctx, time.Second)
+			},
+			expected: "ActivityScheduled(activity1)",
+			actual:   "ScheduleTimer(Sleep)",
+		},
+		{
+			name: "Missing activity",
+			workflow: func(ctx sync.Context) error {
+				return nil
+			},
+			expected: "ActivityScheduled(activity1)",
+			actual:   "no command",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, policy := range []NonDeterminismPolicy{NonDeterminismPolicyFailWorkflow, NonDeterminismPolicyBlockWorkflow} {
+				r := registry.New()
+				require.NoError(t, r.RegisterWorkflow(tt.workflow, registry.WithName("wf")))
+				require.NoError(t, r.RegisterActivity(activity1))
+				require.NoError(t, r.RegisterActivity(activity2))
+
+				i := core.NewWorkflowInstance(uuid.NewString(), "executionID")
+				hp := &testHistoryProvider{history: activityHistory(t)}
+
+				e, err := NewExecutor(
+					slog.Default(), noop.NewTracerProvider().Tracer("test"), r, converter.DefaultConverter,
+					[]wf.ContextPropagator{}, hp, i, &metadata.WorkflowMetadata{}, clock.New(), 10_000, policy)
+				require.NoError(t, err)
+
+				result, err := e.ExecuteTask(context.Background(), continueTask(i.InstanceID, []*history.Event{}, 3))
+
+				var nde *wf.NonDeterminismError
+				if policy == NonDeterminismPolicyBlockWorkflow {
+					require.ErrorAs(t, err, &nde)
+					require.Equal(t, int64(3), nde.SequenceID)
+					require.Equal(t, tt.expected, nde.Expected)
+					require.Equal(t, tt.actual, nde.Actual)
+				} else {
+					require.NoError(t, err)
+					require.Equal(t, core.WorkflowInstanceStateFinished, result.State)
+
+					finished := result.Executed[len(result.Executed)-1]
+					require.Equal(t, history.EventType_WorkflowExecutionFinished, finished.Type)
+
+					a := finished.Attributes.(*history.ExecutionCompletedAttributes)
+					require.Equal(t, "NonDeterminismError", a.Error.Type)
+					require.Contains(t, a.Error.Message, "history event 3 recorded "+tt.expected+", but workflow produced "+tt.actual)
+				}
+
+				e.Close()
+			}
+		})
+	}
+}
+
+func activityHistory(t *testing.T) []*history.Event {
+	inputs, err := converter.DefaultConverter.To(42)
+	require.NoError(t, err)
+
+	return []*history.Event{
+		history.NewHistoryEvent(
+			1,
+			time.Now(),
+			history.EventType_WorkflowExecutionStarted,
+			&history.ExecutionStartedAttributes{
+				Name:   "wf",
+				Inputs: []payload.Payload{},
+			},
+		),
+		history.NewHistoryEvent(
+			2,
+			time.Now(),
+			history.EventType_WorkflowTaskStarted,
+			&history.WorkflowTaskStartedAttributes{},
+		),
+		history.NewHistoryEvent(
+			3,
+			time.Now(),
+			history.EventType_ActivityScheduled,
+			&history.ActivityScheduledAttributes{
+				Name:   fn.Name(activity1),
+				Inputs: []payload.Payload{inputs},
+			},
+			history.ScheduleEventID(1),
+		),
+	}
+}
@@ -18,6 +18,9 @@ type workflow struct {
 	fn     reflect.Value
 	result payload.Payload
 	err    error
+
+	// retry is called with the error of a failed workflow and returns the error to finish the workflow with
+	retry func(ctx sync.Context, err error) error
 }
 
 func newWorkflow(workflowFn reflect.Value) *workflow {
@@ -43,17 +46,12 @@ func (w *workflow) Execute(ctx sync.Context, inputs []payload.Payload) error {
 
 		args[0] = reflect.ValueOf(ctx)
 
-		// Handle panics in workflows
-		defer func() {
-			if r := recover(); r != nil {
-				stack := string(debug.Stack())
-
-				w.err = workflowerrors.NewPanicError(fmt.Sprintf("panic in workflow: %v\n%v", r, stack))
-			}
-		}()
-
 		// Call workflow function
-		r := w.fn.Call(args)
+		r, panicErr := w.call(args)
+		if panicErr != nil {
+			w.err = w.retryOnError(ctx, panicErr)
+			return nil
+		}
 
 		// Process result
 		if len(r) < 1 || len(r) > 2 {
@@ -84,7 +82,7 @@ func (w *workflow) Execute(ctx sync.Context, inputs []payload.Payload) error {
 				return fmt.Errorf("workflow error result does not satisfy error interface (%T): %v", errResult, errResult)
 			}
 
-			w.err = errInterface
+			w.err = w.retryOnError(ctx, errInterface)
 		}
 
 		return nil
@@ -93,6 +91,35 @@ func (w *workflow) Execute(ctx sync.Context, inputs []payload.Payload) error {
 	return w.s.Execute()
 }
 
+// call calls the workflow function, a panic in the workflow is returned as PanicError
+func (w *workflow) call(args []reflect.Value) (r []reflect.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := string(debug.Stack())
+
+			err = workflowerrors.NewPanicError(fmt.Sprintf("panic in workflow: %v\n%v", r, stack))
+		}
+	}()
+
+	return w.fn.Call(args), nil
+}
+
+func (w *workflow) retryOnError(ctx sync.Context, err error) error {
+	if w.retry == nil {
+		return err
+	}
+
+	return w.retry(ctx, err)
+}
+
+// Go starts the given function in a new coroutine next to the workflow function, for example to run an update
+// handler. The workflow is only completed once all coroutines have finished.
+func (w *workflow) Go(ctx sync.Context, fn func(ctx sync.Context) error) error {
+	w.s.NewCoroutine(ctx, fn)
+
+	return w.s.Execute()
+}
+
 func (w *workflow) Continue() error {
 	return w.s.Execute()
 }
@@ -0,0 +1,26 @@
+package workflow
+
+import (
+	"github.com/cschleiden/go-workflows/internal/workflowstate"
+)
+
+// HistoryLength returns the number of events in the history of the current workflow execution.
+func HistoryLength(ctx Context) int64 {
+	wfState := workflowstate.WorkflowState(ctx)
+	return wfState.HistoryLength()
+}
+
+// HistorySizeBytes returns the approximate size of the history of the current workflow execution in bytes, the
+// size of the payloads stored in it.
+func HistorySizeBytes(ctx Context) int64 {
+	wfState := workflowstate.WorkflowState(ctx)
+	return wfState.HistorySizeBytes()
+}
+
+// ContinueAsNewSuggested returns true once the history of the current workflow execution is getting close to the
+// maximum history length or size of the backend. Long-running workflows should then use ContinueAsNew to continue with a
+// fresh history, instead of being failed when the maximum is exceeded.
+func ContinueAsNewSuggested(ctx Context) bool {
+	wfState := workflowstate.WorkflowState(ctx)
+	return wfState.ContinueAsNewSuggested()
+}
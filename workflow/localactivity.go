@@ -0,0 +1,216 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cschleiden/go-workflows/backend/history"
+	"github.com/cschleiden/go-workflows/backend/payload"
+	a "github.com/cschleiden/go-workflows/internal/args"
+	"github.com/cschleiden/go-workflows/internal/command"
+	"github.com/cschleiden/go-workflows/internal/contextvalue"
+	"github.com/cschleiden/go-workflows/internal/fn"
+	"github.com/cschleiden/go-workflows/internal/sync"
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
+	"github.com/cschleiden/go-workflows/internal/workflowstate"
+)
+
+type LocalActivityOptions struct {
+	// RetryOptions defines how to retry the local activity in case of failure. Retries are executed by the
+	// workflow worker while it executes the workflow task, so retry intervals should be short.
+	RetryOptions RetryOptions
+
+	// StartToCloseTimeout is the maximum time a single attempt of the local activity can take. If it is
+	// exceeded, the attempt is failed with ErrActivityTimeout and retried according to RetryOptions. Defaults
+	// to 0, which means no timeout.
+	StartToCloseTimeout time.Duration
+}
+
+var DefaultLocalActivityOptions = LocalActivityOptions{
+	RetryOptions: DefaultRetryOptions,
+}
+
+// ExecuteLocalActivity executes the given activity in the workflow worker, while the current workflow task is
+// being executed, and returns a future that will be resolved with the result of the activity.
+//
+// In contrast to ExecuteActivity, the activity is not scheduled on an activity queue and only its final
+// result is recorded in the history. Local activities have to be registered with the workflow worker, and
+// should only be used for short operations, since they delay the completion of the workflow task.
+func ExecuteLocalActivity[TResult any](ctx Context, options LocalActivityOptions, activity Activity, args ...any) Future[TResult] {
+	f := sync.NewFuture[TResult]()
+
+	if ctx.Err() != nil {
+		f.Set(*new(TResult), ctx.Err())
+		return f
+	}
+
+	// Check return type
+	if err := a.ReturnTypeMatch[TResult](activity); err != nil {
+		f.Set(*new(TResult), err)
+		return f
+	}
+
+	// Check arguments
+	if err := a.ParamsMatch(activity, args...); err != nil {
+		f.Set(*new(TResult), err)
+		return f
+	}
+
+	cv := contextvalue.Converter(ctx)
+	inputs, err := a.ArgsToInputs(cv, args...)
+	if err != nil {
+		f.Set(*new(TResult), fmt.Errorf("converting activity input: %w", err))
+		return f
+	}
+
+	name := fn.Name(activity)
+
+	var retryExpiration time.Time
+	if options.RetryOptions.RetryTimeout > 0 {
+		retryExpiration = Now(ctx).Add(options.RetryOptions.RetryTimeout)
+	}
+
+	af, scheduleEventID := executeLocalActivityAttempts[TResult](ctx, options, name, inputs, 0, retryExpiration)
+
+	if options.RetryOptions.MaxAttempts <= 1 {
+		// Short-circuit if we don't need to retry
+		return af
+	}
+
+	// Start a separate co-routine for retries that don't fit into a workflow task
+	Go(ctx, func(ctx Context) {
+		attempt := 0
+
+		for {
+			result, err := af.Get(ctx)
+
+			retry, ok := workflowstate.WorkflowState(ctx).TakeLocalActivityRetry(scheduleEventID)
+			if err == nil || !ok {
+				f.Set(result, err)
+				return
+			}
+
+			if _, err := ScheduleTimer(ctx, retry.RetryAfter, WithTimerName("LocalActivity-Retry-Backoff")).Get(ctx); err != nil {
+				f.Set(*new(TResult), err)
+				return
+			}
+
+			attempt += retry.Attempts
+			af, scheduleEventID = executeLocalActivityAttempts[TResult](ctx, options, name, inputs, attempt, retryExpiration)
+		}
+	})
+
+	return f
+}
+
+// executeLocalActivityAttempts executes the local activity in the current workflow task, starting with the given
+// attempt, and records the result. Returns the future for the result and its schedule event id.
+func executeLocalActivityAttempts[TResult any](
+	ctx Context, options LocalActivityOptions, name string, inputs []payload.Payload, attempt int, retryExpiration time.Time,
+) (Future[TResult], int64) {
+	f := sync.NewFuture[TResult]()
+
+	cv := contextvalue.Converter(ctx)
+	wfState := workflowstate.WorkflowState(ctx)
+	scheduleEventID := wfState.GetNextScheduleEventID()
+
+	cmd := command.NewLocalActivityCommand(scheduleEventID, name)
+	wfState.AddCommand(cmd)
+
+	s := workflowstate.AsDecodingSettable(cv, fmt.Sprintf("local activity: %s", name), f)
+	wfState.TrackFuture(scheduleEventID, s)
+
+	if Replaying(ctx) {
+		// Result will be set from the history
+		return f, scheduleEventID
+	}
+
+	// Capture context
+	metadata := &Metadata{}
+	if err := injectFromWorkflow(ctx, metadata, propagators(ctx)); err != nil {
+		cmd.Done()
+		wfState.RemoveFuture(scheduleEventID)
+		f.Set(*new(TResult), fmt.Errorf("injecting workflow context: %w", err))
+		return f, scheduleEventID
+	}
+
+	attempts, retryAfter, result, err := executeLocalActivity(ctx, options, &history.ActivityScheduledAttributes{
+		Name:                name,
+		Inputs:              inputs,
+		Metadata:            metadata,
+		StartToCloseTimeout: options.StartToCloseTimeout,
+	}, attempt, retryExpiration)
+
+	var wfErr *workflowerrors.Error
+	if err != nil {
+		wfErr = workflowerrors.FromErrorEncoded(cv, err)
+		err = workflowerrors.ToError(workflowerrors.FromError(err))
+	}
+
+	cmd.SetResult(attempts, result, wfErr)
+	cmd.RetryAfter = retryAfter
+	if retryAfter > 0 {
+		wfState.SetLocalActivityRetry(scheduleEventID, workflowstate.LocalActivityRetry{
+			Attempts:   attempts,
+			RetryAfter: retryAfter,
+		})
+	}
+
+	wfState.RemoveFuture(scheduleEventID)
+
+	if err := s.Set(result, err); err != nil {
+		f.Set(*new(TResult), err)
+	}
+
+	return f, scheduleEventID
+}
+
+// executeLocalActivity executes the local activity with retries, starting with the given attempt. It returns the
+// number of attempts it took, and the delay after which to retry it with a timer, if the local activity can be
+// retried but not within the current workflow task.
+func executeLocalActivity(
+	ctx Context, options LocalActivityOptions, attributes *history.ActivityScheduledAttributes, attempt int, retryExpiration time.Time,
+) (int, time.Duration, payload.Payload, error) {
+	execute := contextvalue.GetLocalActivityExecutor(ctx)
+	if execute == nil {
+		return 1, 0, nil, workflowerrors.NewPermanentError(errors.New("local activities are not supported"))
+	}
+
+	retryOptions := options.RetryOptions
+	deadline := workflowstate.WorkflowState(ctx).LocalActivityRetryDeadline()
+
+	start := time.Now()
+	for attempts := 1; ; attempts++ {
+		attributes.Attempt = attempt
+
+		result, err := execute(attributes)
+		if err == nil {
+			return attempts, 0, result, nil
+		}
+
+		attempt++
+
+		if attempt >= retryOptions.MaxAttempts || !retryOptions.canRetry(err) {
+			return attempts, 0, nil, err
+		}
+
+		backoff := retryOptions.delay(attempt, err, jitterSeed(ctx, attempt))
+		if backoff < 0 {
+			return attempts, 0, nil, err
+		}
+
+		if !retryExpiration.IsZero() && Now(ctx).Add(time.Since(start)+backoff).After(retryExpiration) {
+			// Waiting would reach maximum retry time, abort retries
+			return attempts, 0, nil, err
+		}
+
+		if !deadline.IsZero() && time.Now().Add(backoff).After(deadline) {
+			// Waiting would exceed the time local activities can be retried within the workflow task, wait with a
+			// durable timer instead. Timers need a positive delay.
+			return attempts, max(backoff, time.Millisecond), nil, err
+		}
+
+		time.Sleep(backoff)
+	}
+}
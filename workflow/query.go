@@ -0,0 +1,57 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/cschleiden/go-workflows/internal/args"
+	"github.com/cschleiden/go-workflows/internal/contextvalue"
+	"github.com/cschleiden/go-workflows/internal/workflowstate"
+)
+
+// ErrUnknownQuery is returned when a workflow instance is queried for a query without a registered handler.
+var ErrUnknownQuery = errors.New("unknown query")
+
+// SetQueryHandler registers a handler for the query with the given name. Handlers have to return
+// (result, error) and can optionally accept a workflow.Context as first parameter, followed by the query
+// arguments.
+//
+// Query handlers are invoked after the history of the workflow instance has been replayed. They must not
+// block or change the state of the workflow, and should only read from it.
+func SetQueryHandler(ctx Context, name string, handler interface{}) error {
+	ht := reflect.TypeOf(handler)
+	if ht == nil || ht.Kind() != reflect.Func {
+		return errors.New("query handler must be a function")
+	}
+
+	if ht.NumOut() != 2 || ht.Out(1) != reflect.TypeOf((*error)(nil)).Elem() {
+		return errors.New("query handler must return (result, error)")
+	}
+
+	hv := reflect.ValueOf(handler)
+	cv := contextvalue.Converter(ctx)
+
+	wfState := workflowstate.WorkflowState(ctx)
+	wfState.SetQueryHandler(name, func(inputs []payload.Payload) (payload.Payload, error) {
+		a, addContext, err := args.InputsToArgs(cv, hv, inputs)
+		if err != nil {
+			return nil, fmt.Errorf("converting query arguments: %w", err)
+		}
+
+		if addContext {
+			a[0] = reflect.ValueOf(ctx)
+		}
+
+		r := hv.Call(a)
+
+		if errResult := r[1].Interface(); errResult != nil {
+			return nil, errResult.(error)
+		}
+
+		return cv.To(r[0].Interface())
+	})
+
+	return nil
+}
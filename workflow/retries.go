@@ -1,11 +1,11 @@
 package workflow
 
 import (
-	"math"
 	"time"
 
+	"github.com/cschleiden/go-workflows/internal/retries"
 	"github.com/cschleiden/go-workflows/internal/sync"
-	"github.com/cschleiden/go-workflows/internal/workflowerrors"
+	"github.com/cschleiden/go-workflows/internal/workflowstate"
 )
 
 type RetryOptions struct {
@@ -23,6 +23,22 @@ type RetryOptions struct {
 
 	// Timeout after which retries are aborted
 	RetryTimeout time.Duration
+
+	// NonRetryableErrorTypes lists error types that are not retried. They are matched against the Type of the
+	// returned *Error and of all its causes, which is the name of the Go type of the original error, for example
+	// "TooManyRequestsError" for a *TooManyRequestsError.
+	NonRetryableErrorTypes []string
+
+	// Jitter randomly shortens each delay by up to the given fraction, between 0 and 1, to spread out retries
+	// of many workflows failing at the same time. The random value is derived from the workflow instance, so
+	// it is the same when the workflow is replayed.
+	Jitter float64
+
+	// Backoff, if set, is called with the attempt that is about to be made and the error of the previous
+	// attempt, and returns the time to wait before the attempt. It replaces the calculation based on
+	// FirstRetryInterval, BackoffCoefficient, MaxRetryInterval, and Jitter. Returning a negative duration
+	// aborts retries. Backoff is called while the workflow is executing, it must be deterministic.
+	Backoff func(attempt int, err error) time.Duration
 }
 
 var DefaultRetryOptions = RetryOptions{
@@ -30,6 +46,35 @@ var DefaultRetryOptions = RetryOptions{
 	BackoffCoefficient: 1,
 }
 
+// policy returns the retry policy for these options
+func (r RetryOptions) policy() retries.Policy {
+	return retries.Policy{
+		FirstRetryInterval:     r.FirstRetryInterval,
+		MaxRetryInterval:       r.MaxRetryInterval,
+		BackoffCoefficient:     r.BackoffCoefficient,
+		NonRetryableErrorTypes: r.NonRetryableErrorTypes,
+		Jitter:                 r.Jitter,
+		Backoff:                r.Backoff,
+	}
+}
+
+// delay returns the time to wait before the given attempt, after the previous attempt failed with err. seed
+// is used to derive the jitter and has to be deterministic.
+func (r RetryOptions) delay(attempt int, err error, seed uint64) time.Duration {
+	return r.policy().Delay(attempt, err, seed)
+}
+
+// canRetry returns true if the given error can be retried with these options
+func (r RetryOptions) canRetry(err error) bool {
+	return r.policy().CanRetry(err)
+}
+
+// jitterSeed returns a value that is stable across replays of the workflow, for the next retry of the caller
+func jitterSeed(ctx Context, attempt int) uint64 {
+	wfState := workflowstate.WorkflowState(ctx)
+	return retries.JitterSeed(wfState.Instance(), wfState.PeekNextScheduleEventID(), attempt)
+}
+
 // WithRetries executes the given function with retries.
 func WithRetries[T any](ctx Context, retryOptions RetryOptions, fn func(ctx Context, attempt int) Future[T]) Future[T] {
 	attempt := 0
@@ -73,13 +118,13 @@ func WithRetries[T any](ctx Context, retryOptions RetryOptions, fn func(ctx Cont
 			}
 
 			// If inner fn indicated that we shouldn't retry, abort retries
-			if !workflowerrors.CanRetry(err) {
+			if !retryOptions.canRetry(err) {
 				break
 			}
 
-			backoffDuration := time.Duration(float64(retryOptions.FirstRetryInterval) * math.Pow(retryOptions.BackoffCoefficient, float64(attempt)))
-			if retryOptions.MaxRetryInterval > 0 {
-				backoffDuration = time.Duration(math.Min(float64(backoffDuration), float64(retryOptions.MaxRetryInterval)))
+			backoffDuration := retryOptions.delay(attempt, err, jitterSeed(ctx, attempt))
+			if backoffDuration < 0 {
+				break
 			}
 
 			if !retryExpiration.IsZero() && Now(ctx).Add(backoffDuration).After(retryExpiration) {
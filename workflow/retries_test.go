@@ -0,0 +1,55 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
+	"github.com/stretchr/testify/require"
+)
+
+type retryTestError struct{}
+
+func (*retryTestError) Error() string {
+	return "retry test error"
+}
+
+func TestRetryOptions_CanRetry(t *testing.T) {
+	r := RetryOptions{
+		NonRetryableErrorTypes: []string{"retryTestError"},
+	}
+
+	require.True(t, r.canRetry(workflowerrors.FromError(errors.New("test"))))
+	require.False(t, r.canRetry(workflowerrors.FromError(&retryTestError{})))
+	require.False(t, r.canRetry(workflowerrors.FromError(fmt.Errorf("wrapped: %w", &retryTestError{}))))
+	require.False(t, r.canRetry(workflowerrors.NewPermanentError(errors.New("test"))))
+
+	// Plain Go errors, e.g., returned by local activities
+	require.True(t, r.canRetry(errors.New("test")))
+	require.False(t, r.canRetry(&retryTestError{}))
+	require.False(t, r.canRetry(fmt.Errorf("wrapped: %w", &retryTestError{})))
+}
+
+func TestRetryOptions_Delay(t *testing.T) {
+	r := RetryOptions{
+		FirstRetryInterval: time.Second,
+		BackoffCoefficient: 2,
+		Jitter:             0.5,
+	}
+
+	for seed := uint64(0); seed < 100; seed++ {
+		d := r.delay(1, nil, seed*7919)
+		require.LessOrEqual(t, d, 2*time.Second)
+		require.GreaterOrEqual(t, d, time.Second)
+
+		// Same seed, same delay
+		require.Equal(t, d, r.delay(1, nil, seed*7919))
+	}
+
+	r.Backoff = func(attempt int, err error) time.Duration {
+		return time.Duration(attempt) * time.Minute
+	}
+	require.Equal(t, 3*time.Minute, r.delay(3, nil, 42))
+}
@@ -0,0 +1,38 @@
+package workflow
+
+import (
+	"github.com/cschleiden/go-workflows/core"
+	"github.com/cschleiden/go-workflows/internal/command"
+	"github.com/cschleiden/go-workflows/internal/workflowstate"
+)
+
+// SearchAttributes are custom, indexed attributes of a workflow instance that instances can be listed by.
+type SearchAttributes = core.SearchAttributes
+
+// UpsertSearchAttributes adds or updates the given search attributes of the current workflow instance.
+// Attributes that are not given keep their current value. The update is recorded in the history.
+func UpsertSearchAttributes(ctx Context, searchAttributes SearchAttributes) error {
+	encoded, err := searchAttributes.Encode()
+	if err != nil {
+		return err
+	}
+
+	if len(encoded) == 0 {
+		return nil
+	}
+
+	wfState := workflowstate.WorkflowState(ctx)
+	scheduleEventID := wfState.GetNextScheduleEventID()
+	cmd := command.NewUpsertSearchAttributesCommand(scheduleEventID, encoded)
+	wfState.AddCommand(cmd)
+
+	wfState.UpsertSearchAttributes(encoded)
+
+	return nil
+}
+
+// GetSearchAttributes returns the current search attributes of the workflow instance.
+func GetSearchAttributes(ctx Context) (SearchAttributes, error) {
+	wfState := workflowstate.WorkflowState(ctx)
+	return core.DecodeSearchAttributes(wfState.SearchAttributes())
+}
@@ -2,6 +2,7 @@ package workflow
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/cschleiden/go-workflows/backend/metadata"
 	a "github.com/cschleiden/go-workflows/internal/args"
@@ -22,6 +23,18 @@ type SubWorkflowOptions struct {
 	Queue Queue
 
 	RetryOptions RetryOptions
+
+	// ExecutionTimeout is the maximum time the sub-workflow instance can run, including all executions
+	// continued via ContinueAsNew. Defaults to 0, which means no timeout.
+	ExecutionTimeout time.Duration
+
+	// RunTimeout is the maximum time a single execution of the sub-workflow instance can run. Defaults to 0,
+	// which means no timeout.
+	RunTimeout time.Duration
+
+	// ParentClosePolicy determines what happens to the sub-workflow instance if it is still running when the
+	// calling workflow finishes. Defaults to ParentClosePolicyAbandon.
+	ParentClosePolicy ParentClosePolicy
 }
 
 var (
@@ -51,6 +64,88 @@ func createSubWorkflowInstance[TResult any](ctx Context, options SubWorkflowOpti
 		return f
 	}
 
+	cmd, err := newScheduleSubWorkflowCommand(ctx, options, attempt, wf, a.ReturnTypeMatch[TResult], args...)
+	if err != nil {
+		f.Set(*new(TResult), err)
+		return f
+	}
+
+	scheduleEventID := cmd.ID()
+
+	wfState := workflowstate.WorkflowState(ctx)
+	cv := contextvalue.Converter(ctx)
+
+	wfState.AddCommand(cmd)
+	wfState.TrackFuture(scheduleEventID, workflowstate.AsDecodingSettable(cv, fmt.Sprintf("subworkflow:%s", cmd.Name), f))
+
+	// Check if the channel is cancelable
+	if c, cancelable := ctx.Done().(sync.CancelChannel); cancelable {
+		cancelReceiver := &sync.Receiver[struct{}]{
+			Receive: func(v struct{}, ok bool) {
+				cmd.Cancel()
+				if cmd.State() == command.CommandState_Canceled {
+					// Remove the sub-workflow future from the workflow state and mark it as canceled if it hasn't already fired
+					if fi, ok := f.(sync.FutureInternal[TResult]); ok {
+						if !fi.Ready() {
+							wfState.RemoveFuture(scheduleEventID)
+							f.Set(*new(TResult), Canceled)
+						}
+					}
+				}
+			},
+		}
+
+		c.AddReceiveCallback(cancelReceiver)
+
+		cmd.WhenDone(func() {
+			c.RemoveReceiveCallback(cancelReceiver)
+		})
+	}
+
+	return f
+}
+
+// StartSubWorkflow starts a detached sub-workflow instance of the given workflow. The returned Future is
+// resolved with the sub-workflow instance once it has been started.
+//
+// Unlike with CreateSubWorkflowInstance, the result of a detached sub-workflow is not reported back to the
+// calling workflow, and canceling ctx does not cancel it. RetryOptions are ignored. The sub-workflow keeps
+// running when the calling workflow finishes, unless options set a different ParentClosePolicy.
+func StartSubWorkflow(ctx Context, options SubWorkflowOptions, wf Workflow, args ...any) Future[*Instance] {
+	f := sync.NewFuture[*Instance]()
+
+	// If the context is already canceled, return immediately.
+	if ctx.Err() != nil {
+		f.Set(nil, ctx.Err())
+		return f
+	}
+
+	cmd, err := newScheduleSubWorkflowCommand(ctx, options, 0, wf, nil, args...)
+	if err != nil {
+		f.Set(nil, err)
+		return f
+	}
+
+	cmd.Detached = true
+	cmd.WhenStarted(func(err error) {
+		if err != nil {
+			f.Set(nil, err)
+			return
+		}
+
+		f.Set(cmd.Instance, nil)
+	})
+
+	workflowstate.WorkflowState(ctx).AddCommand(cmd)
+
+	return f
+}
+
+// newScheduleSubWorkflowCommand validates the given workflow and arguments, and returns a command scheduling
+// the sub-workflow. checkReturnType is skipped if nil.
+func newScheduleSubWorkflowCommand(
+	ctx Context, options SubWorkflowOptions, attempt int, wf Workflow, checkReturnType func(fn interface{}) error, args ...any,
+) (*command.ScheduleSubWorkflowCommand, error) {
 	// Check return type
 	var workflowName string
 	if name, ok := wf.(string); ok {
@@ -58,23 +153,22 @@ func createSubWorkflowInstance[TResult any](ctx Context, options SubWorkflowOpti
 	} else {
 		workflowName = fn.Name(wf)
 
-		if err := a.ReturnTypeMatch[TResult](wf); err != nil {
-			f.Set(*new(TResult), err)
-			return f
+		if checkReturnType != nil {
+			if err := checkReturnType(wf); err != nil {
+				return nil, err
+			}
 		}
 
 		// Check arguments
 		if err := a.ParamsMatch(wf, args...); err != nil {
-			f.Set(*new(TResult), err)
-			return f
+			return nil, err
 		}
 	}
 
 	cv := contextvalue.Converter(ctx)
 	inputs, err := a.ArgsToInputs(cv, args...)
 	if err != nil {
-		f.Set(*new(TResult), fmt.Errorf("converting subworkflow input: %w", err))
-		return f
+		return nil, fmt.Errorf("converting subworkflow input: %w", err)
 	}
 
 	wfState := workflowstate.WorkflowState(ctx)
@@ -84,11 +178,10 @@ func createSubWorkflowInstance[TResult any](ctx Context, options SubWorkflowOpti
 	propagators := propagators(ctx)
 	metadata := &metadata.WorkflowMetadata{}
 	if err := injectFromWorkflow(ctx, metadata, propagators); err != nil {
-		f.Set(*new(TResult), fmt.Errorf("injecting workflow context: %w", err))
-		return f
+		return nil, fmt.Errorf("injecting workflow context: %w", err)
 	}
 
-	tracer := workflowstate.WorkflowState(ctx).Tracer()
+	tracer := wfState.Tracer()
 	workflowSpanID := tracing.GetNewSpanID(tracer)
 
 	cmd := command.NewScheduleSubWorkflowCommand(
@@ -101,33 +194,10 @@ func createSubWorkflowInstance[TResult any](ctx Context, options SubWorkflowOpti
 		metadata,
 		workflowSpanID,
 	)
+	cmd.ExecutionTimeout = options.ExecutionTimeout
+	cmd.RunTimeout = options.RunTimeout
+	cmd.Attempt = attempt
+	cmd.ParentClosePolicy = options.ParentClosePolicy
 
-	wfState.AddCommand(cmd)
-	wfState.TrackFuture(scheduleEventID, workflowstate.AsDecodingSettable(cv, fmt.Sprintf("subworkflow:%s", workflowName), f))
-
-	// Check if the channel is cancelable
-	if c, cancelable := ctx.Done().(sync.CancelChannel); cancelable {
-		cancelReceiver := &sync.Receiver[struct{}]{
-			Receive: func(v struct{}, ok bool) {
-				cmd.Cancel()
-				if cmd.State() == command.CommandState_Canceled {
-					// Remove the sub-workflow future from the workflow state and mark it as canceled if it hasn't already fired
-					if fi, ok := f.(sync.FutureInternal[TResult]); ok {
-						if !fi.Ready() {
-							wfState.RemoveFuture(scheduleEventID)
-							f.Set(*new(TResult), Canceled)
-						}
-					}
-				}
-			},
-		}
-
-		c.AddReceiveCallback(cancelReceiver)
-
-		cmd.WhenDone(func() {
-			c.RemoveReceiveCallback(cancelReceiver)
-		})
-	}
-
-	return f
+	return cmd, nil
 }
@@ -0,0 +1,125 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/cschleiden/go-workflows/backend/payload"
+	"github.com/cschleiden/go-workflows/internal/args"
+	"github.com/cschleiden/go-workflows/internal/contextvalue"
+	"github.com/cschleiden/go-workflows/internal/sync"
+	"github.com/cschleiden/go-workflows/internal/workflowerrors"
+	"github.com/cschleiden/go-workflows/internal/workflowstate"
+)
+
+// ErrUnknownUpdate is returned when an update is sent to a workflow instance without a registered handler.
+var ErrUnknownUpdate = errors.New("unknown update")
+
+// ErrUpdateRejected is matched by errors returned when the validator of an update handler rejects an update. Use
+// errors.As with *UpdateRejectedError to get the reason.
+var ErrUpdateRejected = workflowerrors.ErrUpdateRejected
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// SetUpdateHandler registers a handler for the update with the given name. Updates carry a single argument.
+//
+// The validator is optional and can be nil. It has to return an error, can optionally accept a
+// workflow.Context as first parameter, followed by the update argument. Like query handlers, validators must
+// not block or change the state of the workflow. Validators run in the client sending the update, before the
+// update is recorded. An update rejected by the validator is only returned to the caller, it is not recorded in
+// the history of the workflow instance.
+//
+// The handler has to return either (error) or (result, error), can optionally accept a workflow.Context as
+// first parameter, followed by the update argument. Handlers run in their own coroutine, so they can block,
+// for example to execute activities. The workflow instance only completes once all update handlers have
+// returned.
+func SetUpdateHandler(ctx Context, name string, validator interface{}, handler interface{}) error {
+	ht := reflect.TypeOf(handler)
+	if ht == nil || ht.Kind() != reflect.Func {
+		return errors.New("update handler must be a function")
+	}
+
+	errType := reflect.TypeOf((*error)(nil)).Elem()
+	if ht.NumOut() < 1 || ht.NumOut() > 2 || ht.Out(ht.NumOut()-1) != errType {
+		return errors.New("update handler must return either (error) or (result, error)")
+	}
+
+	cv := contextvalue.Converter(ctx)
+	hv := reflect.ValueOf(handler)
+
+	h := &workflowstate.UpdateHandler{
+		Handle: func(ctx sync.Context, arg payload.Payload) (payload.Payload, error) {
+			a, addContext, err := args.InputsToArgs(cv, hv, updateInputs(ht, arg))
+			if err != nil {
+				return nil, fmt.Errorf("converting update argument: %w", err)
+			}
+
+			if addContext {
+				a[0] = reflect.ValueOf(ctx)
+			}
+
+			r := hv.Call(a)
+
+			if errResult := r[len(r)-1].Interface(); errResult != nil {
+				return nil, errResult.(error)
+			}
+
+			if len(r) == 1 {
+				return cv.To(nil)
+			}
+
+			return cv.To(r[0].Interface())
+		},
+	}
+
+	if validator != nil {
+		vt := reflect.TypeOf(validator)
+		if vt.Kind() != reflect.Func {
+			return errors.New("update validator must be a function")
+		}
+
+		if vt.NumOut() != 1 || vt.Out(0) != errType {
+			return errors.New("update validator must return (error)")
+		}
+
+		vv := reflect.ValueOf(validator)
+
+		h.Validate = func(arg payload.Payload) error {
+			a, addContext, err := args.InputsToArgs(cv, vv, updateInputs(vt, arg))
+			if err != nil {
+				return fmt.Errorf("converting update argument: %w", err)
+			}
+
+			if addContext {
+				a[0] = reflect.ValueOf(ctx)
+			}
+
+			if errResult := vv.Call(a)[0].Interface(); errResult != nil {
+				return errResult.(error)
+			}
+
+			return nil
+		}
+	}
+
+	wfState := workflowstate.WorkflowState(ctx)
+	wfState.SetUpdateHandler(name, h)
+
+	return nil
+}
+
+// updateInputs returns the inputs for the given handler or validator, which might not accept the update argument
+func updateInputs(fnType reflect.Type, arg payload.Payload) []payload.Payload {
+	numArgs := fnType.NumIn()
+	if numArgs > 0 && (args.IsOwnContext(fnType.In(0)) || fnType.In(0).Implements(contextType)) {
+		numArgs--
+	}
+
+	if numArgs == 0 {
+		return nil
+	}
+
+	return []payload.Payload{arg}
+}
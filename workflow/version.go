@@ -0,0 +1,69 @@
+package workflow
+
+import (
+	"fmt"
+
+	"github.com/cschleiden/go-workflows/internal/command"
+	"github.com/cschleiden/go-workflows/internal/workflowstate"
+)
+
+// Version identifies a version of workflow code for a given change.
+type Version int
+
+// DefaultVersion is returned by GetVersion for executions that ran the workflow code before
+// the change was introduced.
+const DefaultVersion Version = -1
+
+// GetVersion returns the version of the workflow code to use for the given change. This allows
+// changing the code of a workflow while instances are still running:
+//
+//	v, err := workflow.GetVersion(ctx, "new-activity", workflow.DefaultVersion, 1)
+//	if err != nil {
+//		return err
+//	}
+//
+//	if v == workflow.DefaultVersion {
+//		// Old code path
+//	} else {
+//		// New code path
+//	}
+//
+// New executions will get maxSupported, and that version is recorded in the history. Executions
+// that replay history get the version that was recorded, or DefaultVersion if the change did not
+// exist when the history was written. An error is returned if the version is outside of the
+// range [minSupported, maxSupported].
+func GetVersion(ctx Context, changeID string, minSupported, maxSupported Version) (Version, error) {
+	wfState := workflowstate.WorkflowState(ctx)
+
+	v, ok := wfState.Version(changeID)
+	if !ok {
+		record := true
+
+		if wfState.Replaying() {
+			v, record = wfState.RecordedVersion(changeID)
+			if !record {
+				// The change was introduced after this part of the workflow was executed, nothing
+				// has been recorded in the history.
+				v = int(DefaultVersion)
+			}
+		} else {
+			v = int(maxSupported)
+		}
+
+		if record {
+			scheduleEventID := wfState.GetNextScheduleEventID()
+			cmd := command.NewVersionMarkerCommand(scheduleEventID, changeID, v)
+			wfState.AddCommand(cmd)
+		}
+
+		wfState.SetVersion(changeID, v)
+	}
+
+	version := Version(v)
+	if version < minSupported || version > maxSupported {
+		return version, fmt.Errorf(
+			"version %d for change %q is not supported, supported versions are [%d, %d]", version, changeID, minSupported, maxSupported)
+	}
+
+	return version, nil
+}